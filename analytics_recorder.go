@@ -0,0 +1,68 @@
+package lightstep
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// Outcomes recorded under the "outcome" key of an AnalyticsEvent.
+const (
+	AnalyticsOutcomeOK    = "ok"
+	AnalyticsOutcomeError = "error"
+)
+
+// AnalyticsEvent is a finished span flattened into key/value pairs, for
+// pipelines that load events into a data warehouse. It has the keys:
+//
+//	trace_id, span_id, parent_span_id  hex-encoded IDs, parent_span_id is
+//	                                   omitted for root spans
+//	operation                          the operation name
+//	start                              the start time, a time.Time
+//	duration_ms                        the duration in milliseconds, a float64
+//	outcome                            AnalyticsOutcomeOK, or AnalyticsOutcomeError
+//	                                   if the span has a true "error" tag
+//	tag.<key>                          each span tag
+//	baggage.<key>                      each baggage item
+type AnalyticsEvent map[string]interface{}
+
+// AnalyticsRecorder is a SpanRecorder that passes every finished span, as an
+// AnalyticsEvent, to the function. Set it as Options.Recorder:
+//
+//	opts.Recorder = lightstep.AnalyticsRecorder(func(e lightstep.AnalyticsEvent) {
+//	    pipeline.Send("span", e)
+//	})
+//
+// The function is called from Span.Finish, so it should not block.
+type AnalyticsRecorder func(AnalyticsEvent)
+
+// RecordSpan satisfies the SpanRecorder interface.
+func (record AnalyticsRecorder) RecordSpan(raw RawSpan) {
+	record(newAnalyticsEvent(raw))
+}
+
+func newAnalyticsEvent(raw RawSpan) AnalyticsEvent {
+	event := make(AnalyticsEvent, 6+len(raw.Tags)+len(raw.Context.Baggage))
+	event["trace_id"] = formatTraceID(raw.Context.TraceIDHigh, raw.Context.TraceID)
+	event["span_id"] = strconv.FormatUint(raw.Context.SpanID, 16)
+	if raw.ParentSpanID != 0 {
+		event["parent_span_id"] = strconv.FormatUint(raw.ParentSpanID, 16)
+	}
+	event["operation"] = raw.Operation
+	event["start"] = raw.Start
+	event["duration_ms"] = float64(raw.Duration) / float64(time.Millisecond)
+
+	event["outcome"] = AnalyticsOutcomeOK
+	if failed, _ := raw.Tags[string(ext.Error)].(bool); failed {
+		event["outcome"] = AnalyticsOutcomeError
+	}
+
+	for k, v := range raw.Tags {
+		event["tag."+k] = v
+	}
+	for k, v := range raw.Context.Baggage {
+		event["baggage."+k] = v
+	}
+	return event
+}
@@ -0,0 +1,59 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"strconv"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+var _ = Describe("AnalyticsRecorder", func() {
+	var tracer Tracer
+	var events []AnalyticsEvent
+
+	BeforeEach(func() {
+		events = nil
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder: AnalyticsRecorder(func(event AnalyticsEvent) {
+				events = append(events, event)
+			}),
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("flattens finished spans into events", func() {
+		parent := tracer.StartSpan("parent")
+		parent.SetBaggageItem("tenant", "acme")
+		child := tracer.StartSpan("child", opentracing.ChildOf(parent.Context()))
+		ext.Error.Set(child, true)
+		child.SetTag("cache.hit", false)
+		child.Finish()
+		parent.Finish()
+
+		Expect(events).To(HaveLen(2))
+		childEvent, parentEvent := events[0], events[1]
+
+		parentContext := parent.Context().(SpanContext)
+		Expect(parentEvent["trace_id"]).To(Equal(strconv.FormatUint(parentContext.TraceID, 16)))
+		Expect(parentEvent).NotTo(HaveKey("parent_span_id"))
+		Expect(parentEvent["outcome"]).To(Equal(AnalyticsOutcomeOK))
+		Expect(parentEvent["duration_ms"]).To(BeNumerically(">=", 0))
+
+		Expect(childEvent["operation"]).To(Equal("child"))
+		Expect(childEvent["parent_span_id"]).To(Equal(strconv.FormatUint(parentContext.SpanID, 16)))
+		Expect(childEvent["outcome"]).To(Equal(AnalyticsOutcomeError))
+		Expect(childEvent["tag.cache.hit"]).To(Equal(false))
+		Expect(childEvent["baggage.tenant"]).To(Equal("acme"))
+	})
+})
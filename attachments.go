@@ -0,0 +1,79 @@
+package lightstep
+
+import (
+	ot "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// AttachmentsKey is the tag key recording how many of a span's log values
+// were moved to the Options.AttachmentStore.
+const AttachmentsKey = "lightstep.attachments"
+
+// Attachment is a log value too large to report inline with its span.
+type Attachment struct {
+	TraceID uint64
+	SpanID  uint64
+	Key     string
+	Value   string
+}
+
+// AttachmentStore ships large log values separately from span reports, e.g.
+// to a blob store, keeping reports small. Store returns a reference to the
+// stored value, which is reported in its place. Store is called when a span
+// finishes, so it should not block; if it returns an error, the value is
+// reported inline and truncated to Options.MaxLogValueLen.
+type AttachmentStore interface {
+	Store(Attachment) (ref string, err error)
+}
+
+// newAttachmentSpiller moves the string log values longer than threshold
+// to store.
+func newAttachmentSpiller(store AttachmentStore, threshold int) spanProcessor {
+	return func(span *RawSpan) bool {
+		var logs []ot.LogRecord
+		var stored int
+		for i, record := range span.Logs {
+			var fields []log.Field
+			for j, field := range record.Fields {
+				value, ok := field.Value().(string)
+				if !ok || len(value) <= threshold {
+					continue
+				}
+				ref, err := store.Store(Attachment{
+					TraceID: span.Context.TraceID,
+					SpanID:  span.Context.SpanID,
+					Key:     field.Key(),
+					Value:   value,
+				})
+				if err != nil {
+					continue
+				}
+				if fields == nil {
+					// Copy, as the logs may be shared with a SpanRecorder.
+					fields = append([]log.Field(nil), record.Fields...)
+				}
+				fields[j] = log.String(field.Key(), ref)
+				stored++
+			}
+			if fields == nil {
+				continue
+			}
+			if logs == nil {
+				logs = append([]ot.LogRecord(nil), span.Logs...)
+			}
+			logs[i].Fields = fields
+		}
+		if logs == nil {
+			return true
+		}
+
+		tags := make(ot.Tags, len(span.Tags)+1)
+		for k, v := range span.Tags {
+			tags[k] = v
+		}
+		tags[AttachmentsKey] = stored
+		span.Logs = logs
+		span.Tags = tags
+		return true
+	}
+}
@@ -0,0 +1,92 @@
+package lightstep
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes how long the tracer waits before retrying an operation
+// that has failed, such as sending a report or reconnecting to the
+// collector. Implementations must be safe for concurrent use.
+type Backoff interface {
+	// Delay returns the wait before the next attempt. attempt is the number
+	// of consecutive failures, starting at 1, and previous is the delay
+	// returned for the previous attempt, or zero.
+	Delay(attempt int, previous time.Duration) time.Duration
+}
+
+// ConstantBackoff waits the same duration after every failure.
+type ConstantBackoff time.Duration
+
+// Delay implements Backoff.
+func (b ConstantBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	return time.Duration(b)
+}
+
+// ExponentialBackoff waits Base after the first failure, multiplying the wait
+// by Multiplier (2 if unset) after each further failure, up to Max, if set.
+type ExponentialBackoff struct {
+	Base       time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// Delay implements Backoff.
+func (b ExponentialBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	max := b.Max
+	if max <= 0 {
+		max = math.MaxInt64
+	}
+	delay := float64(b.Base)
+	for i := 1; i < attempt && delay < float64(max); i++ {
+		delay *= multiplier
+	}
+	if delay >= float64(max) {
+		return max
+	}
+	return time.Duration(delay)
+}
+
+// DecorrelatedJitterBackoff waits a random duration between Base and three
+// times the previous wait, up to Max, which spreads out the retries of many
+// tracers failing at the same time.
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay implements Backoff.
+func (b DecorrelatedJitterBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	upper := 3 * previous
+	if upper <= b.Base {
+		return b.Base
+	}
+	delay := b.Base + time.Duration(rand.Int63n(int64(upper-b.Base)))
+	if b.Max > 0 && delay > b.Max {
+		return b.Max
+	}
+	return delay
+}
+
+// backoffState tracks consecutive failures of one operation.
+type backoffState struct {
+	failures int
+	delay    time.Duration
+}
+
+// fail records a failure, returning the wait before the next attempt.
+func (s *backoffState) fail(backoff Backoff) time.Duration {
+	s.failures++
+	s.delay = backoff.Delay(s.failures, s.delay)
+	return s.delay
+}
+
+func (s *backoffState) reset() {
+	s.failures = 0
+	s.delay = 0
+}
@@ -0,0 +1,92 @@
+package lightstep
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backoff", func() {
+	Describe("ConstantBackoff", func() {
+		It("always waits the same duration", func() {
+			backoff := ConstantBackoff(time.Second)
+			Expect(backoff.Delay(1, 0)).To(Equal(time.Second))
+			Expect(backoff.Delay(10, time.Second)).To(Equal(time.Second))
+		})
+	})
+
+	Describe("ExponentialBackoff", func() {
+		It("grows by the multiplier up to the maximum", func() {
+			backoff := ExponentialBackoff{Base: time.Second, Max: 10 * time.Second}
+			Expect(backoff.Delay(1, 0)).To(Equal(time.Second))
+			Expect(backoff.Delay(2, 0)).To(Equal(2 * time.Second))
+			Expect(backoff.Delay(3, 0)).To(Equal(4 * time.Second))
+			Expect(backoff.Delay(5, 0)).To(Equal(10 * time.Second))
+			Expect(backoff.Delay(1000, 0)).To(Equal(10 * time.Second))
+		})
+
+		It("grows without a maximum", func() {
+			backoff := ExponentialBackoff{Base: time.Second}
+			Expect(backoff.Delay(1, 0)).To(Equal(time.Second))
+			Expect(backoff.Delay(2, 0)).To(Equal(2 * time.Second))
+			Expect(backoff.Delay(5, 0)).To(Equal(16 * time.Second))
+			Expect(backoff.Delay(1000, 0)).To(Equal(time.Duration(math.MaxInt64)))
+		})
+	})
+
+	Describe("DecorrelatedJitterBackoff", func() {
+		It("stays within its bounds", func() {
+			backoff := DecorrelatedJitterBackoff{Base: time.Second, Max: 10 * time.Second}
+			var state backoffState
+			for i := 0; i < 100; i++ {
+				delay := state.fail(backoff)
+				Expect(delay).To(BeNumerically(">=", time.Second))
+				Expect(delay).To(BeNumerically("<=", 10*time.Second))
+			}
+		})
+	})
+
+	Describe("tracer retries", func() {
+		var tracer *tracerImpl
+
+		BeforeEach(func() {
+			tracer = &tracerImpl{
+				opts: Options{
+					MinReportingPeriod: time.Second,
+					ReportingPeriod:    2 * time.Second,
+					Backoff:            ConstantBackoff(time.Minute),
+				},
+				reportingPeriod: 2 * time.Second,
+				buffer:          newSpansBuffer(10, 0),
+			}
+		})
+
+		It("waits for the backoff after a failed report", func() {
+			now := time.Now()
+			tracer.lastReportAttempt = now
+			Expect(tracer.shouldFlushLocked(now.Add(5 * time.Second))).To(BeTrue())
+
+			tracer.reportBackoff.fail(tracer.opts.Backoff)
+			Expect(tracer.shouldFlushLocked(now.Add(5 * time.Second))).To(BeFalse())
+			Expect(tracer.shouldFlushLocked(now.Add(time.Minute))).To(BeTrue())
+		})
+
+		It("waits for the backoff after a failed reconnect", func() {
+			tracer.client = &failingConnectClient{}
+			now := time.Now()
+			tracer.reconnectClient(now)
+			Expect(tracer.nextReconnect).To(Equal(now.Add(time.Minute)))
+		})
+	})
+})
+
+type failingConnectClient struct {
+	collectorClient
+}
+
+func (*failingConnectClient) ConnectClient() (Connection, error) {
+	return nil, errors.New("unreachable")
+}
@@ -0,0 +1,106 @@
+package lightstep
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// BaggageLimitsOptions bounds the baggage of span contexts, which the
+// propagators inject into every outgoing request, so that it can't grow
+// into headers that proxies reject. Baggage items set with SetBaggageItem,
+// and those of extracted span contexts, are limited. A zero limit is
+// unlimited.
+type BaggageLimitsOptions struct {
+	// MaxItems is the most baggage items of a span context. Items with new
+	// keys beyond it are dropped; existing items may still be replaced.
+	MaxItems int `yaml:"max_items"`
+	// MaxKeyLength is the longest key of a baggage item, in bytes. Items
+	// with longer keys are dropped, as a truncated key would name another
+	// item.
+	MaxKeyLength int `yaml:"max_key_length"`
+	// MaxValueLength is the longest value of a baggage item, in bytes.
+	// Longer values are truncated, without cutting a UTF-8 encoded
+	// character.
+	MaxValueLength int `yaml:"max_value_length"`
+}
+
+func (l BaggageLimitsOptions) enabled() bool {
+	return l.MaxItems > 0 || l.MaxKeyLength > 0 || l.MaxValueLength > 0
+}
+
+func (l BaggageLimitsOptions) validate() error {
+	if l.MaxItems < 0 || l.MaxKeyLength < 0 || l.MaxValueLength < 0 {
+		return validationErrorBaggageLimits
+	}
+	return nil
+}
+
+// setItem returns sc with the baggage item set, within the limits, and the
+// EventBaggageLimited to emit if the item was dropped or truncated. The
+// event is returned rather than emitted so callers can emit it after
+// releasing their locks.
+func (l BaggageLimitsOptions) setItem(sc SpanContext, key, val string) (SpanContext, Event) {
+	if l.MaxKeyLength > 0 && len(key) > l.MaxKeyLength {
+		return sc, newEventBaggageLimited(sc, key, val, baggageLimitKeyLength)
+	}
+	if _, replaced := sc.Baggage[key]; !replaced && l.MaxItems > 0 && len(sc.Baggage) >= l.MaxItems {
+		return sc, newEventBaggageLimited(sc, key, val, baggageLimitItems)
+	}
+	if l.MaxValueLength > 0 && len(val) > l.MaxValueLength {
+		return sc.WithBaggageItem(key, truncateBaggageValue(val, l.MaxValueLength)),
+			newEventBaggageLimited(sc, key, val, baggageLimitValueLength)
+	}
+	return sc.WithBaggageItem(key, val), nil
+}
+
+// limit returns sc with its baggage within the limits, emitting an
+// EventBaggageLimited for each item dropped or truncated. When there are
+// too many items, those with the first keys in sorted order are kept. The
+// limited baggage is built in a single pass, as extracted contexts may
+// carry many items.
+func (l BaggageLimitsOptions) limit(sc SpanContext) SpanContext {
+	if !l.enabled() || len(sc.Baggage) == 0 {
+		return sc
+	}
+	keys := make([]string, 0, len(sc.Baggage))
+	for key := range sc.Baggage {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var events []Event
+	baggage := make(map[string]string, len(sc.Baggage))
+	for _, key := range keys {
+		val := sc.Baggage[key]
+		switch {
+		case l.MaxKeyLength > 0 && len(key) > l.MaxKeyLength:
+			events = append(events, newEventBaggageLimited(sc, key, val, baggageLimitKeyLength))
+			continue
+		case l.MaxItems > 0 && len(baggage) >= l.MaxItems:
+			events = append(events, newEventBaggageLimited(sc, key, val, baggageLimitItems))
+			continue
+		case l.MaxValueLength > 0 && len(val) > l.MaxValueLength:
+			events = append(events, newEventBaggageLimited(sc, key, val, baggageLimitValueLength))
+			val = truncateBaggageValue(val, l.MaxValueLength)
+		}
+		baggage[key] = val
+	}
+	if len(events) == 0 {
+		return sc
+	}
+	for _, event := range events {
+		emitEvent(event)
+	}
+	// Use positional parameters so the compiler will help catch new fields.
+	return SpanContext{sc.TraceID, sc.TraceIDHigh, sc.SpanID, baggage, sc.passThrough, sc.traceState, &carrierCache{}}
+}
+
+// truncateBaggageValue returns the longest prefix of val at most max bytes
+// long that ends on a character boundary.
+func truncateBaggageValue(val string, max int) string {
+	cut := max
+	for cut > 0 && !utf8.RuneStart(val[cut]) {
+		cut--
+	}
+	return val[:cut]
+}
@@ -0,0 +1,154 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("BaggageLimits", func() {
+	var tracer Tracer
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			BaggageLimits: BaggageLimitsOptions{
+				MaxItems:       2,
+				MaxKeyLength:   8,
+				MaxValueLength: 6,
+			},
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	// limitedEvents returns the EventBaggageLimited emitted.
+	limitedEvents := func() []EventBaggageLimited {
+		var events []EventBaggageLimited
+		for {
+			select {
+			case event := <-eventChan:
+				if limited, ok := event.(EventBaggageLimited); ok {
+					events = append(events, limited)
+				}
+			default:
+				return events
+			}
+		}
+	}
+
+	It("keeps baggage within the limits", func() {
+		span := tracer.StartSpan("span")
+		defer span.Finish()
+		span.SetBaggageItem("user", "alice")
+		Expect(span.BaggageItem("user")).To(Equal("alice"))
+		Expect(limitedEvents()).To(BeEmpty())
+	})
+
+	It("truncates long values", func() {
+		span := tracer.StartSpan("span")
+		defer span.Finish()
+		span.SetBaggageItem("user", "alice-bob")
+		span.SetBaggageItem("name", "chlorë")
+		Expect(span.BaggageItem("user")).To(Equal("alice-"))
+		Expect(span.BaggageItem("name")).To(Equal("chlor"))
+
+		events := limitedEvents()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Key()).To(Equal("user"))
+		Expect(events[0].Length()).To(Equal(9))
+		Expect(events[0].Truncated()).To(BeTrue())
+	})
+
+	It("drops items with long keys", func() {
+		span := tracer.StartSpan("span")
+		defer span.Finish()
+		span.SetBaggageItem("a-long-key", "v")
+		Expect(span.BaggageItem("a-long-key")).To(BeEmpty())
+
+		events := limitedEvents()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Key()).To(Equal("a-long-key"))
+		Expect(events[0].Truncated()).To(BeFalse())
+	})
+
+	It("drops items beyond MaxItems, but replaces existing ones", func() {
+		span := tracer.StartSpan("span")
+		defer span.Finish()
+		span.SetBaggageItem("a", "1")
+		span.SetBaggageItem("b", "2")
+		span.SetBaggageItem("c", "3")
+		span.SetBaggageItem("a", "4")
+		Expect(span.BaggageItem("a")).To(Equal("4"))
+		Expect(span.BaggageItem("b")).To(Equal("2"))
+		Expect(span.BaggageItem("c")).To(BeEmpty())
+
+		events := limitedEvents()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Key()).To(Equal("c"))
+	})
+
+	It("identifies 128-bit traces in the event", func() {
+		parent := SpanContext{TraceIDHigh: 0xabc, TraceID: 0x123, SpanID: 1}
+		span := tracer.StartSpan("span", opentracing.ChildOf(parent))
+		defer span.Finish()
+		span.SetBaggageItem("a", "1234567890")
+
+		events := limitedEvents()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].TraceID()).To(Equal("abc0000000000000123"))
+	})
+
+	It("limits the baggage of extracted span contexts", func() {
+		carrier := opentracing.TextMapCarrier{
+			"ot-tracer-traceid": "1",
+			"ot-tracer-spanid":  "2",
+			"ot-tracer-sampled": "true",
+			"ot-baggage-a":      "1234567890",
+			"ot-baggage-b":      "2",
+			"ot-baggage-c":      "3",
+		}
+		sc, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sc.(SpanContext).Baggage).To(Equal(map[string]string{"a": "123456", "b": "2"}))
+		Expect(limitedEvents()).To(HaveLen(2))
+	})
+
+	It("limits the baggage merged from several references", func() {
+		first := SpanContext{TraceID: 1, SpanID: 2, Baggage: map[string]string{"a": "1", "b": "2"}}
+		second := SpanContext{TraceID: 3, SpanID: 4, Baggage: map[string]string{"c": "3", "d": "4"}}
+		span := tracer.StartSpan("span", opentracing.ChildOf(first), opentracing.FollowsFrom(second))
+		defer span.Finish()
+
+		Expect(span.Context().(SpanContext).Baggage).To(Equal(map[string]string{"a": "1", "b": "2"}))
+		Expect(limitedEvents()).To(HaveLen(2))
+
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(span.Context(), opentracing.TextMap, carrier)).To(Succeed())
+		Expect(carrier).NotTo(HaveKey("ot-baggage-c"))
+	})
+
+	It("rejects negative limits", func() {
+		opts := Options{
+			AccessToken:   "ACCESS_TOKEN",
+			BaggageLimits: BaggageLimitsOptions{MaxItems: -1},
+		}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
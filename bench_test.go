@@ -1,7 +1,11 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
 package lightstep_test
 
 import (
 	"bytes"
+	"fmt"
 	"net/http"
 	"sync/atomic"
 	"testing"
@@ -180,3 +184,52 @@ func BenchmarkJoin_TextMap_Empty(b *testing.B) {
 func BenchmarkJoin_TextMap_100BaggageItems(b *testing.B) {
 	benchmarkExtract(b, ot.TextMap, 100)
 }
+
+// BenchmarkJoin_TextMap_10000BaggageItems_Limited measures extracting
+// contexts whose baggage is far beyond Options.BaggageLimits.
+func BenchmarkJoin_TextMap_10000BaggageItems_Limited(b *testing.B) {
+	SetGlobalEventHandler(func(Event) {})
+	defer SetGlobalEventHandler(NewEventLogOneError())
+	tracer := NewTracer(Options{
+		AccessToken:   "token",
+		ConnFactory:   fakeGrpcConnection(new(cpbfakes.FakeCollectorServiceClient)),
+		BaggageLimits: BaggageLimitsOptions{MaxItems: 100, MaxValueLength: 8},
+	})
+	carrier := ot.TextMapCarrier{
+		"ot-tracer-traceid": "1",
+		"ot-tracer-spanid":  "2",
+		"ot-tracer-sampled": "true",
+	}
+	for i := 0; i < 10000; i++ {
+		carrier[fmt.Sprintf("ot-baggage-item%05d", i)] = "truncated value"
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tracer.Extract(ot.TextMap, carrier); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSpan_Unsampled measures the spans of traces dropped by caller
+// sampling, for comparison with BenchmarkSpan_NoopTracer.
+func BenchmarkSpan_Unsampled(b *testing.B) {
+	t := NewTracer(Options{
+		AccessToken: "token",
+		ConnFactory: fakeGrpcConnection(new(cpbfakes.FakeCollectorServiceClient)),
+		CallerSampling: CallerSamplingOptions{
+			BaggageKey:  "caller",
+			SampleRates: map[string]float64{"": 0},
+		},
+	})
+	benchmarkWithOpsAndCB(b, func() ot.Span {
+		return t.StartSpan("test")
+	}, 10, 10, 0)
+}
+
+func BenchmarkSpan_NoopTracer(b *testing.B) {
+	var t ot.NoopTracer
+	benchmarkWithOpsAndCB(b, func() ot.Span {
+		return t.StartSpan("test")
+	}, 10, 10, 0)
+}
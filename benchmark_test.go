@@ -1,3 +1,6 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
 package lightstep_test
 
 import (
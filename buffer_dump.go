@@ -0,0 +1,43 @@
+package lightstep
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// BufferDump is the JSON document written by Tracer.DumpBuffer.
+type BufferDump struct {
+	// Spans are the finished spans waiting for the next report.
+	Spans []ForwardedSpan `json:"spans"`
+	// FlushingSpans are the spans of the report in progress, if any. They
+	// return to Spans if the report fails.
+	FlushingSpans []ForwardedSpan `json:"flushing_spans"`
+	// DroppedSpans counts the spans dropped since the last report because
+	// the buffer was full.
+	DroppedSpans int64 `json:"dropped_spans"`
+}
+
+// DumpBuffer writes the spans buffered by the tracer, which have not yet
+// been reported, to w as a JSON BufferDump. Spans are in the format of
+// NewForwardedSpan. Spans held by Options.TailSampling until their trace
+// is sampled are not included.
+func (tracer *tracerImpl) DumpBuffer(w io.Writer) error {
+	tracer.lock.Lock()
+	buffered := append([]RawSpan(nil), tracer.buffer.rawSpans...)
+	flushing := append([]RawSpan(nil), tracer.flushing.rawSpans...)
+	dropped := tracer.buffer.droppedSpanCount
+	tracer.lock.Unlock()
+
+	dump := BufferDump{
+		Spans:         make([]ForwardedSpan, 0, len(buffered)),
+		FlushingSpans: make([]ForwardedSpan, 0, len(flushing)),
+		DroppedSpans:  dropped,
+	}
+	for _, raw := range buffered {
+		dump.Spans = append(dump.Spans, NewForwardedSpan(raw))
+	}
+	for _, raw := range flushing {
+		dump.FlushingSpans = append(dump.FlushingSpans, NewForwardedSpan(raw))
+	}
+	return json.NewEncoder(w).Encode(dump)
+}
@@ -0,0 +1,66 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("DumpBuffer", func() {
+	var tracer Tracer
+
+	BeforeEach(func() {
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			MinReportingPeriod: time.Hour,
+			ReportingPeriod:    time.Hour,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	dump := func() BufferDump {
+		var buf bytes.Buffer
+		Expect(DumpBuffer(tracer, &buf)).To(Succeed())
+		var dump BufferDump
+		Expect(json.Unmarshal(buf.Bytes(), &dump)).To(Succeed())
+		return dump
+	}
+
+	It("writes the spans waiting to be reported", func() {
+		Expect(dump().Spans).To(BeEmpty())
+
+		parent := tracer.StartSpan("checkout")
+		child := tracer.StartSpan("charge", opentracing.ChildOf(parent.Context()), opentracing.Tag{Key: "attempt", Value: "2"})
+		child.Finish()
+		parent.Finish()
+		tracer.StartSpan("unfinished")
+
+		spans := dump().Spans
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Operation).To(Equal("charge"))
+		Expect(spans[0].ParentSpanID).To(Equal(strconv.FormatUint(parent.Context().(SpanContext).SpanID, 16)))
+		Expect(spans[0].Tags).To(HaveKeyWithValue("attempt", "2"))
+		Expect(spans[1].Operation).To(Equal("checkout"))
+
+		tracer.Flush(context.Background())
+		Expect(dump().Spans).To(BeEmpty())
+	})
+
+	It("rejects other tracers", func() {
+		Expect(DumpBuffer(opentracing.NoopTracer{}, &bytes.Buffer{})).To(HaveOccurred())
+	})
+})
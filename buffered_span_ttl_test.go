@@ -0,0 +1,71 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("BufferedSpanTTL", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			BufferedSpanTTL:    time.Minute,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	finishAt := func(span opentracing.Span, finish time.Time) {
+		span.FinishWithOptions(opentracing.FinishOptions{FinishTime: finish})
+	}
+
+	It("drops the spans that finished longer ago than the TTL", func() {
+		past := time.Now().Add(-time.Hour)
+		finishAt(tracer.StartSpan("stale", opentracing.StartTime(past)), past.Add(time.Second))
+		finishAt(tracer.StartSpan("stale but required", opentracing.StartTime(past), MustDeliver{}), past.Add(time.Second))
+		tracer.StartSpan("fresh").Finish()
+		tracer.Flush(context.Background())
+
+		var names []string
+		for _, span := range fakeClient.Spans() {
+			names = append(names, span.GetOperationName())
+		}
+		Expect(names).To(ConsistOf("stale but required", "fresh"))
+
+		var status EventStatusReport
+		Eventually(eventChan).Should(Receive(&status))
+		Expect(status.ExpiredSpans()).To(Equal(1))
+		Expect(status.DroppedSpans()).To(Equal(1))
+	})
+
+	It("rejects a negative TTL", func() {
+		opts := Options{AccessToken: "token", BufferedSpanTTL: -time.Second}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
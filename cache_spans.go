@@ -0,0 +1,63 @@
+package lightstep
+
+import (
+	"context"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// Tags set by StartCacheSpan and SetCacheHit.
+const (
+	CacheKeyPrefixKey = "cache.key_prefix"
+	CacheHitKey       = "cache.hit"
+)
+
+// Values of the db.type tag for StartCacheSpan.
+const (
+	CacheSystemRedis     = "redis"
+	CacheSystemMemcached = "memcached"
+)
+
+// StartCacheSpan starts a client span for a cache command, such as "GET",
+// as a child of the span in ctx, and returns it with a context carrying
+// it. The span is named after system and command, and tagged with system
+// as db.type, command as db.statement, and the CacheKeyPrefix of key under
+// CacheKeyPrefixKey. Keys often embed user or session identifiers, so the
+// full key is not recorded. Call SetCacheHit with the outcome of reads.
+//
+// It is meant to be called from go-redis hooks and gomemcache wrappers,
+// which this package doesn't depend on:
+//
+//	span, ctx := lightstep.StartCacheSpan(ctx, tracer, lightstep.CacheSystemMemcached, "get", key)
+//	item, err := client.Get(key)
+//	lightstep.SetCacheHit(span, err == nil)
+//	span.Finish()
+func StartCacheSpan(ctx context.Context, tracer opentracing.Tracer, system, command, key string) (opentracing.Span, context.Context) {
+	var opts []opentracing.StartSpanOption
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	span := tracer.StartSpan(system+" "+command, opts...)
+	ext.SpanKindRPCClient.Set(span)
+	ext.DBType.Set(span, system)
+	ext.DBStatement.Set(span, command)
+	span.SetTag(CacheKeyPrefixKey, CacheKeyPrefix(key))
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+// CacheKeyPrefix returns the part of key up to and including its first
+// ':', such as "session:" for "session:8d3f2a", or "" if key has none.
+func CacheKeyPrefix(key string) string {
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		return key[:i+1]
+	}
+	return ""
+}
+
+// SetCacheHit tags span with whether the cache read it records found the
+// key. A miss is not an error, so it is not tagged as one.
+func SetCacheHit(span opentracing.Span, hit bool) {
+	span.SetTag(CacheHitKey, hit)
+}
@@ -0,0 +1,62 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("StartCacheSpan", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("records a client span with the key prefix and hit", func() {
+		parent := tracer.StartSpan("request")
+		ctx := opentracing.ContextWithSpan(context.Background(), parent)
+
+		span, spanCtx := StartCacheSpan(ctx, tracer, CacheSystemRedis, "GET", "session:8d3f2a")
+		Expect(opentracing.SpanFromContext(spanCtx)).To(Equal(span))
+		SetCacheHit(span, false)
+		span.Finish()
+
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Operation).To(Equal("redis GET"))
+		Expect(raw.ParentSpanID).To(Equal(parent.Context().(SpanContext).SpanID))
+		Expect(raw.Tags).To(HaveKeyWithValue("db.type", "redis"))
+		Expect(raw.Tags).To(HaveKeyWithValue("db.statement", "GET"))
+		Expect(raw.Tags).To(HaveKeyWithValue(CacheKeyPrefixKey, "session:"))
+		Expect(raw.Tags).To(HaveKeyWithValue(CacheHitKey, false))
+		Expect(raw.Tags).NotTo(HaveKey("error"))
+	})
+})
+
+var _ = Describe("CacheKeyPrefix", func() {
+	It("returns the key up to its first colon", func() {
+		Expect(CacheKeyPrefix("session:8d3f2a")).To(Equal("session:"))
+		Expect(CacheKeyPrefix("user:42:profile")).To(Equal("user:"))
+	})
+
+	It("returns nothing for keys without a prefix", func() {
+		Expect(CacheKeyPrefix("8d3f2a")).To(BeEmpty())
+	})
+})
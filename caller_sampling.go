@@ -0,0 +1,60 @@
+package lightstep
+
+import (
+	"strings"
+)
+
+// CallerSamplingOptions samples traces by their caller, so that traffic from
+// load tests or synthetic monitors can be sampled differently from real
+// users. The caller of a trace is the value of its BaggageKey baggage item.
+//
+// Unless a Recorder, OnSpanStart or Destinations need every span, the spans
+// of traces that aren't sampled are dropped as they start, at little more
+// cost than those of a noop tracer. Their caller is then the one they start
+// with, rather than the one they finish with.
+type CallerSamplingOptions struct {
+	// BaggageKey is the baggage item naming the caller, e.g. "caller".
+	// Baggage keys are lowercase. Empty disables caller sampling.
+	BaggageKey string `yaml:"baggage_key"`
+
+	// Header, if set, is a carrier field, such as the "X-Caller" HTTP
+	// header, read on Extract and kept as the BaggageKey baggage item, so
+	// that the sampling decision carries over to the descendants of the
+	// extracted span, in this and downstream processes. It takes precedence
+	// over a BaggageKey item in the same carrier. It is only read along
+	// with a propagated span context.
+	Header string `yaml:"header"`
+
+	// SampleRates maps callers to the fraction (between 0.0 and 1.0) of
+	// their traces that are reported. It is applied to the trace ID, so
+	// processes sampling a caller at the same rate keep the same traces.
+	// The spans of other callers, and spans started with MustDeliver, are
+	// reported. The empty caller matches traces without one.
+	SampleRates map[string]float64 `yaml:"sample_rates"`
+}
+
+func (o CallerSamplingOptions) enabled() bool {
+	return o.BaggageKey != "" && len(o.SampleRates) > 0
+}
+
+func (o CallerSamplingOptions) validate() error {
+	if o.Header != "" && o.BaggageKey == "" {
+		return validationErrorCallerSamplingKey
+	}
+	for _, rate := range o.SampleRates {
+		if rate < 0 || rate > 1 {
+			return validationErrorCallerSamplingRate
+		}
+	}
+	return nil
+}
+
+// newCallerSampler drops the spans of the traces not sampled at the rate of
+// their caller.
+func newCallerSampler(opts CallerSamplingOptions) spanProcessor {
+	key := strings.ToLower(opts.BaggageKey)
+	return func(span *RawSpan) bool {
+		rate, ok := opts.SampleRates[span.Context.Baggage[key]]
+		return !ok || span.mustDeliver || traceSampled(span.Context.TraceID, rate)
+	}
+}
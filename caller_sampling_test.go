@@ -0,0 +1,119 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"net/http"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("CallerSampling", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+
+	// Trace IDs sampled and not sampled at a rate of 0.5.
+	const sampledTraceID, unsampledTraceID = 0x2, 0x7fffffffffffffff
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+			CallerSampling: CallerSamplingOptions{
+				BaggageKey:  "caller",
+				Header:      "X-Caller",
+				SampleRates: map[string]float64{"load-test": 0, "synthetic": 0.5},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	startSpan := func(traceID uint64, caller string) opentracing.Span {
+		span := tracer.StartSpan("request", SetTraceID(traceID), SetSpanID(1))
+		if caller != "" {
+			span.SetBaggageItem("caller", caller)
+		}
+		return span
+	}
+
+	reported := func() []uint64 {
+		var traceIDs []uint64
+		for i := 0; i < fakeRecorder.RecordSpanCallCount(); i++ {
+			traceIDs = append(traceIDs, fakeRecorder.RecordSpanArgsForCall(i).Context.TraceID)
+		}
+		return traceIDs
+	}
+
+	It("samples traces at the rate of their caller", func() {
+		startSpan(1, "load-test").Finish()
+		startSpan(sampledTraceID, "synthetic").Finish()
+		startSpan(unsampledTraceID, "synthetic").Finish()
+		startSpan(unsampledTraceID-1, "user").Finish()
+		startSpan(unsampledTraceID-2, "").Finish()
+		tracer.StartSpan("must", SetTraceID(3), MustDeliver{}).SetBaggageItem("caller", "load-test").Finish()
+
+		Expect(reported()).To(ConsistOf(
+			uint64(sampledTraceID), uint64(unsampledTraceID-1), uint64(unsampledTraceID-2), uint64(3)))
+	})
+
+	It("reads the caller from the header on Extract", func() {
+		headers := http.Header{}
+		Expect(tracer.Inject(startSpan(5, "").Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers))).To(Succeed())
+		headers.Set("X-Caller", "load-test")
+
+		parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parent.(SpanContext).Baggage).To(HaveKeyWithValue("caller", "load-test"))
+
+		tracer.StartSpan("child", opentracing.ChildOf(parent)).Finish()
+		Expect(fakeRecorder.RecordSpanCallCount()).To(BeZero())
+	})
+
+	for _, propagator := range []string{PropagatorTraceContext, PropagatorB3} {
+		propagator := propagator
+		It("reads the caller from the header on Extract in the "+propagator+" format", func() {
+			closeTestTracer(tracer)
+			tracer = NewTracer(Options{
+				AccessToken: "ACCESS_TOKEN",
+				ConnFactory: fakeGrpcConnection(new(cpbfakes.FakeCollectorServiceClient)),
+				Recorder:    fakeRecorder,
+				Propagators: []string{propagator},
+				CallerSampling: CallerSamplingOptions{
+					BaggageKey:  "caller",
+					Header:      "X-Caller",
+					SampleRates: map[string]float64{"load-test": 0},
+				},
+			})
+
+			headers := http.Header{}
+			Expect(tracer.Inject(startSpan(5, "").Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers))).To(Succeed())
+			headers.Set("X-Caller", "load-test")
+
+			parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parent.(SpanContext).Baggage).To(HaveKeyWithValue("caller", "load-test"))
+
+			tracer.StartSpan("child", opentracing.ChildOf(parent)).Finish()
+			Expect(fakeRecorder.RecordSpanCallCount()).To(BeZero())
+		})
+	}
+
+	It("validates the rates", func() {
+		opts := Options{AccessToken: "token", CallerSampling: CallerSamplingOptions{
+			BaggageKey:  "caller",
+			SampleRates: map[string]float64{"load-test": 2},
+		}}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
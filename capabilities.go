@@ -0,0 +1,131 @@
+package lightstep
+
+import (
+	"sort"
+	"strings"
+)
+
+// Capability names an optional payload feature. The tracer advertises the
+// capabilities it implements in every report, and the collector advertises
+// the ones it accepts in its responses. A feature is only used once both
+// sides support it, so new payload features can be rolled out without
+// breaking older collectors.
+type Capability string
+
+const (
+	// CapabilityTagDictionary allows tags repeated by the spans of a report
+	// to be sent once, see Options.ExperimentalTagDictionary.
+	CapabilityTagDictionary Capability = "tag_dictionary"
+	// CapabilityIndexedTags allows spans to list the tags the backend
+	// should index for search first, see IndexedTagsKey.
+	CapabilityIndexedTags Capability = "indexed_tags"
+)
+
+const (
+	// TracerProtocolVersionKey is the reporter tag carrying the version of
+	// the collector protocol the tracer speaks.
+	TracerProtocolVersionKey   = "lightstep.tracer_protocol_version"
+	TracerProtocolVersionValue = "2"
+	// TracerCapabilitiesKey is the reporter tag carrying the tracer's
+	// capabilities, as a comma separated list.
+	TracerCapabilitiesKey = "lightstep.tracer_capabilities"
+
+	// collectorCapabilitiesPrefix marks the report response info that lists
+	// the collector's capabilities, e.g. "capabilities: tag_dictionary,indexed_tags".
+	collectorCapabilitiesPrefix = "capabilities:"
+)
+
+// tracerCapabilities are the capabilities this tracer implements. The
+// tracer also advertises CapabilityTagDictionary when it is enabled. Typed
+// tag values and references to spans other than the parent are sent to
+// every collector, which ignores what it doesn't use, so they aren't
+// negotiated.
+var tracerCapabilities = []Capability{
+	CapabilityIndexedTags,
+}
+
+// capabilitySet is a set of capabilities; the zero value is empty.
+type capabilitySet map[Capability]bool
+
+func joinCapabilities(caps []Capability) string {
+	names := make([]string, len(caps))
+	for i, c := range caps {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ",")
+}
+
+// parseCapabilities reads a comma separated list of capabilities.
+func parseCapabilities(list string) capabilitySet {
+	caps := capabilitySet{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			caps[Capability(name)] = true
+		}
+	}
+	return caps
+}
+
+// list returns the capabilities in the set, sorted by name.
+func (caps capabilitySet) list() []Capability {
+	list := make([]Capability, 0, len(caps))
+	for c := range caps {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+func (caps capabilitySet) equal(other capabilitySet) bool {
+	if len(caps) != len(other) {
+		return false
+	}
+	for c := range caps {
+		if !other[c] {
+			return false
+		}
+	}
+	return true
+}
+
+// collectorCapabilities returns the capabilities advertised in a report
+// response. A collector that advertises nothing supports nothing. ok is
+// false when the transport cannot carry capabilities at all.
+func collectorCapabilities(resp collectorResponse) (caps capabilitySet, ok bool) {
+	switch resp := resp.(type) {
+	case multiResponse:
+		// A pool of collectors only supports what every member supports.
+		for _, shard := range resp {
+			shardCaps, shardOK := collectorCapabilities(shard)
+			if !shardOK {
+				return nil, false
+			}
+			if caps == nil {
+				caps = shardCaps
+				continue
+			}
+			for c := range caps {
+				if !shardCaps[c] {
+					delete(caps, c)
+				}
+			}
+		}
+		return caps, caps != nil
+	case chaosResponse:
+		return collectorCapabilities(resp.collectorResponse)
+	case interface {
+		GetInfos() []string
+	}:
+		caps = capabilitySet{}
+		for _, info := range resp.GetInfos() {
+			if strings.HasPrefix(info, collectorCapabilitiesPrefix) {
+				for c := range parseCapabilities(strings.TrimPrefix(info, collectorCapabilitiesPrefix)) {
+					caps[c] = true
+				}
+			}
+		}
+		return caps, true
+	}
+	return nil, false
+}
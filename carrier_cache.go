@@ -0,0 +1,100 @@
+package lightstep
+
+import (
+	"encoding/base64"
+	"strconv"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	lightstep "github.com/lightstep/lightstep-tracer-go/lightsteppb"
+)
+
+// carrierCache memoizes the serialized forms of a SpanContext, so that a
+// context injected many times, e.g. by a fan-out HTTP client, is only
+// serialized once. It is shared by every copy of the SpanContext, which is
+// why SpanContexts must not be modified once they are in use.
+type carrierCache struct {
+	textOnce  sync.Once
+	text      [][2]string
+	textNames textMapFieldNames
+
+	binaryOnce sync.Once
+	binary     []byte
+	binaryErr  error
+
+	base64Once sync.Once
+	base64     string
+}
+
+// textFields returns the key/value pairs the context is injected as into
+// TextMap and HTTPHeaders carriers, with the field names of the LightStep
+// format given by names. Only the fields of the first names are cached, as
+// a tracer uses one set.
+func (sc SpanContext) textFields(names textMapFieldNames) [][2]string {
+	if sc.cache == nil {
+		return encodeTextFields(sc, names)
+	}
+	sc.cache.textOnce.Do(func() {
+		sc.cache.text = encodeTextFields(sc, names)
+		sc.cache.textNames = names
+	})
+	if sc.cache.textNames != names {
+		return encodeTextFields(sc, names)
+	}
+	return sc.cache.text
+}
+
+// binaryMessage returns the serialized BinaryCarrier message the context is
+// injected as into Binary carriers. It must not be modified.
+func (sc SpanContext) binaryMessage() ([]byte, error) {
+	if sc.cache == nil {
+		return encodeBinary(sc)
+	}
+	sc.cache.binaryOnce.Do(func() {
+		sc.cache.binary, sc.cache.binaryErr = encodeBinary(sc)
+	})
+	return sc.cache.binary, sc.cache.binaryErr
+}
+
+// binaryEncoded returns the base64 encoded form of binaryMessage.
+func (sc SpanContext) binaryEncoded() (string, error) {
+	if sc.cache == nil {
+		data, err := encodeBinary(sc)
+		return base64.StdEncoding.EncodeToString(data), err
+	}
+	data, err := sc.binaryMessage()
+	if err != nil {
+		return "", err
+	}
+	sc.cache.base64Once.Do(func() {
+		sc.cache.base64 = base64.StdEncoding.EncodeToString(data)
+	})
+	return sc.cache.base64, nil
+}
+
+func encodeTextFields(sc SpanContext, names textMapFieldNames) [][2]string {
+	fields := make([][2]string, 0, tracerStateFieldCount+len(sc.Baggage)+len(sc.passThrough))
+	fields = append(fields,
+		[2]string{names.traceID, formatTraceID(sc.TraceIDHigh, sc.TraceID)},
+		[2]string{names.spanID, strconv.FormatUint(sc.SpanID, 16)},
+		[2]string{names.sampled, "true"},
+	)
+	for k, v := range sc.Baggage {
+		fields = append(fields, [2]string{names.baggagePrefix + k, v})
+	}
+	for k, v := range sc.passThrough {
+		fields = append(fields, [2]string{k, v})
+	}
+	return fields
+}
+
+func encodeBinary(sc SpanContext) ([]byte, error) {
+	return proto.Marshal(&lightstep.BinaryCarrier{
+		BasicCtx: &lightstep.BasicTracerCarrier{
+			TraceId:      sc.TraceID,
+			SpanId:       sc.SpanID,
+			Sampled:      true,
+			BaggageItems: sc.Baggage,
+		},
+	})
+}
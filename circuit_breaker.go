@@ -0,0 +1,75 @@
+package lightstep
+
+import (
+	"time"
+)
+
+// DefaultCircuitBreakerCoolDown is the default CircuitBreakerOptions.CoolDown.
+const DefaultCircuitBreakerCoolDown = time.Minute
+
+// CircuitBreakerOptions stops reporting while the collector is unreachable,
+// so that the tracer doesn't spend CPU serializing reports that time out.
+// After FailureThreshold consecutive reports fail to be sent, the breaker
+// opens and reports are suppressed for CoolDown, with spans kept in the
+// buffer as long as it has room. Then one report is attempted: if it is
+// sent the breaker closes, otherwise it stays open for another CoolDown.
+// Reports are suppressed even on Close, whose final flush is skipped while
+// the breaker is open. Only reports that fail to be sent count as failures:
+// a report the collector answers, even rejecting all of its spans, closes
+// the breaker, as the collector is reachable. An EventCircuitBreaker is emitted when the breaker
+// opens and closes.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed reports that
+	// opens the breaker. Zero disables the breaker.
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	// CoolDown is how long reports are suppressed once the breaker opens.
+	// Defaults to DefaultCircuitBreakerCoolDown.
+	CoolDown time.Duration `yaml:"cool_down"`
+}
+
+func (o CircuitBreakerOptions) validate() error {
+	if o.FailureThreshold < 0 || o.CoolDown < 0 {
+		return validationErrorCircuitBreaker
+	}
+	return nil
+}
+
+// circuitBreaker tracks the consecutive failures of reports. It is guarded
+// by the tracer's lock.
+type circuitBreaker struct {
+	opts      CircuitBreakerOptions
+	failures  int
+	open      bool
+	openUntil time.Time
+}
+
+// allow reports whether a report may be attempted at now.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	return !b.open || !now.Before(b.openUntil)
+}
+
+// record records whether a report attempted at now failed to be sent,
+// returning an EventCircuitBreaker if the breaker opened or closed.
+func (b *circuitBreaker) record(failed bool, now time.Time) Event {
+	if !failed {
+		b.failures = 0
+		if !b.open {
+			return nil
+		}
+		b.open = false
+		return newEventCircuitBreaker(false, 0, 0)
+	}
+
+	b.failures++
+	if b.open {
+		b.openUntil = now.Add(b.opts.CoolDown)
+		return nil
+	}
+	if b.failures < b.opts.FailureThreshold {
+		return nil
+	}
+	b.open = true
+	b.openUntil = now.Add(b.opts.CoolDown)
+	return newEventCircuitBreaker(true, b.failures, b.opts.CoolDown)
+}
@@ -0,0 +1,106 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CircuitBreaker", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var eventChan <-chan Event
+
+	const coolDown = 100 * time.Millisecond
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(nil, errors.New("collector down"))
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			CircuitBreaker:     CircuitBreakerOptions{FailureThreshold: 2, CoolDown: coolDown},
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	// breakerEvents returns the EventCircuitBreakers and the states of the
+	// EventFlushErrors emitted so far.
+	breakerEvents := func() ([]EventCircuitBreaker, []EventFlushErrorState) {
+		var breakers []EventCircuitBreaker
+		var states []EventFlushErrorState
+		for {
+			select {
+			case event := <-eventChan:
+				switch e := event.(type) {
+				case EventCircuitBreaker:
+					breakers = append(breakers, e)
+				case EventFlushError:
+					states = append(states, e.State())
+				}
+			default:
+				return breakers, states
+			}
+		}
+	}
+
+	It("suppresses reports while open and closes after a sent report", func() {
+		tracer.StartSpan("kept").Finish()
+		tracer.Flush(context.Background())
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(2))
+
+		breakers, _ := breakerEvents()
+		Expect(breakers).To(HaveLen(1))
+		Expect(breakers[0].Open()).To(BeTrue())
+		Expect(breakers[0].Failures()).To(Equal(2))
+		Expect(breakers[0].CoolDown()).To(Equal(coolDown))
+
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(2))
+		_, states := breakerEvents()
+		Expect(states).To(Equal([]EventFlushErrorState{FlushErrorCircuitOpen}))
+
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		time.Sleep(coolDown)
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(3))
+		Expect(fakeClient.Reports()[2].GetSpans()).To(HaveLen(1))
+
+		breakers, _ = breakerEvents()
+		Expect(breakers).To(HaveLen(1))
+		Expect(breakers[0].Open()).To(BeFalse())
+	})
+
+	It("stays open when the trial report fails", func() {
+		tracer.Flush(context.Background())
+		tracer.Flush(context.Background())
+		time.Sleep(coolDown)
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(3))
+
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(3))
+		breakers, _ := breakerEvents()
+		Expect(breakers).To(HaveLen(1))
+	})
+})
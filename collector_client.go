@@ -2,11 +2,13 @@ package lightstep
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 
+	"github.com/golang/protobuf/proto"
 	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
-	"github.com/lightstep/lightstep-tracer-go/lightstep_thrift"
 )
 
 // Connection describes a closable connection. Exposed for testing.
@@ -24,9 +26,114 @@ type collectorResponse interface {
 }
 
 type reportRequest struct {
-	thriftRequest *lightstep_thrift.ReportRequest
+	thriftRequest *thriftReportRequest
 	protoRequest  *cpb.ReportRequest
 	httpRequest   *http.Request
+	otlpRequest   *otlpExportRequest
+
+	// shards holds one request per collector when reporting to a pool of
+	// Options.Collectors, or per access token when reporting for tenants,
+	// along with the number of spans in each.
+	shards    []reportRequest
+	spanCount int
+	// spanIndexes holds the indexes of a shard's spans in the buffer of the
+	// request it is a shard of, so that only the spans of the shards that
+	// failed are retried, see partialReportError.
+	spanIndexes []int
+}
+
+// partialReportError is returned by the clients that send a report as
+// several requests when some of them failed. Only the spans it lists are
+// retried by the tracer; the others were sent.
+type partialReportError struct {
+	err error
+	// failedSpans holds the indexes, in the flushed buffer, of the spans
+	// that weren't sent.
+	failedSpans []int
+	// metricsSent is set if the report-level metrics, which are attributed
+	// to the first request, were sent.
+	metricsSent bool
+}
+
+func (e *partialReportError) Error() string {
+	return e.err.Error()
+}
+
+// failedShardSpans returns the indexes, in the buffer of the request it is
+// a shard of, of the spans of shard that err failed to send.
+func failedShardSpans(shard reportRequest, err error) []int {
+	partial, ok := err.(*partialReportError)
+	if !ok {
+		return shard.spanIndexes
+	}
+	failed := make([]int, len(partial.failedSpans))
+	for i, index := range partial.failedSpans {
+		failed[i] = shard.spanIndexes[index]
+	}
+	return failed
+}
+
+// spanRange returns the indexes of n spans from start.
+func spanRange(start, n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = start + i
+	}
+	return indexes
+}
+
+// empty reports whether the request is the zero reportRequest, as left for
+// the skipped shards of a sharded request.
+func (r reportRequest) empty() bool {
+	return r.thriftRequest == nil && r.protoRequest == nil && r.httpRequest == nil &&
+		r.otlpRequest == nil && r.shards == nil
+}
+
+// payload returns the serialized form of the request, as it would be
+// written to the wire by its transport.
+func (r reportRequest) payload() ([]byte, error) {
+	switch {
+	case r.protoRequest != nil:
+		return proto.Marshal(r.protoRequest)
+	case r.otlpRequest != nil:
+		return r.otlpRequest.data, nil
+	case r.httpRequest != nil:
+		if r.httpRequest.GetBody == nil {
+			return nil, fmt.Errorf("httpRequest body cannot be replayed")
+		}
+		body, err := r.httpRequest.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	case r.thriftRequest != nil:
+		return r.thriftPayload()
+	case r.shards != nil:
+		return nil, fmt.Errorf("sharded reportRequest has no single payload")
+	}
+	return nil, fmt.Errorf("reportRequest is empty")
+}
+
+// size returns the number of bytes the request occupies on the wire,
+// excluding transport framing.
+func (r reportRequest) size() int {
+	switch {
+	case r.protoRequest != nil:
+		return proto.Size(r.protoRequest)
+	case r.otlpRequest != nil:
+		return len(r.otlpRequest.data)
+	case r.httpRequest != nil:
+		return int(r.httpRequest.ContentLength)
+	case r.shards != nil:
+		var size int
+		for _, shard := range r.shards {
+			size += shard.size()
+		}
+		return size
+	}
+	payload, _ := r.payload()
+	return len(payload)
 }
 
 // collectorClient encapsulates internal thrift/grpc transports.
@@ -37,19 +144,119 @@ type collectorClient interface {
 	ShouldReconnect() bool
 }
 
+// connectionWaiter is implemented by collectorClients that can establish
+// their connection to the collector ahead of the first report.
+type connectionWaiter interface {
+	waitForConnection(context.Context, Connection) error
+}
+
+// activeTransportNamer is implemented by collectorClients whose transport
+// can change while the tracer runs, such as the gRPC transport falling back
+// to HTTP, and by the clients wrapping them.
+type activeTransportNamer interface {
+	activeTransport() string
+}
+
+// activeTransport names the transport client currently reports with, or
+// returns "" if it is the one transportName names.
+func activeTransport(client collectorClient) string {
+	if namer, ok := client.(activeTransportNamer); ok {
+		return namer.activeTransport()
+	}
+	return ""
+}
+
+// transportName names the transport newCollectorClient selects for opts.
+func transportName(opts Options) string {
+	switch {
+	case opts.CustomTransportFactory != nil:
+		return "custom"
+	case opts.CustomTransport != "":
+		return opts.CustomTransport
+	case opts.Agent.Address != "":
+		return "agent"
+	case opts.UseThrift:
+		return "thrift"
+	case opts.UseOTLP:
+		return "OTLP"
+	case opts.UseHttp:
+		return "HTTP"
+	}
+	return "gRPC"
+}
+
+// reportsOverGRPC reports whether opts select the gRPC or OTLP/gRPC
+// transport.
+func reportsOverGRPC(opts Options) bool {
+	switch transportName(opts) {
+	case "gRPC":
+		return true
+	case "OTLP":
+		return opts.OTLPProtocol != OTLPProtocolHTTP
+	}
+	return false
+}
+
 func newCollectorClient(opts Options, reporterId uint64, attributes map[string]string) (collectorClient, error) {
+	client, err := newTransportClient(opts, reporterId, attributes)
+	if err != nil || !opts.Chaos.enabled() {
+		return client, err
+	}
+	return newChaosCollectorClient(client, opts.Chaos), nil
+}
+
+func newTransportClient(opts Options, reporterId uint64, attributes map[string]string) (collectorClient, error) {
+	if factory := customTransportFactory(opts); factory != nil {
+		return newCustomCollectorClient(factory, opts, reporterId, attributes)
+	}
+
+	if opts.Agent.Address != "" {
+		return newAgentCollectorClient(opts, reporterId, attributes), nil
+	}
+
+	if len(opts.TenantAccessTokens) > 0 {
+		return newTenantCollectorClient(opts, reporterId, attributes)
+	}
+
+	if len(opts.Collectors) > 0 {
+		return newShardedCollectorClient(opts, reporterId, attributes)
+	}
+
+	if len(opts.FailoverCollectors) > 0 {
+		return newFailoverCollectorClient(opts, reporterId, attributes)
+	}
+
 	if opts.UseThrift {
-		return newThriftCollectorClient(opts, reporterId, attributes), nil
+		client, err := newThriftTransport(opts, reporterId, attributes)
+		if err == nil {
+			return splitReports(client, opts), nil
+		}
+		// Not available in this build, use the next transport instead.
+		opts.UseThrift = false
+		emitEvent(newEventTransportFallback(transportName(opts), err))
 	}
 
-	if opts.UseHttp {
-		return newHttpCollectorClient(opts, reporterId, attributes)
+	client, err := newEndpointClient(opts, reporterId, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return splitReports(client, opts), nil
+}
+
+// newEndpointClient returns the client of the OTLP, HTTP or gRPC transport
+// reporting to opts.Collector.
+func newEndpointClient(opts Options, reporterId uint64, attributes map[string]string) (collectorClient, error) {
+	if opts.UseOTLP {
+		if opts.OTLPProtocol == OTLPProtocolHTTP {
+			return newOTLPHTTPCollectorClient(opts, reporterId, attributes)
+		}
+		return newOTLPCollectorClient(opts, reporterId, attributes)
 	}
 
-	if opts.UseGRPC {
-		return newGrpcCollectorClient(opts, reporterId, attributes), nil
+	if opts.UseHttp {
+		return newHttpCollectorClient(opts, reporterId, attributes)
 	}
 
-	// No transport specified, defaulting to GRPC
-	return newGrpcCollectorClient(opts, reporterId, attributes), nil
+	// UseGRPC, or no transport specified, defaulting to GRPC
+	return newGrpcTransport(opts, reporterId, attributes)
 }
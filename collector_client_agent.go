@@ -0,0 +1,207 @@
+package lightstep
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Networks for AgentOptions.Network.
+const (
+	AgentNetworkUDP = "udp"
+	AgentNetworkTCP = "tcp"
+)
+
+const (
+	// DefaultAgentMaxPacketBytes is the default AgentOptions.MaxPacketBytes.
+	DefaultAgentMaxPacketBytes = 8192
+	// maxUDPPayloadBytes is the largest payload of a UDP datagram.
+	maxUDPPayloadBytes = 65507
+	// agentFrameHeaderLen is the length of the big-endian uint32 prefixing
+	// each report sent over TCP.
+	agentFrameHeaderLen = 4
+)
+
+var (
+	validationErrorAgentNetwork = fmt.Errorf("Options invalid: Agent.Network must be \"udp\" or \"tcp\"")
+	validationErrorAgentPacket  = fmt.Errorf("Options invalid: Agent.MaxPacketBytes must be between 0 and 65507 for udp, and not negative for tcp")
+	validationErrorAgentMixed   = fmt.Errorf("Options invalid: Agent must not be set with Collectors, FailoverCollectors, TenantAccessTokens, UseThrift or UseOTLP")
+)
+
+// AgentOptions configures reporting to an agent on the same node, such as a
+// sidecar or daemon set, instead of to the collector. Reports are written to
+// the agent without waiting for a response, and the agent forwards them to
+// the collector, so the service keeps no collector connection of its own.
+// Each report is a serialized lightstep.collector.ReportRequest, in one UDP
+// datagram, or over a TCP connection prefixed by its length as a big-endian
+// uint32. Reports larger than MaxPacketBytes are split, and a single span
+// larger than MaxPacketBytes is dropped. Options.CustomHeaders and
+// Options.Compression are not used.
+type AgentOptions struct {
+	// Network is "udp", the default, or "tcp".
+	Network string `yaml:"network"`
+	// Address, such as "127.0.0.1:8361", enables reporting to the agent.
+	Address string `yaml:"address"`
+	// MaxPacketBytes limits the size of each report. Defaults to
+	// DefaultAgentMaxPacketBytes.
+	MaxPacketBytes int `yaml:"max_packet_bytes"`
+}
+
+func (o AgentOptions) validate() error {
+	if o.Address == "" {
+		return nil
+	}
+	switch o.Network {
+	case "", AgentNetworkUDP:
+		if o.MaxPacketBytes < 0 || o.MaxPacketBytes > maxUDPPayloadBytes {
+			return validationErrorAgentPacket
+		}
+	case AgentNetworkTCP:
+		if o.MaxPacketBytes < 0 {
+			return validationErrorAgentPacket
+		}
+	default:
+		return validationErrorAgentNetwork
+	}
+	return nil
+}
+
+// agentCollectorClient writes reports to a node-local agent.
+type agentCollectorClient struct {
+	// auth and runtime information
+	reporterID  uint64
+	accessToken string
+	attributes  map[string]string
+
+	network        string
+	address        string
+	maxPacketBytes int
+	reportTimeout  time.Duration
+
+	converter *protoConverter
+
+	// lock protects conn, which is dialed by the first report after it's
+	// closed or fails.
+	lock sync.Mutex
+	conn net.Conn
+}
+
+// agentResponse is the response to a report written to the agent, which
+// doesn't reply. It counts the spans dropped for being too large.
+type agentResponse struct {
+	errors   []string
+	rejected int64
+}
+
+func (r agentResponse) GetErrors() []string { return r.errors }
+func (r agentResponse) Disable() bool       { return false }
+
+func newAgentCollectorClient(opts Options, reporterID uint64, attributes map[string]string) collectorClient {
+	network := opts.Agent.Network
+	if network == "" {
+		network = AgentNetworkUDP
+	}
+	maxPacketBytes := opts.Agent.MaxPacketBytes
+	if maxPacketBytes == 0 {
+		maxPacketBytes = DefaultAgentMaxPacketBytes
+	}
+	client := &agentCollectorClient{
+		reporterID:     reporterID,
+		accessToken:    opts.AccessToken,
+		attributes:     attributes,
+		network:        network,
+		address:        opts.Agent.Address,
+		maxPacketBytes: maxPacketBytes,
+		reportTimeout:  opts.ReportTimeout,
+		converter:      newProtoConverter(opts),
+	}
+	return &splittingCollectorClient{collectorClient: client, maxBytes: maxPacketBytes}
+}
+
+// ConnectClient doesn't dial the agent, which may start after the service:
+// the connection is dialed by the first report.
+func (client *agentCollectorClient) ConnectClient() (Connection, error) {
+	return client, nil
+}
+
+func (client *agentCollectorClient) ShouldReconnect() bool {
+	// Reports redial the agent after a failed write.
+	return false
+}
+
+// Close closes the connection to the agent, if it is open.
+func (client *agentCollectorClient) Close() error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.conn == nil {
+		return nil
+	}
+	err := client.conn.Close()
+	client.conn = nil
+	return err
+}
+
+func (client *agentCollectorClient) Translate(_ context.Context, buffer *reportBuffer) (reportRequest, error) {
+	return reportRequest{
+		protoRequest: client.converter.toReportRequest(
+			client.reporterID,
+			client.attributes,
+			client.accessToken,
+			buffer,
+		),
+	}, nil
+}
+
+// Report writes the report to the agent, dialing it if needed. A report of
+// a single span too large for a packet is dropped, and counted as rejected
+// by the response, rather than failed, so that it isn't retried.
+func (client *agentCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	if req.protoRequest == nil {
+		return nil, fmt.Errorf("protoRequest cannot be null")
+	}
+	message, err := proto.Marshal(req.protoRequest)
+	if err != nil {
+		return nil, err
+	}
+	if len(message) > client.maxPacketBytes {
+		return agentResponse{
+			errors:   []string{fmt.Sprintf("report of %d bytes exceeds Agent.MaxPacketBytes", len(message))},
+			rejected: int64(len(req.protoRequest.Spans)),
+		}, nil
+	}
+	packet := message
+	if client.network == AgentNetworkTCP {
+		packet = make([]byte, agentFrameHeaderLen+len(message))
+		binary.BigEndian.PutUint32(packet, uint32(len(message)))
+		copy(packet[agentFrameHeaderLen:], message)
+	}
+
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.conn == nil {
+		dialer := net.Dialer{Timeout: client.reportTimeout}
+		conn, err := dialer.DialContext(ctx, client.network, client.address)
+		if err != nil {
+			return nil, err
+		}
+		client.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		client.conn.SetWriteDeadline(deadline)
+	} else {
+		client.conn.SetWriteDeadline(time.Time{})
+	}
+	if _, err := client.conn.Write(packet); err != nil {
+		// A partial write leaves a TCP stream out of step, so the next
+		// report starts on a new connection.
+		client.conn.Close()
+		client.conn = nil
+		return nil, err
+	}
+	return agentResponse{}, nil
+}
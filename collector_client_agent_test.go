@@ -0,0 +1,196 @@
+package lightstep_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("reporting to an agent", func() {
+	var tracer Tracer
+	var opts Options
+	var reports chan *cpb.ReportRequest
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		reports = make(chan *cpb.ReportRequest, 100)
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	received := func() []*cpb.ReportRequest {
+		var received []*cpb.ReportRequest
+		for {
+			select {
+			case req := <-reports:
+				received = append(received, req)
+			case <-time.After(100 * time.Millisecond):
+				return received
+			}
+		}
+	}
+
+	finishSpans := func(n, tagBytes int) {
+		for i := 0; i < n; i++ {
+			span := tracer.StartSpan(fmt.Sprint("span ", i))
+			span.SetTag("payload", strings.Repeat("x", tagBytes))
+			span.Finish()
+		}
+	}
+
+	Context("over udp", func() {
+		var conn net.PacketConn
+
+		BeforeEach(func() {
+			var err error
+			conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			opts.Agent = AgentOptions{Address: conn.LocalAddr().String(), MaxPacketBytes: 2048}
+
+			go func() {
+				buf := make([]byte, 65536)
+				for {
+					n, _, err := conn.ReadFrom(buf)
+					if err != nil {
+						return
+					}
+					req := &cpb.ReportRequest{}
+					if proto.Unmarshal(buf[:n], req) == nil {
+						reports <- req
+					}
+				}
+			}()
+		})
+
+		AfterEach(func() {
+			conn.Close()
+		})
+
+		It("sends each report in a datagram", func() {
+			finishSpans(3, 10)
+			tracer.Flush(context.Background())
+
+			reqs := received()
+			Expect(reqs).To(HaveLen(1))
+			Expect(reqs[0].GetAuth().GetAccessToken()).To(Equal("ACCESS_TOKEN"))
+			Expect(reqs[0].GetSpans()).To(HaveLen(3))
+		})
+
+		It("splits reports into datagrams under MaxPacketBytes", func() {
+			finishSpans(20, 500)
+			tracer.Flush(context.Background())
+
+			reqs := received()
+			Expect(len(reqs)).To(BeNumerically(">", 1))
+			var spans int
+			for _, req := range reqs {
+				Expect(proto.Size(req)).To(BeNumerically("<=", 2048))
+				spans += len(req.GetSpans())
+			}
+			Expect(spans).To(Equal(20))
+		})
+
+		It("drops a span too large for a datagram", func() {
+			finishSpans(1, 4096)
+			tracer.Flush(context.Background())
+
+			Expect(received()).To(BeEmpty())
+			var partial EventReportPartialSuccess
+			Eventually(eventChan).Should(Receive(&partial))
+			Expect(partial.RejectedSpans()).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("over tcp", func() {
+		var listener net.Listener
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			opts.Agent = AgentOptions{Network: AgentNetworkTCP, Address: listener.Addr().String()}
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				for {
+					header := make([]byte, 4)
+					if _, err := io.ReadFull(conn, header); err != nil {
+						return
+					}
+					message := make([]byte, binary.BigEndian.Uint32(header))
+					if _, err := io.ReadFull(conn, message); err != nil {
+						return
+					}
+					req := &cpb.ReportRequest{}
+					if proto.Unmarshal(message, req) == nil {
+						reports <- req
+					}
+				}
+			}()
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		It("sends length-prefixed reports over one connection", func() {
+			finishSpans(2, 10)
+			tracer.Flush(context.Background())
+			finishSpans(1, 10)
+			tracer.Flush(context.Background())
+
+			reqs := received()
+			Expect(reqs).To(HaveLen(2))
+			Expect(reqs[0].GetSpans()).To(HaveLen(2))
+			Expect(reqs[1].GetSpans()).To(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("AgentOptions", func() {
+	It("rejects an unknown network", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", Agent: AgentOptions{Network: "unix", Address: "/tmp/agent"}}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+
+	It("rejects datagrams larger than UDP allows", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", Agent: AgentOptions{Address: "127.0.0.1:8361", MaxPacketBytes: 70000}}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+
+	It("rejects the agent with other transports", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", UseOTLP: true, Agent: AgentOptions{Address: "127.0.0.1:8361"}}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,139 @@
+package lightstep
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ChaosOptions injects faults into the reports sent by a Tracer, so that
+// the alerting on a degraded tracer can be exercised in tests and staging.
+// Each rate is the probability, between 0 and 1, that a report is affected.
+// The zero value injects nothing. Do not set ChaosOptions in production.
+type ChaosOptions struct {
+	// Latency, plus up to LatencyJitter, is added before every report.
+	Latency       time.Duration `yaml:"latency"`
+	LatencyJitter time.Duration `yaml:"latency_jitter"`
+
+	// FailureRate fails reports before they are sent.
+	FailureRate float64 `yaml:"failure_rate"`
+	// ErrorResponseRate sends reports, and adds an error to the response,
+	// as a collector does when it rejects part of a report.
+	ErrorResponseRate float64 `yaml:"error_response_rate"`
+	// DropResponseRate sends reports, and fails them as if the response
+	// never arrived.
+	DropResponseRate float64 `yaml:"drop_response_rate"`
+	// DisableRate sends reports, and replaces the response's commands with
+	// a command to disable the tracer.
+	DisableRate float64 `yaml:"disable_rate"`
+}
+
+func (c ChaosOptions) enabled() bool {
+	return c != ChaosOptions{}
+}
+
+func (c ChaosOptions) validate() error {
+	for _, rate := range []float64{c.FailureRate, c.ErrorResponseRate, c.DropResponseRate, c.DisableRate} {
+		if rate < 0 || rate > 1 {
+			return validationErrorChaosRate
+		}
+	}
+	if c.Latency < 0 || c.LatencyJitter < 0 {
+		return validationErrorChaosLatency
+	}
+	return nil
+}
+
+var (
+	errChaosFailure      = errors.New("chaos: report failed")
+	errChaosDropResponse = errors.New("chaos: response dropped")
+)
+
+const chaosErrorResponse = "chaos: report partially rejected"
+
+// chaosCollectorClient wraps a collectorClient and injects the faults
+// configured by ChaosOptions into its reports.
+type chaosCollectorClient struct {
+	collectorClient
+	chaos ChaosOptions
+}
+
+func newChaosCollectorClient(client collectorClient, chaos ChaosOptions) *chaosCollectorClient {
+	return &chaosCollectorClient{collectorClient: client, chaos: chaos}
+}
+
+func (client *chaosCollectorClient) waitForConnection(ctx context.Context, conn Connection) error {
+	if waiter, ok := client.collectorClient.(connectionWaiter); ok {
+		return waiter.waitForConnection(ctx, conn)
+	}
+	return nil
+}
+
+func (client *chaosCollectorClient) activeTransport() string {
+	return activeTransport(client.collectorClient)
+}
+
+func (client *chaosCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	if delay := client.delay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+
+	if chance(client.chaos.FailureRate) {
+		return nil, errChaosFailure
+	}
+
+	resp, err := client.collectorClient.Report(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	if chance(client.chaos.DropResponseRate) {
+		return nil, errChaosDropResponse
+	}
+
+	chaosResp := chaosResponse{collectorResponse: resp}
+	chaosResp.failed = chance(client.chaos.ErrorResponseRate)
+	chaosResp.disable = chance(client.chaos.DisableRate)
+	if !chaosResp.failed && !chaosResp.disable {
+		return resp, nil
+	}
+	return chaosResp, nil
+}
+
+func (client *chaosCollectorClient) delay() time.Duration {
+	delay := client.chaos.Latency
+	if client.chaos.LatencyJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(client.chaos.LatencyJitter)))
+	}
+	return delay
+}
+
+func chance(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+// chaosResponse overrides the errors and commands of a collectorResponse.
+type chaosResponse struct {
+	collectorResponse
+	failed  bool
+	disable bool
+}
+
+func (resp chaosResponse) GetErrors() []string {
+	errs := resp.collectorResponse.GetErrors()
+	if resp.failed {
+		errs = append(errs[:len(errs):len(errs)], chaosErrorResponse)
+	}
+	return errs
+}
+
+func (resp chaosResponse) Disable() bool {
+	return resp.disable || resp.collectorResponse.Disable()
+}
@@ -0,0 +1,95 @@
+package lightstep
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// stubCollectorClient counts reports and answers each with resp.
+type stubCollectorClient struct {
+	collectorClient
+	reports int
+	resp    collectorResponse
+}
+
+func (client *stubCollectorClient) Report(context.Context, reportRequest) (collectorResponse, error) {
+	client.reports++
+	return client.resp, nil
+}
+
+type stubResponse struct {
+	errors  []string
+	disable bool
+}
+
+func (resp stubResponse) GetErrors() []string { return resp.errors }
+func (resp stubResponse) Disable() bool       { return resp.disable }
+
+var _ = Describe("chaosCollectorClient", func() {
+	var stub *stubCollectorClient
+
+	BeforeEach(func() {
+		stub = &stubCollectorClient{resp: stubResponse{errors: []string{"collector error"}}}
+	})
+
+	report := func(chaos ChaosOptions) (collectorResponse, error) {
+		return newChaosCollectorClient(stub, chaos).Report(context.Background(), reportRequest{})
+	}
+
+	It("passes reports through when no faults are configured", func() {
+		resp, err := report(ChaosOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal(stub.resp))
+		Expect(stub.reports).To(Equal(1))
+	})
+
+	It("fails reports before sending them", func() {
+		_, err := report(ChaosOptions{FailureRate: 1})
+		Expect(err).To(Equal(errChaosFailure))
+		Expect(stub.reports).To(Equal(0))
+	})
+
+	It("drops responses after sending reports", func() {
+		_, err := report(ChaosOptions{DropResponseRate: 1})
+		Expect(err).To(Equal(errChaosDropResponse))
+		Expect(stub.reports).To(Equal(1))
+	})
+
+	It("adds errors to responses", func() {
+		resp, err := report(ChaosOptions{ErrorResponseRate: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetErrors()).To(Equal([]string{"collector error", chaosErrorResponse}))
+		Expect(resp.Disable()).To(BeFalse())
+	})
+
+	It("sends Disable commands", func() {
+		resp, err := report(ChaosOptions{DisableRate: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetErrors()).To(Equal([]string{"collector error"}))
+		Expect(resp.Disable()).To(BeTrue())
+	})
+
+	It("delays reports", func() {
+		start := time.Now()
+		_, err := report(ChaosOptions{Latency: 20 * time.Millisecond})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+	})
+
+	It("gives up on delayed reports when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		client := newChaosCollectorClient(stub, ChaosOptions{Latency: time.Minute})
+		_, err := client.Report(ctx, reportRequest{})
+		Expect(err).To(Equal(context.Canceled))
+		Expect(stub.reports).To(Equal(0))
+	})
+
+	It("is rejected by Validate with a rate out of range", func() {
+		opts := Options{AccessToken: "token", Chaos: ChaosOptions{FailureRate: 1.5}}
+		Expect(opts.Validate()).To(Equal(validationErrorChaosRate))
+	})
+})
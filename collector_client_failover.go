@@ -0,0 +1,145 @@
+package lightstep
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultFailoverProbePeriod is the default Options.FailoverProbePeriod.
+const DefaultFailoverProbePeriod = time.Minute
+
+// failoverCollectorClient reports to one of its clients at a time: the
+// primary Collector, or while it fails, the next of Options.FailoverCollectors.
+// While failed over, every report after probePeriod is sent to the primary,
+// and reporting returns to it if the report succeeds.
+type failoverCollectorClient struct {
+	clients     []collectorClient
+	endpoints   []Endpoint
+	probePeriod time.Duration
+
+	lock sync.Mutex
+	// active is the index of the client reports are sent to.
+	active int
+	// lastProbe is when the primary was last tried, while failed over.
+	lastProbe time.Time
+}
+
+func newFailoverCollectorClient(opts Options, reporterID uint64, attributes map[string]string) (*failoverCollectorClient, error) {
+	failover := &failoverCollectorClient{
+		endpoints:   append([]Endpoint{opts.Collector}, opts.FailoverCollectors...),
+		probePeriod: opts.FailoverProbePeriod,
+	}
+	for _, collector := range failover.endpoints {
+		endpointOpts := opts
+		endpointOpts.Collector = collector
+		endpointOpts.FailoverCollectors = nil
+
+		client, err := newTransportClient(endpointOpts, reporterID, attributes)
+		if err != nil {
+			return nil, err
+		}
+		failover.clients = append(failover.clients, client)
+	}
+	return failover, nil
+}
+
+// ConnectClient connects every client, so that failing over does not wait
+// for a reconnect. The gRPC transport connects in the background, and the
+// HTTP transports on their first report.
+func (client *failoverCollectorClient) ConnectClient() (Connection, error) {
+	conns := make(multiConnection, 0, len(client.clients))
+	for _, endpoint := range client.clients {
+		conn, err := endpoint.ConnectClient()
+		if err != nil {
+			conns.Close()
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+func (client *failoverCollectorClient) waitForConnection(ctx context.Context, conn Connection) error {
+	conns, ok := conn.(multiConnection)
+	if !ok || len(conns) != len(client.clients) {
+		return nil
+	}
+	client.lock.Lock()
+	active := client.active
+	client.lock.Unlock()
+
+	if waiter, ok := client.clients[active].(connectionWaiter); ok {
+		return waiter.waitForConnection(ctx, conns[active])
+	}
+	return nil
+}
+
+func (client *failoverCollectorClient) ShouldReconnect() bool {
+	for _, endpoint := range client.clients {
+		if endpoint.ShouldReconnect() {
+			return true
+		}
+	}
+	return false
+}
+
+// pick returns the index of the client the next report is sent to.
+func (client *failoverCollectorClient) pick(now time.Time) int {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.active != 0 && now.Sub(client.lastProbe) >= client.probePeriod {
+		client.lastProbe = now
+		return 0
+	}
+	return client.active
+}
+
+// Translate translates the buffer for the client it will be sent to. The
+// request has one shard per client, all but that one empty.
+func (client *failoverCollectorClient) Translate(ctx context.Context, buffer *reportBuffer) (reportRequest, error) {
+	i := client.pick(time.Now())
+	endpointReq, err := client.clients[i].Translate(ctx, buffer)
+	if err != nil {
+		return reportRequest{}, err
+	}
+	req := reportRequest{shards: make([]reportRequest, len(client.clients))}
+	endpointReq.spanCount = len(buffer.rawSpans)
+	req.shards[i] = endpointReq
+	return req, nil
+}
+
+// Report sends the request to the client it was translated for, failing
+// over to the next client if it was the active one and the report failed.
+func (client *failoverCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	i := 0
+	for i < len(req.shards)-1 && req.shards[i].empty() {
+		i++
+	}
+	resp, err := client.clients[i].Report(ctx, req.shards[i])
+
+	if event := client.record(i, err, time.Now()); event != nil {
+		emitEvent(event)
+	}
+	return resp, err
+}
+
+// record updates the active client after a report to client i, returning
+// an EventCollectorFailover if it changed.
+func (client *failoverCollectorClient) record(i int, err error, now time.Time) Event {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	switch {
+	case err != nil && i == client.active:
+		client.active = (i + 1) % len(client.clients)
+		if i == 0 {
+			client.lastProbe = now
+		}
+		return newEventCollectorFailover(client.endpoints[client.active], err)
+	case err == nil && i != client.active:
+		client.active = i
+		return newEventCollectorFailover(client.endpoints[i], nil)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package lightstep
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// failoverTestCollector counts the reports it receives, and fails them
+// while down is set.
+type failoverTestCollector struct {
+	*httptest.Server
+	reports int32
+	down    int32
+}
+
+func startFailoverTestCollector() *failoverTestCollector {
+	collector := &failoverTestCollector{}
+	collector.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			return
+		}
+		atomic.AddInt32(&collector.reports, 1)
+		if atomic.LoadInt32(&collector.down) != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		response, _ := proto.Marshal(&cpb.ReportResponse{})
+		w.Write(response)
+	}))
+	return collector
+}
+
+func (collector *failoverTestCollector) endpoint() Endpoint {
+	serverURL, err := url.Parse(collector.URL)
+	Expect(err).NotTo(HaveOccurred())
+	port, err := strconv.Atoi(serverURL.Port())
+	Expect(err).NotTo(HaveOccurred())
+	return Endpoint{Host: serverURL.Hostname(), Port: port, Plaintext: true}
+}
+
+func (collector *failoverTestCollector) setDown(down bool) {
+	var value int32
+	if down {
+		value = 1
+	}
+	atomic.StoreInt32(&collector.down, value)
+}
+
+func (collector *failoverTestCollector) reportCount() int {
+	return int(atomic.LoadInt32(&collector.reports))
+}
+
+var _ = Describe("failoverCollectorClient", func() {
+	var primary, backup *failoverTestCollector
+	var client collectorClient
+	var events chan Event
+
+	const probePeriod = 50 * time.Millisecond
+
+	BeforeEach(func() {
+		primary = startFailoverTestCollector()
+		backup = startFailoverTestCollector()
+
+		events = make(chan Event, 10)
+		SetGlobalEventHandler(func(e Event) {
+			if _, ok := e.(EventCollectorFailover); ok {
+				events <- e
+			}
+		})
+
+		opts := Options{
+			AccessToken:         "token",
+			UseHttp:             true,
+			Collector:           primary.endpoint(),
+			FailoverCollectors:  []Endpoint{backup.endpoint()},
+			FailoverProbePeriod: probePeriod,
+		}
+		Expect(opts.Initialize()).To(Succeed())
+		Expect(opts.Validate()).To(Succeed())
+
+		var err error
+		client, err = newCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		SetGlobalEventHandler(NewEventLogOneError())
+		primary.Close()
+		backup.Close()
+	})
+
+	report := func() error {
+		buffer := newSpansBuffer(10, 0)
+		buffer.addSpan(RawSpan{Context: SpanContext{TraceID: 1, SpanID: 1}})
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Report(context.Background(), req)
+		return err
+	}
+
+	It("fails over to the next collector and probes the primary", func() {
+		Expect(report()).To(Succeed())
+		Expect(primary.reportCount()).To(Equal(1))
+
+		primary.setDown(true)
+		Expect(report()).NotTo(Succeed())
+		var event EventCollectorFailover
+		Expect(events).To(Receive(&event))
+		Expect(event.Collector()).To(Equal(backup.endpoint()))
+		Expect(event.Err()).To(HaveOccurred())
+
+		Expect(report()).To(Succeed())
+		Expect(backup.reportCount()).To(Equal(1))
+		Expect(primary.reportCount()).To(Equal(2))
+
+		// A failed probe leaves the backup active.
+		time.Sleep(probePeriod)
+		Expect(report()).NotTo(Succeed())
+		Expect(primary.reportCount()).To(Equal(3))
+		Expect(events).NotTo(Receive())
+		Expect(report()).To(Succeed())
+		Expect(backup.reportCount()).To(Equal(2))
+
+		primary.setDown(false)
+		time.Sleep(probePeriod)
+		Expect(report()).To(Succeed())
+		Expect(primary.reportCount()).To(Equal(4))
+		Expect(events).To(Receive(&event))
+		Expect(event.Collector()).To(Equal(primary.endpoint()))
+		Expect(event.Err()).NotTo(HaveOccurred())
+
+		Expect(report()).To(Succeed())
+		Expect(primary.reportCount()).To(Equal(5))
+		Expect(backup.reportCount()).To(Equal(2))
+	})
+
+	It("can't be combined with a pool of collectors", func() {
+		opts := Options{
+			AccessToken:        "token",
+			Collectors:         []Endpoint{{Host: "collector-0"}},
+			FailoverCollectors: []Endpoint{{Host: "collector-1"}},
+		}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,129 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep
+
+import (
+	"context"
+	"sync"
+)
+
+// fallbackCollectorClient reports over gRPC until GRPCFallbackAfter
+// consecutive reports have failed, and over HTTP from then on.
+type fallbackCollectorClient struct {
+	lock sync.Mutex
+
+	grpc      collectorClient
+	http      collectorClient
+	failures  int
+	threshold int
+
+	// fellBack is set once the client has switched to HTTP. pendingConn
+	// holds the HTTP connection until the tracer reconnects.
+	fellBack    bool
+	pendingConn Connection
+}
+
+func newFallbackCollectorClient(opts Options, reporterID uint64, attributes map[string]string) (*fallbackCollectorClient, error) {
+	httpClient, err := newHttpCollectorClient(opts, reporterID, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return &fallbackCollectorClient{
+		grpc:      newGrpcCollectorClient(opts, reporterID, attributes),
+		http:      httpClient,
+		threshold: opts.GRPCFallbackAfter,
+	}, nil
+}
+
+func (client *fallbackCollectorClient) active() collectorClient {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.fellBack {
+		return client.http
+	}
+	return client.grpc
+}
+
+func (client *fallbackCollectorClient) activeTransport() string {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if client.fellBack {
+		return "HTTP"
+	}
+	return "gRPC"
+}
+
+func (client *fallbackCollectorClient) ConnectClient() (Connection, error) {
+	client.lock.Lock()
+	if conn := client.pendingConn; conn != nil {
+		client.pendingConn = nil
+		client.lock.Unlock()
+		return conn, nil
+	}
+	client.lock.Unlock()
+
+	conn, err := client.active().ConnectClient()
+	if err != nil && client.recordFailure(err) {
+		return client.ConnectClient()
+	}
+	return conn, err
+}
+
+func (client *fallbackCollectorClient) waitForConnection(ctx context.Context, conn Connection) error {
+	if waiter, ok := client.active().(connectionWaiter); ok {
+		return waiter.waitForConnection(ctx, conn)
+	}
+	return nil
+}
+
+func (client *fallbackCollectorClient) ShouldReconnect() bool {
+	client.lock.Lock()
+	pending := client.pendingConn != nil
+	client.lock.Unlock()
+	return pending || client.active().ShouldReconnect()
+}
+
+func (client *fallbackCollectorClient) Translate(ctx context.Context, buffer *reportBuffer) (reportRequest, error) {
+	return client.active().Translate(ctx, buffer)
+}
+
+func (client *fallbackCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	resp, err := client.active().Report(ctx, req)
+	if err != nil {
+		client.recordFailure(err)
+		return nil, err
+	}
+
+	client.lock.Lock()
+	client.failures = 0
+	client.lock.Unlock()
+	return resp, nil
+}
+
+// recordFailure counts a failed gRPC attempt, and switches to HTTP when the
+// threshold is reached. It reports whether the switch happened.
+func (client *fallbackCollectorClient) recordFailure(err error) bool {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	if client.fellBack {
+		return false
+	}
+	client.failures++
+	if client.failures < client.threshold {
+		return false
+	}
+
+	// Connect eagerly, so the next report can be sent before the tracer
+	// replaces its gRPC connection.
+	conn, connErr := client.http.ConnectClient()
+	if connErr != nil {
+		emitEvent(newEventConnectionError(connErr))
+		return false
+	}
+	client.fellBack = true
+	client.pendingConn = conn
+	emitEvent(newEventTransportFallback("HTTP", err))
+	return true
+}
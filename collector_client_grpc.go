@@ -1,26 +1,36 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
 package lightstep
 
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"io"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 
 	// N.B.(jmacd): Do not use google.golang.org/glog in this package.
 	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
 )
 
-const (
-	spansDropped     = "spans.dropped"
-	logEncoderErrors = "log_encoder.errors"
-)
+// DialOption configures the gRPC connection, see Options.DialOptions.
+type DialOption = grpc.DialOption
 
-var (
-	intType = reflect.TypeOf(int64(0))
-)
+const grpcTransportAvailable = true
+
+func newGrpcTransport(opts Options, reporterID uint64, attributes map[string]string) (collectorClient, error) {
+	if opts.GRPCFallbackToHttp {
+		return newFallbackCollectorClient(opts, reporterID, attributes)
+	}
+	return newGrpcCollectorClient(opts, reporterID, attributes), nil
+}
 
 // grpcCollectorClient specifies how to send reports back to a LightStep
 // collector via grpc.
@@ -40,6 +50,8 @@ type grpcCollectorClient struct {
 	grpcClient    cpb.CollectorServiceClient
 	connTimestamp time.Time
 	dialOptions   []grpc.DialOption
+	// headers are the Options.CustomHeaders sent as metadata of reports.
+	headers map[string]string
 
 	// converters
 	converter *protoConverter
@@ -56,7 +68,8 @@ func newGrpcCollectorClient(opts Options, reporterID uint64, attributes map[stri
 		maxReportingPeriod:   opts.ReportingPeriod,
 		reconnectPeriod:      opts.ReconnectPeriod,
 		reportingTimeout:     opts.ReportTimeout,
-		dialOptions:          opts.DialOptions,
+		dialOptions:          grpcDialOptions(opts),
+		headers:              opts.CustomHeaders,
 		converter:            newProtoConverter(opts),
 		grpcConnectorFactory: opts.ConnFactory,
 	}
@@ -67,14 +80,90 @@ func newGrpcCollectorClient(opts Options, reporterID uint64, attributes map[stri
 		rec.address = opts.Collector.SocketAddress()
 	}
 
-	rec.dialOptions = append(rec.dialOptions, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(opts.GRPCMaxCallSendMsgSizeBytes)))
-	if opts.Collector.Plaintext {
-		rec.dialOptions = append(rec.dialOptions, grpc.WithInsecure())
-	} else {
-		rec.dialOptions = append(rec.dialOptions, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "")))
+	return rec
+}
+
+// grpcDialOptions returns the options the gRPC and OTLP/gRPC transports dial
+// the collector with. Options.DialOptions come last, so that they can
+// replace the tracer's own credentials, dialer or call options.
+func grpcDialOptions(opts Options) []grpc.DialOption {
+	dialOptions := []grpc.DialOption{
+		proxyDialOption(opts, opts.Collector),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(opts.GRPCMaxCallSendMsgSizeBytes)),
+		transportSecurity(opts.Collector),
 	}
+	dialOptions = append(dialOptions, compressionDialOptions(opts.Compression)...)
+	dialOptions = append(dialOptions, keepaliveDialOptions(opts)...)
+	return append(dialOptions, opts.DialOptions...)
+}
 
-	return rec
+// withCustomMetadata adds Options.CustomHeaders to the metadata of a report.
+func withCustomMetadata(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	kv := make([]string, 0, 2*len(headers))
+	for name, value := range headers {
+		kv = append(kv, strings.ToLower(name), value)
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// keepaliveDialOptions returns the dial options enabling keepalive pings, if
+// GRPCKeepaliveTime is set.
+func keepaliveDialOptions(opts Options) []grpc.DialOption {
+	if opts.GRPCKeepaliveTime <= 0 {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                opts.GRPCKeepaliveTime,
+		Timeout:             opts.GRPCKeepaliveTimeout,
+		PermitWithoutStream: opts.GRPCKeepalivePermitWithoutStream,
+	})}
+}
+
+// transportSecurity returns the dial option securing connections to e.
+func transportSecurity(e Endpoint) grpc.DialOption {
+	if e.Plaintext {
+		return grpc.WithInsecure()
+	}
+	if config := e.tlsConfig(); config != nil {
+		return grpc.WithTransportCredentials(credentials.NewTLS(config))
+	}
+	return grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, ""))
+}
+
+// compressionDialOptions returns the dial options compressing reports with
+// the named CompressionCodec, if it is registered.
+func compressionDialOptions(name string) []grpc.DialOption {
+	codec, ok := lookupCompressionCodec(name)
+	if !ok {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithCompressor(grpcCompressor{codec: codec})}
+}
+
+// grpcCompressor adapts a CompressionCodec to grpc.Compressor.
+type grpcCompressor struct {
+	codec CompressionCodec
+}
+
+func (c grpcCompressor) Do(w io.Writer, p []byte) error {
+	return c.codec.Compress(w, p)
+}
+
+func (c grpcCompressor) Type() string {
+	return c.codec.Name()
+}
+
+// proxyDialOption returns the dial option connecting to e through the proxy
+// chosen for it.
+func proxyDialOption(opts Options, e Endpoint) grpc.DialOption {
+	scheme := "https"
+	if e.Plaintext {
+		scheme = "http"
+	}
+	return grpc.WithDialer(newProxyDialer(opts, scheme).dial)
 }
 
 func (client *grpcCollectorClient) ConnectClient() (Connection, error) {
@@ -106,6 +195,30 @@ func (client *grpcCollectorClient) ConnectClient() (Connection, error) {
 	return conn, nil
 }
 
+// waitForConnection blocks until the gRPC connection is ready. Connections
+// provided by a ConnFactory are assumed to be ready.
+func (client *grpcCollectorClient) waitForConnection(ctx context.Context, conn Connection) error {
+	return waitForGRPCConnection(ctx, conn)
+}
+
+// waitForGRPCConnection blocks until conn, if it is a gRPC connection, is
+// ready.
+func waitForGRPCConnection(ctx context.Context, conn Connection) error {
+	grpcConn, ok := conn.(*grpc.ClientConn)
+	if !ok {
+		return nil
+	}
+	for {
+		state := grpcConn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !grpcConn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("collector connection is %v: %v", state, ctx.Err())
+		}
+	}
+}
+
 func (client *grpcCollectorClient) ShouldReconnect() bool {
 	return time.Now().Sub(client.connTimestamp) > client.reconnectPeriod
 }
@@ -114,7 +227,7 @@ func (client *grpcCollectorClient) Report(ctx context.Context, req reportRequest
 	if req.protoRequest == nil {
 		return nil, fmt.Errorf("protoRequest cannot be null")
 	}
-	resp, err := client.grpcClient.Report(ctx, req.protoRequest)
+	resp, err := client.grpcClient.Report(withCustomMetadata(ctx, client.headers), req.protoRequest)
 	if err != nil {
 		return nil, err
 	}
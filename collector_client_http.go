@@ -3,6 +3,7 @@ package lightstep
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -18,6 +19,8 @@ import (
 var (
 	acceptHeader      = http.CanonicalHeaderKey("Accept")
 	contentTypeHeader = http.CanonicalHeaderKey("Content-Type")
+
+	contentEncodingHeader = http.CanonicalHeaderKey("Content-Encoding")
 )
 
 const (
@@ -37,8 +40,20 @@ type httpCollectorClient struct {
 	reportTimeout time.Duration
 
 	// Remote service that will receive reports.
-	url    *url.URL
-	client *http.Client
+	url       *url.URL
+	tlsConfig *tls.Config
+	dialer    proxyDialer
+	client    *http.Client
+	// custom is the client built from Options.HTTPClient or
+	// Options.HTTPRoundTripper, if either is set.
+	custom *http.Client
+
+	// protocol is the Options.HTTPProtocol reports are sent with.
+	protocol string
+	// codec compresses reports, if Options.Compression is set.
+	codec CompressionCodec
+	// headers are the Options.CustomHeaders added to reports.
+	headers map[string]string
 
 	// converters
 	converter *protoConverter
@@ -69,37 +84,72 @@ func newHttpCollectorClient(
 		return nil, err
 	}
 	url.Path = collectorHttpPath
+	if opts.HTTPProtocol != HTTPProtocolDefault {
+		url.Path = collectorServicePath
+	}
 
-	return &httpCollectorClient{
+	client := &httpCollectorClient{
 		reporterID:    reporterID,
 		accessToken:   opts.AccessToken,
 		attributes:    attributes,
 		reportTimeout: opts.ReportTimeout,
 		url:           url,
+		tlsConfig:     opts.Collector.tlsConfig(),
+		dialer:        newProxyDialer(opts, url.Scheme),
+		custom:        customHTTPClient(opts),
+		protocol:      opts.HTTPProtocol,
+		headers:       opts.CustomHeaders,
 		converter:     newProtoConverter(opts),
-	}, nil
+	}
+	client.codec, _ = lookupCompressionCodec(opts.Compression)
+	return client, nil
 }
 
-func (client *httpCollectorClient) ConnectClient() (Connection, error) {
-	// The golang http2 client implementation doesn't support plaintext http2 (a.k.a h2c) out of the box.
-	// According to https://github.com/golang/go/issues/14141, they don't have plans to.
-	// For now, we are falling back to http1 for plaintext.
-	// In the future, we might want to add out own h2c implementation (see https://github.com/hkwi/h2c).
-	var transport http.RoundTripper
-	if client.url.Scheme == "https" {
-		transport = &http2.Transport{}
-	} else {
-		transport = &http.Transport{}
+// customHTTPClient returns the client the HTTP transports report with if
+// Options.HTTPClient or Options.HTTPRoundTripper is set, otherwise nil.
+func customHTTPClient(opts Options) *http.Client {
+	var client http.Client
+	switch {
+	case opts.HTTPClient != nil:
+		client = *opts.HTTPClient
+	case opts.HTTPRoundTripper != nil:
+		client.Transport = opts.HTTPRoundTripper
+	default:
+		return nil
 	}
+	if client.Timeout == 0 {
+		client.Timeout = opts.ReportTimeout
+	}
+	return &client
+}
 
+func (client *httpCollectorClient) ConnectClient() (Connection, error) {
+	if client.custom != nil {
+		client.client = client.custom
+		return &transportCloser{}, nil
+	}
 	client.client = &http.Client{
-		Transport: transport,
+		Transport: newHTTPRoundTripper(client.url.Scheme, client.tlsConfig, client.dialer),
 		Timeout:   client.reportTimeout,
 	}
 
 	return &transportCloser{}, nil
 }
 
+// waitForConnection opens a connection to the collector with a HEAD
+// request, which the http.Client keeps for the first report.
+func (client *httpCollectorClient) waitForConnection(ctx context.Context, _ Connection) error {
+	request, err := http.NewRequest(http.MethodHead, client.url.String(), nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
 func (client *httpCollectorClient) ShouldReconnect() bool {
 	// http2 will handle connection reuse under the hood
 	return false
@@ -150,6 +200,14 @@ func (client *httpCollectorClient) toRequest(
 	if err != nil {
 		return nil, err
 	}
+	if client.protocol == HTTPProtocolGRPCWeb {
+		buf = encodeGRPCWebMessage(buf)
+	}
+	if client.codec != nil {
+		if buf, err = compress(client.codec, buf); err != nil {
+			return nil, err
+		}
+	}
 
 	requestBody := bytes.NewReader(buf)
 
@@ -158,14 +216,19 @@ func (client *httpCollectorClient) toRequest(
 		return nil, err
 	}
 	request = request.WithContext(context)
+	setCustomHeaders(request.Header, client.headers)
 	request.Header.Set(contentTypeHeader, protoContentType)
 	request.Header.Set(acceptHeader, protoContentType)
+	setWebProtocolHeaders(client.protocol, request.Header)
+	if client.codec != nil {
+		request.Header.Set(contentEncodingHeader, client.codec.Name())
+	}
 
 	return request, nil
 }
 
 func (client *httpCollectorClient) toResponse(response *http.Response) (collectorResponse, error) {
-	if response.StatusCode != http.StatusOK {
+	if response.StatusCode != http.StatusOK && client.protocol != HTTPProtocolConnect {
 		return nil, errors.New(fmt.Sprintf("status code (%d) is not ok", response.StatusCode))
 	}
 
@@ -174,6 +237,17 @@ func (client *httpCollectorClient) toResponse(response *http.Response) (collecto
 		return nil, err
 	}
 
+	switch client.protocol {
+	case HTTPProtocolConnect:
+		if response.StatusCode != http.StatusOK {
+			return nil, decodeConnectError(response.StatusCode, body)
+		}
+	case HTTPProtocolGRPCWeb:
+		if body, err = decodeGRPCWebResponse(response.Header, body); err != nil {
+			return nil, err
+		}
+	}
+
 	protoResponse := &collectorpb.ReportResponse{}
 	if err := proto.Unmarshal(body, protoResponse); err != nil {
 		return nil, err
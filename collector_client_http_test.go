@@ -0,0 +1,111 @@
+package lightstep
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/http2"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+var _ = Describe("httpCollectorClient TLS", func() {
+	var server *httptest.Server
+	var endpoint Endpoint
+	var opts Options
+
+	BeforeEach(func() {
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response, _ := proto.Marshal(&cpb.ReportResponse{})
+			w.Write(response)
+		}))
+		Expect(http2.ConfigureServer(server.Config, &http2.Server{})).To(Succeed())
+		server.TLS = server.Config.TLSConfig
+		server.StartTLS()
+
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(serverURL.Port())
+		Expect(err).NotTo(HaveOccurred())
+		endpoint = Endpoint{Host: serverURL.Hostname(), Port: port}
+		opts = Options{AccessToken: "token", UseHttp: true}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	report := func() error {
+		opts.Collector = endpoint
+		Expect(opts.Initialize()).To(Succeed())
+		client, err := newHttpCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer := newSpansBuffer(10, 0)
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Report(context.Background(), req)
+		return err
+	}
+
+	It("trusts the CAs of the endpoint's TLSConfig", func() {
+		roots := x509.NewCertPool()
+		roots.AddCert(server.Certificate())
+		endpoint.TLSConfig = &tls.Config{RootCAs: roots}
+		Expect(report()).To(Succeed())
+	})
+
+	It("rejects unknown CAs by default", func() {
+		Expect(report()).To(HaveOccurred())
+	})
+
+	Context("with a custom client", func() {
+		var requests int
+		var transport http.RoundTripper
+
+		BeforeEach(func() {
+			requests = 0
+			roots := x509.NewCertPool()
+			roots.AddCert(server.Certificate())
+			base := &http2.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}
+			transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				requests++
+				return base.RoundTrip(r)
+			})
+		})
+
+		It("reports with Options.HTTPClient", func() {
+			opts.HTTPClient = &http.Client{Transport: transport}
+			Expect(report()).To(Succeed())
+			Expect(requests).To(Equal(1))
+			Expect(opts.HTTPClient.Timeout).To(BeZero())
+		})
+
+		It("reports with Options.HTTPRoundTripper", func() {
+			opts.HTTPRoundTripper = transport
+			Expect(report()).To(Succeed())
+			Expect(requests).To(Equal(1))
+		})
+	})
+
+	It("rejects TLSConfig with Plaintext", func() {
+		opts := Options{AccessToken: "token", Collector: Endpoint{Plaintext: true, TLSConfig: &tls.Config{}}}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,23 @@
+//go:build lightstep_nogrpc || lightstep_constrained || js
+// +build lightstep_nogrpc lightstep_constrained js
+
+package lightstep
+
+import (
+	"errors"
+)
+
+// DialOption stands in for grpc.DialOption, see Options.DialOptions.
+type DialOption interface{}
+
+const grpcTransportAvailable = false
+
+var errGRPCExcluded = errors.New("the gRPC transport is excluded by the lightstep_nogrpc or lightstep_constrained build tag, or by GOOS=js")
+
+func newGrpcTransport(Options, uint64, map[string]string) (collectorClient, error) {
+	return nil, errGRPCExcluded
+}
+
+func newOTLPCollectorClient(Options, uint64, map[string]string) (collectorClient, error) {
+	return nil, errGRPCExcluded
+}
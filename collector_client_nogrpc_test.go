@@ -0,0 +1,38 @@
+//go:build lightstep_nogrpc || lightstep_constrained || js
+// +build lightstep_nogrpc lightstep_constrained js
+
+package lightstep
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Options without gRPC", func() {
+	It("rejects the gRPC transport", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", UseGRPC: true}
+		Expect(opts.Validate()).To(Equal(validationErrorGRPCExcluded))
+	})
+
+	It("rejects OTLP over gRPC", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", UseOTLP: true}
+		Expect(opts.Validate()).To(Equal(validationErrorGRPCExcluded))
+	})
+
+	It("rejects gRPC keepalives", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", UseHttp: true, GRPCKeepaliveTime: time.Minute}
+		Expect(opts.Validate()).To(Equal(validationErrorGRPCExcluded))
+	})
+
+	It("accepts the HTTP transport", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", UseHttp: true}
+		Expect(opts.Validate()).To(Succeed())
+	})
+
+	It("doesn't create gRPC clients", func() {
+		_, err := newGrpcTransport(Options{}, 1, nil)
+		Expect(err).To(Equal(errGRPCExcluded))
+	})
+})
@@ -0,0 +1,21 @@
+//go:build lightstep_nothrift || lightstep_constrained
+// +build lightstep_nothrift lightstep_constrained
+
+package lightstep
+
+import (
+	"errors"
+)
+
+var errThriftExcluded = errors.New("the thrift transport is excluded by the lightstep_nothrift or lightstep_constrained build tag")
+
+// thriftReportRequest stands in for the thrift request, which is never built.
+type thriftReportRequest struct{}
+
+func newThriftTransport(Options, uint64, map[string]string) (collectorClient, error) {
+	return nil, errThriftExcluded
+}
+
+func (r reportRequest) thriftPayload() ([]byte, error) {
+	return nil, errThriftExcluded
+}
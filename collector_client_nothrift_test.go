@@ -0,0 +1,26 @@
+//go:build lightstep_nothrift
+// +build lightstep_nothrift
+
+package lightstep
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("newCollectorClient without thrift", func() {
+	It("falls back to the next transport", func() {
+		events := make(chan Event, 1)
+		SetGlobalEventHandler(func(e Event) { events <- e })
+		defer SetGlobalEventHandler(NewEventLogOneError())
+
+		client, err := newCollectorClient(Options{UseThrift: true, UseHttp: true}, 1, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client).To(BeAssignableToTypeOf(&httpCollectorClient{}))
+
+		var event Event
+		Expect(events).To(Receive(&event))
+		Expect(event).To(BeAssignableToTypeOf(&eventTransportFallback{}))
+		Expect(event.(ErrorEvent).Err()).To(Equal(errThriftExcluded))
+	})
+})
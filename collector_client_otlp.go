@@ -0,0 +1,97 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const otlpExportMethod = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+
+// otlpCollectorClient reports spans to an OpenTelemetry collector using
+// the OTLP/gRPC protocol, see Options.UseOTLP.
+type otlpCollectorClient struct {
+	// auth and runtime information
+	attributes  map[string]string
+	reporterID  uint64
+	accessToken string
+
+	reconnectPeriod time.Duration
+
+	// Remote service that will receive reports.
+	address       string
+	conn          *grpc.ClientConn
+	connTimestamp time.Time
+	dialOptions   []grpc.DialOption
+	// headers are the Options.CustomHeaders sent as metadata of reports.
+	headers map[string]string
+
+	// converters
+	converter *protoConverter
+}
+
+func newOTLPCollectorClient(opts Options, reporterID uint64, attributes map[string]string) (collectorClient, error) {
+	client := &otlpCollectorClient{
+		attributes:      attributes,
+		reporterID:      reporterID,
+		accessToken:     opts.AccessToken,
+		reconnectPeriod: opts.ReconnectPeriod,
+		address:         opts.Collector.SocketAddress(),
+		dialOptions:     grpcDialOptions(opts),
+		headers:         opts.CustomHeaders,
+		converter:       newProtoConverter(opts),
+	}
+
+	return client, nil
+}
+
+func (client *otlpCollectorClient) ConnectClient() (Connection, error) {
+	conn, err := grpc.Dial(client.address, client.dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	client.conn = conn
+	client.connTimestamp = time.Now()
+	return conn, nil
+}
+
+func (client *otlpCollectorClient) waitForConnection(ctx context.Context, conn Connection) error {
+	return waitForGRPCConnection(ctx, conn)
+}
+
+func (client *otlpCollectorClient) ShouldReconnect() bool {
+	return time.Now().Sub(client.connTimestamp) > client.reconnectPeriod
+}
+
+func (client *otlpCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	if req.otlpRequest == nil {
+		return nil, fmt.Errorf("otlpRequest cannot be null")
+	}
+	if client.accessToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, otlpAccessTokenHeader, client.accessToken)
+	}
+	ctx = withCustomMetadata(ctx, client.headers)
+	resp := &otlpExportResponse{}
+	if err := grpc.Invoke(ctx, otlpExportMethod, req.otlpRequest, resp, client.conn); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (client *otlpCollectorClient) Translate(ctx context.Context, buffer *reportBuffer) (reportRequest, error) {
+	req := client.converter.toReportRequest(
+		client.reporterID,
+		client.attributes,
+		client.accessToken,
+		buffer,
+	)
+	return reportRequest{
+		otlpRequest: newOTLPExportRequest(req, buffer.rawSpans),
+	}, nil
+}
@@ -0,0 +1,169 @@
+package lightstep
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Protocols for Options.OTLPProtocol, named as by the
+// OTEL_EXPORTER_OTLP_PROTOCOL environment variable.
+const (
+	// OTLPProtocolGRPC reports with OTLP/gRPC.
+	OTLPProtocolGRPC = "grpc"
+	// OTLPProtocolHTTP reports with OTLP/HTTP, posting binary protobuf
+	// requests, for networks that block gRPC.
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
+const (
+	otlpHTTPPath        = "/v1/traces"
+	otlpHTTPContentType = "application/x-protobuf"
+
+	// otlpAccessTokenHeader carries the access token to LightStep's OTLP
+	// endpoint. Other receivers ignore it.
+	otlpAccessTokenHeader = "lightstep-access-token"
+)
+
+func validOTLPProtocol(protocol string) bool {
+	switch protocol {
+	case "", OTLPProtocolGRPC, OTLPProtocolHTTP:
+		return true
+	}
+	return false
+}
+
+// otlpHTTPCollectorClient reports spans to an OpenTelemetry collector
+// using the OTLP/HTTP protocol, see Options.OTLPProtocol.
+type otlpHTTPCollectorClient struct {
+	// auth and runtime information
+	attributes  map[string]string
+	reporterID  uint64
+	accessToken string
+
+	reportTimeout time.Duration
+
+	// Remote service that will receive reports.
+	url       *url.URL
+	tlsConfig *tls.Config
+	dialer    proxyDialer
+	client    *http.Client
+	// custom is the client built from Options.HTTPClient or
+	// Options.HTTPRoundTripper, if either is set.
+	custom *http.Client
+
+	// codec compresses reports, if Options.Compression is set.
+	codec CompressionCodec
+	// headers are the Options.CustomHeaders added to reports.
+	headers map[string]string
+
+	// converters
+	converter *protoConverter
+}
+
+func newOTLPHTTPCollectorClient(opts Options, reporterID uint64, attributes map[string]string) (*otlpHTTPCollectorClient, error) {
+	url, err := url.Parse(opts.Collector.URL())
+	if err != nil {
+		return nil, err
+	}
+	url.Path = otlpHTTPPath
+
+	client := &otlpHTTPCollectorClient{
+		attributes:    attributes,
+		reporterID:    reporterID,
+		accessToken:   opts.AccessToken,
+		reportTimeout: opts.ReportTimeout,
+		url:           url,
+		tlsConfig:     opts.Collector.tlsConfig(),
+		dialer:        newProxyDialer(opts, url.Scheme),
+		custom:        customHTTPClient(opts),
+		headers:       opts.CustomHeaders,
+		converter:     newProtoConverter(opts),
+	}
+	client.codec, _ = lookupCompressionCodec(opts.Compression)
+	return client, nil
+}
+
+func (client *otlpHTTPCollectorClient) ConnectClient() (Connection, error) {
+	if client.custom != nil {
+		client.client = client.custom
+		return &transportCloser{}, nil
+	}
+	client.client = &http.Client{
+		Transport: newHTTPRoundTripper(client.url.Scheme, client.tlsConfig, client.dialer),
+		Timeout:   client.reportTimeout,
+	}
+
+	return &transportCloser{}, nil
+}
+
+func (client *otlpHTTPCollectorClient) ShouldReconnect() bool {
+	return false
+}
+
+func (client *otlpHTTPCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	if req.httpRequest == nil {
+		return nil, fmt.Errorf("httpRequest cannot be null")
+	}
+
+	httpResponse, err := client.client.Do(req.httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("otlp: status code (%d) is not ok", httpResponse.StatusCode)
+	}
+
+	resp := &otlpExportResponse{}
+	if err := resp.Unmarshal(body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (client *otlpHTTPCollectorClient) Translate(ctx context.Context, buffer *reportBuffer) (reportRequest, error) {
+	report := client.converter.toReportRequest(
+		client.reporterID,
+		client.attributes,
+		client.accessToken,
+		buffer,
+	)
+
+	body := newOTLPExportRequest(report, buffer.rawSpans).data
+	if client.codec != nil {
+		var err error
+		if body, err = compress(client.codec, body); err != nil {
+			return reportRequest{}, err
+		}
+	}
+
+	request, err := http.NewRequest(http.MethodPost, client.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return reportRequest{}, err
+	}
+	request = request.WithContext(ctx)
+	setCustomHeaders(request.Header, client.headers)
+	request.Header.Set(contentTypeHeader, otlpHTTPContentType)
+	request.Header.Set(acceptHeader, otlpHTTPContentType)
+	if client.accessToken != "" {
+		request.Header.Set(otlpAccessTokenHeader, client.accessToken)
+	}
+	if client.codec != nil {
+		request.Header.Set(contentEncodingHeader, client.codec.Name())
+	}
+
+	return reportRequest{
+		httpRequest: request,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package lightstep
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// otlpField returns the contents of the length-delimited fields at path,
+// each element of which is a field number in the enclosing message.
+func otlpField(data []byte, path ...int) [][]byte {
+	var found [][]byte
+	walkProtoFields(data, func(field int, wire int, value uint64, bytes []byte) error {
+		if field != path[0] {
+			return nil
+		}
+		if len(path) == 1 {
+			if wire == protoWireBytes {
+				found = append(found, bytes)
+			} else {
+				found = append(found, appendVarint(nil, value))
+			}
+			return nil
+		}
+		found = append(found, otlpField(bytes, path[1:]...)...)
+		return nil
+	})
+	return found
+}
+
+var _ = Describe("otlpHTTPCollectorClient", func() {
+	var server *httptest.Server
+	var requests chan *http.Request
+	var bodies chan []byte
+	var status int
+	var client collectorClient
+
+	BeforeEach(func() {
+		requests = make(chan *http.Request, 1)
+		bodies = make(chan []byte, 1)
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests <- r
+			bodies <- body
+			w.WriteHeader(status)
+		}))
+
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(serverURL.Port())
+		Expect(err).NotTo(HaveOccurred())
+		opts := Options{
+			AccessToken:  "token",
+			Collector:    Endpoint{Host: serverURL.Hostname(), Port: port, Plaintext: true},
+			UseOTLP:      true,
+			OTLPProtocol: OTLPProtocolHTTP,
+		}
+		Expect(opts.Initialize()).To(Succeed())
+		client, err = newCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	report := func() (collectorResponse, error) {
+		buffer := newSpansBuffer(10, 0)
+		buffer.addSpan(RawSpan{Context: SpanContext{TraceID: 1, SpanID: 2}, Operation: "charge"})
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		return client.Report(context.Background(), req)
+	}
+
+	It("posts spans as OTLP protobuf", func() {
+		resp, err := report()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetErrors()).To(BeEmpty())
+
+		r := <-requests
+		Expect(r.URL.Path).To(Equal("/v1/traces"))
+		Expect(r.Header.Get("Content-Type")).To(Equal("application/x-protobuf"))
+		Expect(r.Header.Get(otlpAccessTokenHeader)).To(Equal("token"))
+
+		spans := otlpField(<-bodies, otlpRequestResourceSpans, otlpResourceSpansScopeSpans, otlpScopeSpansSpans)
+		Expect(spans).To(HaveLen(1))
+		Expect(otlpField(spans[0], otlpSpanName)).To(Equal([][]byte{[]byte("charge")}))
+	})
+
+	It("reports 128-bit trace IDs whole", func() {
+		buffer := newSpansBuffer(10, 0)
+		buffer.addSpan(RawSpan{Context: SpanContext{TraceIDHigh: 0x0102030405060708, TraceID: 0x090a0b0c0d0e0f10, SpanID: 2}, Operation: "charge"})
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Report(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		<-requests
+
+		spans := otlpField(<-bodies, otlpRequestResourceSpans, otlpResourceSpansScopeSpans, otlpScopeSpansSpans)
+		Expect(spans).To(HaveLen(1))
+		Expect(otlpField(spans[0], otlpSpanTraceID)).To(Equal([][]byte{
+			{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		}))
+		Expect(otlpField(spans[0], otlpSpanAttributes)).To(BeEmpty())
+	})
+
+	It("reports the 128-bit trace IDs of links to other traces whole", func() {
+		buffer := newSpansBuffer(10, 0)
+		buffer.addSpan(RawSpan{
+			Context:      SpanContext{TraceIDHigh: 1, TraceID: 2, SpanID: 3},
+			ParentSpanID: 4,
+			References: []SpanReference{
+				{Type: opentracing.ChildOfRef, TraceIDHigh: 1, TraceID: 2, SpanID: 4},
+				{Type: opentracing.FollowsFromRef, TraceIDHigh: 0x0102030405060708, TraceID: 0x090a0b0c0d0e0f10, SpanID: 5},
+			},
+			Operation: "charge",
+		})
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Report(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		<-requests
+
+		spans := otlpField(<-bodies, otlpRequestResourceSpans, otlpResourceSpansScopeSpans, otlpScopeSpansSpans)
+		Expect(spans).To(HaveLen(1))
+		links := otlpField(spans[0], otlpSpanLinks)
+		Expect(links).To(HaveLen(1))
+		Expect(otlpField(links[0], otlpLinkTraceID)).To(Equal([][]byte{
+			{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		}))
+	})
+
+	It("returns an error for failed requests", func() {
+		status = http.StatusServiceUnavailable
+		_, err := report()
+		Expect(err).To(MatchError("otlp: status code (503) is not ok"))
+	})
+
+	It("rejects unknown protocols", func() {
+		opts := Options{AccessToken: "token", UseOTLP: true, OTLPProtocol: "http/json"}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,197 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// rawOTLPRequest keeps the serialized ExportTraceServiceRequest received by
+// the test server.
+type rawOTLPRequest struct {
+	data []byte
+}
+
+func (r *rawOTLPRequest) Reset()         { *r = rawOTLPRequest{} }
+func (r *rawOTLPRequest) String() string { return "rawOTLPRequest" }
+func (r *rawOTLPRequest) ProtoMessage()  {}
+func (r *rawOTLPRequest) Unmarshal(data []byte) error {
+	r.data = append([]byte(nil), data...)
+	return nil
+}
+
+// rawOTLPResponse is the serialized ExportTraceServiceResponse sent by the
+// test server.
+type rawOTLPResponse struct {
+	data []byte
+}
+
+func (r *rawOTLPResponse) Reset()                   { *r = rawOTLPResponse{} }
+func (r *rawOTLPResponse) String() string           { return "rawOTLPResponse" }
+func (r *rawOTLPResponse) ProtoMessage()            {}
+func (r *rawOTLPResponse) Marshal() ([]byte, error) { return r.data, nil }
+
+var _ = Describe("otlpCollectorClient", func() {
+	var server *grpc.Server
+	var requests chan *rawOTLPRequest
+	var tokens chan []string
+	var response []byte
+	var opts Options
+	var client collectorClient
+
+	BeforeEach(func() {
+		requests = make(chan *rawOTLPRequest, 1)
+		tokens = make(chan []string, 1)
+		response = nil
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		server = grpc.NewServer()
+		server.RegisterService(&grpc.ServiceDesc{
+			ServiceName: "opentelemetry.proto.collector.trace.v1.TraceService",
+			HandlerType: (*interface{})(nil),
+			Methods: []grpc.MethodDesc{{
+				MethodName: "Export",
+				Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+					req := &rawOTLPRequest{}
+					if err := dec(req); err != nil {
+						return nil, err
+					}
+					md, _ := metadata.FromIncomingContext(ctx)
+					tokens <- md[otlpAccessTokenHeader]
+					requests <- req
+					return &rawOTLPResponse{data: response}, nil
+				},
+			}},
+		}, struct{}{})
+		go server.Serve(listener)
+
+		port := listener.Addr().(*net.TCPAddr).Port
+		opts = Options{
+			AccessToken: "token",
+			Collector:   Endpoint{Host: "127.0.0.1", Port: port, Plaintext: true},
+			UseOTLP:     true,
+			Tags:        map[string]interface{}{ComponentNameKey: "checkout"},
+		}
+		Expect(opts.Initialize()).To(Succeed())
+		client, err = newCollectorClient(opts, 1, map[string]string{ComponentNameKey: "checkout"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	report := func() (collectorResponse, error) {
+		buffer := newSpansBuffer(10, 0)
+		buffer.addSpan(RawSpan{
+			Context:      SpanContext{TraceID: 0x0102030405060708, SpanID: 2},
+			ParentSpanID: 1,
+			Operation:    "charge",
+			Tags:         map[string]interface{}{"span.kind": "client", "error": true, "attempt": 2},
+		})
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		return client.Report(context.Background(), req)
+	}
+
+	It("exports spans as OTLP", func() {
+		resp, err := report()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetErrors()).To(BeEmpty())
+		Expect(<-tokens).To(Equal([]string{"token"}))
+
+		data := (<-requests).data
+		resourceSpans := otlpField(data, otlpRequestResourceSpans)
+		Expect(resourceSpans).To(HaveLen(1))
+
+		attributes := otlpField(resourceSpans[0], otlpResourceSpansResource, otlpResourceAttributes, otlpKeyValueKey)
+		Expect(attributes).To(ContainElement([]byte(OTLPServiceNameKey)))
+
+		spans := otlpField(resourceSpans[0], otlpResourceSpansScopeSpans, otlpScopeSpansSpans)
+		Expect(spans).To(HaveLen(1))
+		span := spans[0]
+		Expect(otlpField(span, otlpSpanName)).To(Equal([][]byte{[]byte("charge")}))
+		traceID := otlpField(span, otlpSpanTraceID)[0]
+		Expect(traceID).To(HaveLen(16))
+		Expect(binary.BigEndian.Uint64(traceID[8:])).To(Equal(uint64(0x0102030405060708)))
+		Expect(binary.BigEndian.Uint64(otlpField(span, otlpSpanParentSpanID)[0])).To(Equal(uint64(1)))
+		Expect(otlpField(span, otlpSpanKind)).To(Equal([][]byte{{3}}))
+		Expect(otlpField(span, otlpSpanStatus, otlpStatusCode)).To(Equal([][]byte{{otlpStatusCodeError}}))
+		Expect(otlpField(span, otlpSpanAttributes, otlpKeyValueKey)).To(ContainElement([]byte("attempt")))
+	})
+
+	It("connects through the proxy", func() {
+		proxy := startConnectProxy()
+		defer proxy.Close()
+		opts.ProxyURL = proxy.URL()
+		var err error
+		client, err = newCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		_, err = report()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proxy.targets).To(Receive(Equal(opts.Collector.SocketAddress())))
+	})
+
+	It("lets DialOptions replace the tracer's credentials", func() {
+		opts.Collector.Plaintext = false
+		opts.DialOptions = []DialOption{grpc.WithInsecure()}
+		var err error
+		client, err = newCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		_, err = report()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("reports with keepalive pings enabled", func() {
+		Expect(keepaliveDialOptions(opts)).To(BeEmpty())
+		opts.GRPCKeepaliveTime = 30 * time.Second
+		opts.GRPCKeepalivePermitWithoutStream = true
+		Expect(keepaliveDialOptions(opts)).To(HaveLen(1))
+
+		var err error
+		client, err = newCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		_, err = report()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("reports rejected spans as errors", func() {
+		var partial, body protoEncoder
+		partial.varint(otlpPartialRejectedSpans, 1)
+		partial.string(otlpPartialErrorMessage, "too old")
+		body.message(otlpResponsePartialSuccess, partial.bytes())
+		response = body.bytes()
+
+		resp, err := report()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetErrors()).To(Equal([]string{"1 spans rejected: too old"}))
+		rejected, ok := responseRejectedSpans(resp)
+		Expect(ok).To(BeTrue())
+		Expect(rejected).To(Equal(int64(1)))
+		Expect(resp.Disable()).To(BeFalse())
+	})
+})
@@ -0,0 +1,212 @@
+package lightstep
+
+import (
+	"context"
+	"fmt"
+)
+
+// shardedCollectorClient partitions reports across a set of clients: a
+// pool of collectors, by trace ID, so that every span of a trace is sent to
+// the same collector, or the access tokens of tenants, see
+// newTenantCollectorClient.
+type shardedCollectorClient struct {
+	clients []collectorClient
+	// shard returns the index of the client span is reported by.
+	shard func(span *RawSpan) int
+	// skipEmpty skips the reports of clients other than the first when
+	// they have no spans.
+	skipEmpty bool
+}
+
+func newShardedCollectorClient(opts Options, reporterID uint64, attributes map[string]string) (*shardedCollectorClient, error) {
+	sharded := &shardedCollectorClient{
+		clients: make([]collectorClient, len(opts.Collectors)),
+	}
+	sharded.shard = func(span *RawSpan) int {
+		return jumpHash(span.Context.TraceID, len(sharded.clients))
+	}
+	for i, collector := range opts.Collectors {
+		shardOpts := opts
+		shardOpts.Collector = collector
+		shardOpts.Collectors = nil
+
+		client, err := newTransportClient(shardOpts, reporterID, attributes)
+		if err != nil {
+			return nil, err
+		}
+		sharded.clients[i] = client
+	}
+	return sharded, nil
+}
+
+// jumpHash maps key onto one of buckets using Lamping and Veach's "jump"
+// consistent hash, which moves only 1/n of the keys when a bucket is added.
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+func (client *shardedCollectorClient) ConnectClient() (Connection, error) {
+	conns := make(multiConnection, 0, len(client.clients))
+	for _, shard := range client.clients {
+		conn, err := shard.ConnectClient()
+		if err != nil {
+			conns.Close()
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+func (client *shardedCollectorClient) waitForConnection(ctx context.Context, conn Connection) error {
+	conns, ok := conn.(multiConnection)
+	if !ok || len(conns) != len(client.clients) {
+		return nil
+	}
+	for i, shard := range client.clients {
+		if waiter, ok := shard.(connectionWaiter); ok {
+			if err := waiter.waitForConnection(ctx, conns[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (client *shardedCollectorClient) ShouldReconnect() bool {
+	for _, shard := range client.clients {
+		if shard.ShouldReconnect() {
+			return true
+		}
+	}
+	return false
+}
+
+// Translate splits the buffer into one report per client. Report-level
+// metrics are only attributed to the first client, so they are not counted
+// more than once.
+func (client *shardedCollectorClient) Translate(ctx context.Context, buffer *reportBuffer) (reportRequest, error) {
+	shards := make([]reportBuffer, len(client.clients))
+	for i := range shards {
+		shards[i].reportStart = buffer.reportStart
+		shards[i].reportEnd = buffer.reportEnd
+		shards[i].attributes = buffer.attributes
+		shards[i].tagDictionary = buffer.tagDictionary
+		shards[i].indexedTags = buffer.indexedTags
+	}
+	shards[0].droppedSpanCount = buffer.droppedSpanCount
+	shards[0].logEncoderErrorCount = buffer.logEncoderErrorCount
+
+	spanIndexes := make([][]int, len(shards))
+	for i := range buffer.rawSpans {
+		span := &buffer.rawSpans[i]
+		shard := client.shard(span)
+		shards[shard].rawSpans = append(shards[shard].rawSpans, *span)
+		spanIndexes[shard] = append(spanIndexes[shard], i)
+	}
+
+	req := reportRequest{shards: make([]reportRequest, len(shards))}
+	for i, shard := range client.clients {
+		if client.skipEmpty && i > 0 && len(shards[i].rawSpans) == 0 {
+			continue
+		}
+		shardReq, err := shard.Translate(ctx, &shards[i])
+		if err != nil {
+			return reportRequest{}, err
+		}
+		shardReq.spanCount = len(shards[i].rawSpans)
+		shardReq.spanIndexes = spanIndexes[i]
+		req.shards[i] = shardReq
+	}
+
+	// The log encoders count errors against the shard buffers; the first
+	// shard already carries the buffer's previous count.
+	var logEncoderErrorCount int64
+	for i := range shards {
+		logEncoderErrorCount += shards[i].logEncoderErrorCount
+	}
+	buffer.logEncoderErrorCount = logEncoderErrorCount
+	return req, nil
+}
+
+// Report sends every shard to its client. If some shards fail, the report
+// fails with a partialReportError, so that the tracer only retries the
+// spans of the shards that failed.
+func (client *shardedCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	if len(req.shards) != len(client.clients) {
+		return nil, fmt.Errorf("sharded reportRequest has %d shards, expected %d", len(req.shards), len(client.clients))
+	}
+
+	var resps multiResponse
+	var firstErr error
+	partial := &partialReportError{}
+	var spans int
+	for i, shard := range client.clients {
+		if req.shards[i].empty() {
+			continue
+		}
+		spans += len(req.shards[i].spanIndexes)
+		resp, err := shard.Report(ctx, req.shards[i])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			partial.failedSpans = append(partial.failedSpans, failedShardSpans(req.shards[i], err)...)
+			if shardErr, ok := err.(*partialReportError); ok && i == 0 {
+				partial.metricsSent = shardErr.metricsSent
+			}
+			continue
+		}
+		if i == 0 {
+			partial.metricsSent = true
+		}
+		resps = append(resps, resp)
+	}
+	if firstErr == nil {
+		return resps, nil
+	}
+	if len(partial.failedSpans) == spans && !partial.metricsSent {
+		return nil, firstErr
+	}
+	partial.err = firstErr
+	return nil, partial
+}
+
+// multiConnection closes a set of connections together.
+type multiConnection []Connection
+
+func (conns multiConnection) Close() error {
+	var firstErr error
+	for _, conn := range conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// multiResponse combines the responses of several collectors.
+type multiResponse []collectorResponse
+
+func (resps multiResponse) GetErrors() []string {
+	var errs []string
+	for _, resp := range resps {
+		errs = append(errs, resp.GetErrors()...)
+	}
+	return errs
+}
+
+func (resps multiResponse) Disable() bool {
+	for _, resp := range resps {
+		if resp.Disable() {
+			return true
+		}
+	}
+	return false
+}
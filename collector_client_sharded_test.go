@@ -0,0 +1,155 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep
+
+import (
+	"context"
+	"errors"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("jumpHash", func() {
+	It("returns a bucket in range", func() {
+		for key := uint64(0); key < 1000; key++ {
+			bucket := jumpHash(key*0x9E3779B97F4A7C15, 7)
+			Expect(bucket).To(BeNumerically(">=", 0))
+			Expect(bucket).To(BeNumerically("<", 7))
+		}
+	})
+
+	It("only moves keys to a new bucket when the pool grows", func() {
+		for key := uint64(0); key < 1000; key++ {
+			before := jumpHash(key*0x9E3779B97F4A7C15, 4)
+			after := jumpHash(key*0x9E3779B97F4A7C15, 5)
+			if after != before {
+				Expect(after).To(Equal(4))
+			}
+		}
+	})
+})
+
+var _ = Describe("shardedCollectorClient", func() {
+	var client collectorClient
+
+	BeforeEach(func() {
+		opts := Options{
+			AccessToken: "token",
+			Collectors: []Endpoint{
+				{Host: "collector-0", Plaintext: true},
+				{Host: "collector-1", Plaintext: true},
+				{Host: "collector-2", Plaintext: true},
+			},
+		}
+		Expect(opts.Initialize()).To(Succeed())
+
+		var err error
+		client, err = newCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("sends all the spans of a trace to the same collector", func() {
+		buffer := newSpansBuffer(100, 0)
+		for traceID := uint64(1); traceID <= 10; traceID++ {
+			for spanID := uint64(1); spanID <= 3; spanID++ {
+				buffer.addSpan(RawSpan{Context: SpanContext{TraceID: traceID, SpanID: spanID}})
+			}
+		}
+		buffer.droppedSpanCount = 5
+
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(req.shards).To(HaveLen(3))
+
+		var total int
+		for i, shard := range req.shards {
+			Expect(shard.protoRequest.Spans).To(HaveLen(shard.spanCount))
+			total += shard.spanCount
+			for _, span := range shard.protoRequest.Spans {
+				Expect(jumpHash(span.SpanContext.TraceId, 3)).To(Equal(i))
+			}
+		}
+		Expect(total).To(Equal(30))
+
+		droppedSamples := 0
+		for _, shard := range req.shards {
+			for _, metric := range shard.protoRequest.InternalMetrics.Counts {
+				if metric.Name == spansDropped && metric.GetIntValue() > 0 {
+					droppedSamples++
+				}
+			}
+		}
+		Expect(droppedSamples).To(Equal(1))
+	})
+
+	Context("when some shards fail", func() {
+		var failing []bool
+		var buffer reportBuffer
+
+		BeforeEach(func() {
+			failing = make([]bool, 2)
+			shards := make([]collectorClient, len(failing))
+			for i := range shards {
+				i := i
+				shards[i] = &fakeCollectorClient{
+					translate: func(context.Context, *reportBuffer) (reportRequest, error) {
+						return reportRequest{protoRequest: &cpb.ReportRequest{}}, nil
+					},
+					report: func(context.Context, reportRequest) (collectorResponse, error) {
+						if failing[i] {
+							return nil, errors.New("unavailable")
+						}
+						return &cpb.ReportResponse{}, nil
+					},
+				}
+			}
+			client = &shardedCollectorClient{
+				clients: shards,
+				shard: func(span *RawSpan) int {
+					return int(span.Context.TraceID % 2)
+				},
+			}
+
+			buffer = newSpansBuffer(100, 0)
+			for traceID := uint64(1); traceID <= 6; traceID++ {
+				buffer.addSpan(RawSpan{Context: SpanContext{TraceID: traceID}})
+			}
+		})
+
+		report := func() error {
+			req, err := client.Translate(context.Background(), &buffer)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.Report(context.Background(), req)
+			return err
+		}
+
+		It("only retries the spans of the shards that failed", func() {
+			failing[1] = true
+
+			partial, ok := report().(*partialReportError)
+			Expect(ok).To(BeTrue())
+			Expect(partial.failedSpans).To(Equal([]int{0, 2, 4}))
+			Expect(partial.metricsSent).To(BeTrue())
+
+			Expect(buffer.retainFailed(partial)).To(Equal(3))
+			var traceIDs []uint64
+			for _, span := range buffer.rawSpans {
+				traceIDs = append(traceIDs, span.Context.TraceID)
+			}
+			Expect(traceIDs).To(Equal([]uint64{1, 3, 5}))
+		})
+
+		It("fails the whole report if every shard failed", func() {
+			failing[0] = true
+			failing[1] = true
+
+			err := report()
+			Expect(err).To(MatchError("unavailable"))
+			_, ok := err.(*partialReportError)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,123 @@
+package lightstep
+
+import (
+	"context"
+)
+
+// splittingCollectorClient wraps the client of a single collector and
+// splits the reports larger than maxBytes into smaller ones, sent one after
+// another, so that a large flush isn't rejected as a whole.
+type splittingCollectorClient struct {
+	collectorClient
+	maxBytes int
+}
+
+// maxReportBytes returns the size above which the reports of a single
+// collector are split: Options.MaxReportBytes, or for the gRPC transports
+// Options.GRPCMaxCallSendMsgSizeBytes if it is smaller. Zero means reports
+// are never split.
+func maxReportBytes(opts Options) int {
+	limit := opts.MaxReportBytes
+	usesGRPC := !opts.UseThrift && !opts.UseHttp
+	if opts.UseOTLP {
+		usesGRPC = !opts.UseThrift && opts.OTLPProtocol != OTLPProtocolHTTP
+	}
+	grpcLimit := opts.GRPCMaxCallSendMsgSizeBytes
+	if usesGRPC && grpcLimit > 0 && grpcLimit < DefaultGRPCMaxCallSendMsgSizeBytes && (limit == 0 || grpcLimit < limit) {
+		limit = grpcLimit
+	}
+	return limit
+}
+
+// splitReports wraps client in a splittingCollectorClient if opts limit the
+// size of reports.
+func splitReports(client collectorClient, opts Options) collectorClient {
+	if limit := maxReportBytes(opts); limit > 0 {
+		return &splittingCollectorClient{collectorClient: client, maxBytes: limit}
+	}
+	return client
+}
+
+func (client *splittingCollectorClient) waitForConnection(ctx context.Context, conn Connection) error {
+	if waiter, ok := client.collectorClient.(connectionWaiter); ok {
+		return waiter.waitForConnection(ctx, conn)
+	}
+	return nil
+}
+
+func (client *splittingCollectorClient) activeTransport() string {
+	return activeTransport(client.collectorClient)
+}
+
+// Translate translates the buffer, and if the report is too large, halves
+// it until each part fits, returning the parts as shards. A report of a
+// single span is not split further, and is sent even if it is too large.
+func (client *splittingCollectorClient) Translate(ctx context.Context, buffer *reportBuffer) (reportRequest, error) {
+	logEncoderErrorCount := buffer.logEncoderErrorCount
+	req, err := client.collectorClient.Translate(ctx, buffer)
+	if err != nil || len(buffer.rawSpans) < 2 || req.size() <= client.maxBytes {
+		return req, err
+	}
+	// The halves are translated again, and count their own errors.
+	buffer.logEncoderErrorCount = logEncoderErrorCount
+
+	half := len(buffer.rawSpans) / 2
+	halves := [2]reportBuffer{*buffer, *buffer}
+	halves[0].rawSpans = buffer.rawSpans[:half]
+	halves[1].rawSpans = buffer.rawSpans[half:]
+	// Report-level metrics are only attributed to the first part, so they
+	// are not counted more than once.
+	halves[1].droppedSpanCount = 0
+	halves[1].expiredSpanCount = 0
+	halves[1].logEncoderErrorCount = 0
+
+	req = reportRequest{}
+	for i := range halves {
+		part, err := client.Translate(ctx, &halves[i])
+		if err != nil {
+			return reportRequest{}, err
+		}
+		offset := i * half
+		if part.shards != nil {
+			for _, shard := range part.shards {
+				for j := range shard.spanIndexes {
+					shard.spanIndexes[j] += offset
+				}
+				req.shards = append(req.shards, shard)
+			}
+			continue
+		}
+		part.spanCount = len(halves[i].rawSpans)
+		part.spanIndexes = spanRange(offset, part.spanCount)
+		req.shards = append(req.shards, part)
+	}
+	buffer.logEncoderErrorCount = halves[0].logEncoderErrorCount + halves[1].logEncoderErrorCount
+	return req, nil
+}
+
+// Report sends the parts of a split report in order. If a part fails, the
+// rest are not sent. If parts were sent before, the report fails with a
+// partialReportError, so that the tracer only retries the spans of the
+// parts that weren't sent.
+func (client *splittingCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	if req.shards == nil {
+		return client.collectorClient.Report(ctx, req)
+	}
+
+	resps := make(multiResponse, 0, len(req.shards))
+	for i, part := range req.shards {
+		resp, err := client.collectorClient.Report(ctx, part)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			partial := &partialReportError{err: err, metricsSent: true}
+			for _, unsent := range req.shards[i:] {
+				partial.failedSpans = append(partial.failedSpans, unsent.spanIndexes...)
+			}
+			return nil, partial
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
@@ -0,0 +1,144 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("splitting oversized reports", func() {
+	var tracer Tracer
+	var opts Options
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			MaxReportBytes:     4096,
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	finishSpans := func(n, tagBytes int) {
+		for i := 0; i < n; i++ {
+			span := tracer.StartSpan(fmt.Sprint("span ", i))
+			span.SetTag("payload", strings.Repeat("x", tagBytes))
+			span.Finish()
+		}
+	}
+
+	It("sends every span once, in reports under the limit", func() {
+		finishSpans(20, 500)
+		tracer.Flush(context.Background())
+
+		Expect(fakeClient.ReportCallCount()).To(BeNumerically(">", 1))
+		for i := 0; i < fakeClient.ReportCallCount(); i++ {
+			_, req, _ := fakeClient.ReportArgsForCall(i)
+			Expect(proto.Size(req)).To(BeNumerically("<=", opts.MaxReportBytes))
+		}
+		var names []string
+		for _, span := range fakeClient.Spans() {
+			names = append(names, span.GetOperationName())
+		}
+		Expect(names).To(HaveLen(20))
+		for i := 0; i < 20; i++ {
+			Expect(names).To(ContainElement(fmt.Sprint("span ", i)))
+		}
+
+		var status EventStatusReport
+		Eventually(eventChan).Should(Receive(&status))
+		Expect(status.SentSpans()).To(Equal(20))
+	})
+
+	It("only retries the parts that weren't sent", func() {
+		fakeClient.ReportReturnsOnCall(1, nil, errors.New("unavailable"))
+		finishSpans(20, 500)
+		tracer.Flush(context.Background())
+
+		_, first, _ := fakeClient.ReportArgsForCall(0)
+		var status EventStatusReport
+		Eventually(eventChan).Should(Receive(&status))
+		Expect(status.SentSpans()).To(Equal(len(first.GetSpans())))
+
+		tracer.Flush(context.Background())
+		var names []string
+		for i := 0; i < fakeClient.ReportCallCount(); i++ {
+			if i == 1 {
+				continue
+			}
+			_, req, _ := fakeClient.ReportArgsForCall(i)
+			for _, span := range req.GetSpans() {
+				names = append(names, span.GetOperationName())
+			}
+		}
+		Expect(names).To(HaveLen(20))
+		for i := 0; i < 20; i++ {
+			Expect(names).To(ContainElement(fmt.Sprint("span ", i)))
+		}
+	})
+
+	It("doesn't split reports under the limit", func() {
+		finishSpans(2, 10)
+		tracer.Flush(context.Background())
+
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+	})
+
+	It("sends a single span larger than the limit", func() {
+		finishSpans(1, 8192)
+		tracer.Flush(context.Background())
+
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		Expect(fakeClient.Spans()).To(HaveLen(1))
+	})
+
+	Context("when the gRPC message size is limited", func() {
+		BeforeEach(func() {
+			opts.MaxReportBytes = 0
+			opts.GRPCMaxCallSendMsgSizeBytes = 4096
+		})
+
+		It("splits reports over it", func() {
+			finishSpans(20, 500)
+			tracer.Flush(context.Background())
+
+			Expect(fakeClient.ReportCallCount()).To(BeNumerically(">", 1))
+			Expect(fakeClient.Spans()).To(HaveLen(20))
+		})
+	})
+
+	It("rejects a negative MaxReportBytes", func() {
+		opts := Options{AccessToken: "token", MaxReportBytes: -1}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
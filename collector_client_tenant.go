@@ -0,0 +1,62 @@
+package lightstep
+
+import (
+	"fmt"
+	"sort"
+)
+
+// newTenantCollectorClient returns a client that reports each span with the
+// access token of its tenant, named by its Options.TenantTagKey tag, by
+// partitioning reports into one report per access token. Spans of unknown
+// tenants, and report-level metrics, are reported with Options.AccessToken.
+// Tenants without spans are skipped.
+func newTenantCollectorClient(opts Options, reporterID uint64, attributes map[string]string) (*shardedCollectorClient, error) {
+	tokens := []string{opts.AccessToken}
+	tokenShards := map[string]int{opts.AccessToken: 0}
+	tenants := make([]string, 0, len(opts.TenantAccessTokens))
+	for tenant := range opts.TenantAccessTokens {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	tenantShards := make(map[string]int, len(tenants))
+	for _, tenant := range tenants {
+		token := opts.TenantAccessTokens[tenant]
+		shard, found := tokenShards[token]
+		if !found {
+			shard = len(tokens)
+			tokens = append(tokens, token)
+			tokenShards[token] = shard
+		}
+		tenantShards[tenant] = shard
+	}
+
+	tenant := &shardedCollectorClient{
+		clients:   make([]collectorClient, len(tokens)),
+		skipEmpty: true,
+	}
+	for i, token := range tokens {
+		tokenOpts := opts
+		tokenOpts.AccessToken = token
+		tokenOpts.TenantAccessTokens = nil
+
+		client, err := newTransportClient(tokenOpts, reporterID, attributes)
+		if err != nil {
+			return nil, err
+		}
+		tenant.clients[i] = client
+	}
+
+	tenant.shard = func(span *RawSpan) int {
+		value, found := span.Tags[opts.TenantTagKey]
+		if !found {
+			return 0
+		}
+		name, ok := value.(string)
+		if !ok {
+			name = fmt.Sprint(value)
+		}
+		return tenantShards[name]
+	}
+	return tenant, nil
+}
@@ -0,0 +1,86 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("newTenantCollectorClient", func() {
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken:  "default-token",
+			Collector:    Endpoint{Host: "collector", Plaintext: true},
+			TenantTagKey: "tenant",
+			TenantAccessTokens: map[string]string{
+				"acme":    "acme-token",
+				"globex":  "globex-token",
+				"initech": "globex-token",
+			},
+		}
+		Expect(opts.Initialize()).To(Succeed())
+		Expect(opts.Validate()).To(Succeed())
+	})
+
+	translate := func(spans ...RawSpan) map[string]int {
+		client, err := newCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		buffer := newSpansBuffer(100, 0)
+		for _, span := range spans {
+			buffer.addSpan(span)
+		}
+
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(req.shards).To(HaveLen(3))
+		reports := map[string]int{}
+		for _, shard := range req.shards {
+			if !shard.empty() {
+				reports[shard.protoRequest.Auth.AccessToken] += len(shard.protoRequest.Spans)
+			}
+		}
+		return reports
+	}
+
+	tenantSpan := func(tenant interface{}) RawSpan {
+		return RawSpan{Context: SpanContext{TraceID: 1, SpanID: 1}, Tags: map[string]interface{}{"tenant": tenant}}
+	}
+
+	It("reports each span with its tenant's access token", func() {
+		Expect(translate(
+			tenantSpan("acme"),
+			tenantSpan("acme"),
+			tenantSpan("globex"),
+			tenantSpan("initech"),
+			tenantSpan("unknown"),
+			RawSpan{Context: SpanContext{TraceID: 2, SpanID: 2}},
+		)).To(Equal(map[string]int{
+			"default-token": 2,
+			"acme-token":    2,
+			"globex-token":  2,
+		}))
+	})
+
+	It("skips tenants without spans, but always sends the default report", func() {
+		Expect(translate(tenantSpan("acme"))).To(Equal(map[string]int{
+			"default-token": 0,
+			"acme-token":    1,
+		}))
+	})
+
+	It("validates and redacts the tenant access tokens", func() {
+		Expect(opts.String()).NotTo(ContainSubstring("acme-token"))
+
+		opts.TenantAccessTokens["acme"] = ""
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.TenantAccessTokens["acme"] = "acme-token"
+		opts.TenantTagKey = ""
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
@@ -1,3 +1,6 @@
+//go:build !lightstep_nothrift && !lightstep_constrained
+// +build !lightstep_nothrift,!lightstep_constrained
+
 package lightstep
 
 import (
@@ -11,6 +14,17 @@ import (
 	"github.com/lightstep/lightstep-tracer-go/thrift_0_9_2/lib/go/thrift"
 )
 
+// thriftReportRequest is the thrift request carried by a reportRequest.
+type thriftReportRequest = lightstep_thrift.ReportRequest
+
+func newThriftTransport(opts Options, reporterID uint64, attributes map[string]string) (collectorClient, error) {
+	return newThriftCollectorClient(opts, reporterID, attributes), nil
+}
+
+func (r reportRequest) thriftPayload() ([]byte, error) {
+	return thrift.NewTSerializer().Write(r.thriftRequest)
+}
+
 // thriftCollectorClient specifies how to send reports back to a LightStep
 // collector via thrift
 type thriftCollectorClient struct {
@@ -37,8 +51,11 @@ type thriftCollectorClient struct {
 	verbose bool
 
 	// flags replacement
-	maxLogMessageLen int
-	maxLogKeyLen     int
+	maxLogMessageLen      int
+	maxLogKeyLen          int
+	truncator             logTruncator
+	reportTruncatedLength bool
+	formatValues          bool
 
 	reportTimeout time.Duration
 
@@ -64,6 +81,9 @@ func newThriftCollectorClient(opts Options, guid uint64, attributes map[string]s
 		AccessToken:            opts.AccessToken,
 		maxLogMessageLen:       opts.MaxLogValueLen,
 		maxLogKeyLen:           opts.MaxLogKeyLen,
+		truncator:              newLogTruncator(opts.LogTruncation),
+		reportTruncatedLength:  opts.LogTruncation.ReportLength,
+		formatValues:           opts.FormatValues,
 		reportTimeout:          reportTimeout,
 		thriftConnectorFactory: opts.ConnFactory,
 		reporterID:             guid,
@@ -127,6 +147,11 @@ func (client *thriftCollectorClient) Translate(_ context.Context, buffer *report
 		var joinIds []*lightstep_thrift.TraceJoinId
 		var attributes []*lightstep_thrift.KeyValue
 		for key, value := range raw.Tags {
+			if client.formatValues {
+				if formatted, ok := formatValue(value); ok {
+					value = formatted
+				}
+			}
 			// Note: the gRPC tracer uses Sprintf("%#v") for non-scalar non-string
 			// values, differs from the treatment here:
 			if strings.HasPrefix(key, "join:") {
@@ -161,7 +186,7 @@ func (client *thriftCollectorClient) Translate(_ context.Context, buffer *report
 			SpanName:       thrift.StringPtr(raw.Operation),
 			JoinIds:        joinIds,
 			OldestMicros:   thrift.Int64Ptr(raw.Start.UnixNano() / 1000),
-			YoungestMicros: thrift.Int64Ptr(raw.Start.Add(raw.Duration).UnixNano() / 1000),
+			YoungestMicros: thrift.Int64Ptr(raw.FinishTime().UnixNano() / 1000),
 			Attributes:     attributes,
 			LogRecords:     logs,
 		}
@@ -179,7 +204,7 @@ func (client *thriftCollectorClient) Translate(_ context.Context, buffer *report
 	req := &lightstep_thrift.ReportRequest{
 		OldestMicros:    thrift.Int64Ptr(buffer.reportEnd.UnixNano() / 1000),
 		YoungestMicros:  thrift.Int64Ptr(buffer.reportStart.UnixNano() / 1000),
-		Runtime:         client.thriftRuntime(),
+		Runtime:         client.thriftRuntime(buffer.reportAttributes(client.attributes)),
 		SpanRecords:     recs,
 		InternalMetrics: &metrics,
 	}
@@ -189,10 +214,10 @@ func (client *thriftCollectorClient) Translate(_ context.Context, buffer *report
 }
 
 // caller must hold r.lock
-func (r *thriftCollectorClient) thriftRuntime() *lightstep_thrift.Runtime {
+func (r *thriftCollectorClient) thriftRuntime(attributes map[string]string) *lightstep_thrift.Runtime {
 	guid := strconv.FormatUint(r.reporterID, 10)
 	runtimeAttrs := []*lightstep_thrift.KeyValue{}
-	for k, v := range r.attributes {
+	for k, v := range attributes {
 		runtimeAttrs = append(runtimeAttrs, &lightstep_thrift.KeyValue{k, v})
 	}
 	return &lightstep_thrift.Runtime{
@@ -0,0 +1,130 @@
+package lightstep
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Protocols for Options.HTTPProtocol.
+const (
+	// HTTPProtocolDefault posts reports to the collector's /api/v2/reports
+	// endpoint.
+	HTTPProtocolDefault = ""
+	// HTTPProtocolGRPCWeb calls the collector's gRPC service using the
+	// gRPC-Web protocol, which works without HTTP/2 trailers.
+	HTTPProtocolGRPCWeb = "grpc-web"
+	// HTTPProtocolConnect calls the collector's gRPC service using the
+	// Connect protocol's unary requests.
+	HTTPProtocolConnect = "connect"
+)
+
+const (
+	collectorServicePath = "/lightstep.collector.CollectorService/Report"
+
+	grpcWebContentType     = "application/grpc-web+proto"
+	connectContentType     = "application/proto"
+	connectProtocolVersion = "1"
+
+	grpcWebTrailerFlag = 0x80
+	grpcWebHeaderLen   = 5
+)
+
+func validHTTPProtocol(protocol string) bool {
+	switch protocol {
+	case HTTPProtocolDefault, HTTPProtocolGRPCWeb, HTTPProtocolConnect:
+		return true
+	}
+	return false
+}
+
+// setWebProtocolHeaders sets the headers of a gRPC-Web or Connect request.
+func setWebProtocolHeaders(protocol string, header http.Header) {
+	switch protocol {
+	case HTTPProtocolGRPCWeb:
+		header.Set(contentTypeHeader, grpcWebContentType)
+		header.Set(acceptHeader, grpcWebContentType)
+		header.Set("X-Grpc-Web", "1")
+	case HTTPProtocolConnect:
+		header.Set(contentTypeHeader, connectContentType)
+		header.Set(acceptHeader, connectContentType)
+		header.Set("Connect-Protocol-Version", connectProtocolVersion)
+	}
+}
+
+// encodeGRPCWebMessage frames a serialized message for a gRPC-Web request.
+func encodeGRPCWebMessage(message []byte) []byte {
+	frame := make([]byte, grpcWebHeaderLen+len(message))
+	binary.BigEndian.PutUint32(frame[1:grpcWebHeaderLen], uint32(len(message)))
+	copy(frame[grpcWebHeaderLen:], message)
+	return frame
+}
+
+// decodeGRPCWebResponse returns the serialized message of a gRPC-Web
+// response body, or the error given by its status.
+func decodeGRPCWebResponse(header http.Header, body []byte) ([]byte, error) {
+	// A response without a message carries its status in the headers.
+	status, message := header.Get("Grpc-Status"), header.Get("Grpc-Message")
+
+	var data []byte
+	for len(body) > 0 {
+		if len(body) < grpcWebHeaderLen {
+			return nil, fmt.Errorf("grpc-web: truncated frame header")
+		}
+		flags := body[0]
+		length := binary.BigEndian.Uint32(body[1:grpcWebHeaderLen])
+		if uint64(len(body)-grpcWebHeaderLen) < uint64(length) {
+			return nil, fmt.Errorf("grpc-web: truncated frame")
+		}
+		frame := body[grpcWebHeaderLen : grpcWebHeaderLen+int(length)]
+		body = body[grpcWebHeaderLen+int(length):]
+
+		if flags&grpcWebTrailerFlag == 0 {
+			data = frame
+			continue
+		}
+		trailer := parseGRPCWebTrailer(frame)
+		status, message = trailer.Get("Grpc-Status"), trailer.Get("Grpc-Message")
+	}
+
+	if status == "" {
+		return nil, fmt.Errorf("grpc-web: response has no status")
+	}
+	if code, err := strconv.Atoi(status); err != nil || code != 0 {
+		return nil, fmt.Errorf("grpc-web: status %s: %s", status, message)
+	}
+	return data, nil
+}
+
+// connectError is the JSON body of a failed Connect unary response.
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// decodeConnectError returns the error described by a failed Connect
+// response.
+func decodeConnectError(statusCode int, body []byte) error {
+	var e connectError
+	if err := json.Unmarshal(body, &e); err != nil || e.Code == "" {
+		return fmt.Errorf("connect: status code (%d) is not ok", statusCode)
+	}
+	return fmt.Errorf("connect: %s: %s", e.Code, e.Message)
+}
+
+// parseGRPCWebTrailer parses the "key: value" lines of a gRPC-Web trailer
+// frame.
+func parseGRPCWebTrailer(frame []byte) http.Header {
+	trailer := http.Header{}
+	for _, line := range strings.Split(string(frame), "\r\n") {
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			continue
+		}
+		trailer.Add(strings.TrimSpace(line[:colon]), strings.TrimSpace(line[colon+1:]))
+	}
+	return trailer
+}
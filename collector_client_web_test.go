@@ -0,0 +1,149 @@
+package lightstep
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func grpcWebFrame(flags byte, data []byte) []byte {
+	frame := make([]byte, grpcWebHeaderLen, grpcWebHeaderLen+len(data))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+var _ = Describe("httpCollectorClient web protocols", func() {
+	var server *httptest.Server
+	var handler http.HandlerFunc
+	var requests chan *http.Request
+	var bodies chan []byte
+
+	report := func(protocol string) (collectorResponse, error) {
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(serverURL.Port())
+		Expect(err).NotTo(HaveOccurred())
+
+		opts := Options{
+			AccessToken:  "token",
+			Collector:    Endpoint{Host: serverURL.Hostname(), Port: port, Plaintext: true},
+			UseHttp:      true,
+			HTTPProtocol: protocol,
+		}
+		Expect(opts.Initialize()).To(Succeed())
+		client, err := newHttpCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer := newSpansBuffer(10, 0)
+		buffer.addSpan(RawSpan{Context: SpanContext{TraceID: 1, SpanID: 2}, Operation: "op"})
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		return client.Report(context.Background(), req)
+	}
+
+	BeforeEach(func() {
+		requests = make(chan *http.Request, 1)
+		bodies = make(chan []byte, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests <- r
+			bodies <- body
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("with gRPC-Web", func() {
+		It("frames the request and reads the response", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				response, _ := proto.Marshal(&cpb.ReportResponse{Infos: []string{"ok"}})
+				w.Header().Set("Content-Type", grpcWebContentType)
+				w.Write(grpcWebFrame(0, response))
+				w.Write(grpcWebFrame(grpcWebTrailerFlag, []byte("grpc-status: 0\r\ngrpc-message: \r\n")))
+			}
+
+			resp, err := report(HTTPProtocolGRPCWeb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.(*cpb.ReportResponse).Infos).To(Equal([]string{"ok"}))
+
+			r := <-requests
+			Expect(r.URL.Path).To(Equal(collectorServicePath))
+			Expect(r.Header.Get("Content-Type")).To(Equal(grpcWebContentType))
+			body := <-bodies
+			Expect(body[0]).To(BeZero())
+			Expect(binary.BigEndian.Uint32(body[1:5])).To(BeEquivalentTo(len(body) - 5))
+			request := &cpb.ReportRequest{}
+			Expect(proto.Unmarshal(body[5:], request)).To(Succeed())
+			Expect(request.Spans).To(HaveLen(1))
+		})
+
+		It("returns the error status from the trailer", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Write(grpcWebFrame(grpcWebTrailerFlag, []byte("grpc-status: 16\r\ngrpc-message: bad token\r\n")))
+			}
+			_, err := report(HTTPProtocolGRPCWeb)
+			Expect(err).To(MatchError("grpc-web: status 16: bad token"))
+		})
+
+		It("returns the error status from the headers", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Grpc-Status", "14")
+				w.Header().Set("Grpc-Message", "unavailable")
+			}
+			_, err := report(HTTPProtocolGRPCWeb)
+			Expect(err).To(MatchError("grpc-web: status 14: unavailable"))
+		})
+	})
+
+	Context("with Connect", func() {
+		It("sends the request unframed and reads the response", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				response, _ := proto.Marshal(&cpb.ReportResponse{Infos: []string{"ok"}})
+				w.Header().Set("Content-Type", connectContentType)
+				w.Write(response)
+			}
+
+			resp, err := report(HTTPProtocolConnect)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.(*cpb.ReportResponse).Infos).To(Equal([]string{"ok"}))
+
+			r := <-requests
+			Expect(r.URL.Path).To(Equal(collectorServicePath))
+			Expect(r.Header.Get("Content-Type")).To(Equal(connectContentType))
+			Expect(r.Header.Get("Connect-Protocol-Version")).To(Equal("1"))
+			request := &cpb.ReportRequest{}
+			Expect(proto.Unmarshal(<-bodies, request)).To(Succeed())
+			Expect(request.Spans).To(HaveLen(1))
+		})
+
+		It("returns the error from the response body", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"code":"unauthenticated","message":"bad token"}`))
+			}
+			_, err := report(HTTPProtocolConnect)
+			Expect(err).To(MatchError("connect: unauthenticated: bad token"))
+		})
+	})
+
+	It("rejects unknown protocols", func() {
+		opts := Options{AccessToken: "token", HTTPProtocol: "carrier-pigeon"}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
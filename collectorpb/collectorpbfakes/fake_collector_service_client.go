@@ -1,4 +1,8 @@
 // Code generated by counterfeiter. DO NOT EDIT.
+
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
 package collectorpbfakes
 
 import (
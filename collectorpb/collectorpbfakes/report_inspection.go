@@ -0,0 +1,83 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package collectorpbfakes
+
+import (
+	"github.com/lightstep/lightstep-tracer-go/collectorpb"
+)
+
+// Reports returns the ReportRequests passed to Report, in call order.
+func (fake *FakeCollectorServiceClient) Reports() []*collectorpb.ReportRequest {
+	reports := make([]*collectorpb.ReportRequest, 0, fake.ReportCallCount())
+	for i := 0; i < fake.ReportCallCount(); i++ {
+		_, req, _ := fake.ReportArgsForCall(i)
+		reports = append(reports, req)
+	}
+	return reports
+}
+
+// Spans returns the spans of every captured ReportRequest, in report order.
+func (fake *FakeCollectorServiceClient) Spans() []*collectorpb.Span {
+	var spans []*collectorpb.Span
+	for _, req := range fake.Reports() {
+		spans = append(spans, req.GetSpans()...)
+	}
+	return spans
+}
+
+// SpansByOperation returns the captured spans whose operation name is
+// operation.
+func (fake *FakeCollectorServiceClient) SpansByOperation(operation string) []*collectorpb.Span {
+	var spans []*collectorpb.Span
+	for _, span := range fake.Spans() {
+		if span.GetOperationName() == operation {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// ReporterAttributes returns the reporter tags of the most recent
+// ReportRequest as a map, or nil if Report has not been called.
+func (fake *FakeCollectorServiceClient) ReporterAttributes() map[string]interface{} {
+	count := fake.ReportCallCount()
+	if count == 0 {
+		return nil
+	}
+	_, req, _ := fake.ReportArgsForCall(count - 1)
+	return Tags(req.GetReporter().GetTags())
+}
+
+// SpanTags returns the tags of span as a map.
+func SpanTags(span *collectorpb.Span) map[string]interface{} {
+	return Tags(span.GetTags())
+}
+
+// Tags converts key-values to a map from key to the typed value: string,
+// int64, float64 or bool. JSON values are returned as their string encoding.
+func Tags(kvs []*collectorpb.KeyValue) map[string]interface{} {
+	tags := make(map[string]interface{}, len(kvs))
+	for _, kv := range kvs {
+		tags[kv.GetKey()] = KeyValueValue(kv)
+	}
+	return tags
+}
+
+// KeyValueValue returns the value set on kv, whichever its type.
+func KeyValueValue(kv *collectorpb.KeyValue) interface{} {
+	switch v := kv.GetValue().(type) {
+	case *collectorpb.KeyValue_StringValue:
+		return v.StringValue
+	case *collectorpb.KeyValue_IntValue:
+		return v.IntValue
+	case *collectorpb.KeyValue_DoubleValue:
+		return v.DoubleValue
+	case *collectorpb.KeyValue_BoolValue:
+		return v.BoolValue
+	case *collectorpb.KeyValue_JsonValue:
+		return v.JsonValue
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package lightstep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// GzipCompression names the built-in gzip CompressionCodec.
+const GzipCompression = "gzip"
+
+// A CompressionCodec compresses the reports sent to collectors, see
+// Options.Compression. Codecs for zstd or snappy can be registered with
+// RegisterCompressionCodec; the collector must support them.
+type CompressionCodec interface {
+	// Name identifies the codec in Options.Compression, and in the
+	// Content-Encoding or grpc-encoding header of compressed reports.
+	Name() string
+	// Compress writes the compressed form of data to w. It must be safe to
+	// call concurrently.
+	Compress(w io.Writer, data []byte) error
+}
+
+var compressionCodecs = struct {
+	sync.RWMutex
+	codecs map[string]CompressionCodec
+}{
+	codecs: map[string]CompressionCodec{GzipCompression: gzipCodec{}},
+}
+
+// RegisterCompressionCodec makes codec available to Options.Compression
+// under its Name, replacing any codec of the same name. It is typically
+// called from an init function.
+func RegisterCompressionCodec(codec CompressionCodec) {
+	compressionCodecs.Lock()
+	defer compressionCodecs.Unlock()
+	compressionCodecs.codecs[codec.Name()] = codec
+}
+
+// lookupCompressionCodec returns the codec registered under name.
+func lookupCompressionCodec(name string) (CompressionCodec, bool) {
+	if name == "" {
+		return nil, false
+	}
+	compressionCodecs.RLock()
+	defer compressionCodecs.RUnlock()
+	codec, found := compressionCodecs.codecs[name]
+	return codec, found
+}
+
+// compress returns data compressed by codec.
+func compress(codec CompressionCodec, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := codec.Compress(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string {
+	return GzipCompression
+}
+
+func (gzipCodec) Compress(w io.Writer, data []byte) error {
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(data); err != nil {
+		return err
+	}
+	return gz.Close()
+}
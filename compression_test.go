@@ -0,0 +1,114 @@
+package lightstep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/protobuf/proto"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// reverseCodec is a test codec that reverses the payload.
+type reverseCodec struct{}
+
+func (reverseCodec) Name() string {
+	return "reverse"
+}
+
+func (reverseCodec) Compress(w io.Writer, data []byte) error {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	_, err := w.Write(reversed)
+	return err
+}
+
+var _ = Describe("Options.Compression", func() {
+	var server *httptest.Server
+	var requests chan *http.Request
+	var bodies chan []byte
+
+	BeforeEach(func() {
+		requests = make(chan *http.Request, 1)
+		bodies = make(chan []byte, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests <- r
+			bodies <- body
+			response, _ := proto.Marshal(&cpb.ReportResponse{})
+			w.Write(response)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	report := func(compression string) {
+		addr := server.Listener.Addr().(*net.TCPAddr)
+		opts := Options{
+			AccessToken: "token",
+			UseHttp:     true,
+			Compression: compression,
+			Collector:   Endpoint{Host: addr.IP.String(), Port: addr.Port, Plaintext: true},
+		}
+		Expect(opts.Initialize()).To(Succeed())
+		Expect(opts.Validate()).To(Succeed())
+		client, err := newHttpCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer := newSpansBuffer(10, 0)
+		buffer.addSpan(RawSpan{Context: SpanContext{TraceID: 1, SpanID: 2}, Operation: "compressed"})
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Report(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	decode := func(data []byte) *cpb.ReportRequest {
+		protoRequest := &cpb.ReportRequest{}
+		Expect(proto.Unmarshal(data, protoRequest)).To(Succeed())
+		return protoRequest
+	}
+
+	It("compresses HTTP reports with gzip", func() {
+		report(GzipCompression)
+		Expect((<-requests).Header.Get("Content-Encoding")).To(Equal("gzip"))
+
+		gz, err := gzip.NewReader(bytes.NewReader(<-bodies))
+		Expect(err).NotTo(HaveOccurred())
+		data, err := ioutil.ReadAll(gz)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decode(data).Spans[0].OperationName).To(Equal("compressed"))
+	})
+
+	It("compresses HTTP reports with registered codecs", func() {
+		RegisterCompressionCodec(reverseCodec{})
+		report("reverse")
+		Expect((<-requests).Header.Get("Content-Encoding")).To(Equal("reverse"))
+
+		// Reversing the payload again restores it.
+		var data bytes.Buffer
+		Expect(reverseCodec{}.Compress(&data, <-bodies)).To(Succeed())
+		Expect(decode(data.Bytes()).Spans[0].OperationName).To(Equal("compressed"))
+	})
+
+	It("rejects unknown and unsupported codecs", func() {
+		opts := Options{AccessToken: "token", Compression: "brotli"}
+		Expect(opts.Validate()).To(HaveOccurred())
+
+		opts = Options{AccessToken: "token", Compression: GzipCompression, UseHttp: true, HTTPProtocol: HTTPProtocolGRPCWeb}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,88 @@
+package lightstep
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// reservedHeaders are the headers set by the transports themselves, which
+// Options.CustomHeaders must not replace.
+var reservedHeaders = map[string]bool{
+	"accept":                   true,
+	"connect-protocol-version": true,
+	"content-encoding":         true,
+	"content-length":           true,
+	"content-type":             true,
+	"host":                     true,
+	"te":                       true,
+	"user-agent":               true,
+	"x-grpc-web":               true,
+	otlpAccessTokenHeader:      true,
+}
+
+// validateCustomHeaders checks that headers are valid HTTP header fields,
+// or gRPC metadata if grpc is set, and that they don't replace the headers
+// the transports set.
+func validateCustomHeaders(headers map[string]string, grpc bool) error {
+	validName := validHeaderName
+	if grpc {
+		validName = validMetadataKey
+	}
+	for name, value := range headers {
+		if !validName(name) {
+			return fmt.Errorf("Options invalid: CustomHeaders has an invalid name %q", name)
+		}
+		lower := strings.ToLower(name)
+		if reservedHeaders[lower] || strings.HasPrefix(lower, "grpc-") {
+			return fmt.Errorf("Options invalid: CustomHeaders must not set %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("Options invalid: CustomHeaders has an invalid value for %q", name)
+		}
+	}
+	return nil
+}
+
+// validHeaderName reports whether name is an HTTP token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validMetadataKey reports whether name is a gRPC metadata key, once
+// lowercased as withCustomMetadata does.
+func validMetadataKey(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// setCustomHeaders adds Options.CustomHeaders to the header of a report
+// request.
+func setCustomHeaders(header http.Header, headers map[string]string) {
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+}
@@ -0,0 +1,125 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerRecorder answers HTTP reports with an empty response, keeping the
+// headers of each request.
+type headerRecorder chan http.Header
+
+func (headers headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers <- req.Header
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+var _ = Describe("CustomHeaders", func() {
+	var tracer Tracer
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			CustomHeaders: map[string]string{
+				"X-Tenant-ID":     "acme",
+				"X-Routing-Hint":  "us-east",
+				"X-Authorization": "secret",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		if tracer != nil {
+			closeTestTracer(tracer)
+			tracer = nil
+		}
+	})
+
+	It("sends them as gRPC metadata", func() {
+		fakeClient := new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		opts.ConnFactory = fakeGrpcConnection(fakeClient)
+		tracer = NewTracer(opts)
+
+		tracer.StartSpan("span").Finish()
+		tracer.Flush(context.Background())
+
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		ctx, _, _ := fakeClient.ReportArgsForCall(0)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		Expect(md["x-tenant-id"]).To(Equal([]string{"acme"}))
+		Expect(md["x-routing-hint"]).To(Equal([]string{"us-east"}))
+	})
+
+	It("sends them as HTTP headers", func() {
+		headers := make(headerRecorder, 1)
+		opts.UseHttp = true
+		opts.Collector = Endpoint{Host: "localhost", Port: 8080, Plaintext: true}
+		opts.HTTPClient = &http.Client{Transport: headers}
+		tracer = NewTracer(opts)
+
+		tracer.StartSpan("span").Finish()
+		tracer.Flush(context.Background())
+
+		var header http.Header
+		Eventually(headers).Should(Receive(&header))
+		Expect(header.Get("X-Tenant-ID")).To(Equal("acme"))
+		Expect(header.Get("X-Routing-Hint")).To(Equal("us-east"))
+		Expect(header.Get("Content-Type")).To(Equal("application/octet-stream"))
+	})
+
+	It("masks the values of headers that look like secrets", func() {
+		s := opts.String()
+		Expect(s).To(ContainSubstring("acme"))
+		Expect(s).NotTo(ContainSubstring("secret"))
+	})
+
+	It("validates them", func() {
+		Expect(opts.Validate()).To(Succeed())
+
+		opts.CustomHeaders = map[string]string{"Content-Type": "text/plain"}
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.CustomHeaders = map[string]string{"grpc-timeout": "1S"}
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.CustomHeaders = map[string]string{"X Tenant": "acme"}
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.CustomHeaders = map[string]string{"X-Tenant": "acme\r\nX-Injected: 1"}
+		Expect(opts.Validate()).To(HaveOccurred())
+
+		opts.CustomHeaders = map[string]string{"X-Tenant!": "acme"}
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.UseHttp = true
+		Expect(opts.Validate()).To(Succeed())
+		opts.UseHttp = false
+		opts.UseOTLP = true
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.OTLPProtocol = OTLPProtocolHTTP
+		Expect(opts.Validate()).To(Succeed())
+		opts.UseOTLP = false
+
+		opts.CustomHeaders = map[string]string{"X-Tenant": "acme"}
+		opts.UseThrift = true
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
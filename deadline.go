@@ -0,0 +1,60 @@
+package lightstep
+
+import (
+	"context"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// Tag keys recorded by StartSpanFromContextWithDeadline.
+const (
+	DeadlineRemainingAtStartKey  = "deadline.remaining_ms.start"
+	DeadlineRemainingAtFinishKey = "deadline.remaining_ms.finish"
+	DeadlineExceededKey          = "deadline.exceeded"
+)
+
+// StartSpanFromContextWithDeadline starts a span like StartSpanFromContext,
+// and records how much of the context's deadline remains when the span
+// starts and finishes. If the deadline has already passed when the span
+// starts, the span is tagged with DeadlineExceededKey and an
+// EventDeadlineExceeded is emitted. Contexts without a deadline start a
+// plain span. Only the spans of LightStep tracers record the remaining
+// deadline at finish.
+func StartSpanFromContextWithDeadline(
+	ctx context.Context,
+	tracer opentracing.Tracer,
+	operationName string,
+	opts ...opentracing.StartSpanOption,
+) (opentracing.Span, context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return StartSpanFromContext(ctx, tracer, operationName, opts...)
+	}
+
+	// Leave the caller's backing array alone.
+	opts = append(opts[:len(opts):len(opts)], spanDeadline(deadline))
+	span, ctx := StartSpanFromContext(ctx, tracer, operationName, opts...)
+
+	remaining := deadline.Sub(time.Now())
+	span.SetTag(DeadlineRemainingAtStartKey, durationMillis(remaining))
+	if remaining <= 0 {
+		span.SetTag(DeadlineExceededKey, true)
+		emitEvent(newEventDeadlineExceeded(operationName, -remaining))
+	}
+	return span, ctx
+}
+
+// spanDeadline is a StartSpanOption that has the span tagged with the time
+// remaining until the deadline when it finishes.
+type spanDeadline time.Time
+
+// Apply satisfies the StartSpanOption interface.
+func (d spanDeadline) Apply(sso *opentracing.StartSpanOptions) {}
+func (d spanDeadline) applyLS(sso *startSpanOptions) {
+	sso.Deadline = time.Time(d)
+}
+
+func durationMillis(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
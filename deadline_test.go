@@ -0,0 +1,116 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("StartSpanFromContextWithDeadline", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(10)
+		SetGlobalEventHandler(eventHandler)
+
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("records the remaining deadline at start and finish", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		span, spanCtx := StartSpanFromContextWithDeadline(ctx, tracer, "op")
+		Expect(opentracing.SpanFromContext(spanCtx)).To(Equal(span))
+		span.Finish()
+
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Tags[DeadlineRemainingAtStartKey]).To(BeNumerically(">", 0))
+		Expect(raw.Tags[DeadlineRemainingAtFinishKey]).To(BeNumerically(">", 0))
+		Expect(raw.Tags).NotTo(HaveKey(DeadlineExceededKey))
+	})
+
+	It("warns when the deadline has already passed", func() {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		span, _ := StartSpanFromContextWithDeadline(ctx, tracer, "late")
+		span.Finish()
+
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Tags[DeadlineExceededKey]).To(BeTrue())
+
+		var event Event
+		Expect(eventChan).To(Receive(&event))
+		deadlineEvent, ok := event.(EventDeadlineExceeded)
+		Expect(ok).To(BeTrue())
+		Expect(deadlineEvent.OperationName()).To(Equal("late"))
+		Expect(deadlineEvent.Lateness()).To(BeNumerically(">=", time.Second))
+	})
+
+	It("marks the trace of the span as a debug trace", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		span, _ := StartSpanFromContextWithDeadline(ctx, tracer, "op")
+		EnableDebugTrace(span)
+		span.Finish()
+
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Tags[DebugKey]).To(BeTrue())
+		Expect(IsDebugTrace(span.Context())).To(BeTrue())
+	})
+
+	It("records the remaining deadline when a chained call finishes the span", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		span, _ := StartSpanFromContextWithDeadline(ctx, tracer, "op")
+		span.SetTag("key", "value").Finish()
+
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Tags[DeadlineRemainingAtFinishKey]).To(BeNumerically(">", 0))
+	})
+
+	It("does not write to the spare capacity of the caller's options", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		opts := make([]opentracing.StartSpanOption, 1, 2)
+		opts[0] = opentracing.Tag{Key: "key", Value: "value"}
+		span, _ := StartSpanFromContextWithDeadline(ctx, tracer, "op", opts...)
+		span.Finish()
+
+		Expect(opts[:2][1]).To(BeNil())
+	})
+
+	It("does not tag spans without a deadline", func() {
+		span, _ := StartSpanFromContextWithDeadline(context.Background(), tracer, "op")
+		span.Finish()
+
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Tags).NotTo(HaveKey(DeadlineRemainingAtStartKey))
+		Expect(raw.Tags).NotTo(HaveKey(DeadlineRemainingAtFinishKey))
+	})
+})
@@ -0,0 +1,64 @@
+package lightstep
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+const (
+	// DebugBaggageKey is the baggage item that marks a debug trace, set by
+	// EnableDebugTrace. It is propagated with the other baggage, and in the
+	// debug flags of the B3 and Jaeger formats.
+	DebugBaggageKey = "lightstep.debug"
+	// DebugKey tags the spans of a debug trace.
+	DebugKey = "lightstep.debug"
+
+	debugEnabled = "1"
+)
+
+// EnableDebugTrace marks the trace of span as a debug trace, from span on:
+// span, and the spans started in the trace from then on, in this and
+// downstream processes, are reported whatever the sampling options and
+// buffer limits, as if started with MustDeliver, and tagged with DebugKey.
+// Those started later have their start stacks captured if
+// Options.StartStackFrames is set. The flag is propagated by every format
+// but the W3C and X-Ray formats, which have no place for it.
+//
+// A span of a trace dropped by caller sampling, see CallerSamplingOptions,
+// has already been discarded, but its descendants are reported.
+func EnableDebugTrace(span opentracing.Span) {
+	if debugSpan, ok := span.(interface {
+		enableDebug()
+	}); ok {
+		debugSpan.enableDebug()
+		return
+	}
+	span.SetBaggageItem(DebugBaggageKey, debugEnabled)
+}
+
+// IsDebugTrace reports whether sc belongs to a debug trace, see
+// EnableDebugTrace.
+func IsDebugTrace(sc opentracing.SpanContext) bool {
+	lsc, ok := sc.(SpanContext)
+	return ok && lsc.isDebug()
+}
+
+func (sc SpanContext) isDebug() bool {
+	return sc.Baggage[DebugBaggageKey] == debugEnabled
+}
+
+func (s *spanImpl) enableDebug() {
+	s.Lock()
+	defer s.Unlock()
+	s.raw.Context = s.raw.Context.WithBaggageItem(DebugBaggageKey, debugEnabled)
+	s.enableDebugLocked()
+}
+
+// enableDebugLocked retains the span, which belongs to a debug trace, and
+// tags it with DebugKey.
+func (s *spanImpl) enableDebugLocked() {
+	s.raw.mustDeliver = true
+	if s.raw.Tags == nil {
+		s.raw.Tags = opentracing.Tags{}
+	}
+	s.raw.Tags[DebugKey] = true
+}
@@ -0,0 +1,153 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("EnableDebugTrace", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var opts Options
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			MinSpanDuration:    time.Hour,
+			CallerSampling: CallerSamplingOptions{
+				BaggageKey:  "caller",
+				SampleRates: map[string]float64{"": 0},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	// reportedDebugSpans returns the operations of the reported spans, and
+	// whether each was tagged with DebugKey.
+	reportedDebugSpans := func() map[string]bool {
+		tracer.Flush(context.Background())
+		spans := map[string]bool{}
+		for i := 0; i < fakeClient.ReportCallCount(); i++ {
+			_, req, _ := fakeClient.ReportArgsForCall(i)
+			for _, span := range req.GetSpans() {
+				spans[span.GetOperationName()] = false
+				for _, tag := range span.GetTags() {
+					if tag.GetKey() == DebugKey {
+						spans[span.GetOperationName()] = tag.GetBoolValue()
+					}
+				}
+			}
+		}
+		return spans
+	}
+
+	It("reports the spans of a debug trace whatever the sampling options", func() {
+		root := tracer.StartSpan("root", MustDeliver{})
+		EnableDebugTrace(root)
+		child := tracer.StartSpan("child", opentracing.ChildOf(root.Context()))
+		Expect(IsDebugTrace(child.Context())).To(BeTrue())
+		child.Finish()
+		root.Finish()
+		tracer.StartSpan("other", opentracing.ChildOf(SpanContext{TraceID: 1, SpanID: 2})).Finish()
+
+		Expect(reportedDebugSpans()).To(Equal(map[string]bool{"root": true, "child": true}))
+	})
+
+	It("continues debug traces extracted from the LightStep format", func() {
+		root := tracer.StartSpan("root")
+		EnableDebugTrace(root)
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(root.Context(), opentracing.TextMap, carrier)).To(Succeed())
+		Expect(carrier).To(HaveKeyWithValue("ot-baggage-lightstep.debug", "1"))
+
+		parent, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		tracer.StartSpan("downstream", opentracing.ChildOf(parent)).Finish()
+
+		Expect(reportedDebugSpans()).To(HaveKeyWithValue("downstream", true))
+	})
+
+	Context("with the single b3 field", func() {
+		BeforeEach(func() {
+			opts.Propagators = []string{PropagatorB3}
+		})
+
+		It("propagates its debug flag", func() {
+			header := http.Header{}
+			header.Set("b3", "a1b2c3d4e5f60718-1234567890abcdef-d")
+			parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(IsDebugTrace(parent)).To(BeTrue())
+
+			child := tracer.StartSpan("child", opentracing.ChildOf(parent))
+			defer child.Finish()
+			carrier := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(child.Context(), opentracing.TextMap, carrier)).To(Succeed())
+			Expect(carrier["b3"]).To(HaveSuffix("-d"))
+		})
+	})
+
+	Context("with the X-B3-* fields", func() {
+		BeforeEach(func() {
+			opts.Propagators = []string{PropagatorB3Multi}
+		})
+
+		It("propagates the X-B3-Flags field", func() {
+			header := http.Header{}
+			header.Set("X-B3-TraceId", "a1b2c3d4e5f60718")
+			header.Set("X-B3-SpanId", "1234567890abcdef")
+			header.Set("X-B3-Flags", "1")
+			parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(IsDebugTrace(parent)).To(BeTrue())
+
+			carrier := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(parent, opentracing.TextMap, carrier)).To(Succeed())
+			Expect(carrier).To(HaveKeyWithValue("x-b3-flags", "1"))
+			Expect(carrier).ToNot(HaveKey("x-b3-sampled"))
+		})
+	})
+
+	Context("with the Jaeger format", func() {
+		BeforeEach(func() {
+			opts.Propagators = []string{PropagatorJaeger}
+		})
+
+		It("propagates the debug flag of uber-trace-id", func() {
+			carrier := opentracing.TextMapCarrier{"uber-trace-id": "a1b2c3d4e5f60718:1234567890abcdef:0:3"}
+			parent, err := tracer.Extract(opentracing.TextMap, carrier)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(IsDebugTrace(parent)).To(BeTrue())
+
+			injected := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(parent, opentracing.TextMap, injected)).To(Succeed())
+			Expect(injected).To(Equal(opentracing.TextMapCarrier{
+				"uber-trace-id": "a1b2c3d4e5f60718:1234567890abcdef:0:3",
+			}))
+		})
+	})
+})
@@ -0,0 +1,145 @@
+package lightstep
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+var validationErrorDestinationName = fmt.Errorf("Options invalid: Destinations must not have an empty name")
+
+// DestinationOptions configures an alternate endpoint for the spans routed
+// to it, see Options.Destinations. Each destination buffers its spans apart
+// from the tracer's and the other destinations', so routing the spans of a
+// component to a destination of its own, by a DestinationSelector, keeps a
+// noisy component from evicting the spans of the others.
+type DestinationOptions struct {
+	// Collector receives the destination's spans. Defaults to the default
+	// collector of the tracer's transport, as for Options.Collector.
+	Collector Endpoint `yaml:"collector"`
+	// AccessToken reports the destination's spans to another project.
+	// Defaults to Options.AccessToken.
+	AccessToken string `yaml:"access_token"`
+
+	// MaxBufferedSpans and MaxBufferedPrioritySpans limit the buffer of the
+	// destination's spans. Default to Options.MaxBufferedSpans and
+	// Options.MaxBufferedPrioritySpans.
+	MaxBufferedSpans         int `yaml:"max_buffered_spans"`
+	MaxBufferedPrioritySpans int `yaml:"max_buffered_priority_spans"`
+	// MaxMemoryBytes limits the estimated memory of the destination's
+	// spans. Defaults to Options.MaxMemoryBytes. Each destination applies
+	// its limit to its own spans, apart from the tracer's, so that with N
+	// destinations the tracer may hold up to the sum of their limits and
+	// Options.MaxMemoryBytes: N+1 times Options.MaxMemoryBytes by default.
+	MaxMemoryBytes int `yaml:"max_memory_bytes"`
+
+	// TailSampling samples the destination's traces in place of
+	// Options.TailSampling, if its Window is set.
+	TailSampling TailSamplingOptions `yaml:"tail_sampling"`
+}
+
+func validateDestinations(destinations map[string]DestinationOptions) error {
+	for name, destination := range destinations {
+		if name == "" {
+			return validationErrorDestinationName
+		}
+		if err := destination.Collector.validateTLS(); err != nil {
+			return err
+		}
+		if destination.MaxMemoryBytes < 0 {
+			return validationErrorMemoryLimit
+		}
+		if err := destination.TailSampling.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destinationOptions returns the Options of the tracer that reports the
+// spans of a destination: opts, reporting to the destination's endpoint
+// instead of to any of the collectors, agent or transport opts configure,
+// with the destination's buffer and sampling, and without the forwarder,
+// which only the main tracer runs. Its reporter ID is persisted next to the
+// tracer's, in ReporterIDFile suffixed with the destination's name, so that
+// the destinations don't share the tracer's reporter ID.
+func destinationOptions(opts Options, name string, destination DestinationOptions) Options {
+	opts = opts.Clone()
+	opts.Collector = destination.Collector
+	if destination.AccessToken != "" {
+		opts.AccessToken = destination.AccessToken
+	}
+	if destination.MaxBufferedSpans != 0 {
+		opts.MaxBufferedSpans = destination.MaxBufferedSpans
+	}
+	if destination.MaxBufferedPrioritySpans != 0 {
+		opts.MaxBufferedPrioritySpans = destination.MaxBufferedPrioritySpans
+	}
+	if destination.MaxMemoryBytes != 0 {
+		opts.MaxMemoryBytes = destination.MaxMemoryBytes
+	}
+	if destination.TailSampling.Window > 0 {
+		opts.TailSampling = destination.TailSampling
+	}
+	if opts.ReporterIDFile != "" {
+		opts.ReporterIDFile += "." + url.PathEscape(name)
+	}
+	opts.Collectors = nil
+	opts.FailoverCollectors = nil
+	opts.TenantAccessTokens = nil
+	opts.Agent = AgentOptions{}
+	opts.CustomTransportFactory = nil
+	opts.CustomTransport = ""
+	opts.Forwarder = ForwarderOptions{}
+	opts.Destinations = nil
+	opts.DestinationSelector = nil
+	return opts
+}
+
+// startDestinations starts a tracer for each of opts.Destinations, or
+// returns nil, having closed the ones it started, if one fails to start.
+func startDestinations(opts Options) (map[string]*tracerImpl, bool) {
+	names := make([]string, 0, len(opts.Destinations))
+	for name := range opts.Destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tracers := make(map[string]*tracerImpl, len(names))
+	for _, name := range names {
+		tracer, _ := NewTracer(destinationOptions(opts, name, opts.Destinations[name])).(*tracerImpl)
+		if tracer == nil {
+			for _, started := range tracers {
+				started.Close(context.Background())
+			}
+			return nil, false
+		}
+		tracer.destination = name
+		tracers[name] = tracer
+	}
+	return tracers, true
+}
+
+// routeSpan returns the name of the destination raw is reported to, if any.
+func (tracer *tracerImpl) routeSpan(raw *RawSpan) string {
+	if raw.Destination == "" && tracer.opts.DestinationSelector != nil {
+		raw.Destination = tracer.opts.DestinationSelector(*raw)
+	}
+	return raw.Destination
+}
+
+// recordDestinationSpan hands raw to the tracer of its destination. Spans
+// routed to an unknown destination are dropped, rather than reported to the
+// tracer's own collector.
+func (tracer *tracerImpl) recordDestinationSpan(name string, raw RawSpan) {
+	destination, found := tracer.destinations[name]
+	if !found {
+		tracer.lock.Lock()
+		tracer.buffer.droppedSpanCount++
+		tracer.lock.Unlock()
+		resolveSpans([]RawSpan{raw}, ErrSpanDropped)
+		return
+	}
+	destination.RecordSpan(raw)
+}
@@ -0,0 +1,169 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Destinations", func() {
+	var tracer Tracer
+	var opts Options
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			Destinations: map[string]DestinationOptions{
+				"compliance": {AccessToken: "COMPLIANCE_TOKEN"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	// reportedOperations returns the operations reported with each access
+	// token.
+	reportedOperations := func() map[string][]string {
+		operations := map[string][]string{}
+		for i := 0; i < fakeClient.ReportCallCount(); i++ {
+			_, req, _ := fakeClient.ReportArgsForCall(i)
+			token := req.GetAuth().GetAccessToken()
+			for _, span := range req.GetSpans() {
+				operations[token] = append(operations[token], span.GetOperationName())
+			}
+		}
+		return operations
+	}
+
+	It("reports the spans of a destination apart from the others", func() {
+		tracer.StartSpan("audit", Destination("compliance")).Finish()
+		tracer.StartSpan("request").Finish()
+		tracer.Flush(context.Background())
+
+		Expect(reportedOperations()).To(Equal(map[string][]string{
+			"ACCESS_TOKEN":     {"request"},
+			"COMPLIANCE_TOKEN": {"audit"},
+		}))
+	})
+
+	It("drops the spans of unknown destinations", func() {
+		tracer.StartSpan("audit", Destination("unknown")).Finish()
+		tracer.Flush(context.Background())
+
+		Expect(reportedOperations()).To(BeEmpty())
+	})
+
+	Context("with a DestinationSelector", func() {
+		BeforeEach(func() {
+			opts.DestinationSelector = func(span RawSpan) string {
+				if span.Tags["pii"] == true {
+					return "compliance"
+				}
+				return ""
+			}
+		})
+
+		It("routes the spans it selects", func() {
+			tracer.StartSpan("lookup").SetTag("pii", true).Finish()
+			tracer.StartSpan("request").Finish()
+			tracer.Flush(context.Background())
+
+			Expect(reportedOperations()).To(Equal(map[string][]string{
+				"ACCESS_TOKEN":     {"request"},
+				"COMPLIANCE_TOKEN": {"lookup"},
+			}))
+		})
+	})
+
+	Context("with a component's own buffer", func() {
+		BeforeEach(func() {
+			opts.MaxBufferedSpans = 10
+			opts.Destinations["noisy"] = DestinationOptions{AccessToken: "NOISY_TOKEN", MaxBufferedSpans: 2}
+			opts.DestinationSelector = func(span RawSpan) string {
+				if span.Tags["component"] == "noisy" {
+					return "noisy"
+				}
+				return ""
+			}
+		})
+
+		It("keeps the component from evicting the spans of others", func() {
+			tracer.StartSpan("critical").Finish()
+			for i := 0; i < 20; i++ {
+				tracer.StartSpan("chatter").SetTag("component", "noisy").Finish()
+			}
+			tracer.Flush(context.Background())
+
+			operations := reportedOperations()
+			Expect(operations["ACCESS_TOKEN"]).To(Equal([]string{"critical"}))
+			Expect(operations["NOISY_TOKEN"]).To(HaveLen(2))
+		})
+	})
+
+	It("rejects invalid tail sampling of a destination", func() {
+		invalid := Options{
+			AccessToken:  "ACCESS_TOKEN",
+			Destinations: map[string]DestinationOptions{"noisy": {TailSampling: TailSamplingOptions{SampleRate: 2}}},
+		}
+		Expect(invalid.Validate()).To(HaveOccurred())
+	})
+
+	It("rejects a negative memory limit of a destination", func() {
+		invalid := Options{
+			AccessToken:  "ACCESS_TOKEN",
+			Destinations: map[string]DestinationOptions{"noisy": {MaxMemoryBytes: -1}},
+		}
+		Expect(invalid.Validate()).To(HaveOccurred())
+	})
+
+	Context("with a ReporterIDFile", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "reporter-id")
+			Expect(err).NotTo(HaveOccurred())
+			opts.ReporterIDFile = filepath.Join(dir, "reporter-id")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("persists the reporter ID of each destination apart", func() {
+			tracerID, err := ioutil.ReadFile(opts.ReporterIDFile)
+			Expect(err).NotTo(HaveOccurred())
+			destinationID, err := ioutil.ReadFile(opts.ReporterIDFile + ".compliance")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destinationID).NotTo(Equal(tracerID))
+		})
+	})
+
+	It("masks the access tokens of destinations", func() {
+		Expect(tracer.Options().String()).ToNot(ContainSubstring("COMPLIANCE_TOKEN"))
+	})
+})
@@ -0,0 +1,105 @@
+package lightstep
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsConfig returns the TLS configuration of connections to e: its
+// TLSConfig, with its client certificate loaded from ClientCertFile and
+// ClientKeyFile if they are set. It returns nil for the default
+// configuration.
+func (e Endpoint) tlsConfig() *tls.Config {
+	if e.ClientCertFile == "" {
+		return e.TLSConfig
+	}
+	config := &tls.Config{}
+	if e.TLSConfig != nil {
+		config = e.TLSConfig.Clone()
+	}
+	reloader := &certReloader{certFile: e.ClientCertFile, keyFile: e.ClientKeyFile}
+	config.GetClientCertificate = reloader.GetClientCertificate
+	return config
+}
+
+// customTLS reports whether e has TLS options.
+func (e Endpoint) customTLS() bool {
+	return e.TLSConfig != nil || e.ClientCertFile != ""
+}
+
+// validateTLS checks that e's TLS options can be used together, and that
+// its client certificate can be loaded.
+func (e Endpoint) validateTLS() error {
+	if (e.ClientCertFile == "") != (e.ClientKeyFile == "") {
+		return validationErrorClientCert
+	}
+	if !e.customTLS() {
+		return nil
+	}
+	if e.Plaintext {
+		return validationErrorPlaintextTLS
+	}
+	if e.ClientCertFile != "" {
+		if _, err := tls.LoadX509KeyPair(e.ClientCertFile, e.ClientKeyFile); err != nil {
+			return fmt.Errorf("Options invalid: Collector client certificate: %v", err)
+		}
+	}
+	return nil
+}
+
+// certReloader loads a client certificate for each TLS handshake, reloading
+// it when its files are modified, so that rotated certificates are used by
+// new connections without restarting the tracer.
+type certReloader struct {
+	certFile, keyFile string
+
+	lock            sync.Mutex
+	cert            *tls.Certificate
+	certMod, keyMod time.Time
+}
+
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	certMod, err := modTime(r.certFile)
+	if err != nil {
+		return r.current(err)
+	}
+	keyMod, err := modTime(r.keyFile)
+	if err != nil {
+		return r.current(err)
+	}
+	if r.cert != nil && certMod.Equal(r.certMod) && keyMod.Equal(r.keyMod) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		// The files may be mid-rotation; try again on the next handshake.
+		return r.current(err)
+	}
+	r.cert, r.certMod, r.keyMod = &cert, certMod, keyMod
+	return r.cert, nil
+}
+
+// current returns the certificate loaded last, or err if none has been
+// loaded.
+func (r *certReloader) current(err error) (*tls.Certificate, error) {
+	emitEvent(newEventConnectionError(fmt.Errorf("reloading client certificate: %v", err)))
+	if r.cert == nil {
+		return nil, err
+	}
+	return r.cert, nil
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
@@ -0,0 +1,153 @@
+package lightstep
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/http2"
+)
+
+// writeClientCert writes a self-signed certificate for commonName, and its
+// key, to certFile and keyFile.
+func writeClientCert(commonName, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).To(Succeed())
+	Expect(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).To(Succeed())
+}
+
+var _ = Describe("Endpoint client certificates", func() {
+	var dir, certFile, keyFile string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "client-cert")
+		Expect(err).NotTo(HaveOccurred())
+		certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+		writeClientCert("first", certFile, keyFile)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	commonName := func(cert *tls.Certificate) string {
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		Expect(err).NotTo(HaveOccurred())
+		return parsed.Subject.CommonName
+	}
+
+	It("reloads the certificate when it is rotated", func() {
+		config := Endpoint{ClientCertFile: certFile, ClientKeyFile: keyFile}.tlsConfig()
+		cert, err := config.GetClientCertificate(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commonName(cert)).To(Equal("first"))
+
+		writeClientCert("second", certFile, keyFile)
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(certFile, later, later)).To(Succeed())
+		Expect(os.Chtimes(keyFile, later, later)).To(Succeed())
+
+		cert, err = config.GetClientCertificate(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commonName(cert)).To(Equal("second"))
+	})
+
+	It("keeps the loaded certificate while the files are unreadable", func() {
+		config := Endpoint{ClientCertFile: certFile, ClientKeyFile: keyFile}.tlsConfig()
+		_, err := config.GetClientCertificate(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Remove(keyFile)).To(Succeed())
+		cert, err := config.GetClientCertificate(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commonName(cert)).To(Equal("first"))
+	})
+
+	It("presents the certificate to the HTTP transport's collector", func() {
+		peers := make(chan []*x509.Certificate, 1)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peers <- r.TLS.PeerCertificates
+			response, _ := proto.Marshal(&cpb.ReportResponse{})
+			w.Write(response)
+		}))
+		Expect(http2.ConfigureServer(server.Config, &http2.Server{})).To(Succeed())
+		server.TLS = server.Config.TLSConfig
+		server.TLS.ClientAuth = tls.RequireAnyClientCert
+		server.StartTLS()
+		defer server.Close()
+
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		port, err := strconv.Atoi(serverURL.Port())
+		Expect(err).NotTo(HaveOccurred())
+		roots := x509.NewCertPool()
+		roots.AddCert(server.Certificate())
+
+		opts := Options{
+			AccessToken: "token",
+			UseHttp:     true,
+			Collector: Endpoint{
+				Host:           serverURL.Hostname(),
+				Port:           port,
+				TLSConfig:      &tls.Config{RootCAs: roots},
+				ClientCertFile: certFile,
+				ClientKeyFile:  keyFile,
+			},
+		}
+		Expect(opts.Initialize()).To(Succeed())
+		client, err := newHttpCollectorClient(opts, 1, map[string]string{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.ConnectClient()
+		Expect(err).NotTo(HaveOccurred())
+		buffer := newSpansBuffer(10, 0)
+		req, err := client.Translate(context.Background(), &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Report(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+
+		peer := <-peers
+		Expect(peer).To(HaveLen(1))
+		Expect(peer[0].Subject.CommonName).To(Equal("first"))
+	})
+
+	It("validates the certificate options", func() {
+		opts := Options{AccessToken: "token", Collector: Endpoint{ClientCertFile: certFile}}
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.Collector.ClientKeyFile = filepath.Join(dir, "missing.key")
+		Expect(opts.Validate()).To(HaveOccurred())
+		opts.Collector.ClientKeyFile = keyFile
+		Expect(opts.Validate()).To(Succeed())
+	})
+})
@@ -3,7 +3,7 @@ package lightstep
 import (
 	"errors"
 	"fmt"
-	"reflect"
+	"strings"
 	"time"
 
 	opentracing "github.com/opentracing/opentracing-go"
@@ -69,11 +69,13 @@ const (
 	FlushErrorTransport      EventFlushErrorState = "flush failed, could not send report to Collector"
 	FlushErrorReport         EventFlushErrorState = "flush failed, report contained errors"
 	FlushErrorTranslate      EventFlushErrorState = "flush failed, could not translate report"
+	FlushErrorCircuitOpen    EventFlushErrorState = "flush skipped, the circuit breaker is open"
 )
 
 var (
 	flushErrorTracerClosed   = errors.New(string(FlushErrorTracerClosed))
 	flushErrorTracerDisabled = errors.New(string(FlushErrorTracerDisabled))
+	flushErrorCircuitOpen    = errors.New(string(FlushErrorCircuitOpen))
 )
 
 // EventFlushError occurs when a flush fails to send. Call the `State` method to
@@ -142,6 +144,341 @@ func (e *eventConnectionError) Err() error {
 	return e.err
 }
 
+// EventTransportFallback occurs when the tracer gives up on gRPC and starts
+// reporting over HTTP, see Options.GRPCFallbackToHttp, or when UseThrift is
+// set but the thrift transport is not compiled in. `Err` returns the error
+// from the abandoned transport.
+type EventTransportFallback interface {
+	ErrorEvent
+	EventTransportFallback()
+}
+
+type eventTransportFallback struct {
+	transport string
+	err       error
+}
+
+func newEventTransportFallback(transport string, err error) *eventTransportFallback {
+	return &eventTransportFallback{transport: transport, err: err}
+}
+
+func (*eventTransportFallback) Event()                  {}
+func (*eventTransportFallback) EventTransportFallback() {}
+
+func (e *eventTransportFallback) String() string {
+	return fmt.Sprint("falling back to ", e.transport, " transport: ", e.err)
+}
+
+func (e *eventTransportFallback) Error() string {
+	return e.String()
+}
+
+func (e *eventTransportFallback) Err() error {
+	return e.err
+}
+
+// EventCollectorFailover occurs when the tracer switches the collector it
+// reports to, see Options.FailoverCollectors: when a report fails, with the
+// error that made it fail, and when reporting returns to the primary
+// Collector after a successful probe, with a nil error.
+type EventCollectorFailover interface {
+	Event
+	EventCollectorFailover()
+	// Collector is the collector reports are now sent to.
+	Collector() Endpoint
+	Err() error
+}
+
+type eventCollectorFailover struct {
+	collector Endpoint
+	err       error
+}
+
+func newEventCollectorFailover(collector Endpoint, err error) *eventCollectorFailover {
+	return &eventCollectorFailover{collector: collector, err: err}
+}
+
+func (*eventCollectorFailover) Event()                  {}
+func (*eventCollectorFailover) EventCollectorFailover() {}
+
+func (e *eventCollectorFailover) Collector() Endpoint {
+	return e.collector
+}
+
+func (e *eventCollectorFailover) Err() error {
+	return e.err
+}
+
+func (e *eventCollectorFailover) String() string {
+	if e.err != nil {
+		return fmt.Sprint("failing over to collector ", e.collector.SocketAddress(), ": ", e.err)
+	}
+	return fmt.Sprint("returning to collector ", e.collector.SocketAddress())
+}
+
+// EventReportPartialSuccess occurs when the collector answers a report with
+// errors and counts the spans it rejected, possibly all or none of them.
+// The collector's answer is final, so the report is not retried: resending
+// it would duplicate the spans the collector accepted, and the collector
+// would reject the others again.
+type EventReportPartialSuccess interface {
+	Event
+	EventReportPartialSuccess()
+	// Errors is a sample of the errors the collector returned, at most
+	// MaxPartialSuccessErrors of them.
+	Errors() []string
+	// ErrorCount is the total number of errors the collector returned.
+	ErrorCount() int
+	// RejectedSpans is the number of spans the collector reported it did
+	// not accept, when it counts them.
+	RejectedSpans() int
+}
+
+type eventReportPartialSuccess struct {
+	errors        []string
+	errorCount    int
+	rejectedSpans int
+}
+
+func newEventReportPartialSuccess(errors []string, rejectedSpans int) *eventReportPartialSuccess {
+	sample := errors
+	if len(sample) > MaxPartialSuccessErrors {
+		sample = sample[:MaxPartialSuccessErrors]
+	}
+	return &eventReportPartialSuccess{
+		errors:        append([]string(nil), sample...),
+		errorCount:    len(errors),
+		rejectedSpans: rejectedSpans,
+	}
+}
+
+func (*eventReportPartialSuccess) Event()                     {}
+func (*eventReportPartialSuccess) EventReportPartialSuccess() {}
+
+func (e *eventReportPartialSuccess) Errors() []string {
+	return e.errors
+}
+
+func (e *eventReportPartialSuccess) ErrorCount() int {
+	return e.errorCount
+}
+
+func (e *eventReportPartialSuccess) RejectedSpans() int {
+	return e.rejectedSpans
+}
+
+func (e *eventReportPartialSuccess) String() string {
+	return fmt.Sprint(
+		"report partially accepted, errors: ", e.errorCount,
+		", rejected spans: ", e.rejectedSpans,
+		", first error: ", e.errors[0],
+	)
+}
+
+// EventReporterIDError occurs when the tracer fails to load or persist its
+// runtime GUID using Options.ReporterIDFile. The tracer continues to run with
+// the returned ReporterID, which may not survive a restart.
+type EventReporterIDError interface {
+	ErrorEvent
+	EventReporterIDError()
+	ReporterID() uint64
+}
+
+type eventReporterIDError struct {
+	err        error
+	reporterID uint64
+}
+
+func newEventReporterIDError(err error, reporterID uint64) *eventReporterIDError {
+	return &eventReporterIDError{err: err, reporterID: reporterID}
+}
+
+func (*eventReporterIDError) Event()                {}
+func (*eventReporterIDError) EventReporterIDError() {}
+
+func (e *eventReporterIDError) ReporterID() uint64 {
+	return e.reporterID
+}
+
+func (e *eventReporterIDError) String() string {
+	return e.err.Error()
+}
+
+func (e *eventReporterIDError) Error() string {
+	return e.err.Error()
+}
+
+func (e *eventReporterIDError) Err() error {
+	return e.err
+}
+
+// EventForwarderError occurs when the listener configured by
+// Options.Forwarder can't be started or stops serving. The tracer continues
+// to report its own spans.
+type EventForwarderError interface {
+	ErrorEvent
+	EventForwarderError()
+}
+
+type eventForwarderError struct {
+	err error
+}
+
+func newEventForwarderError(err error) *eventForwarderError {
+	return &eventForwarderError{err: err}
+}
+
+func (*eventForwarderError) Event()               {}
+func (*eventForwarderError) EventForwarderError() {}
+
+func (e *eventForwarderError) String() string {
+	return fmt.Sprint("span forwarder: ", e.err)
+}
+
+func (e *eventForwarderError) Error() string {
+	return e.String()
+}
+
+func (e *eventForwarderError) Err() error {
+	return e.err
+}
+
+// EventCollectorCapabilities occurs when the capabilities advertised by the
+// collector change. A collector starts out advertising none.
+type EventCollectorCapabilities interface {
+	Event
+	EventCollectorCapabilities()
+	Capabilities() []Capability
+}
+
+type eventCollectorCapabilities struct {
+	capabilities []Capability
+}
+
+func newEventCollectorCapabilities(capabilities []Capability) *eventCollectorCapabilities {
+	return &eventCollectorCapabilities{capabilities: capabilities}
+}
+
+func (*eventCollectorCapabilities) Event()                      {}
+func (*eventCollectorCapabilities) EventCollectorCapabilities() {}
+
+func (e *eventCollectorCapabilities) Capabilities() []Capability {
+	return e.capabilities
+}
+
+func (e *eventCollectorCapabilities) String() string {
+	return fmt.Sprint("collector capabilities: ", joinCapabilities(e.capabilities))
+}
+
+// EventMemoryPressure occurs when the estimated memory of the spans held by
+// the tracer exceeds Options.MaxMemoryBytes, and it starts sampling traces
+// at Options.MemoryPressureSampleRate, and again when their memory falls to
+// half the limit and it stops.
+type EventMemoryPressure interface {
+	Event
+	EventMemoryPressure()
+	// Sampling reports whether the tracer started sampling, rather than
+	// stopped.
+	Sampling() bool
+	MemoryBytes() int
+	MaxMemoryBytes() int
+	SampleRate() float64
+}
+
+type eventMemoryPressure struct {
+	sampling       bool
+	memoryBytes    int
+	maxMemoryBytes int
+	sampleRate     float64
+}
+
+func newEventMemoryPressure(sampling bool, memoryBytes, maxMemoryBytes int, sampleRate float64) *eventMemoryPressure {
+	return &eventMemoryPressure{
+		sampling:       sampling,
+		memoryBytes:    memoryBytes,
+		maxMemoryBytes: maxMemoryBytes,
+		sampleRate:     sampleRate,
+	}
+}
+
+func (*eventMemoryPressure) Event()               {}
+func (*eventMemoryPressure) EventMemoryPressure() {}
+
+func (e *eventMemoryPressure) Sampling() bool {
+	return e.sampling
+}
+
+func (e *eventMemoryPressure) MemoryBytes() int {
+	return e.memoryBytes
+}
+
+func (e *eventMemoryPressure) MaxMemoryBytes() int {
+	return e.maxMemoryBytes
+}
+
+func (e *eventMemoryPressure) SampleRate() float64 {
+	return e.sampleRate
+}
+
+func (e *eventMemoryPressure) String() string {
+	if e.sampling {
+		return fmt.Sprintf("buffered spans use %d bytes, over the limit of %d: sampling traces at %v", e.memoryBytes, e.maxMemoryBytes, e.sampleRate)
+	}
+	return fmt.Sprintf("buffered spans use %d bytes, under the limit of %d: no longer sampling traces", e.memoryBytes, e.maxMemoryBytes)
+}
+
+// EventCircuitBreaker occurs when the circuit breaker configured by
+// Options.CircuitBreaker opens, after consecutive failed reports, and when
+// it closes again after a report is sent.
+type EventCircuitBreaker interface {
+	Event
+	EventCircuitBreaker()
+	// Open reports whether the breaker opened, rather than closed.
+	Open() bool
+	// Failures is the number of consecutive failed reports that opened
+	// the breaker, or zero when it closes.
+	Failures() int
+	// CoolDown is how long reports are suppressed, or zero when the
+	// breaker closes.
+	CoolDown() time.Duration
+}
+
+type eventCircuitBreaker struct {
+	open     bool
+	failures int
+	coolDown time.Duration
+}
+
+func newEventCircuitBreaker(open bool, failures int, coolDown time.Duration) *eventCircuitBreaker {
+	return &eventCircuitBreaker{
+		open:     open,
+		failures: failures,
+		coolDown: coolDown,
+	}
+}
+
+func (*eventCircuitBreaker) Event()               {}
+func (*eventCircuitBreaker) EventCircuitBreaker() {}
+
+func (e *eventCircuitBreaker) Open() bool {
+	return e.open
+}
+
+func (e *eventCircuitBreaker) Failures() int {
+	return e.failures
+}
+
+func (e *eventCircuitBreaker) CoolDown() time.Duration {
+	return e.coolDown
+}
+
+func (e *eventCircuitBreaker) String() string {
+	if e.open {
+		return fmt.Sprintf("circuit breaker opened after %d failed reports: suppressing reports for %v", e.failures, e.coolDown)
+	}
+	return "circuit breaker closed: reporting resumed"
+}
+
 // EventStatusReport occurs on every successful flush. It contains all metrics
 // collected since the previous succesful flush.
 type EventStatusReport interface {
@@ -152,7 +489,20 @@ type EventStatusReport interface {
 	Duration() time.Duration
 	SentSpans() int
 	DroppedSpans() int
+	// ExpiredSpans is the number of the dropped spans that were buffered
+	// longer than Options.BufferedSpanTTL.
+	ExpiredSpans() int
+	// RejectedSpans is the number of sent spans the collector reported it
+	// did not accept, see EventReportPartialSuccess.
+	RejectedSpans() int
 	EncodingErrors() int
+	// PayloadBytes is the serialized size of the report that was sent.
+	PayloadBytes() int
+	// ReportLatency is how long the collector took to accept the report.
+	ReportLatency() time.Duration
+	// ReportingPeriod is the current interval between reports, see
+	// Options.AdaptiveReportingPeriod.
+	ReportingPeriod() time.Duration
 }
 
 type eventStatusReport struct {
@@ -160,7 +510,12 @@ type eventStatusReport struct {
 	finishTime     time.Time
 	sentSpans      int
 	droppedSpans   int
+	expiredSpans   int
+	rejectedSpans  int
 	encodingErrors int
+	payloadBytes   int
+	reportLatency  time.Duration
+	reportPeriod   time.Duration
 }
 
 func newEventStatusReport(
@@ -184,6 +539,23 @@ func (s *eventStatusReport) SetSentSpans(sent int) {
 	s.sentSpans = sent
 }
 
+func (s *eventStatusReport) SetExpiredSpans(expired int) {
+	s.expiredSpans = expired
+}
+
+func (s *eventStatusReport) SetRejectedSpans(rejected int) {
+	s.rejectedSpans = rejected
+}
+
+func (s *eventStatusReport) SetPayloadBytes(bytes int) {
+	s.payloadBytes = bytes
+}
+
+func (s *eventStatusReport) SetReportLatency(latency, period time.Duration) {
+	s.reportLatency = latency
+	s.reportPeriod = period
+}
+
 func (s *eventStatusReport) StartTime() time.Time {
 	return s.startTime
 }
@@ -204,16 +576,40 @@ func (s *eventStatusReport) DroppedSpans() int {
 	return s.droppedSpans
 }
 
+func (s *eventStatusReport) ExpiredSpans() int {
+	return s.expiredSpans
+}
+
+func (s *eventStatusReport) RejectedSpans() int {
+	return s.rejectedSpans
+}
+
 func (s *eventStatusReport) EncodingErrors() int {
 	return s.encodingErrors
 }
 
+func (s *eventStatusReport) PayloadBytes() int {
+	return s.payloadBytes
+}
+
+func (s *eventStatusReport) ReportLatency() time.Duration {
+	return s.reportLatency
+}
+
+func (s *eventStatusReport) ReportingPeriod() time.Duration {
+	return s.reportPeriod
+}
+
 func (s *eventStatusReport) String() string {
 	return fmt.Sprint(
 		"STATUS REPORT start: ", s.startTime,
 		", end: ", s.finishTime,
 		", dropped spans: ", s.droppedSpans,
+		", expired spans: ", s.expiredSpans,
+		", rejected spans: ", s.rejectedSpans,
 		", encoding errors: ", s.encodingErrors,
+		", payload bytes: ", s.payloadBytes,
+		", report latency: ", s.reportLatency,
 	)
 }
 
@@ -233,7 +629,7 @@ type eventUnsupportedTracer struct {
 func newEventUnsupportedTracer(tracer opentracing.Tracer) EventUnsupportedTracer {
 	return &eventUnsupportedTracer{
 		tracer: tracer,
-		err:    fmt.Errorf("unsupported tracer type: %v", reflect.TypeOf(tracer)),
+		err:    fmt.Errorf("unsupported tracer type: %T", tracer),
 	}
 }
 
@@ -308,6 +704,308 @@ func (e *eventUnsupportedValue) Err() error {
 	return e.err
 }
 
+// EventDeadlineExceeded occurs when StartSpanFromContextWithDeadline starts a
+// span after its context's deadline has passed. Lateness is how long after
+// the deadline the span started.
+type EventDeadlineExceeded interface {
+	Event
+	EventDeadlineExceeded()
+	OperationName() string
+	Lateness() time.Duration
+}
+
+type eventDeadlineExceeded struct {
+	operationName string
+	lateness      time.Duration
+}
+
+func newEventDeadlineExceeded(operationName string, lateness time.Duration) *eventDeadlineExceeded {
+	return &eventDeadlineExceeded{operationName: operationName, lateness: lateness}
+}
+
+func (*eventDeadlineExceeded) Event()                 {}
+func (*eventDeadlineExceeded) EventDeadlineExceeded() {}
+
+func (e *eventDeadlineExceeded) OperationName() string {
+	return e.operationName
+}
+
+func (e *eventDeadlineExceeded) Lateness() time.Duration {
+	return e.lateness
+}
+
+func (e *eventDeadlineExceeded) String() string {
+	return fmt.Sprintf("span %q started %v after its context deadline", e.operationName, e.lateness)
+}
+
+// EventStartTimeClamped occurs when Options.ClampStartTimes corrects a span
+// that started in the future or before the process started. Start is the
+// span's original start, and Clamped the start it is reported with.
+type EventStartTimeClamped interface {
+	Event
+	EventStartTimeClamped()
+	OperationName() string
+	// TraceID is the hex trace ID, including the high half of 128-bit IDs.
+	TraceID() string
+	SpanID() uint64
+	Start() time.Time
+	Clamped() time.Time
+}
+
+type eventStartTimeClamped struct {
+	operationName string
+	traceID       string
+	spanID        uint64
+	start         time.Time
+	clamped       time.Time
+}
+
+func newEventStartTimeClamped(span *RawSpan, clamped time.Time) *eventStartTimeClamped {
+	return &eventStartTimeClamped{
+		operationName: span.Operation,
+		traceID:       formatTraceID(span.Context.TraceIDHigh, span.Context.TraceID),
+		spanID:        span.Context.SpanID,
+		start:         span.Start,
+		clamped:       clamped,
+	}
+}
+
+func (*eventStartTimeClamped) Event()                 {}
+func (*eventStartTimeClamped) EventStartTimeClamped() {}
+
+func (e *eventStartTimeClamped) OperationName() string {
+	return e.operationName
+}
+
+func (e *eventStartTimeClamped) TraceID() string {
+	return e.traceID
+}
+
+func (e *eventStartTimeClamped) SpanID() uint64 {
+	return e.spanID
+}
+
+func (e *eventStartTimeClamped) Start() time.Time {
+	return e.start
+}
+
+func (e *eventStartTimeClamped) Clamped() time.Time {
+	return e.clamped
+}
+
+func (e *eventStartTimeClamped) String() string {
+	return fmt.Sprintf("span %q (trace %s, span %x) started at %v, clamped to %v",
+		e.operationName, e.traceID, e.spanID, e.start, e.clamped)
+}
+
+// EventBaggageLimited occurs when a baggage item is dropped or truncated by
+// Options.BaggageLimits, either when it is set or when its span context is
+// extracted. Length is the length of the item's original value, in bytes.
+type EventBaggageLimited interface {
+	Event
+	EventBaggageLimited()
+	// TraceID is the hex trace ID, including the high half of 128-bit IDs.
+	TraceID() string
+	SpanID() uint64
+	Key() string
+	Length() int
+	Truncated() bool
+}
+
+// baggageLimit names the limit of BaggageLimitsOptions a baggage item
+// exceeded.
+type baggageLimit string
+
+const (
+	baggageLimitItems       baggageLimit = "MaxItems"
+	baggageLimitKeyLength   baggageLimit = "MaxKeyLength"
+	baggageLimitValueLength baggageLimit = "MaxValueLength"
+)
+
+type eventBaggageLimited struct {
+	traceID string
+	spanID  uint64
+	key     string
+	length  int
+	limit   baggageLimit
+}
+
+func newEventBaggageLimited(sc SpanContext, key, val string, limit baggageLimit) *eventBaggageLimited {
+	return &eventBaggageLimited{
+		traceID: formatTraceID(sc.TraceIDHigh, sc.TraceID),
+		spanID:  sc.SpanID,
+		key:     key,
+		length:  len(val),
+		limit:   limit,
+	}
+}
+
+func (*eventBaggageLimited) Event()               {}
+func (*eventBaggageLimited) EventBaggageLimited() {}
+
+func (e *eventBaggageLimited) TraceID() string {
+	return e.traceID
+}
+
+func (e *eventBaggageLimited) SpanID() uint64 {
+	return e.spanID
+}
+
+func (e *eventBaggageLimited) Key() string {
+	return e.key
+}
+
+func (e *eventBaggageLimited) Length() int {
+	return e.length
+}
+
+// Truncated reports whether the item's value was truncated, rather than
+// the item dropped.
+func (e *eventBaggageLimited) Truncated() bool {
+	return e.limit == baggageLimitValueLength
+}
+
+func (e *eventBaggageLimited) String() string {
+	action := "dropped"
+	if e.Truncated() {
+		action = "truncated"
+	}
+	return fmt.Sprintf("baggage item %q (trace %s, span %x) %s by BaggageLimits.%s",
+		e.key, e.traceID, e.spanID, action, e.limit)
+}
+
+// EventInvalidSpan occurs when Options.ValidateSpans is set and a finished
+// span breaks the constraints of the collector protocol. The span is still
+// reported, but the collector may reject or alter it.
+type EventInvalidSpan interface {
+	Event
+	EventInvalidSpan()
+	OperationName() string
+	// TraceID is the hex trace ID, including the high half of 128-bit IDs.
+	TraceID() string
+	SpanID() uint64
+	Violations() []string
+}
+
+type eventInvalidSpan struct {
+	operationName string
+	traceID       string
+	spanID        uint64
+	violations    []string
+}
+
+func newEventInvalidSpan(span *RawSpan, violations []string) *eventInvalidSpan {
+	return &eventInvalidSpan{
+		operationName: span.Operation,
+		traceID:       formatTraceID(span.Context.TraceIDHigh, span.Context.TraceID),
+		spanID:        span.Context.SpanID,
+		violations:    violations,
+	}
+}
+
+func (*eventInvalidSpan) Event()            {}
+func (*eventInvalidSpan) EventInvalidSpan() {}
+
+func (e *eventInvalidSpan) OperationName() string {
+	return e.operationName
+}
+
+func (e *eventInvalidSpan) TraceID() string {
+	return e.traceID
+}
+
+func (e *eventInvalidSpan) SpanID() uint64 {
+	return e.spanID
+}
+
+func (e *eventInvalidSpan) Violations() []string {
+	return e.violations
+}
+
+func (e *eventInvalidSpan) String() string {
+	return fmt.Sprintf("span %q (trace %s, span %x) is invalid: %s",
+		e.operationName, e.traceID, e.spanID, strings.Join(e.violations, "; "))
+}
+
+// EventReportLoopStalled occurs when the watchdog configured by
+// Options.Watchdog finds that the report loop has stopped running, because
+// it panicked or is blocked.
+type EventReportLoopStalled interface {
+	Event
+	EventReportLoopStalled()
+	// Stalled is how long the report loop has not run.
+	Stalled() time.Duration
+	// Panic is the value the report loop panicked with, or nil if it is
+	// blocked.
+	Panic() interface{}
+	// Stack is the stack trace of the panic, if any.
+	Stack() []byte
+	// Restarted reports whether a new report loop is started, after
+	// RestartDelay.
+	Restarted() bool
+	RestartDelay() time.Duration
+	// Goroutines is the number of goroutines in the process, which grows
+	// if the report loop is blocked by a leak.
+	Goroutines() int
+}
+
+type eventReportLoopStalled struct {
+	stalled      time.Duration
+	panicValue   interface{}
+	panicStack   []byte
+	restarted    bool
+	restartDelay time.Duration
+	goroutines   int
+}
+
+func newEventReportLoopStalled(stalled time.Duration, panicValue interface{}, panicStack []byte, goroutines int) *eventReportLoopStalled {
+	return &eventReportLoopStalled{
+		stalled:    stalled,
+		panicValue: panicValue,
+		panicStack: panicStack,
+		goroutines: goroutines,
+	}
+}
+
+func (*eventReportLoopStalled) Event()                  {}
+func (*eventReportLoopStalled) EventReportLoopStalled() {}
+
+func (e *eventReportLoopStalled) Stalled() time.Duration {
+	return e.stalled
+}
+
+func (e *eventReportLoopStalled) Panic() interface{} {
+	return e.panicValue
+}
+
+func (e *eventReportLoopStalled) Stack() []byte {
+	return e.panicStack
+}
+
+func (e *eventReportLoopStalled) Restarted() bool {
+	return e.restarted
+}
+
+func (e *eventReportLoopStalled) RestartDelay() time.Duration {
+	return e.restartDelay
+}
+
+func (e *eventReportLoopStalled) Goroutines() int {
+	return e.goroutines
+}
+
+func (e *eventReportLoopStalled) String() string {
+	cause := "blocked"
+	if e.panicValue != nil {
+		cause = fmt.Sprintf("panicked: %v", e.panicValue)
+	}
+	action := "not restarted"
+	if e.restarted {
+		action = fmt.Sprintf("restarting in %v", e.restartDelay)
+	}
+	return fmt.Sprintf("report loop stalled for %v, %s, with %d goroutines running: %s", e.stalled, cause, e.goroutines, action)
+}
+
 const tracerDisabled = "the tracer has been disabled"
 
 // EventTracerDisabled occurs when a tracer is disabled by either the user or
@@ -0,0 +1,123 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+// The backend service of the multi-service demo. It serves the gRPC
+// demo.Inventory service and, for every reservation, enqueues a fulfillment
+// job with the worker. Reserving the item "sold-out" fails.
+//
+// $ go run ./examples/demo/backend -worker=http://localhost:8081 -collector=localhost:9997
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/lightstep/lightstep-tracer-go/examples/demo/internal/demo"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	flagListen = flag.String("listen", ":9000", "address to serve gRPC on")
+	flagWorker = flag.String("worker", "http://localhost:8081", "base URL of the worker's queue")
+)
+
+type inventory struct {
+	tracer opentracing.Tracer
+	client *http.Client
+}
+
+func (inv *inventory) Reserve(ctx context.Context, item *wrappers.StringValue) (*wrappers.StringValue, error) {
+	parent, _ := demo.ExtractMetadata(ctx, inv.tracer)
+	span := inv.tracer.StartSpan("demo.Inventory/Reserve", ext.RPCServerOption(parent))
+	defer span.Finish()
+	span.SetTag("item", item.GetValue())
+	customer := span.BaggageItem(demo.CustomerBaggageKey)
+	span.SetTag(demo.CustomerBaggageKey, customer)
+
+	if item.GetValue() == "" || item.GetValue() == "sold-out" {
+		err := status.Errorf(codes.FailedPrecondition, "item %q is not available", item.GetValue())
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
+		return nil, err
+	}
+
+	// Pretend to check the stock.
+	time.Sleep(time.Duration(10+rand.Intn(40)) * time.Millisecond)
+	reservation := fmt.Sprintf("r-%08x", rand.Uint32())
+	span.LogFields(otlog.String("reservation", reservation))
+
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	if err := inv.enqueue(ctx, reservation, item.GetValue()); err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
+		return nil, status.Errorf(codes.Unavailable, "enqueueing fulfillment: %v", err)
+	}
+	return &wrappers.StringValue{Value: reservation}, nil
+}
+
+// enqueue posts a fulfillment job to the worker's queue, carrying the span
+// context in the request headers so the worker can continue the trace.
+func (inv *inventory) enqueue(ctx context.Context, reservation, item string) error {
+	span, _ := opentracing.StartSpanFromContextWithTracer(ctx, inv.tracer, "enqueue fulfillment",
+		ext.SpanKindProducer)
+	defer span.Finish()
+
+	form := url.Values{"reservation": {reservation}, "item": {item}}
+	req, err := http.NewRequest("POST", *flagWorker+"/enqueue", nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = form.Encode()
+	if err := inv.tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		span.LogFields(otlog.Error(err))
+	}
+
+	resp, err := inv.client.Do(req.WithContext(ctx))
+	if err != nil {
+		ext.Error.Set(span, true)
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		ext.Error.Set(span, true)
+		return fmt.Errorf("worker responded %s", resp.Status)
+	}
+	return nil
+}
+
+func main() {
+	flag.Parse()
+	tracer := demo.NewTracer("backend")
+
+	lis, err := net.Listen("tcp", *flagListen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := grpc.NewServer()
+	demo.RegisterInventoryServer(server, &inventory{
+		tracer: tracer,
+		client: &http.Client{Timeout: time.Second},
+	})
+
+	go func() {
+		log.Printf("backend listening on %s", *flagListen)
+		if err := server.Serve(lis); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	demo.WaitForShutdown(tracer, server.GracefulStop)
+}
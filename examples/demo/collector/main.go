@@ -0,0 +1,103 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+// A fake LightStep collector for the multi-service demo. It accepts reports
+// over plaintext gRPC and prints every span it receives, so the demo can be
+// run without a LightStep account.
+//
+// $ go run ./examples/demo/collector -listen=:9997
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	lightstep "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	"google.golang.org/grpc"
+)
+
+var flagListen = flag.String("listen", ":9997", "address to serve the collector on")
+
+type collector struct {
+	mu sync.Mutex // serializes output
+}
+
+func (c *collector) Report(ctx context.Context, req *cpb.ReportRequest) (*cpb.ReportResponse, error) {
+	component := "unknown"
+	for _, tag := range req.GetReporter().GetTags() {
+		if tag.GetKey() == lightstep.ComponentNameKey {
+			component = tag.GetStringValue()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, span := range req.GetSpans() {
+		sc := span.GetSpanContext()
+		fmt.Printf("%-8s trace=%016x span=%016x %s (%dus)\n",
+			component, sc.GetTraceId(), sc.GetSpanId(), span.GetOperationName(), span.GetDurationMicros())
+		for _, ref := range span.GetReferences() {
+			fmt.Printf("\t%s %016x\n", strings.ToLower(ref.GetRelationship().String()), ref.GetSpanContext().GetSpanId())
+		}
+		if tags := formatTags(span.GetTags()); tags != "" {
+			fmt.Printf("\ttags: %s\n", tags)
+		}
+		if baggage := formatBaggage(sc.GetBaggage()); baggage != "" {
+			fmt.Printf("\tbaggage: %s\n", baggage)
+		}
+		for _, l := range span.GetLogs() {
+			fmt.Printf("\tlog: %s\n", formatTags(l.GetFields()))
+		}
+	}
+	return &cpb.ReportResponse{}, nil
+}
+
+func formatTags(tags []*cpb.KeyValue) string {
+	fields := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		var value interface{}
+		switch v := tag.GetValue().(type) {
+		case *cpb.KeyValue_StringValue:
+			value = v.StringValue
+		case *cpb.KeyValue_IntValue:
+			value = v.IntValue
+		case *cpb.KeyValue_DoubleValue:
+			value = v.DoubleValue
+		case *cpb.KeyValue_BoolValue:
+			value = v.BoolValue
+		case *cpb.KeyValue_JsonValue:
+			value = v.JsonValue
+		}
+		fields = append(fields, fmt.Sprintf("%s=%v", tag.GetKey(), value))
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, " ")
+}
+
+func formatBaggage(baggage map[string]string) string {
+	fields := make([]string, 0, len(baggage))
+	for k, v := range baggage {
+		fields = append(fields, k+"="+v)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, " ")
+}
+
+func main() {
+	flag.Parse()
+	lis, err := net.Listen("tcp", *flagListen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := grpc.NewServer()
+	cpb.RegisterCollectorServiceServer(server, &collector{})
+	log.Printf("collector listening on %s", *flagListen)
+	log.Fatal(server.Serve(lis))
+}
@@ -0,0 +1,110 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+// The frontend service of the multi-service demo. It serves
+//
+//	GET /checkout?item=ITEM&customer=NAME
+//
+// starting a trace, recording the customer as baggage, and reserving the
+// item with the backend over gRPC.
+//
+// $ go run ./examples/demo/frontend -backend=localhost:9000 -collector=localhost:9997
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	lightstep "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/examples/demo/internal/demo"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+)
+
+var (
+	flagListen  = flag.String("listen", ":8080", "address to serve HTTP on")
+	flagBackend = flag.String("backend", "localhost:9000", "host:port of the backend gRPC service")
+)
+
+type frontend struct {
+	tracer  opentracing.Tracer
+	backend *grpc.ClientConn
+}
+
+func (f *frontend) checkout(w http.ResponseWriter, r *http.Request) {
+	// Continue a trace started by the caller, if there is one.
+	parent, _ := f.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+	span := f.tracer.StartSpan("GET /checkout", ext.RPCServerOption(parent))
+	defer span.Finish()
+	ext.HTTPMethod.Set(span, r.Method)
+	ext.HTTPUrl.Set(span, r.URL.String())
+
+	item := r.URL.Query().Get("item")
+	customer := r.URL.Query().Get("customer")
+	if customer == "" {
+		customer = "anonymous"
+	}
+	span.SetBaggageItem(demo.CustomerBaggageKey, customer)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	ctx = lightstep.WithScopedTags(ctx, opentracing.Tags{"item": item})
+
+	reservation, err := f.reserve(ctx, item)
+	if err != nil {
+		ext.Error.Set(span, true)
+		ext.HTTPStatusCode.Set(span, http.StatusBadGateway)
+		span.LogFields(otlog.Error(err))
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	ext.HTTPStatusCode.Set(span, http.StatusOK)
+	fmt.Fprintf(w, "reserved %s for %s: %s\n", item, customer, reservation)
+}
+
+func (f *frontend) reserve(ctx context.Context, item string) (string, error) {
+	span, ctx := lightstep.StartSpanFromContext(ctx, f.tracer, "demo.Inventory/Reserve", ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	reservation, err := demo.Reserve(demo.InjectMetadata(ctx), f.backend, item)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
+	}
+	return reservation, err
+}
+
+func main() {
+	flag.Parse()
+	tracer := demo.NewTracer("frontend")
+
+	conn, err := grpc.Dial(*flagBackend, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("dialing backend: %v", err)
+	}
+	defer conn.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/checkout", (&frontend{tracer: tracer, backend: conn}).checkout)
+	server := &http.Server{Addr: *flagListen, Handler: mux}
+
+	go func() {
+		log.Printf("frontend listening on %s", *flagListen)
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	demo.WaitForShutdown(tracer, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+}
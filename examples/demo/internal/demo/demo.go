@@ -0,0 +1,71 @@
+// Package demo holds the pieces shared by the services of the multi-service
+// demo: tracer setup, graceful shutdown, and the gRPC inventory service.
+package demo
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	lightstep "github.com/lightstep/lightstep-tracer-go"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// CustomerBaggageKey is the baggage item set by the frontend and read by
+// every service downstream of it.
+const CustomerBaggageKey = "customer"
+
+var (
+	flagCollector   = flag.String("collector", "localhost:9997", "host:port of the collector to report spans to")
+	flagAccessToken = flag.String("access_token", "demo", "access token to report spans with")
+)
+
+// NewTracer creates a tracer for component that reports to the plaintext
+// gRPC collector given by the -collector flag, and installs it as the
+// global tracer. Events from the tracer are logged.
+func NewTracer(component string) opentracing.Tracer {
+	host, portString, err := net.SplitHostPort(*flagCollector)
+	if err != nil {
+		log.Fatalf("invalid -collector %q: %v", *flagCollector, err)
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		log.Fatalf("invalid -collector %q: %v", *flagCollector, err)
+	}
+
+	lightstep.SetGlobalEventHandler(func(event lightstep.Event) {
+		log.Printf("tracer: %s", event)
+	})
+
+	tracer := lightstep.NewTracer(lightstep.Options{
+		AccessToken: *flagAccessToken,
+		Collector:   lightstep.Endpoint{Host: host, Port: port, Plaintext: true},
+		UseGRPC:     true,
+		Tags:        opentracing.Tags{lightstep.ComponentNameKey: component},
+	})
+	opentracing.SetGlobalTracer(tracer)
+	return tracer
+}
+
+// WaitForShutdown blocks until the process receives SIGINT or SIGTERM, calls
+// stop, and then closes tracer so that spans still buffered are flushed to
+// the collector before the process exits.
+func WaitForShutdown(tracer opentracing.Tracer, stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signals
+	log.Printf("received %s, shutting down", sig)
+
+	stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lightstep.Close(ctx, tracer)
+	log.Print("tracer flushed")
+}
@@ -0,0 +1,106 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package demo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	opentracing "github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// InventoryServer reserves an item and returns a reservation ID.
+//
+// The service is declared by hand, using the protobuf well-known wrapper
+// types for its messages, so the demo doesn't need generated code.
+type InventoryServer interface {
+	Reserve(context.Context, *wrappers.StringValue) (*wrappers.StringValue, error)
+}
+
+// RegisterInventoryServer registers srv with s.
+func RegisterInventoryServer(s *grpc.Server, srv InventoryServer) {
+	s.RegisterService(&inventoryServiceDesc, srv)
+}
+
+// Reserve calls the inventory service over conn.
+func Reserve(ctx context.Context, conn *grpc.ClientConn, item string) (string, error) {
+	out := new(wrappers.StringValue)
+	err := grpc.Invoke(ctx, "/demo.Inventory/Reserve", &wrappers.StringValue{Value: item}, out, conn)
+	return out.GetValue(), err
+}
+
+var inventoryServiceDesc = grpc.ServiceDesc{
+	ServiceName: "demo.Inventory",
+	HandlerType: (*InventoryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Reserve",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(wrappers.StringValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(InventoryServer).Reserve(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/demo.Inventory/Reserve"}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(InventoryServer).Reserve(ctx, req.(*wrappers.StringValue))
+				}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// metadataCarrier adapts gRPC metadata to the opentracing TextMap format.
+// gRPC requires lowercase keys, so keys are lowercased on the way in; the
+// tracer's text propagation is case-insensitive.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Set(key, val string) {
+	key = strings.ToLower(key)
+	c[key] = append(c[key], val)
+}
+
+func (c metadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for key, values := range c {
+		for _, val := range values {
+			if err := handler(key, val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// InjectMetadata returns a copy of ctx whose outgoing gRPC metadata carries
+// the span context of the span in ctx.
+func InjectMetadata(ctx context.Context) context.Context {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ctx
+	}
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	if err := span.Tracer().Inject(span.Context(), opentracing.TextMap, metadataCarrier(md)); err != nil {
+		return ctx
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// ExtractMetadata returns the span context carried by the incoming gRPC
+// metadata of ctx, or an error if there is none.
+func ExtractMetadata(ctx context.Context, tracer opentracing.Tracer) (opentracing.SpanContext, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return tracer.Extract(opentracing.TextMap, metadataCarrier(md))
+}
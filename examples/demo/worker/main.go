@@ -0,0 +1,120 @@
+// The worker service of the multi-service demo. It accepts fulfillment jobs
+// on an in-memory queue,
+//
+//	POST /enqueue?reservation=ID&item=ITEM
+//
+// and processes them in the background. Each job's span follows from the
+// span that enqueued it. One job in five fails.
+//
+// $ go run ./examples/demo/worker -collector=localhost:9997
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lightstep/lightstep-tracer-go/examples/demo/internal/demo"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+var flagListen = flag.String("listen", ":8081", "address to serve the queue on")
+
+type job struct {
+	reservation string
+	item        string
+	enqueued    opentracing.SpanContext
+}
+
+type worker struct {
+	tracer opentracing.Tracer
+	queue  chan job
+}
+
+func (w *worker) enqueue(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	enqueued, err := w.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+	if err != nil && err != opentracing.ErrSpanContextNotFound {
+		log.Printf("extracting span context: %v", err)
+	}
+
+	select {
+	case w.queue <- job{
+		reservation: r.URL.Query().Get("reservation"),
+		item:        r.URL.Query().Get("item"),
+		enqueued:    enqueued,
+	}:
+		rw.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(rw, "queue full", http.StatusServiceUnavailable)
+	}
+}
+
+func (w *worker) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for j := range w.queue {
+		w.process(j)
+	}
+}
+
+func (w *worker) process(j job) {
+	var opts []opentracing.StartSpanOption
+	if j.enqueued != nil {
+		opts = append(opts, opentracing.FollowsFrom(j.enqueued))
+	}
+	opts = append(opts, ext.SpanKindConsumer, opentracing.Tag{Key: "reservation", Value: j.reservation})
+	span := w.tracer.StartSpan("fulfill", opts...)
+	defer span.Finish()
+	span.SetTag(demo.CustomerBaggageKey, span.BaggageItem(demo.CustomerBaggageKey))
+
+	time.Sleep(time.Duration(50+rand.Intn(200)) * time.Millisecond)
+	if rand.Intn(5) == 0 {
+		err := errors.New("warehouse did not acknowledge")
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
+		log.Printf("fulfilling %s: %v", j.reservation, err)
+		return
+	}
+	log.Printf("fulfilled %s (%s)", j.reservation, j.item)
+}
+
+func main() {
+	flag.Parse()
+	tracer := demo.NewTracer("worker")
+
+	w := &worker{tracer: tracer, queue: make(chan job, 100)}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go w.run(&wg)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/enqueue", w.enqueue)
+	server := &http.Server{Addr: *flagListen, Handler: mux}
+
+	go func() {
+		log.Printf("worker listening on %s", *flagListen)
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	demo.WaitForShutdown(tracer, func() {
+		// Stop accepting jobs, then drain the queue so that every job's
+		// span is finished before the tracer is flushed.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+		close(w.queue)
+		wg.Wait()
+	})
+}
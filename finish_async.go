@@ -0,0 +1,90 @@
+package lightstep
+
+import (
+	"errors"
+	"sync"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var (
+	// ErrSpanDropped resolves a FinishHandle whose span was discarded, e.g.
+	// because the buffer was full or the tracer was disabled or closed.
+	ErrSpanDropped = errors.New("span was dropped before being reported")
+	// ErrSpanAlreadyFinished resolves a FinishHandle for a span that had
+	// already been finished.
+	ErrSpanAlreadyFinished = errors.New("span was already finished")
+	// ErrSpanRejected resolves the FinishHandles of the spans in a report
+	// the collector rejected every span of. Rejected reports aren't
+	// retried.
+	ErrSpanRejected = errors.New("span was rejected by the collector")
+	// ErrSpanPartiallyRejected resolves the FinishHandles of the spans in a
+	// report the collector accepted with errors, as it doesn't say which
+	// spans it rejected.
+	ErrSpanPartiallyRejected = errors.New("span was reported, but the collector rejected part of the report")
+
+	errFinishAsyncUnsupported = errors.New("span does not support FinishAsync")
+)
+
+// FinishHandle tracks the delivery of a span finished with FinishAsync.
+type FinishHandle interface {
+	// Done is closed once the span has been handed to the transport, or
+	// dropped.
+	Done() <-chan struct{}
+	// Err blocks until Done is closed, then returns nil if the span was
+	// reported, or the reason it was not.
+	Err() error
+}
+
+type finishHandle struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func newFinishHandle() *finishHandle {
+	return &finishHandle{done: make(chan struct{})}
+}
+
+func (h *finishHandle) resolve(err error) {
+	h.once.Do(func() {
+		h.err = err
+		close(h.done)
+	})
+}
+
+func (h *finishHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+func (h *finishHandle) Err() error {
+	<-h.done
+	return h.err
+}
+
+// resolveSpans resolves the FinishHandles of any spans finished with
+// FinishAsync.
+func resolveSpans(spans []RawSpan, err error) {
+	for _, span := range spans {
+		if span.finishHandle != nil {
+			span.finishHandle.resolve(err)
+		}
+	}
+}
+
+// FinishAsync finishes the span, returning a FinishHandle that is resolved
+// once the span has been handed to the transport or dropped. Spans not
+// created by a LightStep Tracer are finished, and their handle resolved with
+// an error.
+func FinishAsync(span opentracing.Span) FinishHandle {
+	if asyncSpan, ok := span.(interface {
+		FinishAsync() FinishHandle
+	}); ok {
+		return asyncSpan.FinishAsync()
+	}
+
+	span.Finish()
+	handle := newFinishHandle()
+	handle.resolve(errFinishAsyncUnsupported)
+	return handle
+}
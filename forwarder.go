@@ -0,0 +1,277 @@
+package lightstep
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// ForwarderPath is the path forwarded spans are posted to.
+const ForwarderPath = "/v1/spans"
+
+// DefaultForwarderMaxRequestBytes is the default
+// ForwarderOptions.MaxRequestBytes.
+const DefaultForwarderMaxRequestBytes = 4 << 20
+
+var (
+	validationErrorForwarderNetwork = fmt.Errorf("Options invalid: Forwarder.Network must be \"unix\" or \"tcp\"")
+	validationErrorForwarderAddress = fmt.Errorf("Options invalid: Forwarder.Address must be a loopback address for the tcp network")
+)
+
+// ForwarderOptions configures a listener that accepts finished spans from
+// other processes, such as co-processes without their own tracer or
+// sidecar, and reports them through this tracer's buffer and connection.
+// Spans are posted to ForwarderPath as a JSON array of ForwardedSpan, and
+// pass through the same filters and sampling as this process's spans.
+type ForwarderOptions struct {
+	// Network is "unix", to listen on the socket at Address, or "tcp", to
+	// listen on a loopback Address such as "127.0.0.1:8360". Defaults to
+	// "unix".
+	Network string `yaml:"network"`
+	// Address enables the forwarder.
+	Address string `yaml:"address"`
+	// MaxRequestBytes limits the size of a posted request body. Defaults to
+	// DefaultForwarderMaxRequestBytes.
+	MaxRequestBytes int64 `yaml:"max_request_bytes"`
+}
+
+func (o ForwarderOptions) validate() error {
+	if o.Address == "" {
+		return nil
+	}
+	switch o.Network {
+	case "", "unix":
+		return nil
+	case "tcp":
+		host, _, err := net.SplitHostPort(o.Address)
+		if err != nil {
+			return validationErrorForwarderAddress
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return validationErrorForwarderAddress
+		}
+		return nil
+	}
+	return validationErrorForwarderNetwork
+}
+
+// ForwardedSpan is the JSON form of a RawSpan accepted by the forwarder.
+// IDs are hex strings, as JSON numbers can't hold every uint64.
+type ForwardedSpan struct {
+	TraceID        string                 `json:"trace_id"`
+	SpanID         string                 `json:"span_id"`
+	ParentSpanID   string                 `json:"parent_span_id,omitempty"`
+	Operation      string                 `json:"operation"`
+	Start          time.Time              `json:"start"`
+	DurationMicros int64                  `json:"duration_micros"`
+	Tags           map[string]interface{} `json:"tags,omitempty"`
+	Baggage        map[string]string      `json:"baggage,omitempty"`
+	Logs           []ForwardedLog         `json:"logs,omitempty"`
+}
+
+// ForwardedLog is the JSON form of a span's log record.
+type ForwardedLog struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// NewForwardedSpan returns the forwarded form of raw, for processes that
+// record spans with this package but report them through another
+// process's forwarder, for example from a Recorder.
+func NewForwardedSpan(raw RawSpan) ForwardedSpan {
+	span := ForwardedSpan{
+		TraceID:        formatTraceID(raw.Context.TraceIDHigh, raw.Context.TraceID),
+		SpanID:         strconv.FormatUint(raw.Context.SpanID, 16),
+		Operation:      raw.Operation,
+		Start:          raw.Start,
+		DurationMicros: int64(raw.Duration / time.Microsecond),
+		Tags:           raw.Tags,
+		Baggage:        raw.Context.Baggage,
+	}
+	if raw.ParentSpanID != 0 {
+		span.ParentSpanID = strconv.FormatUint(raw.ParentSpanID, 16)
+	}
+	for _, record := range raw.Logs {
+		fields := make(map[string]interface{}, len(record.Fields))
+		for _, field := range record.Fields {
+			fields[field.Key()] = field.Value()
+		}
+		span.Logs = append(span.Logs, ForwardedLog{Timestamp: record.Timestamp, Fields: fields})
+	}
+	return span
+}
+
+// RawSpan returns the span described by s.
+func (s ForwardedSpan) RawSpan() (RawSpan, error) {
+	traceIDHigh, traceID, err := parseTraceID(s.TraceID)
+	if err != nil || traceID == 0 {
+		return RawSpan{}, fmt.Errorf("invalid trace_id %q", s.TraceID)
+	}
+	spanID, err := strconv.ParseUint(s.SpanID, 16, 64)
+	if err != nil || spanID == 0 {
+		return RawSpan{}, fmt.Errorf("invalid span_id %q", s.SpanID)
+	}
+	var parentSpanID uint64
+	if s.ParentSpanID != "" {
+		if parentSpanID, err = strconv.ParseUint(s.ParentSpanID, 16, 64); err != nil {
+			return RawSpan{}, fmt.Errorf("invalid parent_span_id %q", s.ParentSpanID)
+		}
+	}
+	if s.DurationMicros < 0 {
+		return RawSpan{}, fmt.Errorf("negative duration_micros")
+	}
+
+	raw := RawSpan{
+		Context:      SpanContext{TraceID: traceID, TraceIDHigh: traceIDHigh, SpanID: spanID, Baggage: s.Baggage},
+		ParentSpanID: parentSpanID,
+		Operation:    s.Operation,
+		Start:        s.Start.Round(0),
+		Duration:     time.Duration(s.DurationMicros) * time.Microsecond,
+		Tags:         opentracing.Tags{},
+	}
+	for k, v := range s.Tags {
+		raw.Tags[k] = forwardedValue(v)
+	}
+	for _, l := range s.Logs {
+		record := opentracing.LogRecord{Timestamp: l.Timestamp.Round(0)}
+		for k, v := range l.Fields {
+			record.Fields = append(record.Fields, log.Object(k, forwardedValue(v)))
+		}
+		raw.Logs = append(raw.Logs, record)
+	}
+	return raw, nil
+}
+
+// forwardedValue converts the numbers of a decoded JSON value to int64 or
+// float64, so that they're reported as numbers.
+func forwardedValue(v interface{}) interface{} {
+	number, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
+
+// forwarder serves the spans posted by other processes to record.
+type forwarder struct {
+	record          func(RawSpan)
+	maxRequestBytes int64
+	server          *http.Server
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// startForwarder listens on the address given by opts and records the
+// spans posted to it until it is closed.
+func startForwarder(opts ForwarderOptions, record func(RawSpan)) (*forwarder, error) {
+	network := opts.Network
+	if network == "" {
+		network = "unix"
+	}
+	if network == "unix" {
+		removeStaleSocket(opts.Address)
+	}
+	listener, err := net.Listen(network, opts.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &forwarder{
+		record:          record,
+		maxRequestBytes: opts.MaxRequestBytes,
+		closed:          make(chan struct{}),
+	}
+	if f.maxRequestBytes <= 0 {
+		f.maxRequestBytes = DefaultForwarderMaxRequestBytes
+	}
+	mux := http.NewServeMux()
+	mux.Handle(ForwarderPath, f)
+	f.server = &http.Server{Handler: mux}
+
+	go func() {
+		defer close(f.closed)
+		if err := f.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			emitEvent(newEventForwarderError(err))
+		}
+	}()
+	return f, nil
+}
+
+// removeStaleSocket removes a socket left at address by a previous run, so
+// that it can be listened on again. Other files are left alone.
+func removeStaleSocket(address string) {
+	if info, err := os.Lstat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(address)
+	}
+}
+
+func (f *forwarder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, f.maxRequestBytes+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > f.maxRequestBytes {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var spans []ForwardedSpan
+	if err := decoder.Decode(&spans); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	raws := make([]RawSpan, len(spans))
+	for i, span := range spans {
+		raw, err := span.RawSpan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("span %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		raws[i] = raw
+	}
+	for _, raw := range raws {
+		f.record(raw)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Close stops accepting spans and waits for the requests in progress, so
+// that their spans are buffered before the tracer's final flush.
+func (f *forwarder) Close(ctx context.Context) error {
+	var err error
+	f.closeOnce.Do(func() {
+		err = f.server.Shutdown(ctx)
+		if err == nil {
+			<-f.closed
+		}
+	})
+	return err
+}
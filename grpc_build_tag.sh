@@ -0,0 +1,23 @@
+#!/bin/sh
+
+# Adds the build constraint of the gRPC transport to generated files, which
+# excludes them from builds with the lightstep_nogrpc or lightstep_constrained
+# tag and on GOOS=js. The constraint follows the files' leading comments,
+# such as their "Code generated" comment.
+
+set -e
+
+for file in "$@"; do
+	if grep -q '^//go:build' "$file"; then
+		continue
+	fi
+	awk '!tagged && !/^\/\// {
+		tagged = 1
+		print ""
+		print "//go:build !lightstep_nogrpc && !lightstep_constrained && !js"
+		print "// +build !lightstep_nogrpc,!lightstep_constrained,!js"
+		if ($0 != "") print ""
+	}
+	{ print }' "$file" > "$file.tmp"
+	mv "$file.tmp" "$file"
+done
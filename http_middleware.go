@@ -0,0 +1,132 @@
+package lightstep
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// DefaultTraceIDHeader is the conventional response header for
+// HTTPMiddlewareOptions.TraceIDHeader.
+const DefaultTraceIDHeader = "X-Trace-Id"
+
+// HTTPMiddlewareOptions configures HTTPMiddleware.
+type HTTPMiddlewareOptions struct {
+	// OperationName names the span of a request. Defaults to "HTTP " and
+	// the request method.
+	OperationName func(*http.Request) string
+	// TraceIDHeader, if set, is a response header, such as
+	// DefaultTraceIDHeader, set to the hex trace ID of each request, so
+	// that clients and support staff can find the trace of a failed
+	// request. The header is exposed to anyone who can make requests, but
+	// the trace ID alone can't be used to read the trace.
+	TraceIDHeader string
+}
+
+// HTTPMiddleware returns a handler that calls next with the span of each
+// request in the request's context. The span continues the trace
+// propagated in the request's headers, and is tagged with the request's
+// method, URL and response status. If the request's context already
+// carries a span, from other tracing middleware, it is used instead, and
+// only the TraceIDHeader is set.
+func HTTPMiddleware(tracer opentracing.Tracer, next http.Handler, opts HTTPMiddlewareOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span := opentracing.SpanFromContext(r.Context()); span != nil {
+			setTraceIDHeader(w, opts.TraceIDHeader, span)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		operationName := "HTTP " + r.Method
+		if opts.OperationName != nil {
+			operationName = opts.OperationName(r)
+		}
+		parent, _ := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+		span := tracer.StartSpan(operationName, ext.RPCServerOption(parent))
+		defer span.Finish()
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.String())
+
+		setTraceIDHeader(w, opts.TraceIDHeader, span)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(opentracing.ContextWithSpan(r.Context(), span)))
+
+		ext.HTTPStatusCode.Set(span, uint16(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			ext.Error.Set(span, true)
+		}
+	})
+}
+
+// setTraceIDHeader sets header, if it is not empty, to the trace ID of a
+// span created by a LightStep Tracer.
+func setTraceIDHeader(w http.ResponseWriter, header string, span opentracing.Span) {
+	if header == "" {
+		return
+	}
+	if sc, ok := span.Context().(SpanContext); ok {
+		w.Header().Set(header, formatTraceID(sc.TraceIDHigh, sc.TraceID))
+	}
+}
+
+// statusRecorder records the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush lets handlers stream responses through the middleware.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets handlers, such as those of WebSockets, take over the
+// connection through the middleware.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	r.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// Push lets handlers push resources over HTTP/2 through the middleware.
+func (r *statusRecorder) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := r.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// ReadFrom keeps the optimized copies, such as sendfile, of the wrapped
+// ResponseWriter.
+func (r *statusRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.wroteHeader = true
+	return io.Copy(r.ResponseWriter, src)
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,106 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("HTTPMiddleware", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+	var active opentracing.Span
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+		})
+		active = nil
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	handler := func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			active = opentracing.SpanFromContext(r.Context())
+			w.WriteHeader(status)
+		})
+	}
+
+	It("traces requests and echoes the trace ID", func() {
+		parent := tracer.StartSpan("client")
+		req := httptest.NewRequest("GET", "/checkout", nil)
+		Expect(tracer.Inject(parent.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))).To(Succeed())
+
+		w := httptest.NewRecorder()
+		HTTPMiddleware(tracer, handler(http.StatusBadGateway), HTTPMiddlewareOptions{
+			TraceIDHeader: DefaultTraceIDHeader,
+		}).ServeHTTP(w, req)
+
+		traceID := parent.Context().(SpanContext).TraceID
+		Expect(w.Header().Get(DefaultTraceIDHeader)).To(Equal(strconv.FormatUint(traceID, 16)))
+		Expect(active).NotTo(BeNil())
+
+		Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(1))
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Operation).To(Equal("HTTP GET"))
+		Expect(raw.Context.TraceID).To(Equal(traceID))
+		Expect(raw.Tags).To(HaveKeyWithValue("http.status_code", uint16(http.StatusBadGateway)))
+		Expect(raw.Tags).To(HaveKeyWithValue("error", true))
+	})
+
+	It("uses the span already in the request's context", func() {
+		span := tracer.StartSpan("server")
+		req := httptest.NewRequest("GET", "/", nil)
+		req = req.WithContext(opentracing.ContextWithSpan(req.Context(), span))
+
+		w := httptest.NewRecorder()
+		HTTPMiddleware(tracer, handler(http.StatusOK), HTTPMiddlewareOptions{
+			TraceIDHeader: "Trace",
+		}).ServeHTTP(w, req)
+
+		Expect(w.Header().Get("Trace")).To(Equal(strconv.FormatUint(span.Context().(SpanContext).TraceID, 16)))
+		Expect(active).To(BeIdenticalTo(span))
+		Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(0))
+	})
+
+	It("lets handlers hijack the connection", func() {
+		server := httptest.NewServer(HTTPMiddleware(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+			rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+			rw.Flush()
+		}), HTTPMiddlewareOptions{}))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("hijacked"))
+		Eventually(fakeRecorder.RecordSpanCallCount).Should(Equal(1))
+	})
+
+	It("doesn't set the header by default", func() {
+		w := httptest.NewRecorder()
+		HTTPMiddleware(tracer, handler(http.StatusOK), HTTPMiddlewareOptions{}).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		Expect(w.Header()).NotTo(HaveKey(DefaultTraceIDHeader))
+	})
+})
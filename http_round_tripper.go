@@ -0,0 +1,24 @@
+//go:build !js
+// +build !js
+
+package lightstep
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// newHTTPRoundTripper returns the RoundTripper the HTTP transport reports
+// with, using tlsConfig if it is set, and the proxies chosen by dialer.
+func newHTTPRoundTripper(scheme string, tlsConfig *tls.Config, dialer proxyDialer) http.RoundTripper {
+	// The golang http2 client implementation doesn't support plaintext http2 (a.k.a h2c) out of the box.
+	// According to https://github.com/golang/go/issues/14141, they don't have plans to.
+	// For now, we are falling back to http1 for plaintext.
+	// In the future, we might want to add out own h2c implementation (see https://github.com/hkwi/h2c).
+	if scheme == "https" {
+		return &http2.Transport{TLSClientConfig: tlsConfig, DialTLS: dialer.dialTLS}
+	}
+	return &http.Transport{TLSClientConfig: tlsConfig, Proxy: dialer.proxy}
+}
@@ -0,0 +1,17 @@
+//go:build js
+// +build js
+
+package lightstep
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// newHTTPRoundTripper returns the RoundTripper the HTTP transport reports
+// with. In a browser, http.Transport sends requests with the Fetch API,
+// which negotiates HTTP/2, TLS and proxies itself, so tlsConfig and the
+// proxyDialer are ignored.
+func newHTTPRoundTripper(string, *tls.Config, proxyDialer) http.RoundTripper {
+	return &http.Transport{}
+}
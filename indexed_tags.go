@@ -0,0 +1,87 @@
+package lightstep
+
+import (
+	"sort"
+	"strings"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// IndexedTagsKey lists the keys of a span's tags the backend should index
+// for search first, separated by commas. It is only reported to collectors
+// that advertise CapabilityIndexedTags; see Options.IndexedTags, IndexedTag
+// and SetIndexedTag.
+const IndexedTagsKey = "lightstep.indexed_tags"
+
+// IndexedTag is an opentracing.StartSpanOption that sets a tag, like
+// opentracing.Tag, and marks it as one the backend should index for search
+// first, such as a customer or order ID.
+type IndexedTag struct {
+	Key   string
+	Value interface{}
+}
+
+// Apply satisfies the StartSpanOption interface.
+func (t IndexedTag) Apply(sso *opentracing.StartSpanOptions) {
+	opentracing.Tag{Key: t.Key, Value: t.Value}.Apply(sso)
+}
+func (t IndexedTag) applyLS(sso *startSpanOptions) {
+	t.Apply(&sso.Options)
+	sso.IndexedTags = append(sso.IndexedTags, t.Key)
+}
+
+// SetIndexedTag sets a tag of span, and marks it as one the backend should
+// index for search first. The tags of spans not created by a LightStep
+// Tracer are set without the mark.
+func SetIndexedTag(span opentracing.Span, key string, value interface{}) {
+	if indexedSpan, ok := span.(interface {
+		setIndexedTag(string, interface{})
+	}); ok {
+		indexedSpan.setIndexedTag(key, value)
+		return
+	}
+	span.SetTag(key, value)
+}
+
+func (s *spanImpl) setIndexedTag(key string, value interface{}) {
+	s.SetTag(key, value)
+	s.Lock()
+	defer s.Unlock()
+	s.raw.indexedTags = append(s.raw.indexedTags, key)
+}
+
+// indexedTagKeys returns the sorted keys of the tags of span that are
+// matched by patterns or marked by IndexedTag or SetIndexedTag.
+func indexedTagKeys(span *RawSpan, patterns []string) []string {
+	if len(patterns) == 0 && len(span.indexedTags) == 0 {
+		return nil
+	}
+	indexed := map[string]bool{}
+	for _, key := range span.indexedTags {
+		if _, ok := span.Tags[key]; ok {
+			indexed[key] = true
+		}
+	}
+	for key := range span.Tags {
+		if matchesAnyPattern(key, patterns) {
+			indexed[key] = true
+		}
+	}
+	keys := make([]string, 0, len(indexed))
+	for key := range indexed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// indexedTagsField returns the IndexedTagsKey tag of span, or nil if it has
+// no indexed tags.
+func (converter *protoConverter) indexedTagsField(span *RawSpan) *cpb.KeyValue {
+	keys := indexedTagKeys(span, converter.indexedTags)
+	if len(keys) == 0 {
+		return nil
+	}
+	return converter.toField(IndexedTagsKey, strings.Join(keys, ","))
+}
@@ -0,0 +1,126 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("IndexedTags", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var opts Options
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{
+			Infos: []string{"capabilities: indexed_tags"},
+		}, nil)
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			IndexedTags:        []string{"customer.*"},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	// reportedIndexedTags reports a span started by start, and returns its
+	// IndexedTagsKey tag, if any.
+	reportedIndexedTags := func(start func() opentracing.Span) (string, bool) {
+		start().Finish()
+		tracer.Flush(context.Background())
+		_, req, _ := fakeClient.ReportArgsForCall(fakeClient.ReportCallCount() - 1)
+		Expect(req.GetSpans()).To(HaveLen(1))
+		for _, tag := range req.GetSpans()[0].GetTags() {
+			if tag.GetKey() == IndexedTagsKey {
+				return tag.GetStringValue(), true
+			}
+		}
+		return "", false
+	}
+
+	withCapabilities := func() {
+		tracer.StartSpan("first").Finish()
+		tracer.Flush(context.Background())
+	}
+
+	It("only lists indexed tags once the collector supports them", func() {
+		_, ok := reportedIndexedTags(func() opentracing.Span {
+			return tracer.StartSpan("span", opentracing.Tag{Key: "customer.id", Value: "c-1"})
+		})
+		Expect(ok).To(BeFalse())
+
+		indexed, ok := reportedIndexedTags(func() opentracing.Span {
+			return tracer.StartSpan("span", opentracing.Tag{Key: "customer.id", Value: "c-1"})
+		})
+		Expect(ok).To(BeTrue())
+		Expect(indexed).To(Equal("customer.id"))
+	})
+
+	It("lists the tags matched by Options.IndexedTags", func() {
+		withCapabilities()
+		indexed, _ := reportedIndexedTags(func() opentracing.Span {
+			return tracer.StartSpan("span", opentracing.Tags{
+				"customer.tier": "gold",
+				"customer.id":   "c-1",
+				"http.method":   "GET",
+			})
+		})
+		Expect(indexed).To(Equal("customer.id,customer.tier"))
+	})
+
+	It("lists the tags marked by IndexedTag and SetIndexedTag", func() {
+		withCapabilities()
+		indexed, _ := reportedIndexedTags(func() opentracing.Span {
+			span := tracer.StartSpan("span", IndexedTag{Key: "order.id", Value: 42})
+			SetIndexedTag(span, "region", "us-east-1")
+			span.SetTag("http.method", "GET")
+			return span
+		})
+		Expect(indexed).To(Equal("order.id,region"))
+	})
+
+	It("lists the tags marked by SetIndexedTag on deadline spans", func() {
+		withCapabilities()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		indexed, _ := reportedIndexedTags(func() opentracing.Span {
+			span, _ := StartSpanFromContextWithDeadline(ctx, tracer, "span")
+			SetIndexedTag(span, "region", "us-east-1")
+			return span
+		})
+		Expect(indexed).To(Equal("region"))
+	})
+
+	It("omits the tag from spans without indexed tags", func() {
+		withCapabilities()
+		_, ok := reportedIndexedTags(func() opentracing.Span {
+			return tracer.StartSpan("span", opentracing.Tag{Key: "http.method", Value: "GET"})
+		})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("sets indexed tags on spans of other tracers", func() {
+		span := opentracing.NoopTracer{}.StartSpan("span")
+		Expect(func() { SetIndexedTag(span, "order.id", 42) }).NotTo(Panic())
+	})
+})
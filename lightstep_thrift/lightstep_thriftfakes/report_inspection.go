@@ -0,0 +1,62 @@
+package lightstep_thriftfakes
+
+import (
+	"github.com/lightstep/lightstep-tracer-go/lightstep_thrift"
+)
+
+// Reports returns the ReportRequests passed to Report, in call order.
+func (fake *FakeReportingService) Reports() []*lightstep_thrift.ReportRequest {
+	reports := make([]*lightstep_thrift.ReportRequest, 0, fake.ReportCallCount())
+	for i := 0; i < fake.ReportCallCount(); i++ {
+		_, req := fake.ReportArgsForCall(i)
+		reports = append(reports, req)
+	}
+	return reports
+}
+
+// Spans returns the span records of every captured ReportRequest, in report
+// order.
+func (fake *FakeReportingService) Spans() []*lightstep_thrift.SpanRecord {
+	var spans []*lightstep_thrift.SpanRecord
+	for _, req := range fake.Reports() {
+		spans = append(spans, req.GetSpanRecords()...)
+	}
+	return spans
+}
+
+// SpansByOperation returns the captured span records whose span name is
+// operation.
+func (fake *FakeReportingService) SpansByOperation(operation string) []*lightstep_thrift.SpanRecord {
+	var spans []*lightstep_thrift.SpanRecord
+	for _, span := range fake.Spans() {
+		if span.GetSpanName() == operation {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// ReporterAttributes returns the runtime attributes of the most recent
+// ReportRequest as a map, or nil if Report has not been called.
+func (fake *FakeReportingService) ReporterAttributes() map[string]string {
+	count := fake.ReportCallCount()
+	if count == 0 {
+		return nil
+	}
+	_, req := fake.ReportArgsForCall(count - 1)
+	return Tags(req.GetRuntime().GetAttrs())
+}
+
+// SpanTags returns the attributes of span as a map.
+func SpanTags(span *lightstep_thrift.SpanRecord) map[string]string {
+	return Tags(span.GetAttributes())
+}
+
+// Tags converts key-values to a map from key to value.
+func Tags(kvs []*lightstep_thrift.KeyValue) map[string]string {
+	tags := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		tags[kv.GetKey()] = kv.GetValue()
+	}
+	return tags
+}
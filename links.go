@@ -0,0 +1,76 @@
+package lightstep
+
+import (
+	"sort"
+	"strings"
+
+	ot "github.com/opentracing/opentracing-go"
+)
+
+// LinkKeyPrefix starts the tag keys used to record a Link. A link of kind
+// "runbook" is recorded under "lightstep.link.runbook.url" and
+// "lightstep.link.runbook.title".
+const LinkKeyPrefix = "lightstep.link."
+
+const (
+	linkURLSuffix   = ".url"
+	linkTitleSuffix = ".title"
+)
+
+// Link connects a span to a page in another system, such as a runbook, a
+// log search, or a ticket. Like opentracing.Tag, a Link can be passed to
+// StartSpan or set on a started span:
+//
+//	span := tracer.StartSpan("checkout", lightstep.Link{
+//	    Kind: "runbook",
+//	    URL:  "https://wiki.example.com/runbooks/checkout",
+//	})
+//	lightstep.Link{Kind: "ticket", URL: ticketURL, Title: "OPS-123"}.Set(span)
+//
+// A span has at most one link of each kind.
+type Link struct {
+	Kind  string
+	URL   string
+	Title string
+}
+
+// Apply satisfies the StartSpanOption interface.
+func (l Link) Apply(o *ot.StartSpanOptions) {
+	if o.Tags == nil {
+		o.Tags = make(map[string]interface{})
+	}
+	for key, value := range l.tags() {
+		o.Tags[key] = value
+	}
+}
+
+// Set records the link on span.
+func (l Link) Set(span ot.Span) {
+	for key, value := range l.tags() {
+		span.SetTag(key, value)
+	}
+}
+
+func (l Link) tags() map[string]string {
+	tags := map[string]string{LinkKeyPrefix + l.Kind + linkURLSuffix: l.URL}
+	if l.Title != "" {
+		tags[LinkKeyPrefix+l.Kind+linkTitleSuffix] = l.Title
+	}
+	return tags
+}
+
+// LinksFromTags returns the links recorded in a span's tags, sorted by kind.
+func LinksFromTags(tags ot.Tags) []Link {
+	var links []Link
+	for key, value := range tags {
+		if !strings.HasPrefix(key, LinkKeyPrefix) || !strings.HasSuffix(key, linkURLSuffix) {
+			continue
+		}
+		kind := strings.TrimSuffix(strings.TrimPrefix(key, LinkKeyPrefix), linkURLSuffix)
+		url, _ := value.(string)
+		title, _ := tags[LinkKeyPrefix+kind+linkTitleSuffix].(string)
+		links = append(links, Link{Kind: kind, URL: url, Title: title})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Kind < links[j].Kind })
+	return links
+}
@@ -0,0 +1,44 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Link", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("records links passed to StartSpan or set on the span", func() {
+		runbook := Link{Kind: "runbook", URL: "https://wiki.example.com/runbooks/checkout"}
+		ticket := Link{Kind: "ticket", URL: "https://tickets.example.com/OPS-123", Title: "OPS-123"}
+
+		span := tracer.StartSpan("checkout", runbook)
+		ticket.Set(span)
+		span.Finish()
+
+		raw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(raw.Tags).To(HaveKeyWithValue("lightstep.link.runbook.url", runbook.URL))
+		Expect(raw.Tags).To(HaveKeyWithValue("lightstep.link.ticket.title", "OPS-123"))
+		Expect(LinksFromTags(raw.Tags)).To(Equal([]Link{runbook, ticket}))
+	})
+})
@@ -0,0 +1,76 @@
+package lightstep
+
+import (
+	"net"
+	"sort"
+	"sync/atomic"
+)
+
+// Load balancing policies for Options.LoadBalancing.
+const (
+	LoadBalancingPickFirst  = "pick_first"
+	LoadBalancingRoundRobin = "round_robin"
+)
+
+func validLoadBalancing(policy string) bool {
+	switch policy {
+	case "", LoadBalancingPickFirst, LoadBalancingRoundRobin:
+		return true
+	}
+	return false
+}
+
+// addressBalancer resolves the collector's host name for every connection,
+// and chooses which of its addresses to connect to, see
+// Options.LoadBalancing.
+type addressBalancer struct {
+	roundRobin bool
+	lookup     func(host string) ([]string, error)
+	// next counts the connections opened with round_robin.
+	next uint32
+}
+
+// newAddressBalancer returns the balancer for policy, or nil if connections
+// are left to the standard dialer.
+func newAddressBalancer(policy string) *addressBalancer {
+	switch policy {
+	case LoadBalancingPickFirst:
+		return &addressBalancer{lookup: net.LookupHost}
+	case LoadBalancingRoundRobin:
+		return &addressBalancer{roundRobin: true, lookup: net.LookupHost}
+	}
+	return nil
+}
+
+// dial connects to the first of the addresses of addr's host that accepts
+// a connection. With pick_first the addresses are tried in the order they
+// were resolved in. With round_robin they are sorted, and each connection
+// starts from the address after the one the previous connection started
+// from.
+func (b *addressBalancer) dial(dialer net.Dialer, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := b.lookup(host)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	if b.roundRobin {
+		sort.Strings(addrs)
+		start = int((atomic.AddUint32(&b.next, 1) - 1) % uint32(len(addrs)))
+	}
+
+	var firstErr error
+	for i := range addrs {
+		conn, err := dialer.Dial("tcp", net.JoinHostPort(addrs[(start+i)%len(addrs)], port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
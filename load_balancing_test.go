@@ -0,0 +1,75 @@
+package lightstep
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("addressBalancer", func() {
+	var listener net.Listener
+	var port string
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", ":0")
+		Expect(err).NotTo(HaveOccurred())
+		_, port, err = net.SplitHostPort(listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		// The listener of each spec is accepted from by its own goroutine.
+		go func(listener net.Listener) {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conn.Close()
+			}
+		}(listener)
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	// dialHosts dials the satellites host n times, returning the addresses
+	// connected to.
+	dialHosts := func(policy string, n int) []string {
+		// Only 127.0.0.1 is routed to loopback by default on macOS.
+		conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.2", port))
+		if err != nil {
+			Skip("127.0.0.2 can't be dialed: " + err.Error())
+		}
+		conn.Close()
+
+		balancer := newAddressBalancer(policy)
+		balancer.lookup = func(host string) ([]string, error) {
+			Expect(host).To(Equal("satellites"))
+			return []string{"127.0.0.2", "127.0.0.1"}, nil
+		}
+		var hosts []string
+		for i := 0; i < n; i++ {
+			conn, err := balancer.dial(net.Dialer{}, net.JoinHostPort("satellites", port))
+			Expect(err).NotTo(HaveOccurred())
+			host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+			hosts = append(hosts, host)
+			conn.Close()
+		}
+		return hosts
+	}
+
+	It("rotates connections over the addresses with round_robin", func() {
+		Expect(dialHosts(LoadBalancingRoundRobin, 3)).To(Equal([]string{"127.0.0.1", "127.0.0.2", "127.0.0.1"}))
+	})
+
+	It("connects to the first address with pick_first", func() {
+		Expect(dialHosts(LoadBalancingPickFirst, 2)).To(Equal([]string{"127.0.0.2", "127.0.0.2"}))
+	})
+
+	It("leaves connections to the system dialer by default", func() {
+		Expect(newAddressBalancer("")).To(BeNil())
+		opts := Options{AccessToken: "token", LoadBalancing: "random"}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
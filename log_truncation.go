@@ -0,0 +1,117 @@
+package lightstep
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// LogTruncatedLengthSuffix is appended to the key of a truncated log value
+// to name the field reporting its untruncated length in bytes, see
+// LogTruncationOptions.ReportLength.
+const LogTruncatedLengthSuffix = ".truncated_length"
+
+const zeroWidthJoiner = '\u200d'
+
+// LogTruncationOptions configures how log keys and values longer than
+// Options.MaxLogKeyLen and Options.MaxLogValueLen are truncated. Truncation
+// never cuts a UTF-8 encoded character, so truncated values remain valid
+// strings, including for collectors and UIs that decode them as UTF-16.
+type LogTruncationOptions struct {
+	// Graphemes, if true, also avoids cutting grapheme clusters, such as a
+	// letter and its combining marks, an emoji and its modifiers, emoji
+	// joined by zero-width joiners, and flags.
+	Graphemes bool `yaml:"graphemes"`
+	// Marker is appended to truncated keys and values. Defaults to "…".
+	Marker string `yaml:"marker"`
+	// ReportLength, if true, adds a field to the log of each truncated
+	// value with its untruncated length in bytes, keyed by the value's key
+	// followed by LogTruncatedLengthSuffix.
+	ReportLength bool `yaml:"report_length"`
+}
+
+type logTruncator struct {
+	graphemes bool
+	marker    string
+}
+
+func newLogTruncator(opts LogTruncationOptions) logTruncator {
+	marker := opts.Marker
+	if marker == "" {
+		marker = ellipsis
+	}
+	return logTruncator{graphemes: opts.Graphemes, marker: marker}
+}
+
+// truncate returns s if it is at most max bytes long, and otherwise the
+// longest prefix of s shorter than max bytes that ends on a character
+// boundary, followed by the marker.
+func (t logTruncator) truncate(s string, max int) (string, bool) {
+	if len(s) <= max {
+		return s, false
+	}
+	cut := max - 1
+	if cut < 0 {
+		cut = 0
+	}
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if t.graphemes {
+		cut = graphemeBoundary(s, cut)
+	}
+	return s[:cut] + t.marker, true
+}
+
+// graphemeBoundary moves cut back until it doesn't split a grapheme
+// cluster. It approximates the Unicode segmentation rules for the common
+// cases, without the tables needed to implement them fully.
+func graphemeBoundary(s string, cut int) int {
+	for cut > 0 {
+		next, _ := utf8.DecodeRuneInString(s[cut:])
+		prev, size := utf8.DecodeLastRuneInString(s[:cut])
+		if !extendsGrapheme(next) && prev != zeroWidthJoiner && !splitsFlag(s, cut, prev, next) {
+			break
+		}
+		cut -= size
+	}
+	return cut
+}
+
+// extendsGrapheme reports whether r belongs to the grapheme cluster of the
+// character before it.
+func extendsGrapheme(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner:
+		return true
+	case r >= 0xfe00 && r <= 0xfe0f: // variation selectors
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji skin tone modifiers
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // emoji tag sequences
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+// splitsFlag reports whether cut falls within a flag, a pair of regional
+// indicators.
+func splitsFlag(s string, cut int, prev, next rune) bool {
+	if !isRegionalIndicator(prev) || !isRegionalIndicator(next) {
+		return false
+	}
+	// Regional indicators pair up from the start of a run of them.
+	preceding := 0
+	for cut > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:cut])
+		if !isRegionalIndicator(r) {
+			break
+		}
+		preceding++
+		cut -= size
+	}
+	return preceding%2 == 1
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
@@ -0,0 +1,91 @@
+package lightstep
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ot "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+var _ = Describe("logTruncator", func() {
+	var truncator logTruncator
+
+	BeforeEach(func() {
+		truncator = newLogTruncator(LogTruncationOptions{})
+	})
+
+	It("leaves short values alone", func() {
+		value, truncated := truncator.truncate("short", 5)
+		Expect(value).To(Equal("short"))
+		Expect(truncated).To(BeFalse())
+	})
+
+	It("doesn't cut multi-byte characters", func() {
+		value, truncated := truncator.truncate(strings.Repeat("é", 10), 6)
+		Expect(truncated).To(BeTrue())
+		Expect(value).To(Equal("éé…"))
+		Expect(utf8.ValidString(value)).To(BeTrue())
+
+		value, _ = truncator.truncate("a😀😀", 5)
+		Expect(value).To(Equal("a…"))
+	})
+
+	It("uses the configured marker", func() {
+		truncator = newLogTruncator(LogTruncationOptions{Marker: "[cut]"})
+		value, _ := truncator.truncate("abcdefgh", 4)
+		Expect(value).To(Equal("abc[cut]"))
+	})
+
+	Context("when grapheme-aware", func() {
+		BeforeEach(func() {
+			truncator = newLogTruncator(LogTruncationOptions{Graphemes: true})
+		})
+
+		It("keeps combining marks with their letter", func() {
+			value, _ := truncator.truncate("abe\u0301cdef", 4)
+			Expect(value).To(Equal("ab…"))
+		})
+
+		It("doesn't split emoji sequences", func() {
+			family := "\U0001f469\u200d\U0001f469\u200d\U0001f467"
+			value, _ := truncator.truncate("a"+family+"b", 1+len(family))
+			Expect(value).To(Equal("a…"))
+
+			thumb := "\U0001f44d\U0001f3fd"
+			value, _ = truncator.truncate(thumb+thumb, len(thumb)+5)
+			Expect(value).To(Equal(thumb + "…"))
+		})
+
+		It("doesn't split flags", func() {
+			flags := "🇫🇷🇩🇪🇮🇹"
+			value, _ := truncator.truncate(flags, 13)
+			Expect(value).To(Equal("🇫🇷…"))
+			value, _ = truncator.truncate(flags, 17)
+			Expect(value).To(Equal("🇫🇷🇩🇪…"))
+		})
+	})
+})
+
+var _ = Describe("protoConverter log truncation", func() {
+	It("reports the untruncated length of values", func() {
+		converter := newProtoConverter(Options{
+			MaxLogKeyLen:   10,
+			MaxLogValueLen: 10,
+			LogTruncation:  LogTruncationOptions{ReportLength: true},
+		})
+		protoLog := converter.toLog(ot.LogRecord{Fields: []log.Field{
+			log.String("long", strings.Repeat("x", 20)),
+			log.String("short", "x"),
+		}}, SpanContext{}, &reportBuffer{})
+
+		Expect(protoLog.Fields).To(Equal([]*cpb.KeyValue{
+			{Key: "long", Value: &cpb.KeyValue_StringValue{strings.Repeat("x", 9) + ellipsis}},
+			{Key: "long" + LogTruncatedLengthSuffix, Value: &cpb.KeyValue_IntValue{20}},
+			{Key: "short", Value: &cpb.KeyValue_StringValue{"x"}},
+		}))
+	})
+})
@@ -0,0 +1,43 @@
+package lightstep
+
+import (
+	"time"
+)
+
+// ManualReportsOptions configures a reporting policy for batch jobs, which
+// don't want network traffic during their compute phases: spans are only
+// reported by Flush, such as between phases, and when the tracer is closed
+// at the end of the job, or once they have waited Deadline.
+type ManualReportsOptions struct {
+	// Enabled stops the periodic reports, and those started by a buffer
+	// half full or by spans started with MustDeliver. Spans are buffered
+	// up to Options.MaxBufferedSpans, a hard cap beyond which they are
+	// dropped until the next report.
+	Enabled bool `yaml:"enabled"`
+
+	// Deadline, if positive, reports the buffered spans once the first
+	// of them has waited this long in the buffer, so that a job that
+	// doesn't flush still reports in time. It is checked every
+	// Options.MinReportingPeriod, and requires Enabled.
+	Deadline time.Duration `yaml:"deadline"`
+}
+
+// manualReportDueLocked reports whether the Deadline of
+// Options.ManualReports has passed for the buffered spans. The spans of a
+// failed report stay past it, so they are retried no sooner than a
+// periodic report would be, or than the report backoff allows.
+func (tracer *tracerImpl) manualReportDueLocked(now time.Time) bool {
+	deadline := tracer.opts.ManualReports.Deadline
+	if deadline <= 0 || len(tracer.buffer.rawSpans) == 0 ||
+		now.Sub(tracer.buffer.bufferedSince) < deadline {
+		return false
+	}
+	if tracer.lastReportFailed {
+		period := tracer.reportingPeriod
+		if tracer.reportBackoff.delay > period {
+			period = tracer.reportBackoff.delay
+		}
+		return now.Add(tracer.opts.MinReportingPeriod).Sub(tracer.lastReportAttempt) > period
+	}
+	return true
+}
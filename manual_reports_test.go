@@ -0,0 +1,116 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ManualReports", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var opts Options
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    10 * time.Millisecond,
+			MinReportingPeriod: 10 * time.Millisecond,
+			MaxBufferedSpans:   10,
+			ManualReports:      ManualReportsOptions{Enabled: true},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	reportedSpans := func() int {
+		count := 0
+		for i := 0; i < fakeClient.ReportCallCount(); i++ {
+			_, req, _ := fakeClient.ReportArgsForCall(i)
+			count += len(req.GetSpans())
+		}
+		return count
+	}
+
+	It("only reports spans on Flush, up to the buffer cap", func() {
+		for i := 0; i < 15; i++ {
+			tracer.StartSpan("span").Finish()
+		}
+		tracer.StartSpan("priority", MustDeliver{}).Finish()
+		Consistently(fakeClient.ReportCallCount, 100*time.Millisecond).Should(Equal(0))
+
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		Expect(reportedSpans()).To(Equal(11))
+	})
+
+	Context("with a Deadline", func() {
+		BeforeEach(func() {
+			opts.ManualReports.Deadline = 50 * time.Millisecond
+		})
+
+		It("reports spans that waited past it", func() {
+			Consistently(fakeClient.ReportCallCount, 100*time.Millisecond).Should(Equal(0))
+
+			tracer.StartSpan("span").Finish()
+			Eventually(reportedSpans).Should(Equal(1))
+		})
+
+		Context("when reports fail", func() {
+			BeforeEach(func() {
+				fakeClient.ReportReturns(nil, errors.New("unavailable"))
+				opts.Backoff = ConstantBackoff(300 * time.Millisecond)
+			})
+
+			It("retries them after the backoff", func() {
+				tracer.StartSpan("span").Finish()
+				Eventually(fakeClient.ReportCallCount).Should(Equal(1))
+				Consistently(fakeClient.ReportCallCount, 200*time.Millisecond).Should(Equal(1))
+				Eventually(fakeClient.ReportCallCount).Should(Equal(2))
+			})
+		})
+
+		Context("longer than the tracer has been idle", func() {
+			BeforeEach(func() {
+				opts.ManualReports.Deadline = 200 * time.Millisecond
+			})
+
+			It("measures it from the first buffered span", func() {
+				time.Sleep(300 * time.Millisecond)
+
+				tracer.StartSpan("span").Finish()
+				Consistently(fakeClient.ReportCallCount, 100*time.Millisecond).Should(Equal(0))
+				Eventually(reportedSpans).Should(Equal(1))
+			})
+		})
+	})
+
+	It("requires Enabled for a Deadline", func() {
+		opts.ManualReports = ManualReportsOptions{Deadline: time.Second}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+
+	It("can't be combined with StreamingReports", func() {
+		opts.StreamingReports = true
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,69 @@
+package lightstep
+
+// Approximate heap costs of buffered spans, excluding the strings and
+// values they hold.
+const (
+	spanMemoryOverhead  = 512
+	logMemoryOverhead   = 64
+	valueMemoryOverhead = 16
+	referenceMemory     = 24
+)
+
+// estimateSpanMemory approximates the memory held by a buffered span. It is
+// much cheaper than EstimateSpanBytes, so it can be called for every span.
+func estimateSpanMemory(raw *RawSpan) int {
+	size := spanMemoryOverhead + len(raw.Operation) + len(raw.References)*referenceMemory
+	for key, value := range raw.Tags {
+		size += len(key) + valueMemory(value)
+	}
+	for key, value := range raw.Context.Baggage {
+		size += len(key) + len(value) + 2*valueMemoryOverhead
+	}
+	for _, record := range raw.Logs {
+		size += logMemoryOverhead
+		for _, field := range record.Fields {
+			size += len(field.Key()) + valueMemory(field.Value())
+		}
+	}
+	return size
+}
+
+func valueMemory(value interface{}) int {
+	switch value := value.(type) {
+	case string:
+		return valueMemoryOverhead + len(value)
+	case []byte:
+		return valueMemoryOverhead + len(value)
+	}
+	return valueMemoryOverhead
+}
+
+// memoryLimiter samples traces while the spans held by a tracer use more
+// than Options.MaxMemoryBytes, see Options.MemoryPressureSampleRate.
+type memoryLimiter struct {
+	maxBytes   int
+	sampleRate float64
+	// sampling is set while the tracer is under memory pressure, from when
+	// its spans exceed maxBytes until they fall to half of it.
+	sampling bool
+}
+
+// admit reports whether to buffer span, given the memory of the spans
+// already held, returning an EventMemoryPressure if sampling started or
+// stopped.
+func (l *memoryLimiter) admit(span *RawSpan, heldBytes int) (bool, Event) {
+	var event Event
+	bytes := heldBytes + span.memoryBytes
+	switch {
+	case !l.sampling && bytes > l.maxBytes:
+		l.sampling = true
+		event = newEventMemoryPressure(true, bytes, l.maxBytes, l.sampleRate)
+	case l.sampling && bytes <= l.maxBytes/2:
+		l.sampling = false
+		event = newEventMemoryPressure(false, bytes, l.maxBytes, l.sampleRate)
+	}
+	if !l.sampling || span.mustDeliver {
+		return true, event
+	}
+	return traceSampled(span.Context.TraceID, l.sampleRate), event
+}
@@ -0,0 +1,75 @@
+package lightstep
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("memoryLimiter", func() {
+	var limiter *memoryLimiter
+
+	BeforeEach(func() {
+		limiter = &memoryLimiter{maxBytes: 10000, sampleRate: 0.5}
+	})
+
+	span := func(traceID uint64) *RawSpan {
+		return &RawSpan{Context: SpanContext{TraceID: traceID}, memoryBytes: 1000}
+	}
+
+	It("estimates the memory of tags and logs", func() {
+		small := RawSpan{Operation: "op"}
+		large := RawSpan{
+			Operation: "op",
+			Tags:      opentracing.Tags{"payload": strings.Repeat("x", 4096)},
+		}
+		Expect(estimateSpanMemory(&large) - estimateSpanMemory(&small)).To(BeNumerically(">", 4096))
+	})
+
+	It("samples traces while over the limit", func() {
+		keep, event := limiter.admit(span(1), 8000)
+		Expect(keep).To(BeTrue())
+		Expect(event).To(BeNil())
+
+		_, event = limiter.admit(span(1), 9500)
+		Expect(event).To(BeAssignableToTypeOf(&eventMemoryPressure{}))
+		Expect(event.(EventMemoryPressure).Sampling()).To(BeTrue())
+		Expect(event.(EventMemoryPressure).MemoryBytes()).To(Equal(10500))
+
+		kept := 0
+		for traceID := uint64(1); traceID <= 1000; traceID++ {
+			keep, event = limiter.admit(span(traceID*0x9E3779B97F4A7C15), 9500)
+			Expect(event).To(BeNil())
+			if keep {
+				kept++
+			}
+		}
+		Expect(kept).To(BeNumerically("~", 500, 100))
+
+		mustDeliver := span(0x7fffffffffffffff)
+		mustDeliver.mustDeliver = true
+		keep, _ = limiter.admit(mustDeliver, 9500)
+		Expect(keep).To(BeTrue())
+	})
+
+	It("stops sampling at half the limit", func() {
+		limiter.admit(span(1), 9500)
+		_, event := limiter.admit(span(1), 5000)
+		Expect(event).To(BeNil())
+
+		keep, event := limiter.admit(span(0x7fffffffffffffff), 3000)
+		Expect(keep).To(BeTrue())
+		Expect(event.(EventMemoryPressure).Sampling()).To(BeFalse())
+	})
+
+	It("validates the options", func() {
+		opts := Options{AccessToken: "token", MaxMemoryBytes: 1 << 20}
+		Expect(opts.Initialize()).To(Succeed())
+		Expect(opts.MemoryPressureSampleRate).To(Equal(DefaultMemoryPressureSampleRate))
+
+		opts.MemoryPressureSampleRate = 2
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
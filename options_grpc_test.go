@@ -0,0 +1,30 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Options", func() {
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{AccessToken: "token"}
+	})
+
+	Describe("GRPC keepalive", func() {
+		It("rejects negative durations", func() {
+			opts.GRPCKeepaliveTime = time.Minute
+			Expect(opts.Validate()).To(Succeed())
+
+			opts.GRPCKeepaliveTimeout = -time.Second
+			Expect(opts.Validate()).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,136 @@
+package lightstep_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Options", func() {
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{AccessToken: "token"}
+	})
+
+	Describe("automatic tags", func() {
+		It("adds the hostname and command line by default", func() {
+			Expect(opts.Initialize()).To(Succeed())
+			Expect(opts.Tags).To(HaveKey(HostnameKey))
+			Expect(opts.Tags).To(HaveKey(CommandLineKey))
+		})
+
+		It("can be disabled", func() {
+			opts.DisableHostnameTag = true
+			opts.DisableCommandLineTag = true
+			Expect(opts.Initialize()).To(Succeed())
+			Expect(opts.Tags).NotTo(HaveKey(HostnameKey))
+			Expect(opts.Tags).NotTo(HaveKey(CommandLineKey))
+		})
+
+		Context("with CommandLineRedactPatterns", func() {
+			var args []string
+
+			BeforeEach(func() {
+				args = os.Args
+				os.Args = []string{"server", "--token=hunter2", "--port=8080"}
+			})
+
+			AfterEach(func() {
+				os.Args = args
+			})
+
+			It("redacts matching arguments", func() {
+				opts.CommandLineRedactPatterns = []string{`hunter\d`}
+				Expect(opts.Initialize()).To(Succeed())
+				Expect(opts.Tags[CommandLineKey]).To(Equal("server --token=" + RedactedValue + " --port=8080"))
+			})
+
+			It("rejects invalid patterns", func() {
+				opts.CommandLineRedactPatterns = []string{`(`}
+				Expect(opts.Initialize()).NotTo(Succeed())
+			})
+		})
+
+		Context("with HostnameRedactPatterns", func() {
+			It("redacts the matching part of the hostname", func() {
+				hostname, _ := os.Hostname()
+				opts.HostnameRedactPatterns = []string{`^.`}
+				Expect(opts.Initialize()).To(Succeed())
+				Expect(opts.Tags[HostnameKey]).To(Equal(RedactedValue + hostname[1:]))
+			})
+
+			It("rejects invalid patterns", func() {
+				opts.HostnameRedactPatterns = []string{`(`}
+				Expect(opts.Initialize()).NotTo(Succeed())
+			})
+		})
+	})
+
+	Describe("Clone", func() {
+		It("does not share tags or lists with the original", func() {
+			opts.Tags = map[string]interface{}{"service": "checkout"}
+			opts.TagDenyList = []string{"user.email"}
+			opts.IndexedTags = []string{"customer.*"}
+
+			clone := opts.Clone()
+			clone.Tags["service"] = "payments"
+			clone.TagDenyList[0] = "user.id"
+			clone.IndexedTags[0] = "order.*"
+
+			Expect(opts.Tags["service"]).To(Equal("checkout"))
+			Expect(opts.TagDenyList).To(Equal([]string{"user.email"}))
+			Expect(opts.IndexedTags).To(Equal([]string{"customer.*"}))
+		})
+	})
+
+	Describe("Constrained", func() {
+		It("reports over HTTP with small buffers", func() {
+			opts.UseGRPC = true
+			opts.MaxBufferedSpans = 10000
+			opts.StartStackFrames = 8
+			opts.TailSampling = TailSamplingOptions{Window: time.Second}
+
+			constrained := opts.Constrained()
+			Expect(constrained.Initialize()).To(Succeed())
+			Expect(constrained.Validate()).To(Succeed())
+			Expect(constrained.UseHttp).To(BeTrue())
+			Expect(constrained.UseGRPC).To(BeFalse())
+			Expect(constrained.MaxBufferedSpans).To(Equal(ConstrainedMaxSpans))
+			Expect(constrained.MaxLogValueLen).To(Equal(ConstrainedMaxLogValueLen))
+			Expect(constrained.StartStackFrames).To(BeZero())
+			Expect(constrained.TailSampling).To(BeZero())
+			Expect(constrained.Tags).NotTo(HaveKey(CommandLineKey))
+			Expect(opts.UseGRPC).To(BeTrue())
+		})
+	})
+
+	Describe("Forwarder", func() {
+		It("only listens on loopback tcp addresses", func() {
+			opts.Forwarder = ForwarderOptions{Network: "tcp", Address: "127.0.0.1:8360"}
+			Expect(opts.Validate()).To(Succeed())
+			opts.Forwarder.Address = "localhost:8360"
+			Expect(opts.Validate()).To(Succeed())
+			opts.Forwarder.Address = "0.0.0.0:8360"
+			Expect(opts.Validate()).To(HaveOccurred())
+			opts.Forwarder = ForwarderOptions{Network: "udp", Address: "127.0.0.1:8360"}
+			Expect(opts.Validate()).To(HaveOccurred())
+		})
+	})
+
+	Describe("String", func() {
+		It("masks secrets", func() {
+			opts.AccessToken = "hunter2"
+			opts.Tags = map[string]interface{}{"service": "checkout", "db.password": "hunter3"}
+
+			s := opts.String()
+			Expect(s).NotTo(ContainSubstring("hunter"))
+			Expect(s).To(ContainSubstring("checkout"))
+			Expect(s).To(ContainSubstring(RedactedValue))
+			Expect(opts.AccessToken).To(Equal("hunter2"))
+		})
+	})
+})
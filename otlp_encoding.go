@@ -0,0 +1,418 @@
+package lightstep
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// OTLPServiceNameKey is the OpenTelemetry resource attribute naming the
+// service, set from the ComponentNameKey tag in OTLP reports.
+const OTLPServiceNameKey = "service.name"
+
+// otlpInstrumentationScope names the instrumentation scope of spans in OTLP
+// reports.
+const otlpInstrumentationScope = "github.com/lightstep/lightstep-tracer-go"
+
+// Field numbers and enum values of the OpenTelemetry protocol messages,
+// from opentelemetry/proto/{collector/trace,trace,common,resource}/v1.
+const (
+	otlpRequestResourceSpans = 1
+
+	otlpResourceSpansResource   = 1
+	otlpResourceSpansScopeSpans = 2
+	otlpResourceAttributes      = 1
+
+	otlpScopeSpansScope = 1
+	otlpScopeSpansSpans = 2
+	otlpScopeName       = 1
+	otlpScopeVersion    = 2
+
+	otlpSpanTraceID      = 1
+	otlpSpanSpanID       = 2
+	otlpSpanParentSpanID = 4
+	otlpSpanName         = 5
+	otlpSpanKind         = 6
+	otlpSpanStartTime    = 7
+	otlpSpanEndTime      = 8
+	otlpSpanAttributes   = 9
+	otlpSpanEvents       = 11
+	otlpSpanLinks        = 13
+	otlpSpanStatus       = 15
+
+	otlpEventTime       = 1
+	otlpEventName       = 2
+	otlpEventAttributes = 3
+
+	otlpLinkTraceID = 1
+	otlpLinkSpanID  = 2
+
+	otlpStatusCode      = 3
+	otlpStatusCodeError = 2
+
+	otlpKeyValueKey   = 1
+	otlpKeyValueValue = 2
+
+	otlpAnyValueString = 1
+	otlpAnyValueBool   = 2
+	otlpAnyValueInt    = 3
+	otlpAnyValueDouble = 4
+
+	otlpResponsePartialSuccess = 1
+	otlpPartialRejectedSpans   = 1
+	otlpPartialErrorMessage    = 2
+)
+
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+var otlpSpanKinds = map[string]uint64{
+	"internal":                        1,
+	string(ext.SpanKindRPCServerEnum): 2,
+	string(ext.SpanKindRPCClientEnum): 3,
+	string(ext.SpanKindProducerEnum):  4,
+	string(ext.SpanKindConsumerEnum):  5,
+}
+
+// otlpExportRequest is a serialized ExportTraceServiceRequest. It
+// implements proto.Marshaler, so it can be sent with the proto codec
+// without the generated OpenTelemetry packages.
+type otlpExportRequest struct {
+	data []byte
+}
+
+func (r *otlpExportRequest) Reset()                   { *r = otlpExportRequest{} }
+func (r *otlpExportRequest) String() string           { return "otlpExportRequest" }
+func (r *otlpExportRequest) ProtoMessage()            {}
+func (r *otlpExportRequest) Marshal() ([]byte, error) { return r.data, nil }
+
+var _ proto.Marshaler = (*otlpExportRequest)(nil)
+
+// otlpExportResponse is a deserialized ExportTraceServiceResponse.
+type otlpExportResponse struct {
+	rejectedSpans int64
+	errorMessage  string
+}
+
+func (r *otlpExportResponse) Reset()         { *r = otlpExportResponse{} }
+func (r *otlpExportResponse) String() string { return fmt.Sprintf("%+v", *r) }
+func (r *otlpExportResponse) ProtoMessage()  {}
+
+// Unmarshal reads the partial success of an ExportTraceServiceResponse,
+// skipping unknown fields.
+func (r *otlpExportResponse) Unmarshal(data []byte) error {
+	*r = otlpExportResponse{}
+	return walkProtoFields(data, func(field int, wire int, value uint64, bytes []byte) error {
+		if field != otlpResponsePartialSuccess || wire != protoWireBytes {
+			return nil
+		}
+		return walkProtoFields(bytes, func(field int, wire int, value uint64, bytes []byte) error {
+			switch {
+			case field == otlpPartialRejectedSpans && wire == protoWireVarint:
+				r.rejectedSpans = int64(value)
+			case field == otlpPartialErrorMessage && wire == protoWireBytes:
+				r.errorMessage = string(bytes)
+			}
+			return nil
+		})
+	})
+}
+
+var _ proto.Unmarshaler = (*otlpExportResponse)(nil)
+
+// GetErrors returns the reason spans were rejected, if any were.
+func (r *otlpExportResponse) GetErrors() []string {
+	if r.rejectedSpans == 0 && r.errorMessage == "" {
+		return nil
+	}
+	return []string{fmt.Sprintf("%d spans rejected: %s", r.rejectedSpans, r.errorMessage)}
+}
+
+// Disable is always false, as OTLP has no way to disable a tracer.
+func (r *otlpExportResponse) Disable() bool {
+	return false
+}
+
+// newOTLPExportRequest translates a report of rawSpans into an
+// ExportTraceServiceRequest. The reporter's tags become resource
+// attributes; baggage and internal metrics have no OTLP equivalent and
+// are left out.
+func newOTLPExportRequest(report *cpb.ReportRequest, rawSpans []RawSpan) *otlpExportRequest {
+	var resource protoEncoder
+	var hasServiceName bool
+	for _, tag := range report.GetReporter().GetTags() {
+		resource.message(otlpResourceAttributes, encodeOTLPKeyValue(tag.Key, tag))
+		hasServiceName = hasServiceName || tag.Key == OTLPServiceNameKey
+	}
+	if !hasServiceName {
+		for _, tag := range report.GetReporter().GetTags() {
+			if tag.Key == ComponentNameKey {
+				resource.message(otlpResourceAttributes, encodeOTLPKeyValue(OTLPServiceNameKey, tag))
+			}
+		}
+	}
+
+	var scope protoEncoder
+	scope.string(otlpScopeName, otlpInstrumentationScope)
+	scope.string(otlpScopeVersion, TracerVersionValue)
+
+	var scopeSpans protoEncoder
+	scopeSpans.message(otlpScopeSpansScope, scope.bytes())
+	for i, span := range report.Spans {
+		var refs []SpanReference
+		if i < len(rawSpans) {
+			refs = rawSpans[i].References
+		}
+		scopeSpans.message(otlpScopeSpansSpans, encodeOTLPSpan(span, refs))
+	}
+
+	var resourceSpans protoEncoder
+	resourceSpans.message(otlpResourceSpansResource, resource.bytes())
+	resourceSpans.message(otlpResourceSpansScopeSpans, scopeSpans.bytes())
+
+	var request protoEncoder
+	request.message(otlpRequestResourceSpans, resourceSpans.bytes())
+	return &otlpExportRequest{data: request.bytes()}
+}
+
+// encodeOTLPSpan encodes span, whose references carry only the lower half
+// of their trace IDs; the upper half of its links' trace IDs is taken from
+// refs, the span's References.
+func encodeOTLPSpan(span *cpb.Span, refs []SpanReference) []byte {
+	var traceIDHigh uint64
+	for _, tag := range span.Tags {
+		if tag.Key == TraceIDHighKey {
+			traceIDHigh, _ = strconv.ParseUint(tag.GetStringValue(), 16, 64)
+		}
+	}
+
+	var e protoEncoder
+	e.bytesField(otlpSpanTraceID, otlpTraceID(traceIDHigh, span.GetSpanContext().GetTraceId()))
+	e.bytesField(otlpSpanSpanID, otlpSpanID(span.GetSpanContext().GetSpanId()))
+	// The parent is the first reference, see protoConverter.toReferences.
+	if len(span.References) > 0 && span.References[0].GetSpanContext().GetSpanId() != 0 {
+		e.bytesField(otlpSpanParentSpanID, otlpSpanID(span.References[0].GetSpanContext().GetSpanId()))
+	}
+	e.string(otlpSpanName, span.OperationName)
+
+	start := otlpTime(span.StartTimestamp.GetSeconds(), span.StartTimestamp.GetNanos())
+	end := start + span.DurationMicros*uint64(time.Microsecond)
+	isError := false
+	for _, tag := range span.Tags {
+		switch tag.Key {
+		case string(ext.SpanKind):
+			if kind, ok := otlpSpanKinds[strings.ToLower(tag.GetStringValue())]; ok {
+				e.varint(otlpSpanKind, kind)
+			}
+		case string(ext.Error):
+			isError = tag.GetBoolValue() || tag.GetStringValue() == "true"
+		}
+	}
+	e.fixed64(otlpSpanStartTime, start)
+	e.fixed64(otlpSpanEndTime, end)
+	for _, tag := range span.Tags {
+		if tag.Key == TraceIDHighKey {
+			continue
+		}
+		e.message(otlpSpanAttributes, encodeOTLPKeyValue(tag.Key, tag))
+	}
+	for _, log := range span.Logs {
+		e.message(otlpSpanEvents, encodeOTLPEvent(log))
+	}
+	// The other references become links.
+	for i := 1; i < len(span.References); i++ {
+		sc := span.References[i].GetSpanContext()
+		high := referencedTraceIDHigh(refs, sc)
+		if high == 0 && sc.GetTraceId() == span.GetSpanContext().GetTraceId() {
+			// Links within the trace share the upper half of its trace ID.
+			high = traceIDHigh
+		}
+		var link protoEncoder
+		link.bytesField(otlpLinkTraceID, otlpTraceID(high, sc.GetTraceId()))
+		link.bytesField(otlpLinkSpanID, otlpSpanID(sc.GetSpanId()))
+		e.message(otlpSpanLinks, link.bytes())
+	}
+	if isError {
+		var status protoEncoder
+		status.varint(otlpStatusCode, otlpStatusCodeError)
+		e.message(otlpSpanStatus, status.bytes())
+	}
+	return e.bytes()
+}
+
+// referencedTraceIDHigh returns the upper half of the trace ID of the
+// reference in refs to sc, or 0 if there is none.
+func referencedTraceIDHigh(refs []SpanReference, sc *cpb.SpanContext) uint64 {
+	for _, ref := range refs {
+		if ref.TraceID == sc.GetTraceId() && ref.SpanID == sc.GetSpanId() {
+			return ref.TraceIDHigh
+		}
+	}
+	return 0
+}
+
+// encodeOTLPEvent encodes a log as a span event, named by its "event"
+// field.
+func encodeOTLPEvent(log *cpb.Log) []byte {
+	var e protoEncoder
+	e.fixed64(otlpEventTime, otlpTime(log.Timestamp.GetSeconds(), log.Timestamp.GetNanos()))
+	name := "log"
+	for _, field := range log.Fields {
+		if field.Key == "event" && field.GetStringValue() != "" {
+			name = field.GetStringValue()
+			continue
+		}
+		e.message(otlpEventAttributes, encodeOTLPKeyValue(field.Key, field))
+	}
+	e.string(otlpEventName, name)
+	return e.bytes()
+}
+
+func encodeOTLPKeyValue(key string, kv *cpb.KeyValue) []byte {
+	var value protoEncoder
+	switch v := kv.Value.(type) {
+	case *cpb.KeyValue_StringValue:
+		value.string(otlpAnyValueString, v.StringValue)
+	case *cpb.KeyValue_JsonValue:
+		value.string(otlpAnyValueString, v.JsonValue)
+	case *cpb.KeyValue_BoolValue:
+		var b uint64
+		if v.BoolValue {
+			b = 1
+		}
+		value.varint(otlpAnyValueBool, b)
+	case *cpb.KeyValue_IntValue:
+		value.varint(otlpAnyValueInt, uint64(v.IntValue))
+	case *cpb.KeyValue_DoubleValue:
+		value.fixed64(otlpAnyValueDouble, math.Float64bits(v.DoubleValue))
+	}
+
+	var e protoEncoder
+	e.string(otlpKeyValueKey, key)
+	e.message(otlpKeyValueValue, value.bytes())
+	return e.bytes()
+}
+
+// otlpTraceID joins the halves of a trace ID into OTLP's 16 bytes; the upper
+// half of a 64-bit trace ID is zero.
+func otlpTraceID(high, low uint64) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, high)
+	binary.BigEndian.PutUint64(b[8:], low)
+	return b
+}
+
+func otlpSpanID(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
+
+func otlpTime(seconds int64, nanos int32) uint64 {
+	return uint64(seconds)*uint64(time.Second) + uint64(nanos)
+}
+
+// protoEncoder appends fields in the protocol buffer wire format.
+type protoEncoder struct {
+	buf []byte
+}
+
+func (e *protoEncoder) bytes() []byte {
+	return e.buf
+}
+
+func (e *protoEncoder) key(field int, wire int) {
+	e.buf = appendVarint(e.buf, uint64(field)<<3|uint64(wire))
+}
+
+func (e *protoEncoder) varint(field int, v uint64) {
+	e.key(field, protoWireVarint)
+	e.buf = appendVarint(e.buf, v)
+}
+
+func (e *protoEncoder) fixed64(field int, v uint64) {
+	e.key(field, protoWireFixed64)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *protoEncoder) bytesField(field int, b []byte) {
+	e.key(field, protoWireBytes)
+	e.buf = appendVarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *protoEncoder) string(field int, s string) {
+	e.key(field, protoWireBytes)
+	e.buf = appendVarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *protoEncoder) message(field int, b []byte) {
+	e.bytesField(field, b)
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	return append(buf, b[:n]...)
+}
+
+// walkProtoFields calls fn with each field of a serialized message: the
+// value of varint and fixed fields, or the contents of length-delimited
+// ones.
+func walkProtoFields(data []byte, fn func(field int, wire int, value uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("proto: bad field key")
+		}
+		data = data[n:]
+		field, wire := int(key>>3), int(key&7)
+
+		var value uint64
+		var bytes []byte
+		switch wire {
+		case protoWireVarint:
+			value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("proto: bad varint")
+			}
+			data = data[n:]
+		case protoWireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("proto: truncated fixed64")
+			}
+			value, data = binary.LittleEndian.Uint64(data), data[8:]
+		case protoWireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("proto: truncated fixed32")
+			}
+			value, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case protoWireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return fmt.Errorf("proto: truncated bytes")
+			}
+			bytes, data = data[n:n+int(length)], data[n+int(length):]
+		default:
+			return fmt.Errorf("proto: unsupported wire type %d", wire)
+		}
+		if err := fn(field, wire, value, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
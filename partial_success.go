@@ -0,0 +1,64 @@
+package lightstep
+
+// MaxPartialSuccessErrors is the number of error messages kept by an
+// EventReportPartialSuccess.
+const MaxPartialSuccessErrors = 10
+
+// rejectionError returns the error the FinishHandles of the spans of a
+// report are resolved with when the collector rejected rejected of its sent
+// spans: nil if it rejected none, ErrSpanRejected if it rejected them all,
+// and otherwise ErrSpanPartiallyRejected, as it doesn't say which spans it
+// rejected.
+func rejectionError(rejected, sent int) error {
+	switch {
+	case rejected <= 0:
+		return nil
+	case rejected >= sent:
+		return ErrSpanRejected
+	default:
+		return ErrSpanPartiallyRejected
+	}
+}
+
+// rejectingResponse is implemented by collector responses that may count
+// the spans the collector rejected, such as OTLP partial successes.
+type rejectingResponse interface {
+	// rejectedSpanCount returns the number of rejected spans, and false
+	// if the response doesn't count them.
+	rejectedSpanCount() (int64, bool)
+}
+
+// responseRejectedSpans returns the number of spans resp rejected, and
+// false if it doesn't count them.
+func responseRejectedSpans(resp collectorResponse) (int64, bool) {
+	if rejecting, ok := resp.(rejectingResponse); ok {
+		return rejecting.rejectedSpanCount()
+	}
+	return 0, false
+}
+
+func (r *otlpExportResponse) rejectedSpanCount() (int64, bool) {
+	return r.rejectedSpans, true
+}
+
+// rejectedSpanCount only counts the rejected spans if every response
+// counts them.
+func (resps multiResponse) rejectedSpanCount() (int64, bool) {
+	var rejected int64
+	for _, resp := range resps {
+		count, ok := responseRejectedSpans(resp)
+		if !ok {
+			return 0, false
+		}
+		rejected += count
+	}
+	return rejected, true
+}
+
+func (resp chaosResponse) rejectedSpanCount() (int64, bool) {
+	return responseRejectedSpans(resp.collectorResponse)
+}
+
+func (r agentResponse) rejectedSpanCount() (int64, bool) {
+	return r.rejected, true
+}
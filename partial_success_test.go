@@ -0,0 +1,182 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("partial success reports", func() {
+	var tracer *tracerImpl
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			CircuitBreaker:     CircuitBreakerOptions{FailureThreshold: 1},
+		}).(*tracerImpl)
+	})
+
+	AfterEach(func() {
+		Close(context.Background(), tracer)
+	})
+
+	// rejectSpans makes the next report return errs, counting rejected
+	// spans.
+	rejectSpans := func(errs []string, rejected int64) {
+		client := newFakeCollectorClient(tracer.client)
+		client.report = func(ctx context.Context, req reportRequest) (collectorResponse, error) {
+			client.report = client.realClient.Report
+			return agentResponse{errors: errs, rejected: rejected}, nil
+		}
+		tracer.client = client
+	}
+
+	// rejectionEvents returns the EventReportPartialSuccess emitted, and the
+	// EventStatusReport of reports with rejected spans. Other events, which
+	// other specs' tracers may emit on the global handler, are ignored.
+	rejectionEvents := func() []Event {
+		var events []Event
+		for {
+			select {
+			case event := <-eventChan:
+				switch event := event.(type) {
+				case EventReportPartialSuccess:
+					events = append(events, event)
+				case EventStatusReport:
+					if event.RejectedSpans() > 0 {
+						events = append(events, event)
+					}
+				}
+			default:
+				return events
+			}
+		}
+	}
+
+	It("emits the collector's errors without retrying the report", func() {
+		var errs []string
+		for i := 0; i < MaxPartialSuccessErrors+2; i++ {
+			errs = append(errs, fmt.Sprint("span rejected ", i))
+		}
+		rejectSpans(errs, 1)
+
+		rejected := FinishAsync(tracer.StartSpan("rejected"))
+		accepted := FinishAsync(tracer.StartSpan("accepted"))
+		tracer.Flush(context.Background())
+
+		var events []Event
+		Eventually(func() []Event {
+			events = append(events, rejectionEvents()...)
+			return events
+		}).Should(HaveLen(2))
+		Consistently(rejectionEvents).Should(BeEmpty())
+
+		partial, ok := events[0].(EventReportPartialSuccess)
+		Expect(ok).To(BeTrue())
+		Expect(partial.ErrorCount()).To(Equal(MaxPartialSuccessErrors + 2))
+		Expect(partial.Errors()).To(Equal(errs[:MaxPartialSuccessErrors]))
+		Expect(partial.RejectedSpans()).To(Equal(1))
+
+		status, ok := events[1].(EventStatusReport)
+		Expect(ok).To(BeTrue())
+		Expect(status.SentSpans()).To(Equal(2))
+		Expect(status.RejectedSpans()).To(Equal(1))
+		Expect(status.DroppedSpans()).To(BeZero())
+
+		Expect(rejected.Err()).To(Equal(ErrSpanPartiallyRejected))
+		Expect(accepted.Err()).To(Equal(ErrSpanPartiallyRejected))
+
+		tracer.StartSpan("next").Finish()
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		_, req, _ := fakeClient.ReportArgsForCall(0)
+		Expect(req.GetSpans()).To(HaveLen(1))
+		Expect(req.GetSpans()[0].GetOperationName()).To(Equal("next"))
+	})
+
+	It("drops the report if the collector rejected all of its spans", func() {
+		rejectSpans([]string{"spans rejected"}, 1)
+
+		handle := FinishAsync(tracer.StartSpan("rejected"))
+		tracer.Flush(context.Background())
+
+		var partial EventReportPartialSuccess
+		Eventually(eventChan).Should(Receive(&partial))
+		Expect(partial.RejectedSpans()).To(Equal(1))
+		Expect(handle.Err()).To(Equal(ErrSpanRejected))
+
+		tracer.StartSpan("next").Finish()
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		_, req, _ := fakeClient.ReportArgsForCall(0)
+		Expect(req.GetSpans()).To(HaveLen(1))
+		Expect(req.GetSpans()[0].GetOperationName()).To(Equal("next"))
+	})
+
+	It("doesn't open the circuit breaker when the collector rejects every span", func() {
+		rejectSpans([]string{"spans rejected"}, 1)
+
+		tracer.StartSpan("rejected").Finish()
+		tracer.Flush(context.Background())
+		tracer.StartSpan("next").Finish()
+		tracer.Flush(context.Background())
+
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		for len(eventChan) > 0 {
+			_, isBreaker := (<-eventChan).(EventCircuitBreaker)
+			Expect(isBreaker).To(BeFalse())
+		}
+	})
+
+	It("resolves the handles of reports with warnings but no rejected spans", func() {
+		rejectSpans([]string{"0 spans rejected: clock skew"}, 0)
+
+		handle := FinishAsync(tracer.StartSpan("accepted"))
+		tracer.Flush(context.Background())
+
+		var partial EventReportPartialSuccess
+		Eventually(eventChan).Should(Receive(&partial))
+		Expect(partial.RejectedSpans()).To(BeZero())
+		Expect(handle.Err()).To(BeNil())
+	})
+
+	It("retries reports with errors that don't count the rejected spans", func() {
+		fakeClient.ReportReturnsOnCall(0, &cpb.ReportResponse{Errors: []string{"report rejected"}}, nil)
+
+		handle := FinishAsync(tracer.StartSpan("rejected"))
+		tracer.Flush(context.Background())
+
+		var flushErr EventFlushError
+		Eventually(eventChan).Should(Receive(&flushErr))
+		Expect(flushErr.State()).To(Equal(FlushErrorReport))
+		Expect(flushErr.Err()).To(MatchError("report rejected"))
+		Consistently(handle.Done()).ShouldNot(BeClosed())
+
+		tracer.Flush(context.Background())
+		Expect(handle.Err()).To(BeNil())
+		Expect(fakeClient.ReportCallCount()).To(Equal(2))
+		_, req, _ := fakeClient.ReportArgsForCall(1)
+		Expect(req.GetSpans()).To(HaveLen(1))
+		Expect(req.GetSpans()[0].GetOperationName()).To(Equal("rejected"))
+	})
+})
@@ -0,0 +1,93 @@
+package lightstep
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// Log fields recorded by StartPhase and EndPhase. A phase is recorded as a
+// pair of logs: one with the PhaseStartEvent event when it starts, and one
+// with the PhaseEndEvent event and its duration when it ends. Both carry the
+// phase name under PhaseKey.
+const (
+	PhaseStartEvent  = "phase.start"
+	PhaseEndEvent    = "phase.end"
+	PhaseKey         = "phase"
+	PhaseDurationKey = "phase.duration_ms"
+)
+
+type phase struct {
+	name  string
+	start time.Time
+}
+
+// StartPhase marks the start of a named phase of the span's work, such as
+// "parse" or "execute", timing part of a span without the cost of a child
+// span. Phases nest: EndPhase ends the phase started last. Phases still open
+// when the span finishes are ended then. Spans not created by a LightStep
+// Tracer are left unchanged.
+func StartPhase(span opentracing.Span, name string) {
+	if phaseSpan, ok := span.(interface {
+		StartPhase(string)
+	}); ok {
+		phaseSpan.StartPhase(name)
+	}
+}
+
+// EndPhase ends the phase of the span started last by StartPhase.
+func EndPhase(span opentracing.Span) {
+	if phaseSpan, ok := span.(interface {
+		EndPhase()
+	}); ok {
+		phaseSpan.EndPhase()
+	}
+}
+
+// StartPhase marks the start of a named phase of the span's work.
+func (s *spanImpl) StartPhase(name string) {
+	now := time.Now()
+	s.Lock()
+	defer s.Unlock()
+	if s.raw.Duration >= 0 {
+		return
+	}
+	s.phases = append(s.phases, phase{name: name, start: now})
+	if !s.tracer.opts.DropSpanLogs {
+		s.appendLog(opentracing.LogRecord{
+			Timestamp: now,
+			Fields: []log.Field{
+				log.String("event", PhaseStartEvent),
+				log.String(PhaseKey, name),
+			},
+		})
+	}
+}
+
+// EndPhase ends the phase started last.
+func (s *spanImpl) EndPhase() {
+	now := time.Now()
+	s.Lock()
+	defer s.Unlock()
+	if s.raw.Duration >= 0 || len(s.phases) == 0 {
+		return
+	}
+	s.endPhaseLocked(now)
+}
+
+func (s *spanImpl) endPhaseLocked(now time.Time) {
+	p := s.phases[len(s.phases)-1]
+	s.phases = s.phases[:len(s.phases)-1]
+	if s.tracer.opts.DropSpanLogs {
+		return
+	}
+	s.appendLog(opentracing.LogRecord{
+		Timestamp: now,
+		Fields: []log.Field{
+			log.String("event", PhaseEndEvent),
+			log.String(PhaseKey, p.name),
+			log.Float64(PhaseDurationKey, float64(now.Sub(p.start))/float64(time.Millisecond)),
+		},
+	})
+}
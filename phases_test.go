@@ -0,0 +1,88 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("StartPhase and EndPhase", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	// phaseLogs returns the event, phase and duration fields of each log.
+	phaseLogs := func(logs []opentracing.LogRecord) []map[string]interface{} {
+		var records []map[string]interface{}
+		for _, lr := range logs {
+			record := map[string]interface{}{}
+			for _, field := range lr.Fields {
+				record[field.Key()] = field.Value()
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+
+	It("records nested phases as pairs of logs", func() {
+		span := tracer.StartSpan("request")
+		StartPhase(span, "parse")
+		EndPhase(span)
+		StartPhase(span, "execute")
+		StartPhase(span, "query")
+		EndPhase(span)
+		EndPhase(span)
+		span.Finish()
+
+		logs := phaseLogs(fakeRecorder.RecordSpanArgsForCall(0).Logs)
+		Expect(logs).To(HaveLen(6))
+		var events, phases []interface{}
+		for _, record := range logs {
+			events = append(events, record["event"])
+			phases = append(phases, record[PhaseKey])
+		}
+		Expect(events).To(Equal([]interface{}{
+			PhaseStartEvent, PhaseEndEvent,
+			PhaseStartEvent, PhaseStartEvent, PhaseEndEvent, PhaseEndEvent,
+		}))
+		Expect(phases).To(Equal([]interface{}{"parse", "parse", "execute", "query", "query", "execute"}))
+		Expect(logs[1][PhaseDurationKey]).To(BeNumerically(">=", 0))
+	})
+
+	It("ends open phases when the span finishes", func() {
+		span := tracer.StartSpan("request")
+		StartPhase(span, "execute")
+		span.Finish()
+		EndPhase(span)
+
+		logs := phaseLogs(fakeRecorder.RecordSpanArgsForCall(0).Logs)
+		Expect(logs).To(HaveLen(2))
+		Expect(logs[1]["event"]).To(Equal(PhaseEndEvent))
+		Expect(logs[1][PhaseKey]).To(Equal("execute"))
+	})
+
+	It("ignores spans from other tracers", func() {
+		span := opentracing.NoopTracer{}.StartSpan("request")
+		StartPhase(span, "parse")
+		EndPhase(span)
+		span.Finish()
+	})
+})
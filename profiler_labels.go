@@ -0,0 +1,50 @@
+package lightstep
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// Keys of the pprof labels set by DoWithProfilerLabels. Their values are
+// the hex IDs of the span, as formatted by AnalyticsRecorder.
+const (
+	ProfilerLabelTraceID = "trace_id"
+	ProfilerLabelSpanID  = "span_id"
+)
+
+// DoWithProfilerLabels calls f with ctx carrying span. If the span's Tracer
+// was created with Options.ProfilerLabels, the goroutine's pprof labels are
+// set to the span's trace and span IDs while f runs, so that samples in CPU
+// and goroutine profiles can be correlated with the trace. Goroutines
+// started by f inherit the labels. Spans not created by a LightStep Tracer
+// are not labeled.
+func DoWithProfilerLabels(ctx context.Context, span opentracing.Span, f func(context.Context)) {
+	if labeledSpan, ok := span.(interface {
+		DoWithProfilerLabels(context.Context, func(context.Context))
+	}); ok {
+		labeledSpan.DoWithProfilerLabels(ctx, f)
+		return
+	}
+	f(opentracing.ContextWithSpan(ctx, span))
+}
+
+// DoWithProfilerLabels calls f with ctx carrying the span, labeled with its
+// IDs if Options.ProfilerLabels is set.
+func (s *spanImpl) DoWithProfilerLabels(ctx context.Context, f func(context.Context)) {
+	ctx = opentracing.ContextWithSpan(ctx, s)
+	if !s.tracer.opts.ProfilerLabels {
+		f(ctx)
+		return
+	}
+	s.Lock()
+	traceID := formatTraceID(s.raw.Context.TraceIDHigh, s.raw.Context.TraceID)
+	spanID := s.raw.Context.SpanID
+	s.Unlock()
+	pprof.Do(ctx, pprof.Labels(
+		ProfilerLabelTraceID, traceID,
+		ProfilerLabelSpanID, strconv.FormatUint(spanID, 16),
+	), f)
+}
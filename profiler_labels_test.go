@@ -0,0 +1,87 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("DoWithProfilerLabels", func() {
+	var tracer Tracer
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	labels := func(span opentracing.Span) (traceID, spanID string, active opentracing.Span) {
+		DoWithProfilerLabels(context.Background(), span, func(ctx context.Context) {
+			traceID, _ = pprof.Label(ctx, ProfilerLabelTraceID)
+			spanID, _ = pprof.Label(ctx, ProfilerLabelSpanID)
+			active = opentracing.SpanFromContext(ctx)
+		})
+		return traceID, spanID, active
+	}
+
+	Context("with ProfilerLabels", func() {
+		BeforeEach(func() {
+			opts.ProfilerLabels = true
+		})
+
+		It("labels the goroutine with the span's IDs", func() {
+			span := tracer.StartSpan("work")
+			defer span.Finish()
+			sc := span.Context().(SpanContext)
+
+			traceID, spanID, active := labels(span)
+			Expect(traceID).To(Equal(strconv.FormatUint(sc.TraceID, 16)))
+			Expect(spanID).To(Equal(strconv.FormatUint(sc.SpanID, 16)))
+			Expect(active).To(BeIdenticalTo(span))
+		})
+
+		It("labels the goroutine with all 32 digits of 128-bit trace IDs", func() {
+			parent := SpanContext{TraceIDHigh: 0xabc, TraceID: 0x123, SpanID: 1}
+			span := tracer.StartSpan("work", opentracing.ChildOf(parent))
+			defer span.Finish()
+
+			traceID, _, _ := labels(span)
+			Expect(traceID).To(Equal("abc0000000000000123"))
+		})
+	})
+
+	It("doesn't label the goroutine by default", func() {
+		span := tracer.StartSpan("work")
+		defer span.Finish()
+
+		traceID, _, active := labels(span)
+		Expect(traceID).To(BeEmpty())
+		Expect(active).To(BeIdenticalTo(span))
+	})
+
+	It("calls f for other spans", func() {
+		span := opentracing.NoopTracer{}.StartSpan("work")
+
+		traceID, _, active := labels(span)
+		Expect(traceID).To(BeEmpty())
+		Expect(active).To(BeIdenticalTo(span))
+	})
+})
@@ -0,0 +1,226 @@
+package lightstep
+
+import (
+	"fmt"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var validationErrorNilPropagator = fmt.Errorf("Options invalid: CustomPropagators must not be nil")
+
+// Propagator injects span contexts into and extracts them from the carriers
+// of a format, see Options.CustomPropagators. Extract returns a SpanContext,
+// as other span contexts are ignored by StartSpan, and
+// opentracing.ErrSpanContextNotFound if the carrier holds none.
+type Propagator interface {
+	Inject(opentracing.SpanContext, interface{}) error
+	Extract(interface{}) (opentracing.SpanContext, error)
+}
+
+// Formats for Options.Propagators.
+const (
+	// PropagatorLightStep propagates span contexts in the ot-tracer-* and
+	// ot-baggage-* fields shared by the LightStep tracers.
+	PropagatorLightStep = "lightstep"
+	// PropagatorTraceContext propagates span contexts in the traceparent
+	// and tracestate fields of the W3C Trace Context recommendation, used
+	// by OpenTelemetry. Baggage isn't propagated in this format.
+	PropagatorTraceContext = "tracecontext"
+	// PropagatorB3 propagates span contexts in the single b3 field of the
+	// B3 format, used by Zipkin and Envoy. Contexts in the X-B3-* fields
+	// are extracted too.
+	PropagatorB3 = "b3"
+	// PropagatorB3Multi propagates span contexts in the X-B3-* fields of
+	// the B3 format. Contexts in the single b3 field are extracted too.
+	PropagatorB3Multi = "b3multi"
+	// PropagatorJaeger propagates span contexts in the uber-trace-id field
+	// and baggage in the uberctx-* fields of the Jaeger clients.
+	PropagatorJaeger = "jaeger"
+	// PropagatorXRay propagates span contexts in the X-Amzn-Trace-Id field
+	// of AWS X-Ray, used by ALBs and API Gateway.
+	PropagatorXRay = "xray"
+)
+
+func validPropagator(name string) bool {
+	switch name {
+	case PropagatorLightStep, PropagatorTraceContext, PropagatorB3, PropagatorB3Multi, PropagatorJaeger, PropagatorXRay:
+		return true
+	}
+	return false
+}
+
+// newTextPropagator returns the propagator of the formats opts.Propagators
+// and opts.ExtractPropagators name, defaulting to the LightStep format.
+func newTextPropagator(opts Options) Propagator {
+	injectNames := opts.Propagators
+	if len(injectNames) == 0 {
+		injectNames = []string{PropagatorLightStep}
+	}
+	extractNames := opts.ExtractPropagators
+	if len(extractNames) == 0 {
+		extractNames = injectNames
+	}
+	if len(injectNames) == 1 && len(extractNames) == 1 && injectNames[0] == extractNames[0] {
+		return newNamedPropagator(opts, injectNames[0])
+	}
+
+	var composite CompositePropagator
+	for _, name := range injectNames {
+		composite.Injectors = append(composite.Injectors, newNamedPropagator(opts, name))
+	}
+	for _, name := range extractNames {
+		composite.Extractors = append(composite.Extractors, newNamedPropagator(opts, name))
+	}
+	return composite
+}
+
+// newNamedPropagator returns the propagator of the format name, one of the
+// Propagator constants.
+func newNamedPropagator(opts Options, name string) Propagator {
+	var propagator Propagator
+	switch name {
+	case PropagatorTraceContext:
+		return newCarrierFieldsPropagator(opts, traceContextPropagator{})
+	case PropagatorXRay:
+		return newCarrierFieldsPropagator(opts, xrayPropagator{})
+	case PropagatorB3, PropagatorB3Multi:
+		propagator = newCarrierFieldsPropagator(opts, b3Propagator{multi: name == PropagatorB3Multi})
+	case PropagatorJaeger:
+		propagator = newCarrierFieldsPropagator(opts, jaegerPropagator{})
+	default:
+		propagator = newTextMapPropagator(opts)
+	}
+	if opts.Propagate64BitTraceIDs {
+		return traceID64BitPropagator{propagator}
+	}
+	return propagator
+}
+
+// carrierFieldsPropagator keeps the CallerSamplingOptions.Header and
+// Options.PassThroughKeys fields of the carriers of a format that, unlike
+// the LightStep format, doesn't read them itself: Extract adds them to the
+// extracted context, and Inject writes the pass-through fields back.
+type carrierFieldsPropagator struct {
+	Propagator
+	passThroughKeys []string
+	callerHeader    string
+	callerKey       string
+}
+
+// newCarrierFieldsPropagator returns propagator, keeping the carrier fields
+// opts names, if any.
+func newCarrierFieldsPropagator(opts Options, propagator Propagator) Propagator {
+	if opts.CallerSampling.Header == "" && len(opts.PassThroughKeys) == 0 {
+		return propagator
+	}
+	return carrierFieldsPropagator{
+		Propagator:      propagator,
+		passThroughKeys: opts.PassThroughKeys,
+		callerHeader:    strings.ToLower(opts.CallerSampling.Header),
+		callerKey:       strings.ToLower(opts.CallerSampling.BaggageKey),
+	}
+}
+
+func (p carrierFieldsPropagator) Inject(spanContext opentracing.SpanContext, opaqueCarrier interface{}) error {
+	if err := p.Propagator.Inject(spanContext, opaqueCarrier); err != nil {
+		return err
+	}
+	sc, ok := spanContext.(SpanContext)
+	carrier, isWriter := opaqueCarrier.(opentracing.TextMapWriter)
+	if !ok || !isWriter {
+		return nil
+	}
+	for k, v := range sc.passThrough {
+		carrier.Set(k, v)
+	}
+	return nil
+}
+
+func (p carrierFieldsPropagator) Extract(opaqueCarrier interface{}) (opentracing.SpanContext, error) {
+	spanContext, err := p.Propagator.Extract(opaqueCarrier)
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := spanContext.(SpanContext)
+	carrier, isReader := opaqueCarrier.(opentracing.TextMapReader)
+	if !ok || !isReader {
+		return spanContext, nil
+	}
+
+	var caller string
+	var foundCaller bool
+	var passThrough map[string]string
+	_ = carrier.ForeachKey(func(k, v string) error {
+		lowercaseK := strings.ToLower(k)
+		if p.callerHeader != "" && lowercaseK == p.callerHeader {
+			caller, foundCaller = v, true
+		} else if matchesAnyPattern(lowercaseK, p.passThroughKeys) {
+			if passThrough == nil {
+				passThrough = map[string]string{}
+			}
+			passThrough[k] = v
+		}
+		return nil
+	})
+	if foundCaller {
+		sc = sc.WithBaggageItem(p.callerKey, caller)
+	}
+	if passThrough != nil {
+		sc.passThrough = passThrough
+		sc.cache = &carrierCache{}
+	}
+	return sc, nil
+}
+
+// CompositePropagator propagates span contexts in several formats at once,
+// as while services move from one tracer to another: Inject writes every
+// format of Injectors, and Extract reads the first format of Extractors, in
+// priority order, that the carrier holds. A corrupted span context is only
+// reported if no other format is found.
+type CompositePropagator struct {
+	Injectors  []Propagator
+	Extractors []Propagator
+}
+
+// Inject implements Propagator.
+func (p CompositePropagator) Inject(sc opentracing.SpanContext, carrier interface{}) error {
+	for _, propagator := range p.Injectors {
+		if err := propagator.Inject(sc, carrier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extract implements Propagator.
+func (p CompositePropagator) Extract(carrier interface{}) (opentracing.SpanContext, error) {
+	firstErr := opentracing.ErrSpanContextNotFound
+	for _, propagator := range p.Extractors {
+		sc, err := propagator.Extract(carrier)
+		if err == nil {
+			return sc, nil
+		}
+		if firstErr == opentracing.ErrSpanContextNotFound {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// newPropagators returns the propagators of the formats the tracer supports:
+// those of opts.Propagators for TextMap and HTTPHeaders carriers, the
+// LightStep binary format for Binary carriers, and opts.CustomPropagators
+// in their place.
+func newPropagators(opts Options) map[opentracing.BuiltinFormat]Propagator {
+	text := newTextPropagator(opts)
+	propagators := map[opentracing.BuiltinFormat]Propagator{
+		opentracing.TextMap:     text,
+		opentracing.HTTPHeaders: text,
+		opentracing.Binary:      binaryPropagator{raw: opts.RawBinaryCarrier},
+	}
+	for format, propagator := range opts.CustomPropagators {
+		propagators[format] = propagator
+	}
+	return propagators
+}
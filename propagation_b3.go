@@ -0,0 +1,148 @@
+package lightstep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+const (
+	fieldNameB3        = "b3"
+	fieldNameB3TraceID = "x-b3-traceid"
+	fieldNameB3SpanID  = "x-b3-spanid"
+	fieldNameB3Sampled = "x-b3-sampled"
+	fieldNameB3Flags   = "x-b3-flags"
+
+	b3Sampled = "1"
+	// b3Debug is the sampling state of the single field, and the flags of
+	// the X-B3-* fields, of a debug trace.
+	b3Debug      = "d"
+	b3DebugFlags = "1"
+)
+
+// b3Propagator propagates span contexts in the B3 format of Zipkin, used by
+// Envoy and other service meshes: in the single b3 field, or if multi is
+// set, in the X-B3-* fields. Both forms are extracted, the single field
+// first. Like the W3C format, a 128-bit trace ID's upper half is kept in
+// the context and injected unchanged by its descendants. The debug flag
+// marks a debug trace, see EnableDebugTrace. Other baggage isn't propagated
+// in this format, and the sampling decision of the caller isn't followed,
+// as every span is reported.
+type b3Propagator struct {
+	multi bool
+}
+
+func (p b3Propagator) Inject(
+	spanContext opentracing.SpanContext,
+	opaqueCarrier interface{},
+) error {
+	sc, ok := spanContext.(SpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	carrier, ok := opaqueCarrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	traceID := fmt.Sprintf("%016x", sc.TraceID)
+	if sc.TraceIDHigh != 0 {
+		traceID = fmt.Sprintf("%016x%016x", sc.TraceIDHigh, sc.TraceID)
+	}
+	spanID := fmt.Sprintf("%016x", sc.SpanID)
+	debug := sc.isDebug()
+	if p.multi {
+		carrier.Set(fieldNameB3TraceID, traceID)
+		carrier.Set(fieldNameB3SpanID, spanID)
+		// The debug flag implies sampling, which isn't sent along with it.
+		if debug {
+			carrier.Set(fieldNameB3Flags, b3DebugFlags)
+		} else {
+			carrier.Set(fieldNameB3Sampled, b3Sampled)
+		}
+		return nil
+	}
+	sampled := b3Sampled
+	if debug {
+		sampled = b3Debug
+	}
+	carrier.Set(fieldNameB3, traceID+"-"+spanID+"-"+sampled)
+	return nil
+}
+
+func (b3Propagator) Extract(
+	opaqueCarrier interface{},
+) (opentracing.SpanContext, error) {
+	carrier, ok := opaqueCarrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+
+	var single, traceID, spanID, flags string
+	err := carrier.ForeachKey(func(k, v string) error {
+		switch strings.ToLower(k) {
+		case fieldNameB3:
+			single = strings.TrimSpace(v)
+		case fieldNameB3TraceID:
+			traceID = strings.TrimSpace(v)
+		case fieldNameB3SpanID:
+			spanID = strings.TrimSpace(v)
+		case fieldNameB3Flags:
+			flags = strings.TrimSpace(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	debug := flags == b3DebugFlags
+	if single != "" {
+		parts := strings.Split(single, "-")
+		if len(parts) == 1 {
+			// A sampling decision without a trace, such as "0".
+			return nil, opentracing.ErrSpanContextNotFound
+		}
+		if len(parts) > 4 {
+			return nil, opentracing.ErrSpanContextCorrupted
+		}
+		traceID, spanID = parts[0], parts[1]
+		debug = len(parts) > 2 && parts[2] == b3Debug
+	} else if traceID == "" && spanID == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	sc, ok := parseB3IDs(traceID, spanID)
+	if !ok {
+		return nil, opentracing.ErrSpanContextCorrupted
+	}
+	if debug {
+		sc.Baggage = map[string]string{DebugBaggageKey: debugEnabled}
+	}
+	return sc, nil
+}
+
+// parseB3IDs parses a 64 or 128-bit trace ID and a 64-bit span ID.
+func parseB3IDs(traceID, spanID string) (SpanContext, bool) {
+	if (len(traceID) != 16 && len(traceID) != 32) || len(spanID) != 16 {
+		return SpanContext{}, false
+	}
+	var high uint64
+	if len(traceID) == 32 {
+		var err error
+		if high, err = strconv.ParseUint(traceID[:16], 16, 64); err != nil {
+			return SpanContext{}, false
+		}
+		traceID = traceID[16:]
+	}
+	low, err := strconv.ParseUint(traceID, 16, 64)
+	if err != nil || low == 0 {
+		return SpanContext{}, false
+	}
+	span, err := strconv.ParseUint(spanID, 16, 64)
+	if err != nil || span == 0 {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceID: low, SpanID: span, TraceIDHigh: high}, true
+}
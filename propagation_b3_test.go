@@ -0,0 +1,123 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("B3 propagation", func() {
+	var tracer Tracer
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			Propagators:        []string{PropagatorB3},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("injects the single b3 field", func() {
+		sc := SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1234567890abcdef}
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+
+		Expect(carrier).To(Equal(opentracing.TextMapCarrier{
+			"b3": "a1b2c3d4e5f60718-1234567890abcdef-1",
+		}))
+	})
+
+	It("continues a 128-bit trace from the single b3 field", func() {
+		header := http.Header{}
+		header.Set("b3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90")
+
+		parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parent.(SpanContext).TraceID).To(Equal(uint64(0x64fe8b2a57d3eff7)))
+		Expect(parent.(SpanContext).SpanID).To(Equal(uint64(0xe457b5a2e4d86bd1)))
+
+		child := tracer.StartSpan("child", opentracing.ChildOf(parent))
+		defer child.Finish()
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(child.Context(), opentracing.TextMap, carrier)).To(Succeed())
+
+		childSpanID := child.Context().(SpanContext).SpanID
+		Expect(carrier["b3"]).To(Equal(fmt.Sprintf("80f198ee56343ba864fe8b2a57d3eff7-%016x-1", childSpanID)))
+	})
+
+	It("extracts the X-B3-* fields of an Envoy sidecar", func() {
+		header := http.Header{}
+		header.Set("X-B3-TraceId", "a1b2c3d4e5f60718")
+		header.Set("X-B3-SpanId", "1234567890abcdef")
+		header.Set("X-B3-ParentSpanId", "0000000000000001")
+		header.Set("X-B3-Sampled", "1")
+
+		sc, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sc.(SpanContext).TraceID).To(Equal(uint64(0xa1b2c3d4e5f60718)))
+		Expect(sc.(SpanContext).SpanID).To(Equal(uint64(0x1234567890abcdef)))
+	})
+
+	It("ignores a sampling decision without a trace", func() {
+		carrier := opentracing.TextMapCarrier{"b3": "0"}
+		_, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+	})
+
+	It("rejects invalid IDs", func() {
+		for _, b3 := range []string{
+			"a1b2c3d4e5f60718",
+			"a1b2c3d4e5f6071-1234567890abcdef",
+			"0000000000000000-1234567890abcdef-1",
+			"a1b2c3d4e5f60718-0000000000000000-1",
+			"a1b2c3d4e5f60718-123456789xabcdef-1",
+			"a1b2c3d4e5f60718-1234567890abcdef-1-0000000000000001-1",
+		} {
+			carrier := opentracing.TextMapCarrier{"b3": b3}
+			_, err := tracer.Extract(opentracing.TextMap, carrier)
+			Expect(err).To(HaveOccurred(), b3)
+		}
+
+		carrier := opentracing.TextMapCarrier{"x-b3-traceid": "a1b2c3d4e5f60718"}
+		_, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).To(Equal(opentracing.ErrSpanContextCorrupted))
+	})
+
+	Context("with the X-B3-* fields", func() {
+		BeforeEach(func() {
+			opts.Propagators = []string{PropagatorB3Multi}
+		})
+
+		It("injects them", func() {
+			sc := SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1234567890abcdef}
+			carrier := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+
+			Expect(carrier).To(Equal(opentracing.TextMapCarrier{
+				"x-b3-traceid": "a1b2c3d4e5f60718",
+				"x-b3-spanid":  "1234567890abcdef",
+				"x-b3-sampled": "1",
+			}))
+		})
+	})
+})
@@ -13,11 +13,16 @@ import (
 // BinaryCarrier is used as the format parameter in inject/extract for lighstep binary propagation.
 const BinaryCarrier = opentracing.Binary
 
-var theBinaryPropagator binaryPropagator
-
-type binaryPropagator struct{}
+// binaryPropagator propagates span contexts in the BinaryCarrier message of
+// the LightStep tracers, whose trace IDs have 64 bits: the upper half of a
+// 128-bit trace ID isn't propagated. The message is base64 encoded, as
+// earlier Go tracers expect, unless raw is set; the Java, Python and Node
+// tracers inject it unencoded. Both forms are extracted.
+type binaryPropagator struct {
+	raw bool
+}
 
-func (binaryPropagator) Inject(
+func (p binaryPropagator) Inject(
 	spanContext opentracing.SpanContext,
 	opaqueCarrier interface{},
 ) error {
@@ -25,29 +30,27 @@ func (binaryPropagator) Inject(
 	if !ok {
 		return opentracing.ErrInvalidSpanContext
 	}
-	data, err := proto.Marshal(&lightstep.BinaryCarrier{
-		BasicCtx: &lightstep.BasicTracerCarrier{
-			TraceId:      sc.TraceID,
-			SpanId:       sc.SpanID,
-			Sampled:      true,
-			BaggageItems: sc.Baggage,
-		},
-	})
+	var encoded string
+	var err error
+	if p.raw {
+		var data []byte
+		data, err = sc.binaryMessage()
+		encoded = string(data)
+	} else {
+		encoded, err = sc.binaryEncoded()
+	}
 	if err != nil {
 		return err
 	}
 
 	switch carrier := opaqueCarrier.(type) {
 	case io.Writer:
-		buf := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
-		base64.StdEncoding.Encode(buf, data)
-		_, err = carrier.Write(buf)
+		_, err = io.WriteString(carrier, encoded)
 		return err
 	case *string:
-		*carrier = base64.StdEncoding.EncodeToString(data)
+		*carrier = encoded
 	case *[]byte:
-		*carrier = make([]byte, base64.StdEncoding.EncodedLen(len(data)))
-		base64.StdEncoding.Encode(*carrier, data)
+		*carrier = []byte(encoded)
 	default:
 		return opentracing.ErrInvalidCarrier
 	}
@@ -58,7 +61,6 @@ func (binaryPropagator) Extract(
 	opaqueCarrier interface{},
 ) (opentracing.SpanContext, error) {
 	var data []byte
-	var err error
 
 	// Decode from string, *string, *[]byte, or []byte
 	switch carrier := opaqueCarrier.(type) {
@@ -67,25 +69,22 @@ func (binaryPropagator) Extract(
 		if err != nil {
 			return nil, err
 		}
-		data, err = decodeBase64Bytes(buf)
+		data = decodeBinary(buf)
 	case *string:
 		if carrier != nil {
-			data, err = base64.StdEncoding.DecodeString(*carrier)
+			data = decodeBinary([]byte(*carrier))
 		}
 	case string:
-		data, err = base64.StdEncoding.DecodeString(carrier)
+		data = decodeBinary([]byte(carrier))
 	case *[]byte:
 		if carrier != nil {
-			data, err = decodeBase64Bytes(*carrier)
+			data = decodeBinary(*carrier)
 		}
 	case []byte:
-		data, err = decodeBase64Bytes(carrier)
+		data = decodeBinary(carrier)
 	default:
 		return nil, opentracing.ErrInvalidCarrier
 	}
-	if err != nil {
-		return nil, err
-	}
 	pb := &lightstep.BinaryCarrier{}
 	if err := proto.Unmarshal(data, pb); err != nil {
 		return nil, err
@@ -101,11 +100,14 @@ func (binaryPropagator) Extract(
 	}, nil
 }
 
-func decodeBase64Bytes(in []byte) ([]byte, error) {
+// decodeBinary returns the BinaryCarrier message of a carrier, base64 encoded
+// or not. A message starts with a field tag outside the base64 alphabet, so
+// the forms can't be confused.
+func decodeBinary(in []byte) []byte {
 	data := make([]byte, base64.StdEncoding.DecodedLen(len(in)))
 	n, err := base64.StdEncoding.Decode(data, in)
 	if err != nil {
-		return nil, err
+		return in
 	}
-	return data[:n], nil
+	return data[:n]
 }
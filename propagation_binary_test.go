@@ -0,0 +1,90 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	. "github.com/lightstep/lightstep-tracer-go"
+	lightsteppb "github.com/lightstep/lightstep-tracer-go/lightsteppb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("Binary propagation", func() {
+	var tracer Tracer
+	var opts Options
+
+	// message is a BinaryCarrier as the Java, Python and Node tracers
+	// inject it.
+	message, _ := proto.Marshal(&lightsteppb.BinaryCarrier{
+		BasicCtx: &lightsteppb.BasicTracerCarrier{
+			TraceId:      0xa1b2c3d4e5f60718,
+			SpanId:       0x1234567890abcdef,
+			Sampled:      true,
+			BaggageItems: map[string]string{"user": "42"},
+		},
+	})
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("extracts the unencoded message of the other tracers", func() {
+		sc, err := tracer.Extract(opentracing.Binary, bytes.NewReader(message))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sc.(SpanContext).TraceID).To(Equal(uint64(0xa1b2c3d4e5f60718)))
+		Expect(sc.(SpanContext).SpanID).To(Equal(uint64(0x1234567890abcdef)))
+		Expect(sc.(SpanContext).Baggage).To(Equal(map[string]string{"user": "42"}))
+	})
+
+	It("injects the base64 encoded message by default", func() {
+		sc := SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1234567890abcdef, Baggage: map[string]string{"user": "42"}}
+		var buf bytes.Buffer
+		Expect(tracer.Inject(sc, opentracing.Binary, &buf)).To(Succeed())
+		Expect(buf.String()).To(Equal(base64.StdEncoding.EncodeToString(message)))
+	})
+
+	Context("with RawBinaryCarrier", func() {
+		BeforeEach(func() {
+			opts.RawBinaryCarrier = true
+		})
+
+		It("injects the unencoded message", func() {
+			span := tracer.StartSpan("span")
+			defer span.Finish()
+			span.SetBaggageItem("user", "42")
+			var buf bytes.Buffer
+			Expect(tracer.Inject(span.Context(), opentracing.Binary, &buf)).To(Succeed())
+
+			var carrier lightsteppb.BinaryCarrier
+			Expect(proto.Unmarshal(buf.Bytes(), &carrier)).To(Succeed())
+			Expect(carrier.GetBasicCtx().GetTraceId()).To(Equal(span.Context().(SpanContext).TraceID))
+			Expect(carrier.GetBasicCtx().GetSpanId()).To(Equal(span.Context().(SpanContext).SpanID))
+			Expect(carrier.GetBasicCtx().GetBaggageItems()).To(Equal(map[string]string{"user": "42"}))
+
+			sc, err := tracer.Extract(opentracing.Binary, &buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sc.(SpanContext).SpanID).To(Equal(span.Context().(SpanContext).SpanID))
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package lightstep_test
+
+import (
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/propagationtest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("propagation conformance", func() {
+	var tracer Tracer
+
+	BeforeEach(func() {
+		tracer = NewTracer(Options{AccessToken: "ACCESS_TOKEN", DryRun: true})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("interoperates with the other LightStep tracers", func() {
+		Expect(propagationtest.Verify(tracer)).To(BeEmpty())
+	})
+
+	It("extracts the IDs of the vectors", func() {
+		for _, v := range propagationtest.Vectors {
+			sc, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(v.Headers))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sc.(SpanContext).TraceID).To(Equal(v.TraceID), v.Name)
+			Expect(sc.(SpanContext).SpanID).To(Equal(v.SpanID), v.Name)
+		}
+	})
+})
@@ -0,0 +1,92 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("PropagationHeaders", func() {
+	var tracer Tracer
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			PropagationHeaders: PropagationHeaderOptions{
+				TraceID:       "X-Acme-Trace-Id",
+				SpanID:        "X-Acme-Span-Id",
+				Sampled:       "X-Acme-Sampled",
+				BaggagePrefix: "X-Acme-Ctx-",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("injects and extracts the renamed fields", func() {
+		sc := SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1234567890abcdef, Baggage: map[string]string{"user": "42"}}
+		header := http.Header{}
+		Expect(tracer.Inject(sc, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))).To(Succeed())
+		Expect(header).To(Equal(http.Header{
+			"X-Acme-Trace-Id": {"a1b2c3d4e5f60718"},
+			"X-Acme-Span-Id":  {"1234567890abcdef"},
+			"X-Acme-Sampled":  {"true"},
+			"X-Acme-Ctx-User": {"42"},
+		}))
+
+		extracted, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(extracted.(SpanContext).TraceID).To(Equal(sc.TraceID))
+		Expect(extracted.(SpanContext).SpanID).To(Equal(sc.SpanID))
+		Expect(extracted.(SpanContext).Baggage).To(Equal(sc.Baggage))
+	})
+
+	It("ignores the default fields", func() {
+		carrier := opentracing.TextMapCarrier{
+			"ot-tracer-traceid": "1",
+			"ot-tracer-spanid":  "2",
+			"ot-tracer-sampled": "true",
+		}
+		_, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+	})
+
+	Context("with some fields renamed", func() {
+		BeforeEach(func() {
+			opts.PropagationHeaders = PropagationHeaderOptions{TraceID: "X-Trace"}
+		})
+
+		It("keeps the default names of the others", func() {
+			carrier := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(SpanContext{TraceID: 1, SpanID: 2}, opentracing.TextMap, carrier)).To(Succeed())
+			Expect(carrier).To(Equal(opentracing.TextMapCarrier{
+				"x-trace":           "1",
+				"ot-tracer-spanid":  "2",
+				"ot-tracer-sampled": "true",
+			}))
+		})
+	})
+
+	It("rejects fields named alike", func() {
+		opts := Options{PropagationHeaders: PropagationHeaderOptions{TraceID: "X-Id", SpanID: "x-id"}}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
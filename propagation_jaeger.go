@@ -0,0 +1,145 @@
+package lightstep
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+const (
+	fieldNameUberTraceID = "uber-trace-id"
+	prefixUberBaggage    = "uberctx-"
+
+	uberTraceIDFieldCount = 4
+	// uberSampledFlags are the flags of an injected uber-trace-id, which
+	// mark it sampled, and uberDebugFlags those of a debug trace.
+	uberSampledFlags = "1"
+	uberDebugFlags   = "3"
+	uberDebugFlag    = 0x2
+)
+
+// jaegerPropagator propagates span contexts in the uber-trace-id field and
+// baggage in the uberctx-* fields of the Jaeger clients. As theirs do, it
+// URL-encodes baggage values in HTTP headers, and decodes the uber-trace-id
+// and baggage values of HTTP headers. A 128-bit trace ID's upper half is kept
+// in the context and injected unchanged by its descendants. The debug flag
+// marks a debug trace, see EnableDebugTrace, and the sampling decision of
+// the caller isn't followed, as every span is reported.
+type jaegerPropagator struct{}
+
+func (jaegerPropagator) Inject(
+	spanContext opentracing.SpanContext,
+	opaqueCarrier interface{},
+) error {
+	sc, ok := spanContext.(SpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	carrier, ok := opaqueCarrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	_, isHTTP := opaqueCarrier.(opentracing.HTTPHeadersCarrier)
+
+	flags := uberSampledFlags
+	if sc.isDebug() {
+		flags = uberDebugFlags
+	}
+	carrier.Set(fieldNameUberTraceID, fmt.Sprintf("%s:%x:0:%s", formatTraceID(sc.TraceIDHigh, sc.TraceID), sc.SpanID, flags))
+	for k, v := range sc.Baggage {
+		if k == DebugBaggageKey {
+			// Carried by the flags.
+			continue
+		}
+		if isHTTP {
+			v = url.QueryEscape(v)
+		}
+		carrier.Set(prefixUberBaggage+k, v)
+	}
+	return nil
+}
+
+func (jaegerPropagator) Extract(
+	opaqueCarrier interface{},
+) (opentracing.SpanContext, error) {
+	carrier, ok := opaqueCarrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+	_, isHTTP := opaqueCarrier.(opentracing.HTTPHeadersCarrier)
+	decode := func(v string) (string, error) {
+		if !isHTTP {
+			return v, nil
+		}
+		return url.QueryUnescape(v)
+	}
+
+	var uberTraceID string
+	baggage := map[string]string{}
+	err := carrier.ForeachKey(func(k, v string) error {
+		lowercaseK := strings.ToLower(k)
+		switch {
+		case lowercaseK == fieldNameUberTraceID:
+			decoded, err := decode(v)
+			if err != nil {
+				return opentracing.ErrSpanContextCorrupted
+			}
+			uberTraceID = decoded
+		case strings.HasPrefix(lowercaseK, prefixUberBaggage):
+			decoded, err := decode(v)
+			if err != nil {
+				return opentracing.ErrSpanContextCorrupted
+			}
+			baggage[strings.TrimPrefix(lowercaseK, prefixUberBaggage)] = decoded
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if uberTraceID == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	uberTraceID = strings.TrimSpace(uberTraceID)
+	sc, ok := parseUberTraceID(uberTraceID)
+	if !ok {
+		return nil, opentracing.ErrSpanContextCorrupted
+	}
+	if uberDebug(uberTraceID) {
+		baggage[DebugBaggageKey] = debugEnabled
+	}
+	sc.Baggage = baggage
+	return sc, nil
+}
+
+// parseUberTraceID parses an uber-trace-id field,
+// {trace-id}:{span-id}:{parent-span-id}:{flags}, whose IDs are hex numbers
+// without leading zeros, and whose trace-id may have 128 bits.
+func parseUberTraceID(value string) (SpanContext, bool) {
+	fields := strings.Split(value, ":")
+	if len(fields) != uberTraceIDFieldCount {
+		return SpanContext{}, false
+	}
+	traceIDHex, spanIDHex := fields[0], fields[1]
+	high, low, err := parseTraceID(traceIDHex)
+	if err != nil || low == 0 {
+		return SpanContext{}, false
+	}
+	spanID, err := strconv.ParseUint(spanIDHex, 16, 64)
+	if err != nil || spanID == 0 {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceID: low, SpanID: spanID, TraceIDHigh: high}, true
+}
+
+// uberDebug reports whether the flags of a valid uber-trace-id field mark a
+// debug trace.
+func uberDebug(value string) bool {
+	fields := strings.Split(value, ":")
+	flags, err := strconv.ParseUint(fields[uberTraceIDFieldCount-1], 16, 8)
+	return err == nil && flags&uberDebugFlag != 0
+}
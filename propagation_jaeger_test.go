@@ -0,0 +1,80 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("Jaeger propagation", func() {
+	var tracer Tracer
+
+	BeforeEach(func() {
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			Propagators:        []string{PropagatorJaeger},
+		})
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("injects the uber-trace-id and uberctx-* fields", func() {
+		sc := SpanContext{TraceID: 0xa1b2c3d4e5f6, SpanID: 0x1234567890abcdef, Baggage: map[string]string{"tenant": "acme corp"}}
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+
+		Expect(carrier).To(Equal(opentracing.TextMapCarrier{
+			"uber-trace-id":  "a1b2c3d4e5f6:1234567890abcdef:0:1",
+			"uberctx-tenant": "acme corp",
+		}))
+	})
+
+	It("continues the trace and baggage of a Jaeger client", func() {
+		header := http.Header{}
+		header.Set("Uber-Trace-Id", "463ac35c9f6413ad48485a3953bb6124%3Aa2fb4a1d1a96d312%3A0%3A1")
+		header.Set("Uberctx-Tenant", "acme%20corp")
+
+		parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parent.(SpanContext).TraceID).To(Equal(uint64(0x48485a3953bb6124)))
+		Expect(parent.(SpanContext).SpanID).To(Equal(uint64(0xa2fb4a1d1a96d312)))
+		Expect(parent.(SpanContext).Baggage).To(Equal(map[string]string{"tenant": "acme corp"}))
+
+		child := tracer.StartSpan("child", opentracing.ChildOf(parent))
+		defer child.Finish()
+		injected := http.Header{}
+		Expect(tracer.Inject(child.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(injected))).To(Succeed())
+
+		childSpanID := child.Context().(SpanContext).SpanID
+		Expect(injected.Get("uber-trace-id")).To(Equal(fmt.Sprintf("463ac35c9f6413ad48485a3953bb6124:%x:0:1", childSpanID)))
+		Expect(injected.Get("uberctx-tenant")).To(Equal("acme+corp"))
+	})
+
+	It("rejects invalid uber-trace-id fields", func() {
+		for _, uberTraceID := range []string{
+			"463ac35c9f6413ad:a2fb4a1d1a96d312:0",
+			"0:a2fb4a1d1a96d312:0:1",
+			"463ac35c9f6413ad:0:0:1",
+			"463ac35c9f6413ad:a2fb4a1d1a96d31x:0:1",
+			"1463ac35c9f6413ad48485a3953bb6124:a2fb4a1d1a96d312:0:1",
+		} {
+			carrier := opentracing.TextMapCarrier{"uber-trace-id": uberTraceID}
+			_, err := tracer.Extract(opentracing.TextMap, carrier)
+			Expect(err).To(Equal(opentracing.ErrSpanContextCorrupted), uberTraceID)
+		}
+	})
+})
@@ -0,0 +1,143 @@
+package lightstep
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// DefaultSignedContextMaxLen is the default ContextSigner.MaxLen, which
+// leaves room for a cookie's name and attributes within the 4096 bytes
+// browsers allow.
+const DefaultSignedContextMaxLen = 2048
+
+var (
+	// ErrSignedContextTooLarge is returned by ContextSigner when a signed
+	// context is longer than its MaxLen.
+	ErrSignedContextTooLarge = errors.New("signed span context is too large")
+	// ErrSignedContextInvalid is returned by ContextSigner when a signed
+	// context is malformed or its signature doesn't match.
+	ErrSignedContextInvalid = errors.New("signed span context is invalid")
+
+	errContextSignerNoKey = errors.New("ContextSigner has no Key")
+)
+
+// ContextSigner propagates span contexts through browsers, in cookies and
+// query parameters, so that traces begun by a page can be continued by the
+// requests it makes. Contexts are signed with HMAC-SHA256, so a client can
+// return a context but not forge or alter one. Baggage is signed but not
+// encrypted: don't put secrets in it.
+type ContextSigner struct {
+	// Key signs contexts. It should be at least 32 random bytes, and shared
+	// by every service that extracts the contexts.
+	Key []byte
+	// MaxLen is the maximum length of a signed context. Contexts with too
+	// much baggage are rejected with ErrSignedContextTooLarge. Defaults to
+	// DefaultSignedContextMaxLen.
+	MaxLen int
+}
+
+func (s ContextSigner) maxLen() int {
+	if s.MaxLen > 0 {
+		return s.MaxLen
+	}
+	return DefaultSignedContextMaxLen
+}
+
+func (s ContextSigner) signature(text string) string {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(text))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Encode returns the signed form of sc: its SpanContext.MarshalText
+// encoding, a ".", and the signature.
+func (s ContextSigner) Encode(sc opentracing.SpanContext) (string, error) {
+	if len(s.Key) == 0 {
+		return "", errContextSignerNoKey
+	}
+	lsc, ok := sc.(SpanContext)
+	if !ok {
+		return "", opentracing.ErrInvalidSpanContext
+	}
+	text, err := lsc.MarshalText()
+	if err != nil {
+		return "", err
+	}
+	signed := string(text) + "." + s.signature(string(text))
+	if len(signed) > s.maxLen() {
+		return "", ErrSignedContextTooLarge
+	}
+	return signed, nil
+}
+
+// Decode returns the span context signed by Encode.
+func (s ContextSigner) Decode(signed string) (opentracing.SpanContext, error) {
+	if len(s.Key) == 0 {
+		return nil, errContextSignerNoKey
+	}
+	if len(signed) > s.maxLen() {
+		return nil, ErrSignedContextTooLarge
+	}
+	dot := strings.LastIndexByte(signed, '.')
+	if dot < 0 {
+		return nil, ErrSignedContextInvalid
+	}
+	text, signature := signed[:dot], signed[dot+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.signature(text))) {
+		return nil, ErrSignedContextInvalid
+	}
+	var sc SpanContext
+	if err := sc.UnmarshalText([]byte(text)); err != nil {
+		return nil, ErrSignedContextInvalid
+	}
+	return sc, nil
+}
+
+// Cookie returns a cookie carrying the signed form of sc. Set its Path,
+// MaxAge and other attributes as needed before sending it. The cookie is
+// readable by scripts, so that browser instrumentation can send it back.
+func (s ContextSigner) Cookie(name string, sc opentracing.SpanContext) (*http.Cookie, error) {
+	signed, err := s.Encode(sc)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Cookie{Name: name, Value: signed}, nil
+}
+
+// ExtractCookie returns the span context carried by the named cookie of r,
+// or opentracing.ErrSpanContextNotFound if there is no such cookie.
+func (s ContextSigner) ExtractCookie(r *http.Request, name string) (opentracing.SpanContext, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	return s.Decode(cookie.Value)
+}
+
+// SetQuery sets the named query parameter of query to the signed form of sc.
+func (s ContextSigner) SetQuery(query url.Values, name string, sc opentracing.SpanContext) error {
+	signed, err := s.Encode(sc)
+	if err != nil {
+		return err
+	}
+	query.Set(name, signed)
+	return nil
+}
+
+// ExtractQuery returns the span context carried by the named query
+// parameter of query, or opentracing.ErrSpanContextNotFound if it is not
+// set.
+func (s ContextSigner) ExtractQuery(query url.Values, name string) (opentracing.SpanContext, error) {
+	signed := query.Get(name)
+	if signed == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	return s.Decode(signed)
+}
@@ -0,0 +1,94 @@
+package lightstep_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("ContextSigner", func() {
+	var signer ContextSigner
+	var sc SpanContext
+
+	BeforeEach(func() {
+		signer = ContextSigner{Key: []byte("0123456789abcdef0123456789abcdef")}
+		sc = SpanContext{TraceID: 1, SpanID: 2, Baggage: map[string]string{"user": "alice"}}
+	})
+
+	It("round trips through a cookie", func() {
+		cookie, err := signer.Cookie("trace", sc)
+		Expect(err).NotTo(HaveOccurred())
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(cookie)
+		extracted, err := signer.ExtractCookie(req, "trace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extracted.(SpanContext).TraceID).To(Equal(uint64(1)))
+		Expect(extracted.(SpanContext).SpanID).To(Equal(uint64(2)))
+		Expect(extracted.(SpanContext).Baggage).To(Equal(sc.Baggage))
+	})
+
+	It("round trips through a query parameter", func() {
+		query := url.Values{}
+		Expect(signer.SetQuery(query, "trace", sc)).To(Succeed())
+
+		parsed, err := url.ParseQuery(query.Encode())
+		Expect(err).NotTo(HaveOccurred())
+		extracted, err := signer.ExtractQuery(parsed, "trace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extracted.(SpanContext).Baggage).To(Equal(sc.Baggage))
+	})
+
+	It("round trips 128-bit trace IDs", func() {
+		sc.TraceIDHigh = 0xabc
+		query := url.Values{}
+		Expect(signer.SetQuery(query, "trace", sc)).To(Succeed())
+
+		extracted, err := signer.ExtractQuery(query, "trace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extracted.(SpanContext).TraceIDHigh).To(Equal(uint64(0xabc)))
+		Expect(extracted.(SpanContext).TraceID).To(Equal(uint64(1)))
+	})
+
+	It("reports missing contexts as not found", func() {
+		_, err := signer.ExtractCookie(httptest.NewRequest("GET", "/", nil), "trace")
+		Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+		_, err = signer.ExtractQuery(url.Values{}, "trace")
+		Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+	})
+
+	It("rejects tampered contexts", func() {
+		signed, err := signer.Encode(sc)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = signer.Decode(strings.Replace(signed, "alice", "admin", 1))
+		Expect(err).To(Equal(ErrSignedContextInvalid))
+		_, err = ContextSigner{Key: []byte("another key")}.Decode(signed)
+		Expect(err).To(Equal(ErrSignedContextInvalid))
+		_, err = signer.Decode("no signature")
+		Expect(err).To(Equal(ErrSignedContextInvalid))
+	})
+
+	It("rejects contexts that are too large", func() {
+		sc.Baggage["big"] = strings.Repeat("x", DefaultSignedContextMaxLen)
+		_, err := signer.Encode(sc)
+		Expect(err).To(Equal(ErrSignedContextTooLarge))
+
+		_, err = signer.Decode(strings.Repeat("x", DefaultSignedContextMaxLen+1))
+		Expect(err).To(Equal(ErrSignedContextTooLarge))
+	})
+
+	It("sets cookies that browsers accept", func() {
+		cookie, err := signer.Cookie("trace", sc)
+		Expect(err).NotTo(HaveOccurred())
+		recorder := httptest.NewRecorder()
+		http.SetCookie(recorder, cookie)
+		Expect(recorder.Header().Get("Set-Cookie")).To(Equal("trace=" + cookie.Value))
+	})
+})
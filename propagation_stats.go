@@ -0,0 +1,87 @@
+package lightstep
+
+import (
+	"fmt"
+	"sync"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// PropagationCounts counts the outcomes of Inject or Extract calls for one
+// carrier format.
+type PropagationCounts struct {
+	Succeeded int64
+	// NotFound counts Extract calls on carriers without a span context.
+	NotFound int64
+	Failed   int64
+}
+
+// PropagationStats holds the Inject and Extract counts of a Tracer, keyed by
+// carrier format, e.g. "http_headers". A service that never extracts a span
+// context is likely breaking its traces.
+type PropagationStats struct {
+	Inject  map[string]PropagationCounts
+	Extract map[string]PropagationCounts
+}
+
+type propagationCounter struct {
+	lock    sync.Mutex
+	inject  map[string]PropagationCounts
+	extract map[string]PropagationCounts
+}
+
+func (c *propagationCounter) record(counts *map[string]PropagationCounts, format interface{}, err error) {
+	name := formatName(format)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if *counts == nil {
+		*counts = map[string]PropagationCounts{}
+	}
+	count := (*counts)[name]
+	switch err {
+	case nil:
+		count.Succeeded++
+	case opentracing.ErrSpanContextNotFound:
+		count.NotFound++
+	default:
+		count.Failed++
+	}
+	(*counts)[name] = count
+}
+
+func (c *propagationCounter) recordInject(format interface{}, err error) {
+	c.record(&c.inject, format, err)
+}
+
+func (c *propagationCounter) recordExtract(format interface{}, err error) {
+	c.record(&c.extract, format, err)
+}
+
+func (c *propagationCounter) stats() PropagationStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	stats := PropagationStats{
+		Inject:  make(map[string]PropagationCounts, len(c.inject)),
+		Extract: make(map[string]PropagationCounts, len(c.extract)),
+	}
+	for name, count := range c.inject {
+		stats.Inject[name] = count
+	}
+	for name, count := range c.extract {
+		stats.Extract[name] = count
+	}
+	return stats
+}
+
+func formatName(format interface{}) string {
+	switch format {
+	case opentracing.TextMap:
+		return "text_map"
+	case opentracing.HTTPHeaders:
+		return "http_headers"
+	case opentracing.Binary:
+		return "binary"
+	}
+	return fmt.Sprint(format)
+}
@@ -0,0 +1,174 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// requestContextPropagator propagates span contexts in a single
+// x-request-context field of the form {trace-id}/{span-id}.
+type requestContextPropagator struct{}
+
+func (requestContextPropagator) Inject(spanContext opentracing.SpanContext, carrier interface{}) error {
+	sc := spanContext.(SpanContext)
+	carrier.(opentracing.TextMapWriter).Set("x-request-context", fmt.Sprintf("%x/%x", sc.TraceID, sc.SpanID))
+	return nil
+}
+
+func (requestContextPropagator) Extract(carrier interface{}) (opentracing.SpanContext, error) {
+	var sc SpanContext
+	err := carrier.(opentracing.TextMapReader).ForeachKey(func(k, v string) error {
+		if http.CanonicalHeaderKey(k) == "X-Request-Context" {
+			if _, err := fmt.Sscanf(v, "%x/%x", &sc.TraceID, &sc.SpanID); err != nil {
+				return opentracing.ErrSpanContextCorrupted
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sc.TraceID == 0 {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	return sc, nil
+}
+
+var _ = Describe("CustomPropagators", func() {
+	var tracer Tracer
+
+	BeforeEach(func() {
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			CustomPropagators: map[opentracing.BuiltinFormat]Propagator{
+				opentracing.HTTPHeaders: requestContextPropagator{},
+			},
+		})
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("propagates the carriers of their format", func() {
+		sc := SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1234567890abcdef}
+		header := http.Header{}
+		Expect(tracer.Inject(sc, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))).To(Succeed())
+		Expect(header).To(Equal(http.Header{"X-Request-Context": {"a1b2c3d4e5f60718/1234567890abcdef"}}))
+
+		extracted, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(extracted.(SpanContext).TraceID).To(Equal(sc.TraceID))
+		Expect(extracted.(SpanContext).SpanID).To(Equal(sc.SpanID))
+	})
+
+	It("leaves the other formats to the tracer's propagators", func() {
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(SpanContext{TraceID: 1, SpanID: 2}, opentracing.TextMap, carrier)).To(Succeed())
+		Expect(carrier).To(HaveKey("ot-tracer-traceid"))
+		Expect(carrier).ToNot(HaveKey("x-request-context"))
+	})
+
+	It("rejects nil propagators", func() {
+		invalid := Options{
+			AccessToken:       "ACCESS_TOKEN",
+			CustomPropagators: map[opentracing.BuiltinFormat]Propagator{opentracing.Binary: nil},
+		}
+		Expect(invalid.Validate()).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("CompositePropagator", func() {
+	var tracer Tracer
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			Propagators:        []string{PropagatorLightStep, PropagatorB3, PropagatorTraceContext},
+			ExtractPropagators: []string{PropagatorTraceContext, PropagatorB3},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("injects every format", func() {
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(SpanContext{TraceID: 1, SpanID: 2}, opentracing.TextMap, carrier)).To(Succeed())
+		Expect(carrier).To(HaveKey("ot-tracer-traceid"))
+		Expect(carrier).To(HaveKey("b3"))
+		Expect(carrier).To(HaveKey("traceparent"))
+	})
+
+	It("extracts the formats of ExtractPropagators by priority", func() {
+		carrier := opentracing.TextMapCarrier{
+			"b3":          "000000000000000a-000000000000000b-1",
+			"traceparent": "00-0000000000000000000000000000000c-000000000000000d-01",
+		}
+		sc, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sc.(SpanContext).TraceID).To(Equal(uint64(0xc)))
+
+		delete(carrier, "traceparent")
+		sc, err = tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sc.(SpanContext).TraceID).To(Equal(uint64(0xa)))
+
+		carrier = opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(SpanContext{TraceID: 1, SpanID: 2}, opentracing.TextMap, carrier)).To(Succeed())
+		delete(carrier, "b3")
+		delete(carrier, "traceparent")
+		_, err = tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+	})
+
+	Context("composing a custom format", func() {
+		BeforeEach(func() {
+			opts.CustomPropagators = map[opentracing.BuiltinFormat]Propagator{
+				opentracing.HTTPHeaders: CompositePropagator{
+					Injectors:  []Propagator{requestContextPropagator{}},
+					Extractors: []Propagator{requestContextPropagator{}},
+				},
+			}
+		})
+
+		It("propagates it", func() {
+			header := http.Header{}
+			sc := SpanContext{TraceID: 3, SpanID: 4}
+			Expect(tracer.Inject(sc, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))).To(Succeed())
+			Expect(header.Get("x-request-context")).To(Equal("3/4"))
+
+			extracted, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(extracted.(SpanContext).SpanID).To(Equal(uint64(4)))
+		})
+	})
+
+	It("rejects unknown extract propagators", func() {
+		invalid := Options{AccessToken: "ACCESS_TOKEN", ExtractPropagators: []string{"unknown"}}
+		Expect(invalid.Validate()).To(HaveOccurred())
+	})
+})
@@ -17,11 +17,92 @@ const (
 	fieldNameSampled      = prefixTracerState + "sampled"
 )
 
-var theTextMapPropagator textMapPropagator
+// PropagationHeaderOptions renames the carrier fields of the LightStep
+// format, for infrastructure that strips unknown ot-* HTTP headers, or
+// standardizes on other names. Empty fields keep their default names. Peers
+// must use the same names. Names are matched case-insensitively.
+type PropagationHeaderOptions struct {
+	// TraceID is the field holding the trace ID, "ot-tracer-traceid" by
+	// default.
+	TraceID string `yaml:"trace_id"`
+	// SpanID is the field holding the span ID, "ot-tracer-spanid" by
+	// default.
+	SpanID string `yaml:"span_id"`
+	// Sampled is the field holding the sampled flag, "ot-tracer-sampled" by
+	// default.
+	Sampled string `yaml:"sampled"`
+	// BaggagePrefix is the prefix of the fields holding baggage items,
+	// "ot-baggage-" by default.
+	BaggagePrefix string `yaml:"baggage_prefix"`
+}
+
+func (o PropagationHeaderOptions) validate() error {
+	names := map[string]bool{}
+	for _, name := range []string{o.TraceID, o.SpanID, o.Sampled} {
+		if name == "" {
+			continue
+		}
+		name = strings.ToLower(name)
+		if names[name] {
+			return validationErrorPropagationHeaders
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// textMapFieldNames are the lowercase carrier field names of the LightStep
+// format.
+type textMapFieldNames struct {
+	traceID, spanID, sampled, baggagePrefix string
+}
 
-type textMapPropagator struct{}
+var defaultTextMapFieldNames = textMapFieldNames{
+	traceID:       fieldNameTraceID,
+	spanID:        fieldNameSpanID,
+	sampled:       fieldNameSampled,
+	baggagePrefix: prefixBaggage,
+}
 
-func (textMapPropagator) Inject(
+func newTextMapFieldNames(opts PropagationHeaderOptions) textMapFieldNames {
+	names := defaultTextMapFieldNames
+	if opts.TraceID != "" {
+		names.traceID = strings.ToLower(opts.TraceID)
+	}
+	if opts.SpanID != "" {
+		names.spanID = strings.ToLower(opts.SpanID)
+	}
+	if opts.Sampled != "" {
+		names.sampled = strings.ToLower(opts.Sampled)
+	}
+	if opts.BaggagePrefix != "" {
+		names.baggagePrefix = strings.ToLower(opts.BaggagePrefix)
+	}
+	return names
+}
+
+type textMapPropagator struct {
+	names textMapFieldNames
+	// passThroughKeys are glob patterns for lowercase carrier keys that are
+	// kept from Extract and written back by Inject, see
+	// Options.PassThroughKeys.
+	passThroughKeys []string
+	// callerHeader is the lowercase carrier key kept as the callerKey
+	// baggage item, see CallerSamplingOptions.Header.
+	callerHeader string
+	callerKey    string
+}
+
+func newTextMapPropagator(opts Options) textMapPropagator {
+	return textMapPropagator{
+		names:           newTextMapFieldNames(opts.PropagationHeaders),
+		passThroughKeys: opts.PassThroughKeys,
+		callerHeader:    strings.ToLower(opts.CallerSampling.Header),
+		callerKey:       strings.ToLower(opts.CallerSampling.BaggageKey),
+	}
+}
+
+func (p textMapPropagator) Inject(
 	spanContext opentracing.SpanContext,
 	opaqueCarrier interface{},
 ) error {
@@ -33,17 +114,13 @@ func (textMapPropagator) Inject(
 	if !ok {
 		return opentracing.ErrInvalidCarrier
 	}
-	carrier.Set(fieldNameTraceID, strconv.FormatUint(sc.TraceID, 16))
-	carrier.Set(fieldNameSpanID, strconv.FormatUint(sc.SpanID, 16))
-	carrier.Set(fieldNameSampled, "true")
-
-	for k, v := range sc.Baggage {
-		carrier.Set(prefixBaggage+k, v)
+	for _, field := range sc.textFields(p.names) {
+		carrier.Set(field[0], field[1])
 	}
 	return nil
 }
 
-func (textMapPropagator) Extract(
+func (p textMapPropagator) Extract(
 	opaqueCarrier interface{},
 ) (opentracing.SpanContext, error) {
 	carrier, ok := opaqueCarrier.(opentracing.TextMapReader)
@@ -52,29 +129,39 @@ func (textMapPropagator) Extract(
 	}
 
 	requiredFieldCount := 0
-	var traceID, spanID uint64
+	var traceIDHigh, traceID, spanID uint64
 	var err error
 	decodedBaggage := map[string]string{}
+	var passThrough map[string]string
+	var caller string
+	var foundCaller bool
 	err = carrier.ForeachKey(func(k, v string) error {
 		switch strings.ToLower(k) {
-		case fieldNameTraceID:
-			traceID, err = strconv.ParseUint(v, 16, 64)
+		case p.names.traceID:
+			traceIDHigh, traceID, err = parseTraceID(v)
 			if err != nil {
 				return opentracing.ErrSpanContextCorrupted
 			}
 			requiredFieldCount++
-		case fieldNameSpanID:
+		case p.names.spanID:
 			spanID, err = strconv.ParseUint(v, 16, 64)
 			if err != nil {
 				return opentracing.ErrSpanContextCorrupted
 			}
 			requiredFieldCount++
-		case fieldNameSampled:
+		case p.names.sampled:
 			requiredFieldCount++
 		default:
 			lowercaseK := strings.ToLower(k)
-			if strings.HasPrefix(lowercaseK, prefixBaggage) {
-				decodedBaggage[strings.TrimPrefix(lowercaseK, prefixBaggage)] = v
+			if p.callerHeader != "" && lowercaseK == p.callerHeader {
+				caller, foundCaller = v, true
+			} else if strings.HasPrefix(lowercaseK, p.names.baggagePrefix) {
+				decodedBaggage[strings.TrimPrefix(lowercaseK, p.names.baggagePrefix)] = v
+			} else if matchesAnyPattern(lowercaseK, p.passThroughKeys) {
+				if passThrough == nil {
+					passThrough = map[string]string{}
+				}
+				passThrough[k] = v
 			}
 		}
 		return nil
@@ -88,10 +175,15 @@ func (textMapPropagator) Extract(
 		}
 		return nil, opentracing.ErrSpanContextCorrupted
 	}
+	if foundCaller {
+		decodedBaggage[p.callerKey] = caller
+	}
 
 	return SpanContext{
-		TraceID: traceID,
-		SpanID:  spanID,
-		Baggage: decodedBaggage,
+		TraceID:     traceID,
+		TraceIDHigh: traceIDHigh,
+		SpanID:      spanID,
+		Baggage:     decodedBaggage,
+		passThrough: passThrough,
 	}, nil
 }
@@ -0,0 +1,123 @@
+package lightstep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+const (
+	fieldNameTraceParent = "traceparent"
+	fieldNameTraceState  = "tracestate"
+
+	traceContextVersion        = "00"
+	traceContextInvalidVersion = "ff"
+	traceContextSampled        = "01"
+	traceParentLen             = 55
+)
+
+// traceContextPropagator propagates span contexts in the W3C Trace Context
+// format. The 64-bit TraceID of a SpanContext is the lower half of the
+// 128-bit trace-id; the upper half of an extracted trace-id, and its
+// tracestate, are kept and injected unchanged by its descendants.
+type traceContextPropagator struct{}
+
+func (traceContextPropagator) Inject(
+	spanContext opentracing.SpanContext,
+	opaqueCarrier interface{},
+) error {
+	sc, ok := spanContext.(SpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	carrier, ok := opaqueCarrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	carrier.Set(fieldNameTraceParent, fmt.Sprintf("%s-%016x%016x-%016x-%s",
+		traceContextVersion, sc.TraceIDHigh, sc.TraceID, sc.SpanID, traceContextSampled))
+	if sc.traceState != "" {
+		carrier.Set(fieldNameTraceState, sc.traceState)
+	}
+	return nil
+}
+
+func (traceContextPropagator) Extract(
+	opaqueCarrier interface{},
+) (opentracing.SpanContext, error) {
+	carrier, ok := opaqueCarrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+
+	var traceParent string
+	var traceState []string
+	err := carrier.ForeachKey(func(k, v string) error {
+		switch strings.ToLower(k) {
+		case fieldNameTraceParent:
+			if traceParent != "" {
+				return opentracing.ErrSpanContextCorrupted
+			}
+			traceParent = v
+		case fieldNameTraceState:
+			// Repeated tracestate fields are combined, as HTTP headers are.
+			if v = strings.TrimSpace(v); v != "" {
+				traceState = append(traceState, v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if traceParent == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	sc, ok := parseTraceParent(strings.TrimSpace(traceParent))
+	if !ok {
+		return nil, opentracing.ErrSpanContextCorrupted
+	}
+	sc.traceState = strings.Join(traceState, ",")
+	return sc, nil
+}
+
+// parseTraceParent parses a traceparent field. Versions after 00 are parsed
+// as version 00, ignoring the fields they add, as the recommendation asks.
+func parseTraceParent(value string) (SpanContext, bool) {
+	if len(value) < traceParentLen || (len(value) > traceParentLen && value[traceParentLen] != '-') {
+		return SpanContext{}, false
+	}
+	version := value[0:2]
+	if !isLowerHex(version) || version == traceContextInvalidVersion ||
+		(version == traceContextVersion && len(value) != traceParentLen) {
+		return SpanContext{}, false
+	}
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return SpanContext{}, false
+	}
+	traceIDHex, spanIDHex, flags := value[3:35], value[36:52], value[53:55]
+	if !isLowerHex(traceIDHex) || !isLowerHex(spanIDHex) || !isLowerHex(flags) {
+		return SpanContext{}, false
+	}
+	high, _ := strconv.ParseUint(traceIDHex[:16], 16, 64)
+	low, _ := strconv.ParseUint(traceIDHex[16:], 16, 64)
+	spanID, _ := strconv.ParseUint(spanIDHex, 16, 64)
+	// A trace-id whose lower half is zero has no 64-bit TraceID.
+	if low == 0 || spanID == 0 {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceID: low, SpanID: spanID, TraceIDHigh: high}, true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
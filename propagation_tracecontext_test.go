@@ -0,0 +1,151 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("W3C Trace Context propagation", func() {
+	var tracer Tracer
+	var opts Options
+
+	BeforeEach(func() {
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeCollectorConnection(),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			Propagators:        []string{PropagatorTraceContext, PropagatorLightStep},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("injects both formats", func() {
+		sc := SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1234567890abcdef}
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+
+		Expect(carrier["traceparent"]).To(Equal("00-0000000000000000a1b2c3d4e5f60718-1234567890abcdef-01"))
+		Expect(carrier["ot-tracer-traceid"]).To(Equal("a1b2c3d4e5f60718"))
+		Expect(carrier).ToNot(HaveKey("tracestate"))
+	})
+
+	It("continues the trace of an OpenTelemetry peer", func() {
+		header := http.Header{}
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		header.Add("tracestate", "congo=t61rcWkgMzE")
+		header.Add("tracestate", "rojo=00f067aa0ba902b7")
+
+		parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parent.(SpanContext).TraceID).To(Equal(uint64(0xa3ce929d0e0e4736)))
+		Expect(parent.(SpanContext).SpanID).To(Equal(uint64(0x00f067aa0ba902b7)))
+
+		child := tracer.StartSpan("child", opentracing.ChildOf(parent))
+		defer child.Finish()
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(child.Context(), opentracing.TextMap, carrier)).To(Succeed())
+
+		childSpanID := child.Context().(SpanContext).SpanID
+		Expect(carrier["traceparent"]).To(Equal(fmt.Sprintf("00-4bf92f3577b34da6a3ce929d0e0e4736-%016x-01", childSpanID)))
+		Expect(carrier["tracestate"]).To(Equal("congo=t61rcWkgMzE,rojo=00f067aa0ba902b7"))
+	})
+
+	It("keeps the trace context through the text and binary encodings", func() {
+		carrier := opentracing.TextMapCarrier{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"tracestate":  "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+		}
+		sc, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+
+		text, err := sc.(SpanContext).MarshalText()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := sc.(SpanContext).MarshalBinary()
+		Expect(err).ToNot(HaveOccurred())
+
+		var fromText, fromBinary SpanContext
+		Expect(fromText.UnmarshalText(text)).To(Succeed())
+		Expect(fromBinary.UnmarshalBinary(data)).To(Succeed())
+		for _, decoded := range []SpanContext{fromText, fromBinary} {
+			injected := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(decoded, opentracing.TextMap, injected)).To(Succeed())
+			Expect(injected["traceparent"]).To(Equal(carrier["traceparent"]))
+			Expect(injected["tracestate"]).To(Equal(carrier["tracestate"]))
+		}
+	})
+
+	It("accepts later versions, ignoring their additional fields", func() {
+		carrier := opentracing.TextMapCarrier{
+			"traceparent": "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-ffff",
+		}
+		sc, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sc.(SpanContext).SpanID).To(Equal(uint64(0x00f067aa0ba902b7)))
+	})
+
+	It("rejects invalid traceparent fields", func() {
+		for _, traceParent := range []string{
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-ffff",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		} {
+			carrier := opentracing.TextMapCarrier{"traceparent": traceParent}
+			_, err := tracer.Extract(opentracing.TextMap, carrier)
+			Expect(err).To(Equal(opentracing.ErrSpanContextCorrupted), traceParent)
+		}
+	})
+
+	It("falls back to the LightStep format", func() {
+		carrier := opentracing.TextMapCarrier{
+			"traceparent":       "00-invalid",
+			"ot-tracer-traceid": "a1b2c3d4e5f60718",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "true",
+		}
+		sc, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sc.(SpanContext).TraceID).To(Equal(uint64(0xa1b2c3d4e5f60718)))
+	})
+
+	Context("by default", func() {
+		BeforeEach(func() {
+			opts.Propagators = nil
+		})
+
+		It("only propagates the LightStep format", func() {
+			carrier := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(SpanContext{TraceID: 1, SpanID: 2}, opentracing.TextMap, carrier)).To(Succeed())
+			Expect(carrier).ToNot(HaveKey("traceparent"))
+
+			carrier = opentracing.TextMapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+			_, err := tracer.Extract(opentracing.TextMap, carrier)
+			Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+		})
+	})
+
+	It("rejects unknown propagators", func() {
+		invalid := Options{AccessToken: "ACCESS_TOKEN", Propagators: []string{"unknown"}}
+		Expect(invalid.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,141 @@
+package lightstep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+const (
+	fieldNameAmznTraceID = "x-amzn-trace-id"
+
+	xrayKeyRoot     = "Root"
+	xrayKeyParent   = "Parent"
+	xrayKeySampled  = "Sampled"
+	xrayRootVersion = "1"
+	xrayRootLen     = 35
+	xraySampled     = "1"
+)
+
+// xrayPropagator propagates span contexts in the X-Amzn-Trace-Id field of AWS
+// X-Ray, which ALBs and API Gateway add to requests:
+//
+//	Root=1-{epoch}-{unique};Parent={span-id};Sampled=1
+//
+// The 32-bit epoch and 96-bit unique ID of the root are the upper and lower
+// bits of a 128-bit trace ID, whose lower 64 bits are the TraceID of the
+// SpanContext. The upper half of an extracted trace ID is kept and injected
+// unchanged by its descendants; a trace started by this tracer is injected
+// with the current epoch. A root without a parent, as an ALB sends, is
+// extracted with a zero SpanID, so that spans continuing it are the roots of
+// its trace. The sampling decision of the caller isn't followed, as every
+// span is reported.
+type xrayPropagator struct{}
+
+func (xrayPropagator) Inject(
+	spanContext opentracing.SpanContext,
+	opaqueCarrier interface{},
+) error {
+	sc, ok := spanContext.(SpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	carrier, ok := opaqueCarrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	high := sc.TraceIDHigh
+	if high == 0 {
+		high = uint64(time.Now().Unix()) << 32
+	}
+	value := fmt.Sprintf("%s=%s-%08x-%08x%016x", xrayKeyRoot, xrayRootVersion, high>>32, high&0xffffffff, sc.TraceID)
+	if sc.SpanID != 0 {
+		value += fmt.Sprintf(";%s=%016x", xrayKeyParent, sc.SpanID)
+	}
+	carrier.Set(fieldNameAmznTraceID, value+";"+xrayKeySampled+"="+xraySampled)
+	return nil
+}
+
+func (xrayPropagator) Extract(
+	opaqueCarrier interface{},
+) (opentracing.SpanContext, error) {
+	carrier, ok := opaqueCarrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+
+	var header string
+	err := carrier.ForeachKey(func(k, v string) error {
+		if strings.ToLower(k) == fieldNameAmznTraceID {
+			header = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if header == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	var root, parent string
+	for _, field := range strings.Split(header, ";") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case xrayKeyRoot:
+			root = kv[1]
+		case xrayKeyParent:
+			parent = kv[1]
+		}
+	}
+	if root == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	sc, ok := parseXRayRoot(root)
+	if !ok {
+		return nil, opentracing.ErrSpanContextCorrupted
+	}
+	if parent != "" {
+		spanID, err := strconv.ParseUint(parent, 16, 64)
+		if err != nil || len(parent) != 16 || spanID == 0 {
+			return nil, opentracing.ErrSpanContextCorrupted
+		}
+		sc.SpanID = spanID
+	}
+	return sc, nil
+}
+
+// parseXRayRoot parses the trace ID of a root, 1-{epoch}-{unique}, of 8 and 24
+// hex digits.
+func parseXRayRoot(root string) (SpanContext, bool) {
+	if len(root) != xrayRootLen || root[:2] != xrayRootVersion+"-" || root[10] != '-' {
+		return SpanContext{}, false
+	}
+	epoch, unique := root[2:10], root[11:]
+	if !isHex(epoch) || !isHex(unique) {
+		return SpanContext{}, false
+	}
+	high, _ := strconv.ParseUint(epoch+unique[:8], 16, 64)
+	low, _ := strconv.ParseUint(unique[8:], 16, 64)
+	if low == 0 {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceID: low, TraceIDHigh: high}, true
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
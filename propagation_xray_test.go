@@ -0,0 +1,102 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("X-Ray propagation", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			Propagators:        []string{PropagatorXRay},
+		})
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("continues the trace of an X-Ray client", func() {
+		header := http.Header{}
+		header.Set("X-Amzn-Trace-Id", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+
+		parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parent.(SpanContext).TraceID).To(Equal(uint64(0xe1be46a994272793)))
+		Expect(parent.(SpanContext).SpanID).To(Equal(uint64(0x53995c3f42cd8ad8)))
+
+		child := tracer.StartSpan("child", opentracing.ChildOf(parent))
+		defer child.Finish()
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(child.Context(), opentracing.TextMap, carrier)).To(Succeed())
+
+		childSpanID := child.Context().(SpanContext).SpanID
+		Expect(carrier["x-amzn-trace-id"]).To(Equal(fmt.Sprintf("Root=1-5759e988-bd862e3fe1be46a994272793;Parent=%016x;Sampled=1", childSpanID)))
+	})
+
+	It("injects the current epoch for traces started here", func() {
+		sc := SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1234567890abcdef}
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+
+		epoch := fmt.Sprintf("%08x", time.Now().Unix())
+		Expect(carrier["x-amzn-trace-id"]).To(MatchRegexp("^Root=1-%s[0-9a-f]{2}-00000000a1b2c3d4e5f60718;Parent=1234567890abcdef;Sampled=1$", epoch[:6]))
+
+		extracted, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(extracted.(SpanContext).TraceID).To(Equal(sc.TraceID))
+	})
+
+	It("roots spans in the trace of a load balancer", func() {
+		carrier := opentracing.TextMapCarrier{"X-Amzn-Trace-Id": "Self=1-67891234-12456789abcdef012345678;Root=1-67891233-abcdef012345678912345678"}
+
+		parent, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parent.(SpanContext).SpanID).To(BeZero())
+
+		tracer.StartSpan("request", opentracing.ChildOf(parent)).Finish()
+		tracer.Flush(context.Background())
+
+		spans := fakeClient.SpansByOperation("request")
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].GetSpanContext().GetTraceId()).To(Equal(uint64(0x2345678912345678)))
+		Expect(spans[0].GetReferences()).To(BeEmpty())
+	})
+
+	It("rejects invalid roots and parents", func() {
+		for _, header := range []string{
+			"Root=2-5759e988-bd862e3fe1be46a994272793",
+			"Root=1-5759e988-bd862e3fe1be46a99427279",
+			"Root=1-5759e98g-bd862e3fe1be46a994272793",
+			"Root=1-5759e988-bd862e3f0000000000000000",
+			"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=0000000000000000",
+			"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad",
+		} {
+			carrier := opentracing.TextMapCarrier{"x-amzn-trace-id": header}
+			_, err := tracer.Extract(opentracing.TextMap, carrier)
+			Expect(err).To(Equal(opentracing.ErrSpanContextCorrupted), header)
+		}
+	})
+})
@@ -0,0 +1,175 @@
+// Package propagationtest holds golden vectors of LightStep span contexts,
+// in the TextMap, HTTPHeaders and Binary formats shared by the LightStep
+// tracers for Go, Java, Python and Node, and a conformance check of a
+// tracer's Inject and Extract against them. Use it to verify that a tracer,
+// or a custom propagator wrapped around one, interoperates with services
+// traced in other languages.
+package propagationtest
+
+// Vector is a span context in the forms the LightStep tracers inject it.
+type Vector struct {
+	Name    string
+	TraceID uint64
+	SpanID  uint64
+	Baggage map[string]string
+	// Headers are the fields injected into TextMap and HTTPHeaders
+	// carriers, with lowercase keys and unpadded lowercase hexadecimal IDs.
+	Headers map[string]string
+	// Binary is the base64 encoded BinaryCarrier protobuf message
+	// injected into Binary carriers.
+	Binary string
+}
+
+// ExtractVector is a carrier that Inject doesn't produce but that Extract
+// must accept, such as headers whose keys were canonicalized by an HTTP
+// library. Extracting it and injecting the result gives Want.
+type ExtractVector struct {
+	Name    string
+	Headers map[string]string
+	Want    map[string]string
+}
+
+// CorruptedVector is a carrier that Extract must reject.
+type CorruptedVector struct {
+	Name    string
+	Headers map[string]string
+}
+
+// Vectors are span contexts and the carriers they are injected into.
+var Vectors = []Vector{
+	{
+		Name:    "typical",
+		TraceID: 0xa1b2c3d4e5f60718,
+		SpanID:  0x1234567890abcdef,
+		Headers: map[string]string{
+			"ot-tracer-traceid": "a1b2c3d4e5f60718",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "true",
+		},
+		Binary: "EhQJGAf25dTDsqER782rkHhWNBIYAQ==",
+	},
+	{
+		Name:    "maximum IDs",
+		TraceID: 0xffffffffffffffff,
+		SpanID:  0xffffffffffffffff,
+		Headers: map[string]string{
+			"ot-tracer-traceid": "ffffffffffffffff",
+			"ot-tracer-spanid":  "ffffffffffffffff",
+			"ot-tracer-sampled": "true",
+		},
+		Binary: "EhQJ//////////8R//////////8YAQ==",
+	},
+	{
+		Name:    "small IDs",
+		TraceID: 0x1,
+		SpanID:  0x2,
+		Headers: map[string]string{
+			"ot-tracer-traceid": "1",
+			"ot-tracer-spanid":  "2",
+			"ot-tracer-sampled": "true",
+		},
+		Binary: "EhQJAQAAAAAAAAARAgAAAAAAAAAYAQ==",
+	},
+	{
+		Name:    "baggage",
+		TraceID: 0x5e1fa7c0ffee4b1d,
+		SpanID:  0x3c9d2e1f0a4b5c6d,
+		Baggage: map[string]string{"user-id": "42", "region": "us-east-1"},
+		Headers: map[string]string{
+			"ot-tracer-traceid":  "5e1fa7c0ffee4b1d",
+			"ot-tracer-spanid":   "3c9d2e1f0a4b5c6d",
+			"ot-tracer-sampled":  "true",
+			"ot-baggage-user-id": "42",
+			"ot-baggage-region":  "us-east-1",
+		},
+		Binary: "EjgJHUvu/8CnH14RbVxLCh8unTwYASINCgd1c2VyLWlkEgI0MiITCgZyZWdpb24SCXVzLWVhc3QtMQ==",
+	},
+}
+
+// ExtractVectors are carriers produced by other tracers, proxies or HTTP
+// libraries, which Extract must accept.
+var ExtractVectors = []ExtractVector{
+	{
+		Name: "canonical HTTP header keys",
+		Headers: map[string]string{
+			"Ot-Tracer-Traceid":  "a1b2c3d4e5f60718",
+			"Ot-Tracer-Spanid":   "1234567890abcdef",
+			"Ot-Tracer-Sampled":  "true",
+			"Ot-Baggage-User-Id": "42",
+		},
+		Want: map[string]string{
+			"ot-tracer-traceid":  "a1b2c3d4e5f60718",
+			"ot-tracer-spanid":   "1234567890abcdef",
+			"ot-tracer-sampled":  "true",
+			"ot-baggage-user-id": "42",
+		},
+	},
+	{
+		Name: "zero padded uppercase IDs",
+		Headers: map[string]string{
+			"ot-tracer-traceid": "00000000000000AB",
+			"ot-tracer-spanid":  "00000000000000CD",
+			"ot-tracer-sampled": "true",
+		},
+		Want: map[string]string{
+			"ot-tracer-traceid": "ab",
+			"ot-tracer-spanid":  "cd",
+			"ot-tracer-sampled": "true",
+		},
+	},
+	{
+		Name: "unsampled",
+		Headers: map[string]string{
+			"ot-tracer-traceid": "a1b2c3d4e5f60718",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "false",
+		},
+		Want: map[string]string{
+			"ot-tracer-traceid": "a1b2c3d4e5f60718",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "true",
+		},
+	},
+	{
+		Name: "unrelated headers",
+		Headers: map[string]string{
+			"ot-tracer-traceid": "a1b2c3d4e5f60718",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "true",
+			"content-type":      "application/json",
+			"x-request-id":      "f00d",
+		},
+		Want: map[string]string{
+			"ot-tracer-traceid": "a1b2c3d4e5f60718",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "true",
+		},
+	},
+}
+
+// CorruptedVectors are carriers with a partial or malformed span context.
+var CorruptedVectors = []CorruptedVector{
+	{
+		Name: "missing span ID",
+		Headers: map[string]string{
+			"ot-tracer-traceid": "a1b2c3d4e5f60718",
+			"ot-tracer-sampled": "true",
+		},
+	},
+	{
+		Name: "non-hexadecimal trace ID",
+		Headers: map[string]string{
+			"ot-tracer-traceid": "not-a-trace-id",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "true",
+		},
+	},
+	{
+		Name: "trace ID over 128 bits",
+		Headers: map[string]string{
+			"ot-tracer-traceid": "1a1b2c3d4e5f60718a1b2c3d4e5f60718",
+			"ot-tracer-spanid":  "1234567890abcdef",
+			"ot-tracer-sampled": "true",
+		},
+	},
+}
@@ -0,0 +1,169 @@
+package propagationtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	lightstep "github.com/lightstep/lightstep-tracer-go/lightsteppb"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// Verify checks the Inject and Extract methods of tracer against Vectors,
+// ExtractVectors and CorruptedVectors, and returns an error for each
+// mismatch. Binary carriers are checked as the Go tracer carries them: an
+// io.Reader or io.Writer of the base64 encoded message. Inject must write
+// exactly the fields of a vector, so a propagator that adds the fields of
+// other formats should be verified on its own.
+func Verify(tracer opentracing.Tracer) []error {
+	var errs []error
+	fail := func(name, format string, err error) {
+		errs = append(errs, fmt.Errorf("%s (%s): %v", name, format, err))
+	}
+
+	for _, v := range Vectors {
+		sc, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(copyHeaders(v.Headers)))
+		if err == nil {
+			err = checkContext(tracer, sc, v.Headers, v.Baggage)
+		}
+		if err != nil {
+			fail(v.Name, "TextMap", err)
+		}
+
+		header := http.Header{}
+		for key, value := range v.Headers {
+			header.Set(key, value)
+		}
+		sc, err = tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+		if err == nil {
+			err = checkHTTPHeaders(tracer, sc, v.Headers)
+		}
+		if err != nil {
+			fail(v.Name, "HTTPHeaders", err)
+		}
+
+		sc, err = tracer.Extract(opentracing.Binary, strings.NewReader(v.Binary))
+		if err == nil {
+			err = checkContext(tracer, sc, v.Headers, v.Baggage)
+		}
+		if err == nil {
+			err = checkBinary(tracer, sc, v)
+		}
+		if err != nil {
+			fail(v.Name, "Binary", err)
+		}
+	}
+
+	for _, v := range ExtractVectors {
+		sc, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(copyHeaders(v.Headers)))
+		if err == nil {
+			err = checkContext(tracer, sc, v.Want, nil)
+		}
+		if err != nil {
+			fail(v.Name, "TextMap", err)
+		}
+	}
+
+	for _, v := range CorruptedVectors {
+		if _, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(copyHeaders(v.Headers))); err == nil {
+			fail(v.Name, "TextMap", fmt.Errorf("Extract accepted a corrupted span context"))
+		}
+	}
+
+	empty := opentracing.TextMapCarrier{"content-type": "application/json"}
+	if _, err := tracer.Extract(opentracing.TextMap, empty); err != opentracing.ErrSpanContextNotFound {
+		fail("no span context", "TextMap", fmt.Errorf("Extract returned %v, want %v", err, opentracing.ErrSpanContextNotFound))
+	}
+
+	return errs
+}
+
+// checkContext checks that sc is injected into a TextMap carrier as want,
+// and that it carries baggage, if it's not nil.
+func checkContext(tracer opentracing.Tracer, sc opentracing.SpanContext, want, baggage map[string]string) error {
+	carrier := opentracing.TextMapCarrier{}
+	if err := tracer.Inject(sc, opentracing.TextMap, carrier); err != nil {
+		return fmt.Errorf("Inject: %v", err)
+	}
+	if !reflect.DeepEqual(map[string]string(carrier), want) {
+		return fmt.Errorf("injected %v, want %v", carrier, want)
+	}
+	if baggage == nil {
+		return nil
+	}
+	got := map[string]string{}
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		got[k] = v
+		return true
+	})
+	if !reflect.DeepEqual(got, baggage) {
+		return fmt.Errorf("extracted baggage %v, want %v", got, baggage)
+	}
+	return nil
+}
+
+// checkHTTPHeaders checks that sc is injected into HTTP headers as want,
+// ignoring the case of their keys.
+func checkHTTPHeaders(tracer opentracing.Tracer, sc opentracing.SpanContext, want map[string]string) error {
+	header := http.Header{}
+	if err := tracer.Inject(sc, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header)); err != nil {
+		return fmt.Errorf("Inject: %v", err)
+	}
+	got := map[string]string{}
+	for key := range header {
+		got[strings.ToLower(key)] = header.Get(key)
+	}
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("injected %v, want %v", got, want)
+	}
+	return nil
+}
+
+// checkBinary checks that sc is injected into a Binary carrier as the
+// message encoded by v.Binary. Messages are compared decoded, as the order
+// of their baggage items isn't specified.
+func checkBinary(tracer opentracing.Tracer, sc opentracing.SpanContext, v Vector) error {
+	var buf bytes.Buffer
+	if err := tracer.Inject(sc, opentracing.Binary, &buf); err != nil {
+		return fmt.Errorf("Inject: %v", err)
+	}
+	got, err := decodeBinary(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("injected an invalid message: %v", err)
+	}
+	want, err := decodeBinary([]byte(v.Binary))
+	if err != nil {
+		return fmt.Errorf("invalid vector: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		return fmt.Errorf("injected %v, want %v", got, want)
+	}
+	return nil
+}
+
+func decodeBinary(encoded []byte) (*lightstep.BinaryCarrier, error) {
+	data, err := ioutil.ReadAll(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(encoded)))
+	if err != nil {
+		return nil, err
+	}
+	carrier := &lightstep.BinaryCarrier{}
+	if err := proto.Unmarshal(data, carrier); err != nil {
+		return nil, err
+	}
+	return carrier, nil
+}
+
+// copyHeaders copies a vector's headers, so that a tracer modifying its
+// carrier doesn't change the vector.
+func copyHeaders(headers map[string]string) map[string]string {
+	copied := make(map[string]string, len(headers))
+	for key, value := range headers {
+		copied[key] = value
+	}
+	return copied
+}
@@ -2,7 +2,7 @@ package lightstep
 
 import (
 	"fmt"
-	"reflect"
+	"strconv"
 	"time"
 
 	google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
@@ -10,17 +10,30 @@ import (
 	ot "github.com/opentracing/opentracing-go"
 )
 
+const (
+	spansDropped     = "spans.dropped"
+	logEncoderErrors = "log_encoder.errors"
+)
+
 type protoConverter struct {
-	verbose        bool
-	maxLogKeyLen   int // see GrpcOptions.MaxLogKeyLen
-	maxLogValueLen int // see GrpcOptions.MaxLogValueLen
+	verbose               bool
+	maxLogKeyLen          int // see GrpcOptions.MaxLogKeyLen
+	maxLogValueLen        int // see GrpcOptions.MaxLogValueLen
+	truncator             logTruncator
+	reportTruncatedLength bool     // see LogTruncationOptions.ReportLength
+	formatValues          bool     // see Options.FormatValues
+	indexedTags           []string // see Options.IndexedTags
 }
 
 func newProtoConverter(options Options) *protoConverter {
 	return &protoConverter{
-		verbose:        options.Verbose,
-		maxLogKeyLen:   options.MaxLogKeyLen,
-		maxLogValueLen: options.MaxLogValueLen,
+		verbose:               options.Verbose,
+		maxLogKeyLen:          options.MaxLogKeyLen,
+		maxLogValueLen:        options.MaxLogValueLen,
+		truncator:             newLogTruncator(options.LogTruncation),
+		reportTruncatedLength: options.LogTruncation.ReportLength,
+		formatValues:          options.FormatValues,
+		indexedTags:           options.IndexedTags,
 	}
 }
 
@@ -30,13 +43,16 @@ func (converter *protoConverter) toReportRequest(
 	accessToken string,
 	buffer *reportBuffer,
 ) *cpb.ReportRequest {
-	return &cpb.ReportRequest{
-		Reporter:        converter.toReporter(reporterId, attributes),
+	req := &cpb.ReportRequest{
+		Reporter:        converter.toReporter(reporterId, buffer.reportAttributes(attributes)),
 		Auth:            converter.toAuth(accessToken),
 		Spans:           converter.toSpans(buffer),
 		InternalMetrics: converter.toInternalMetrics(buffer),
 	}
-
+	if buffer.tagDictionary {
+		applyTagDictionary(req)
+	}
+	return req
 }
 
 func (converter *protoConverter) toReporter(reporterId uint64, attributes map[string]string) *cpb.Reporter {
@@ -61,14 +77,24 @@ func (converter *protoConverter) toSpans(buffer *reportBuffer) []*cpb.Span {
 }
 
 func (converter *protoConverter) toSpan(span RawSpan, buffer *reportBuffer) *cpb.Span {
+	tags := converter.fromTags(span.Tags)
+	if span.Context.TraceIDHigh != 0 {
+		// The collector protocol has 64-bit trace IDs; see TraceIDHighKey.
+		tags = append(tags, converter.toField(TraceIDHighKey, strconv.FormatUint(span.Context.TraceIDHigh, 16)))
+	}
+	if buffer.indexedTags {
+		if field := converter.indexedTagsField(&span); field != nil {
+			tags = append(tags, field)
+		}
+	}
 	return &cpb.Span{
 		SpanContext:    converter.toSpanContext(&span.Context),
 		OperationName:  span.Operation,
-		References:     converter.toReference(span.ParentSpanID),
+		References:     converter.toReferences(span),
 		StartTimestamp: converter.toTimestamp(span.Start),
 		DurationMicros: converter.fromDuration(span.Duration),
-		Tags:           converter.fromTags(span.Tags),
-		Logs:           converter.toLogs(span.Logs, buffer),
+		Tags:           tags,
+		Logs:           converter.toLogs(span.Logs, span.Context, buffer),
 	}
 }
 
@@ -103,17 +129,45 @@ func (converter *protoConverter) fromTags(tags ot.Tags) []*cpb.KeyValue {
 
 func (converter *protoConverter) toField(key string, value interface{}) *cpb.KeyValue {
 	field := cpb.KeyValue{Key: key}
-	reflectedValue := reflect.ValueOf(value)
-	switch reflectedValue.Kind() {
-	case reflect.String:
-		field.Value = &cpb.KeyValue_StringValue{StringValue: reflectedValue.String()}
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		field.Value = &cpb.KeyValue_IntValue{IntValue: reflectedValue.Convert(intType).Int()}
-	case reflect.Float32, reflect.Float64:
-		field.Value = &cpb.KeyValue_DoubleValue{DoubleValue: reflectedValue.Float()}
-	case reflect.Bool:
-		field.Value = &cpb.KeyValue_BoolValue{BoolValue: reflectedValue.Bool()}
+	if converter.formatValues {
+		if formatted, ok := formatValue(value); ok {
+			value = formatted
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		field.Value = &cpb.KeyValue_StringValue{StringValue: value}
+	case int:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case int8:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case int16:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case int32:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case int64:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: value}
+	case uint:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case uint8:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case uint16:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case uint32:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case uint64:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: int64(value)}
+	case float32:
+		field.Value = &cpb.KeyValue_DoubleValue{DoubleValue: float64(value)}
+	case float64:
+		field.Value = &cpb.KeyValue_DoubleValue{DoubleValue: value}
+	case bool:
+		field.Value = &cpb.KeyValue_BoolValue{BoolValue: value}
 	default:
+		// Named types, such as ext.SpanKindEnum, are converted by kind.
+		if setReflectedValue(&field, value) {
+			break
+		}
 		var s string
 		switch value := value.(type) {
 		case fmt.Stringer:
@@ -129,19 +183,20 @@ func (converter *protoConverter) toField(key string, value interface{}) *cpb.Key
 	return &field
 }
 
-func (converter *protoConverter) toLogs(records []ot.LogRecord, buffer *reportBuffer) []*cpb.Log {
+// toLogs converts the log records of the span sc identifies.
+func (converter *protoConverter) toLogs(records []ot.LogRecord, sc SpanContext, buffer *reportBuffer) []*cpb.Log {
 	logs := make([]*cpb.Log, len(records))
 	for i, record := range records {
-		logs[i] = converter.toLog(record, buffer)
+		logs[i] = converter.toLog(record, sc, buffer)
 	}
 	return logs
 }
 
-func (converter *protoConverter) toLog(record ot.LogRecord, buffer *reportBuffer) *cpb.Log {
+func (converter *protoConverter) toLog(record ot.LogRecord, sc SpanContext, buffer *reportBuffer) *cpb.Log {
 	log := &cpb.Log{
 		Timestamp: converter.toTimestamp(record.Timestamp),
 	}
-	marshalFields(converter, log, record.Fields, buffer)
+	marshalFields(converter, log, record.Fields, sc, buffer)
 	return log
 }
 
@@ -161,16 +216,45 @@ func (converter *protoConverter) toSpanContext(sc *SpanContext) *cpb.SpanContext
 	}
 }
 
-func (converter *protoConverter) toReference(parentSpanId uint64) []*cpb.Reference {
-	if parentSpanId == 0 {
+// toReferences returns the span's References, its parent's first. A parent
+// set by SetParentSpanID, outside of the References, is a ChildOf
+// reference. References to a trace rather than a span, such as an X-Ray root
+// without a parent, are omitted.
+func (converter *protoConverter) toReferences(span RawSpan) []*cpb.Reference {
+	parent := span.parentReference()
+	if span.ParentSpanID == 0 && len(span.References) == 0 {
 		return nil
 	}
-	return []*cpb.Reference{
-		{
+	refs := make([]*cpb.Reference, 0, len(span.References)+1)
+	if parent < 0 && span.ParentSpanID != 0 {
+		refs = append(refs, &cpb.Reference{
 			Relationship: cpb.Reference_CHILD_OF,
 			SpanContext: &cpb.SpanContext{
-				SpanId: parentSpanId,
+				SpanId: span.ParentSpanID,
 			},
+		})
+	}
+	if parent >= 0 && span.References[parent].SpanID != 0 {
+		refs = append(refs, converter.toReference(span.References[parent]))
+	}
+	for i, ref := range span.References {
+		if i != parent && ref.SpanID != 0 {
+			refs = append(refs, converter.toReference(ref))
+		}
+	}
+	return refs
+}
+
+func (converter *protoConverter) toReference(ref SpanReference) *cpb.Reference {
+	relationship := cpb.Reference_CHILD_OF
+	if ref.Type == ot.FollowsFromRef {
+		relationship = cpb.Reference_FOLLOWS_FROM
+	}
+	return &cpb.Reference{
+		Relationship: relationship,
+		SpanContext: &cpb.SpanContext{
+			TraceId: ref.TraceID,
+			SpanId:  ref.SpanID,
 		},
 	}
 }
@@ -0,0 +1,24 @@
+//go:build lightstep_constrained
+// +build lightstep_constrained
+
+package lightstep
+
+import (
+	"github.com/opentracing/opentracing-go/ext"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+)
+
+// setReflectedValue sets the value of field to the values of named types
+// that the tracer itself records. Binaries built with the
+// lightstep_constrained tag don't convert other named types by reflection;
+// they're reported by their String method or as unsupported values.
+func setReflectedValue(field *cpb.KeyValue, value interface{}) bool {
+	switch value := value.(type) {
+	case ext.SpanKindEnum:
+		field.Value = &cpb.KeyValue_StringValue{StringValue: string(value)}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+//go:build !lightstep_constrained
+// +build !lightstep_constrained
+
+package lightstep
+
+import (
+	"reflect"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+)
+
+var (
+	intType = reflect.TypeOf(int64(0))
+)
+
+// setReflectedValue sets the value of field to a value whose type is
+// named, but whose kind is a string, number or bool. It returns false for
+// values of other kinds.
+func setReflectedValue(field *cpb.KeyValue, value interface{}) bool {
+	reflectedValue := reflect.ValueOf(value)
+	switch reflectedValue.Kind() {
+	case reflect.String:
+		field.Value = &cpb.KeyValue_StringValue{StringValue: reflectedValue.String()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.Value = &cpb.KeyValue_IntValue{IntValue: reflectedValue.Convert(intType).Int()}
+	case reflect.Float32, reflect.Float64:
+		field.Value = &cpb.KeyValue_DoubleValue{DoubleValue: reflectedValue.Float()}
+	case reflect.Bool:
+		field.Value = &cpb.KeyValue_BoolValue{BoolValue: reflectedValue.Bool()}
+	default:
+		return false
+	}
+	return true
+}
@@ -11,28 +11,58 @@ const (
 	ellipsis = "…"
 )
 
+// SpanLogEncoder is the log.Encoder that the LazyLogger of a log.Lazy field
+// is given, to encode fields of the span they are logged on, such as its
+// IDs, without capturing the span:
+//
+//	span.LogFields(log.Lazy(func(fv log.Encoder) {
+//		if encoder, ok := fv.(lightstep.SpanLogEncoder); ok {
+//			fv.EmitString("span_id", fmt.Sprintf("%x", encoder.SpanContext().SpanID))
+//		}
+//	}))
+//
+// The LazyLogger runs when the span is reported, on the tracer's goroutine.
+type SpanLogEncoder interface {
+	log.Encoder
+	// SpanContext is the context of the span the fields are logged on.
+	SpanContext() SpanContext
+}
+
 // An implementation of the log.Encoder interface
 type grpcLogFieldEncoder struct {
 	converter       *protoConverter
+	spanContext     SpanContext
 	buffer          *reportBuffer
 	currentKeyValue *cpb.KeyValue
+	// truncatedLength is the length of the current value, if it was
+	// truncated.
+	truncatedLength int
 }
 
 func marshalFields(
 	converter *protoConverter,
 	protoLog *cpb.Log,
 	fields []log.Field,
+	spanContext SpanContext,
 	buffer *reportBuffer,
 ) {
 	logFieldEncoder := grpcLogFieldEncoder{
-		converter: converter,
-		buffer:    buffer,
+		converter:   converter,
+		spanContext: spanContext,
+		buffer:      buffer,
 	}
-	protoLog.Fields = make([]*cpb.KeyValue, len(fields))
-	for i, field := range fields {
+	protoLog.Fields = make([]*cpb.KeyValue, 0, len(fields))
+	for _, field := range fields {
 		logFieldEncoder.currentKeyValue = &cpb.KeyValue{}
+		logFieldEncoder.truncatedLength = 0
 		field.Marshal(&logFieldEncoder)
-		protoLog.Fields[i] = logFieldEncoder.currentKeyValue
+		protoLog.Fields = append(protoLog.Fields, logFieldEncoder.currentKeyValue)
+		if logFieldEncoder.truncatedLength > 0 && converter.reportTruncatedLength {
+			protoLog.Fields = append(protoLog.Fields, &cpb.KeyValue{
+				Key:   logFieldEncoder.currentKeyValue.Key + LogTruncatedLengthSuffix,
+				Value: &cpb.KeyValue_IntValue{int64(logFieldEncoder.truncatedLength)},
+			})
+		}
 	}
 }
 
@@ -74,6 +104,16 @@ func (lfe *grpcLogFieldEncoder) EmitFloat64(key string, value float64) {
 }
 func (lfe *grpcLogFieldEncoder) EmitObject(key string, value interface{}) {
 	lfe.emitSafeKey(key)
+	if lfe.converter.formatValues {
+		switch formatted, _ := formatValue(value); formatted := formatted.(type) {
+		case string:
+			lfe.emitSafeString(formatted)
+			return
+		case float64:
+			lfe.currentKeyValue.Value = &cpb.KeyValue_DoubleValue{formatted}
+			return
+		}
+	}
 	jsonBytes, err := json.Marshal(value)
 	if err != nil {
 		emitEvent(newEventUnsupportedValue(key, value, err))
@@ -88,21 +128,25 @@ func (lfe *grpcLogFieldEncoder) EmitLazyLogger(value log.LazyLogger) {
 	value(lfe)
 }
 
+// SpanContext implements SpanLogEncoder.
+func (lfe *grpcLogFieldEncoder) SpanContext() SpanContext {
+	return lfe.spanContext
+}
+
 func (lfe *grpcLogFieldEncoder) emitSafeKey(key string) {
-	if len(key) > lfe.converter.maxLogKeyLen {
-		key = key[:(lfe.converter.maxLogKeyLen-1)] + ellipsis
-	}
+	key, _ = lfe.converter.truncator.truncate(key, lfe.converter.maxLogKeyLen)
 	lfe.currentKeyValue.Key = key
 }
 func (lfe *grpcLogFieldEncoder) emitSafeString(str string) {
-	if len(str) > lfe.converter.maxLogValueLen {
-		str = str[:(lfe.converter.maxLogValueLen-1)] + ellipsis
+	if truncated, ok := lfe.converter.truncator.truncate(str, lfe.converter.maxLogValueLen); ok {
+		lfe.truncatedLength = len(str)
+		str = truncated
 	}
 	lfe.currentKeyValue.Value = &cpb.KeyValue_StringValue{str}
 }
 func (lfe *grpcLogFieldEncoder) emitSafeJSON(json string) {
-	if len(json) > lfe.converter.maxLogValueLen {
-		str := json[:(lfe.converter.maxLogValueLen-1)] + ellipsis
+	if str, ok := lfe.converter.truncator.truncate(json, lfe.converter.maxLogValueLen); ok {
+		lfe.truncatedLength = len(json)
 		lfe.currentKeyValue.Value = &cpb.KeyValue_StringValue{str}
 		return
 	}
@@ -0,0 +1,59 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+var _ = Describe("SpanLogEncoder", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+		})
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("gives lazy loggers the context of their span", func() {
+		span := tracer.StartSpan("lazy")
+		span.LogFields(log.Lazy(func(fv log.Encoder) {
+			if encoder, ok := fv.(SpanLogEncoder); ok {
+				sc := encoder.SpanContext()
+				fv.EmitString("correlation_id", fmt.Sprintf("%x-%x", sc.TraceID, sc.SpanID))
+			}
+		}))
+		span.Finish()
+		tracer.Flush(context.Background())
+
+		sc := span.Context().(SpanContext)
+		spans := fakeClient.SpansByOperation("lazy")
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].GetLogs()).To(HaveLen(1))
+		Expect(spans[0].GetLogs()[0].GetFields()).To(Equal([]*cpb.KeyValue{{
+			Key:   "correlation_id",
+			Value: &cpb.KeyValue_StringValue{StringValue: fmt.Sprintf("%x-%x", sc.TraceID, sc.SpanID)},
+		}}))
+	})
+})
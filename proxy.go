@@ -0,0 +1,181 @@
+package lightstep
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// validateProxyURL checks that rawURL, if set, names an HTTP or HTTPS
+// proxy.
+func validateProxyURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	proxy, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("Options invalid: ProxyURL: %v", err)
+	}
+	if (proxy.Scheme != "http" && proxy.Scheme != "https") || proxy.Host == "" {
+		return fmt.Errorf("Options invalid: ProxyURL %q must be an http or https URL", rawURL)
+	}
+	return nil
+}
+
+// proxyDialer opens connections to collectors, tunneling them with CONNECT
+// requests through the proxy chosen for them, if any.
+type proxyDialer struct {
+	// proxy returns the proxy for a request, or nil to connect directly,
+	// as http.Transport.Proxy does.
+	proxy func(*http.Request) (*url.URL, error)
+	// scheme is the scheme of the collector's URL, which selects the
+	// HTTPS_PROXY or HTTP_PROXY environment variable.
+	scheme  string
+	timeout time.Duration
+	// balancer chooses the address of direct connections, if
+	// Options.LoadBalancing is set.
+	balancer *addressBalancer
+}
+
+// newProxyDialer returns the dialer of connections to collectors at URLs
+// with scheme. It uses opts.ProxyURL if it is set, and otherwise the
+// proxies named by the environment.
+func newProxyDialer(opts Options, scheme string) proxyDialer {
+	dialer := proxyDialer{
+		proxy:    http.ProxyFromEnvironment,
+		scheme:   scheme,
+		timeout:  opts.ReportTimeout,
+		balancer: newAddressBalancer(opts.LoadBalancing),
+	}
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		dialer.proxy = func(*http.Request) (*url.URL, error) {
+			return proxy, err
+		}
+	}
+	return dialer
+}
+
+// dial connects to addr, through a proxy if one is chosen for it, within
+// timeout if it is positive. It has the signature of grpc.WithDialer.
+func (d proxyDialer) dial(addr string, timeout time.Duration) (net.Conn, error) {
+	proxy, err := d.proxy(&http.Request{URL: &url.URL{Scheme: d.scheme, Host: addr}})
+	if err != nil {
+		return nil, err
+	}
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	dialer := net.Dialer{Deadline: deadline}
+	if proxy == nil {
+		if d.balancer != nil {
+			return d.balancer.dial(dialer, addr)
+		}
+		return dialer.Dial("tcp", addr)
+	}
+
+	proxyAddr := proxy.Host
+	switch {
+	case proxy.Port() != "":
+	case proxy.Scheme == "http":
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), "80")
+	case proxy.Scheme == "https":
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), "443")
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxy.Scheme)
+	}
+	conn, err := dialer.Dial("tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(deadline)
+	if proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxy.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if conn, err = connectThroughProxy(conn, addr, proxy); err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// dialTLS connects to addr with TLS for the HTTP/2 transport, which doesn't
+// support proxies itself.
+func (d proxyDialer) dialTLS(network, addr string, config *tls.Config) (net.Conn, error) {
+	conn, err := d.dial(addr, d.timeout)
+	if err != nil {
+		return nil, err
+	}
+	if d.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(d.timeout))
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	if protocol := tlsConn.ConnectionState().NegotiatedProtocol; protocol != "h2" {
+		tlsConn.Close()
+		return nil, fmt.Errorf("collector negotiated protocol %q instead of h2", protocol)
+	}
+	return tlsConn, nil
+}
+
+// connectThroughProxy asks the proxy connected to by conn to tunnel it to
+// addr, closing conn if it refuses.
+func connectThroughProxy(conn net.Conn, addr string, proxy *url.URL) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %v refused to connect to %v: %v", proxy.Host, addr, resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads the bytes the collector sent along with the proxy's
+// response before those still on the connection.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -12,16 +12,26 @@ type RawSpan struct {
 	// SpanContext.
 	Context SpanContext
 
-	// The SpanID of this SpanContext's first intra-trace reference (i.e.,
-	// "parent"), or 0 if there is no parent.
+	// The SpanID of this SpanContext's parent, or 0 if there is no parent.
+	// When a span is started with References, its parent is the first
+	// ChildOf reference, or if there is none, the first FollowsFrom
+	// reference.
 	ParentSpanID uint64
 
+	// References are the span's ChildOf and FollowsFrom references to
+	// LightStep span contexts, in the order they were given, including
+	// its parent's.
+	References []SpanReference
+
 	// The name of the "operation" this span is an instance of. (Called a "span
 	// name" in some implementations)
 	Operation string
 
 	// We store <start, duration> rather than <start, end> so that only
-	// one of the timestamps has global clock uncertainty issues.
+	// one of the timestamps has global clock uncertainty issues. Start is a
+	// wall clock time, without a monotonic clock reading. Duration is
+	// measured on the monotonic clock, unless the span was given an
+	// explicit start or finish time, and is never negative.
 	Start    time.Time
 	Duration time.Duration
 
@@ -31,18 +41,67 @@ type RawSpan struct {
 
 	// The span's "microlog".
 	Logs []opentracing.LogRecord
+
+	// Destination names the Options.Destinations entry the span is
+	// reported to, or is empty if it is reported to the tracer's
+	// collector. See the Destination start option.
+	Destination string
+
+	// finishHandle is set for spans finished with FinishAsync.
+	finishHandle *finishHandle
+
+	// mustDeliver is set for spans started with the MustDeliver option.
+	mustDeliver bool
+
+	// indexedTags are the keys of the tags set by IndexedTag or
+	// SetIndexedTag.
+	indexedTags []string
+
+	// memoryBytes is the estimated memory of the span, set while it is
+	// buffered by a tracer with Options.MaxMemoryBytes.
+	memoryBytes int
+}
+
+// FinishTime returns the wall clock time the span finished, as implied by
+// its Start and Duration.
+func (r RawSpan) FinishTime() time.Time {
+	return r.Start.Add(r.Duration)
 }
 
 // SpanContext holds lightstep-specific Span metadata.
+//
+// A SpanContext is immutable once it is in use: its copies share its
+// Baggage map and its serialized forms, and are read concurrently by the
+// goroutines a context is passed to. Setting a span's baggage replaces the
+// span's context with a new one, leaving the contexts already returned by
+// Span.Context unchanged. Use WithBaggageItem or WithBaggage to derive a
+// context with other baggage, rather than modifying Baggage.
 type SpanContext struct {
 	// A probabilistically unique identifier for a [multi-span] trace.
 	TraceID uint64
 
+	// TraceIDHigh is the upper half of a 128-bit trace ID, whose lower
+	// half is TraceID, or zero for a 64-bit trace ID. See
+	// Options.TraceID128Bit.
+	TraceIDHigh uint64
+
 	// A probabilistically unique identifier for a span.
 	SpanID uint64
 
-	// The span's associated baggage.
-	Baggage map[string]string // initialized on first use
+	// The span's associated baggage. It must not be modified, see
+	// WithBaggageItem.
+	Baggage map[string]string
+
+	// passThrough holds the carrier fields matched by Options.PassThroughKeys
+	// on Extract, to be injected unchanged.
+	passThrough map[string]string
+
+	// traceState is the tracestate of a context extracted in the W3C Trace
+	// Context format, to be injected unchanged, see PropagatorTraceContext.
+	traceState string
+
+	// cache holds the serialized forms of the context, see carrierCache.
+	cache *carrierCache
 }
 
 // ForeachBaggageItem belongs to the opentracing.SpanContext interface
@@ -57,16 +116,20 @@ func (c SpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
 // WithBaggageItem returns an entirely new basictracer SpanContext with the
 // given key:value baggage pair set.
 func (c SpanContext) WithBaggageItem(key, val string) SpanContext {
-	var newBaggage map[string]string
-	if c.Baggage == nil {
-		newBaggage = map[string]string{key: val}
-	} else {
-		newBaggage = make(map[string]string, len(c.Baggage)+1)
-		for k, v := range c.Baggage {
-			newBaggage[k] = v
-		}
-		newBaggage[key] = val
+	return c.WithBaggage(map[string]string{key: val})
+}
+
+// WithBaggage returns a copy of the SpanContext with the given baggage items
+// set, replacing the items of the same keys. Neither c nor items are
+// modified, and the copy doesn't share items.
+func (c SpanContext) WithBaggage(items map[string]string) SpanContext {
+	newBaggage := make(map[string]string, len(c.Baggage)+len(items))
+	for k, v := range c.Baggage {
+		newBaggage[k] = v
+	}
+	for k, v := range items {
+		newBaggage[k] = v
 	}
 	// Use positional parameters so the compiler will help catch new fields.
-	return SpanContext{c.TraceID, c.SpanID, newBaggage}
+	return SpanContext{c.TraceID, c.TraceIDHigh, c.SpanID, newBaggage, c.passThrough, c.traceState, &carrierCache{}}
 }
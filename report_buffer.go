@@ -7,20 +7,46 @@ import (
 type reportBuffer struct {
 	rawSpans             []RawSpan
 	droppedSpanCount     int64
+	expiredSpanCount     int64
 	logEncoderErrorCount int64
 	reportStart          time.Time
 	reportEnd            time.Time
+
+	// rawSpans holds up to maxSpans regular spans. The rest of its capacity
+	// is reserved for must-deliver spans, counted by prioritySpanCount.
+	maxSpans          int
+	prioritySpanCount int
+
+	// memoryBytes is the estimated memory of rawSpans, see
+	// Options.MaxMemoryBytes.
+	memoryBytes int
+
+	// bufferedSince is when the oldest of rawSpans was buffered, see
+	// ManualReportsOptions.Deadline.
+	bufferedSince time.Time
+
+	// attributes are reporter attributes sent with this report only, in
+	// addition to the ones sent with every report.
+	attributes map[string]string
+
+	// tagDictionary encodes this report with a tag dictionary, see
+	// Options.ExperimentalTagDictionary.
+	tagDictionary bool
+
+	// indexedTags lists the indexed tags of each span, see IndexedTagsKey.
+	indexedTags bool
 }
 
-func newSpansBuffer(size int) (b reportBuffer) {
-	b.rawSpans = make([]RawSpan, 0, size)
+func newSpansBuffer(size, prioritySize int) (b reportBuffer) {
+	b.rawSpans = make([]RawSpan, 0, size+prioritySize)
+	b.maxSpans = size
 	b.reportStart = time.Time{}
 	b.reportEnd = time.Time{}
 	return
 }
 
 func (b *reportBuffer) isHalfFull() bool {
-	return len(b.rawSpans) > cap(b.rawSpans)/2
+	return len(b.rawSpans)-b.prioritySpanCount > b.maxSpans/2
 }
 
 func (b *reportBuffer) setCurrent(now time.Time) {
@@ -37,15 +63,110 @@ func (b *reportBuffer) clear() {
 	b.reportStart = time.Time{}
 	b.reportEnd = time.Time{}
 	b.droppedSpanCount = 0
+	b.expiredSpanCount = 0
 	b.logEncoderErrorCount = 0
+	b.prioritySpanCount = 0
+	b.memoryBytes = 0
+	b.bufferedSince = time.Time{}
+	b.attributes = nil
+	b.tagDictionary = false
+	b.indexedTags = false
+}
+
+// reportAttributes returns attributes combined with the buffer's own.
+func (b *reportBuffer) reportAttributes(attributes map[string]string) map[string]string {
+	if len(b.attributes) == 0 {
+		return attributes
+	}
+	combined := make(map[string]string, len(attributes)+len(b.attributes))
+	for k, v := range attributes {
+		combined[k] = v
+	}
+	for k, v := range b.attributes {
+		combined[k] = v
+	}
+	return combined
 }
 
 func (b *reportBuffer) addSpan(span RawSpan) {
-	if len(b.rawSpans) == cap(b.rawSpans) {
+	if !b.tryAddSpan(span) {
 		b.droppedSpanCount++
-		return
+		if span.finishHandle != nil {
+			span.finishHandle.resolve(ErrSpanDropped)
+		}
+	}
+}
+
+// tryAddSpan adds the span if there is room for it. Must-deliver spans may
+// use the whole buffer, regular spans only the first maxSpans slots.
+func (b *reportBuffer) tryAddSpan(span RawSpan) bool {
+	if len(b.rawSpans) == cap(b.rawSpans) {
+		return false
+	}
+	if span.mustDeliver {
+		b.prioritySpanCount++
+	} else if len(b.rawSpans)-b.prioritySpanCount >= b.maxSpans {
+		return false
+	}
+	if len(b.rawSpans) == 0 {
+		b.bufferedSince = time.Now()
 	}
 	b.rawSpans = append(b.rawSpans, span)
+	b.memoryBytes += span.memoryBytes
+	return true
+}
+
+// expire removes the spans that finished before deadline, except
+// must-deliver spans, counting them as expired and dropped, and returns
+// them.
+func (b *reportBuffer) expire(deadline time.Time) []RawSpan {
+	var expired []RawSpan
+	kept := b.rawSpans[:0]
+	for _, span := range b.rawSpans {
+		if span.mustDeliver || !span.Start.Add(span.Duration).Before(deadline) {
+			kept = append(kept, span)
+			continue
+		}
+		expired = append(expired, span)
+		b.memoryBytes -= span.memoryBytes
+	}
+	b.rawSpans = kept
+	b.expiredSpanCount += int64(len(expired))
+	b.droppedSpanCount += int64(len(expired))
+	return expired
+}
+
+// retainFailed keeps only the spans of a report that partially failed that
+// weren't sent, resolving the FinishHandles of the others, and clears the
+// report-level metrics if they were sent. It returns the number of spans
+// sent.
+func (b *reportBuffer) retainFailed(err *partialReportError) int {
+	failed := make([]bool, len(b.rawSpans))
+	for _, i := range err.failedSpans {
+		failed[i] = true
+	}
+	kept := b.rawSpans[:0]
+	for i, span := range b.rawSpans {
+		if failed[i] {
+			kept = append(kept, span)
+			continue
+		}
+		if span.finishHandle != nil {
+			span.finishHandle.resolve(nil)
+		}
+		if span.mustDeliver {
+			b.prioritySpanCount--
+		}
+		b.memoryBytes -= span.memoryBytes
+	}
+	sent := len(b.rawSpans) - len(kept)
+	b.rawSpans = kept
+	if err.metricsSent {
+		b.droppedSpanCount = 0
+		b.expiredSpanCount = 0
+		b.logEncoderErrorCount = 0
+	}
+	return sent
 }
 
 // mergeFrom combines the spans and metadata in `from` with `into`,
@@ -53,6 +174,7 @@ func (b *reportBuffer) addSpan(span RawSpan) {
 // combined data.
 func (into *reportBuffer) mergeFrom(from *reportBuffer) {
 	into.droppedSpanCount += from.droppedSpanCount
+	into.expiredSpanCount += from.expiredSpanCount
 	into.logEncoderErrorCount += from.logEncoderErrorCount
 	if from.reportStart.Before(into.reportStart) {
 		into.reportStart = from.reportStart
@@ -60,20 +182,21 @@ func (into *reportBuffer) mergeFrom(from *reportBuffer) {
 	if from.reportEnd.After(into.reportEnd) {
 		into.reportEnd = from.reportEnd
 	}
+	bufferedSince := from.bufferedSince
 
 	// Note: Somewhat arbitrarily dropping the spans that won't
-	// fit; could be more principled here to avoid bias.
-	have := len(into.rawSpans)
-	space := cap(into.rawSpans) - have
-	unreported := len(from.rawSpans)
-
-	if space > unreported {
-		space = unreported
+	// fit; could be more principled here to avoid bias. Must-deliver
+	// spans are merged first, so they are the last to be dropped.
+	for _, mustDeliver := range []bool{true, false} {
+		for _, span := range from.rawSpans {
+			if span.mustDeliver == mustDeliver {
+				into.addSpan(span)
+			}
+		}
+	}
+	if len(into.rawSpans) > 0 && !bufferedSince.IsZero() && bufferedSince.Before(into.bufferedSince) {
+		into.bufferedSince = bufferedSince
 	}
-
-	into.rawSpans = append(into.rawSpans, from.rawSpans[0:space]...)
-
-	into.droppedSpanCount += int64(unreported - space)
 
 	from.clear()
 }
@@ -0,0 +1,64 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FakeCollectorServiceClient report inspection", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeGrpcConnection(fakeClient),
+			Tags:        map[string]interface{}{ComponentNameKey: "inspector"},
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("returns nothing before a report", func() {
+		Expect(fakeClient.Reports()).To(BeEmpty())
+		Expect(fakeClient.Spans()).To(BeEmpty())
+		Expect(fakeClient.ReporterAttributes()).To(BeNil())
+	})
+
+	It("exposes reported spans and their tags", func() {
+		tracer.StartSpan("query").SetTag("rows", 3).SetTag("cached", true).Finish()
+		tracer.StartSpan("query").SetTag("table", "users").Finish()
+		tracer.StartSpan("render").SetTag("ratio", 0.5).Finish()
+		tracer.Flush(context.Background())
+
+		Expect(fakeClient.Reports()).To(HaveLen(1))
+		Expect(fakeClient.Spans()).To(HaveLen(3))
+
+		queries := fakeClient.SpansByOperation("query")
+		Expect(queries).To(HaveLen(2))
+		Expect(cpbfakes.SpanTags(queries[0])).To(Equal(map[string]interface{}{
+			"rows":   int64(3),
+			"cached": true,
+		}))
+		Expect(cpbfakes.SpanTags(queries[1])).To(HaveKeyWithValue("table", "users"))
+
+		renders := fakeClient.SpansByOperation("render")
+		Expect(renders).To(HaveLen(1))
+		Expect(cpbfakes.SpanTags(renders[0])).To(HaveKeyWithValue("ratio", 0.5))
+
+		Expect(fakeClient.ReporterAttributes()).To(HaveKeyWithValue(ComponentNameKey, "inspector"))
+	})
+})
@@ -0,0 +1,86 @@
+package lightstep
+
+import (
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latencyHistogram buckets. The
+// last bucket also counts every larger latency.
+var latencyBuckets = func() []time.Duration {
+	bounds := make([]time.Duration, 15)
+	for i := range bounds {
+		bounds[i] = time.Millisecond << uint(i)
+	}
+	return bounds
+}()
+
+// latencyHistogram counts report latencies in exponential buckets from 1ms
+// to ~16s.
+type latencyHistogram struct {
+	counts [15]int64
+	total  int64
+}
+
+func (h *latencyHistogram) observe(latency time.Duration) {
+	i := 0
+	for i < len(latencyBuckets)-1 && latency > latencyBuckets[i] {
+		i++
+	}
+	h.counts[i]++
+	h.total++
+}
+
+// quantile returns the upper bound of the bucket containing the q-th
+// quantile, or 0 if nothing has been observed.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(q * float64(h.total))
+	var seen int64
+	for i, count := range h.counts {
+		seen += count
+		if seen > rank {
+			return latencyBuckets[i]
+		}
+	}
+	return latencyBuckets[len(latencyBuckets)-1]
+}
+
+// minAdaptiveSamples is the number of reports observed before latencies are
+// compared to the median.
+const minAdaptiveSamples = 10
+
+// adaptReportingPeriod records the outcome of a report, and when
+// Options.AdaptiveReportingPeriod is set, doubles the reporting period for a
+// struggling collector, or shrinks it by a quarter for a healthy one, within
+// MinReportingPeriod and ReportingPeriod. A collector is struggling if the
+// report failed, took more than twice the median latency, or took more than
+// half of ReportTimeout. Must be called with tracer.lock held.
+func (tracer *tracerImpl) adaptReportingPeriod(latency time.Duration, err error) {
+	median := tracer.reportLatencies.quantile(0.5)
+	tracer.reportLatencies.observe(latency)
+
+	if !tracer.opts.AdaptiveReportingPeriod {
+		return
+	}
+
+	struggling := err != nil ||
+		latency > tracer.opts.ReportTimeout/2 ||
+		(tracer.reportLatencies.total > minAdaptiveSamples && latency > 2*median)
+
+	period := tracer.reportingPeriod
+	if struggling {
+		period *= 2
+	} else {
+		period -= period / 4
+	}
+
+	if period > tracer.opts.ReportingPeriod {
+		period = tracer.opts.ReportingPeriod
+	}
+	if period < tracer.opts.MinReportingPeriod {
+		period = tracer.opts.MinReportingPeriod
+	}
+	tracer.reportingPeriod = period
+}
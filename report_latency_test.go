@@ -0,0 +1,75 @@
+package lightstep
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("latencyHistogram", func() {
+	It("reports the bucket containing a quantile", func() {
+		var h latencyHistogram
+		Expect(h.quantile(0.5)).To(BeZero())
+
+		for i := 0; i < 9; i++ {
+			h.observe(3 * time.Millisecond)
+		}
+		h.observe(time.Minute)
+
+		Expect(h.quantile(0.5)).To(Equal(4 * time.Millisecond))
+		Expect(h.quantile(0.99)).To(Equal(latencyBuckets[len(latencyBuckets)-1]))
+	})
+})
+
+var _ = Describe("adaptReportingPeriod", func() {
+	var tracer *tracerImpl
+
+	BeforeEach(func() {
+		tracer = &tracerImpl{
+			opts: Options{
+				AdaptiveReportingPeriod: true,
+				MinReportingPeriod:      time.Second,
+				ReportingPeriod:         8 * time.Second,
+				ReportTimeout:           10 * time.Second,
+			},
+			reportingPeriod: 4 * time.Second,
+		}
+	})
+
+	It("shrinks the period while the collector is healthy", func() {
+		tracer.adaptReportingPeriod(time.Millisecond, nil)
+		Expect(tracer.reportingPeriod).To(Equal(3 * time.Second))
+
+		for i := 0; i < 10; i++ {
+			tracer.adaptReportingPeriod(time.Millisecond, nil)
+		}
+		Expect(tracer.reportingPeriod).To(Equal(time.Second))
+	})
+
+	It("stretches the period when reports fail", func() {
+		tracer.adaptReportingPeriod(time.Millisecond, errors.New("unavailable"))
+		Expect(tracer.reportingPeriod).To(Equal(8 * time.Second))
+
+		tracer.adaptReportingPeriod(time.Millisecond, errors.New("unavailable"))
+		Expect(tracer.reportingPeriod).To(Equal(8 * time.Second))
+	})
+
+	It("stretches the period when reports are slow", func() {
+		for i := 0; i < minAdaptiveSamples+1; i++ {
+			tracer.adaptReportingPeriod(10*time.Millisecond, nil)
+		}
+		tracer.reportingPeriod = 2 * time.Second
+
+		tracer.adaptReportingPeriod(time.Second, nil)
+		Expect(tracer.reportingPeriod).To(Equal(4 * time.Second))
+	})
+
+	It("keeps the period when disabled", func() {
+		tracer.opts.AdaptiveReportingPeriod = false
+		tracer.adaptReportingPeriod(time.Millisecond, errors.New("unavailable"))
+		Expect(tracer.reportingPeriod).To(Equal(4 * time.Second))
+		Expect(tracer.reportLatencies.total).To(BeEquivalentTo(1))
+	})
+})
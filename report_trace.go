@@ -0,0 +1,52 @@
+package lightstep
+
+// ReportTrace is a set of hooks called as the tracer connects to the
+// collector and sends reports, like httptrace.ClientTrace, so that the
+// reporter's network behavior can be instrumented. Any hook may be nil.
+// Hooks are called synchronously by the goroutine connecting or reporting,
+// and must not block. transport names the transport, such as "gRPC" or
+// "HTTP", as in EventTransportFallback.
+//
+// The HTTP transports connect lazily: their dial hooks cover setting up
+// the client, and the connections are opened by the first report.
+type ReportTrace struct {
+	// OnDialStart is called before the tracer connects, or reconnects, to
+	// the collector.
+	OnDialStart func(transport string)
+	// OnDialDone is called when connecting is done, with the error that
+	// made it fail, if any.
+	OnDialDone func(transport string, err error)
+	// OnReportStart is called before a report of spans is sent, with the
+	// size in bytes of its payload.
+	OnReportStart func(transport string, spans, payloadBytes int)
+	// OnReportDone is called when a report is done, with the error that
+	// made it fail, if any. Errors the collector returns fail the report,
+	// unless it counts the spans it rejected, as OTLP collectors and the
+	// Agent transport do, see EventReportPartialSuccess.
+	OnReportDone func(transport string, err error)
+}
+
+func (t ReportTrace) dialStart(transport string) {
+	if t.OnDialStart != nil {
+		t.OnDialStart(transport)
+	}
+}
+
+func (t ReportTrace) dialDone(transport string, err error) {
+	if t.OnDialDone != nil {
+		t.OnDialDone(transport, err)
+	}
+}
+
+// reportStart calls OnReportStart, sizing req only if it is set.
+func (t ReportTrace) reportStart(transport string, spans int, req reportRequest) {
+	if t.OnReportStart != nil {
+		t.OnReportStart(transport, spans, req.size())
+	}
+}
+
+func (t ReportTrace) reportDone(transport string, err error) {
+	if t.OnReportDone != nil {
+		t.OnReportDone(transport, err)
+	}
+}
@@ -0,0 +1,74 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReportTrace", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var calls []string
+
+	BeforeEach(func() {
+		calls = nil
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			ReportTrace: ReportTrace{
+				OnDialStart: func(transport string) {
+					calls = append(calls, "dial start "+transport)
+				},
+				OnDialDone: func(transport string, err error) {
+					calls = append(calls, fmt.Sprint("dial done ", transport, " ", err))
+				},
+				OnReportStart: func(transport string, spans, payloadBytes int) {
+					Expect(payloadBytes).To(BeNumerically(">", 0))
+					calls = append(calls, fmt.Sprint("report start ", transport, " ", spans))
+				},
+				OnReportDone: func(transport string, err error) {
+					calls = append(calls, fmt.Sprint("report done ", transport, " ", err != nil))
+				},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("calls the hooks around connecting and reporting", func() {
+		tracer.StartSpan("one").Finish()
+		tracer.StartSpan("two").Finish()
+		tracer.Flush(context.Background())
+
+		fakeClient.ReportReturns(nil, errors.New("down"))
+		tracer.StartSpan("three").Finish()
+		tracer.Flush(context.Background())
+
+		Expect(calls).To(Equal([]string{
+			"dial start gRPC",
+			"dial done gRPC <nil>",
+			"report start gRPC 2",
+			"report done gRPC false",
+			"report start gRPC 1",
+			"report done gRPC true",
+		}))
+	})
+})
@@ -0,0 +1,49 @@
+package lightstep
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// loadOrCreateReporterID returns the reporter ID stored in the file at path.
+// If the file does not exist or does not contain a valid ID, a new ID is
+// generated and written to path so it can be reused after a restart. An error
+// is returned alongside a usable ID if the file could not be read or written.
+func loadOrCreateReporterID(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		id, parseErr := strconv.ParseUint(strings.TrimSpace(string(data)), 16, 64)
+		if parseErr == nil && id != 0 {
+			return id, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return genSeededGUID(), err
+	}
+
+	id := genSeededGUID()
+	return id, writeReporterID(path, id)
+}
+
+// writeReporterID atomically replaces the contents of path with id.
+func writeReporterID(path string, id uint64) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(tmp, "%x\n", id); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
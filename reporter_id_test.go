@@ -0,0 +1,70 @@
+package lightstep
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("loadOrCreateReporterID", func() {
+	var dir string
+	var path string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "lightstep-reporter-id")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "reporter_id")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("when the file does not exist", func() {
+		It("creates it with a new reporter id", func() {
+			id, err := loadOrCreateReporterID(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).NotTo(BeZero())
+			Expect(path).To(BeAnExistingFile())
+		})
+	})
+
+	Context("when the file already exists", func() {
+		It("reuses the persisted reporter id", func() {
+			first, err := loadOrCreateReporterID(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := loadOrCreateReporterID(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(Equal(first))
+		})
+	})
+
+	Context("when the file is corrupted", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(path, []byte("not a guid"), 0644)).To(Succeed())
+		})
+
+		It("replaces it with a new reporter id", func() {
+			id, err := loadOrCreateReporterID(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).NotTo(BeZero())
+
+			again, err := loadOrCreateReporterID(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(id))
+		})
+	})
+
+	Context("when the directory does not exist", func() {
+		It("returns an error and a usable reporter id", func() {
+			id, err := loadOrCreateReporterID(filepath.Join(dir, "missing", "reporter_id"))
+			Expect(err).To(HaveOccurred())
+			Expect(id).NotTo(BeZero())
+		})
+	})
+})
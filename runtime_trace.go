@@ -0,0 +1,38 @@
+package lightstep
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// DoInRuntimeTraceRegion calls f with ctx carrying span. If the span's
+// Tracer was created with Options.RuntimeTrace and the runtime tracer is
+// running, f runs in a runtime/trace region named after the span, within
+// the span's task, so that `go tool trace` shows the goroutine's work for
+// the span. Regions must start and end on the same goroutine, which is why
+// they are opt-in per call rather than recorded for every span. Spans not
+// created by a LightStep Tracer are not traced.
+func DoInRuntimeTraceRegion(ctx context.Context, span opentracing.Span, f func(context.Context)) {
+	if tracedSpan, ok := span.(interface {
+		DoInRuntimeTraceRegion(context.Context, func(context.Context))
+	}); ok {
+		tracedSpan.DoInRuntimeTraceRegion(ctx, f)
+		return
+	}
+	f(opentracing.ContextWithSpan(ctx, span))
+}
+
+// DoInRuntimeTraceRegion calls f with ctx carrying the span, in a region of
+// the span's runtime/trace task if it has one.
+func (s *spanImpl) DoInRuntimeTraceRegion(ctx context.Context, f func(context.Context)) {
+	ctx = opentracing.ContextWithSpan(ctx, s)
+	if s.runtimeTask == nil {
+		f(ctx)
+		return
+	}
+	s.Lock()
+	name := s.raw.Operation
+	s.Unlock()
+	s.runtimeTask.region(name, func() { f(ctx) })
+}
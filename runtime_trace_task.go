@@ -0,0 +1,55 @@
+//go:build go1.11
+// +build go1.11
+
+package lightstep
+
+import (
+	"context"
+	"runtime/trace"
+	"strconv"
+	"sync"
+)
+
+// runtimeTasks tracks the runtime/trace tasks of a tracer's unfinished
+// spans, so that the tasks of child spans nest within their parent's.
+type runtimeTasks struct {
+	tasks sync.Map // span ID to *runtimeTask
+}
+
+// runtimeTask is the runtime/trace task of a span.
+type runtimeTask struct {
+	ctx  context.Context
+	task *trace.Task
+}
+
+// start begins a task for raw, if the runtime tracer is running. The task
+// is logged with the span's IDs, under the ProfilerLabelTraceID and
+// ProfilerLabelSpanID categories.
+func (t *runtimeTasks) start(raw *RawSpan) *runtimeTask {
+	if !trace.IsEnabled() {
+		return nil
+	}
+	parent := context.Background()
+	if raw.ParentSpanID != 0 {
+		if p, ok := t.tasks.Load(raw.ParentSpanID); ok {
+			parent = p.(*runtimeTask).ctx
+		}
+	}
+	ctx, task := trace.NewTask(parent, raw.Operation)
+	trace.Log(ctx, ProfilerLabelTraceID, formatTraceID(raw.Context.TraceIDHigh, raw.Context.TraceID))
+	trace.Log(ctx, ProfilerLabelSpanID, strconv.FormatUint(raw.Context.SpanID, 16))
+
+	rt := &runtimeTask{ctx: ctx, task: task}
+	t.tasks.Store(raw.Context.SpanID, rt)
+	return rt
+}
+
+// end ends the task of the span with spanID.
+func (t *runtimeTasks) end(spanID uint64, rt *runtimeTask) {
+	t.tasks.Delete(spanID)
+	rt.task.End()
+}
+
+func (rt *runtimeTask) region(name string, f func()) {
+	trace.WithRegion(rt.ctx, name, f)
+}
@@ -0,0 +1,20 @@
+//go:build !go1.11
+// +build !go1.11
+
+package lightstep
+
+// runtimeTasks does nothing before Go 1.11, whose runtime/trace has no
+// tasks or regions.
+type runtimeTasks struct{}
+
+type runtimeTask struct{}
+
+func (t *runtimeTasks) start(raw *RawSpan) *runtimeTask {
+	return nil
+}
+
+func (t *runtimeTasks) end(spanID uint64, rt *runtimeTask) {}
+
+func (rt *runtimeTask) region(name string, f func()) {
+	f()
+}
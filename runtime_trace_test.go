@@ -0,0 +1,84 @@
+//go:build go1.11 && !lightstep_nogrpc && !lightstep_constrained && !js
+// +build go1.11,!lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"runtime/trace"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("RuntimeTrace", func() {
+	var tracer *tracerImpl
+
+	BeforeEach(func() {
+		tracer = NewTracer(Options{
+			AccessToken:  "ACCESS_TOKEN",
+			ConnFactory:  fakeCollectorConnection(),
+			RuntimeTrace: true,
+		}).(*tracerImpl)
+	})
+
+	AfterEach(func() {
+		tracer.Close(context.Background())
+	})
+
+	Context("while the runtime tracer is running", func() {
+		BeforeEach(func() {
+			Expect(trace.Start(ioutil.Discard)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			trace.Stop()
+		})
+
+		It("starts a task for each span until it finishes", func() {
+			parent := tracer.StartSpan("parent").(*spanImpl)
+			child := tracer.StartSpan("child", opentracing.ChildOf(parent.Context())).(*spanImpl)
+			Expect(parent.runtimeTask).NotTo(BeNil())
+			Expect(child.runtimeTask).NotTo(BeNil())
+
+			task, ok := tracer.runtimeTasks.tasks.Load(parent.raw.Context.SpanID)
+			Expect(ok).To(BeTrue())
+			Expect(task).To(BeIdenticalTo(parent.runtimeTask))
+
+			child.Finish()
+			parent.Finish()
+			_, ok = tracer.runtimeTasks.tasks.Load(parent.raw.Context.SpanID)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("runs regions with the span in their context", func() {
+			span := tracer.StartSpan("work")
+			defer span.Finish()
+
+			var active opentracing.Span
+			DoInRuntimeTraceRegion(context.Background(), span, func(ctx context.Context) {
+				active = opentracing.SpanFromContext(ctx)
+			})
+			Expect(active).To(BeIdenticalTo(span))
+		})
+	})
+
+	It("logs all 32 digits of 128-bit trace IDs", func() {
+		var buf bytes.Buffer
+		Expect(trace.Start(&buf)).To(Succeed())
+		parent := SpanContext{TraceIDHigh: 0xabc, TraceID: 0x123, SpanID: 1}
+		tracer.StartSpan("work", opentracing.ChildOf(parent)).Finish()
+		trace.Stop()
+
+		Expect(buf.String()).To(ContainSubstring("abc0000000000000123"))
+	})
+
+	It("doesn't start tasks while the runtime tracer is stopped", func() {
+		span := tracer.StartSpan("work").(*spanImpl)
+		defer span.Finish()
+		Expect(span.runtimeTask).To(BeNil())
+	})
+})
@@ -0,0 +1,47 @@
+package lightstep
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+type scopedTagsKey struct{}
+
+// WithScopedTags returns a copy of ctx carrying tags, such as a request ID or
+// tenant, for every span started from it, or from its children, by
+// StartSpanFromContext and StartSpanFromContextWithDeadline. Tags already
+// scoped to ctx are kept unless tags replaces them.
+func WithScopedTags(ctx context.Context, tags opentracing.Tags) context.Context {
+	parent := ScopedTags(ctx)
+	scoped := make(opentracing.Tags, len(parent)+len(tags))
+	for k, v := range parent {
+		scoped[k] = v
+	}
+	for k, v := range tags {
+		scoped[k] = v
+	}
+	return context.WithValue(ctx, scopedTagsKey{}, scoped)
+}
+
+// ScopedTags returns the tags scoped to ctx by WithScopedTags. The returned
+// tags must not be modified.
+func ScopedTags(ctx context.Context) opentracing.Tags {
+	tags, _ := ctx.Value(scopedTagsKey{}).(opentracing.Tags)
+	return tags
+}
+
+// StartSpanFromContext starts a span like
+// opentracing.StartSpanFromContextWithTracer, tagged with the tags scoped to
+// ctx. Tags passed in opts take precedence over scoped tags.
+func StartSpanFromContext(
+	ctx context.Context,
+	tracer opentracing.Tracer,
+	operationName string,
+	opts ...opentracing.StartSpanOption,
+) (opentracing.Span, context.Context) {
+	if tags := ScopedTags(ctx); len(tags) > 0 {
+		opts = append([]opentracing.StartSpanOption{tags}, opts...)
+	}
+	return opentracing.StartSpanFromContextWithTracer(ctx, tracer, operationName, opts...)
+}
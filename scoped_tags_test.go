@@ -0,0 +1,52 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("WithScopedTags", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("tags the spans started from the context and its children", func() {
+		ctx := WithScopedTags(context.Background(), opentracing.Tags{"request.id": "r1", "tenant": "acme"})
+
+		parent, parentCtx := StartSpanFromContext(ctx, tracer, "parent")
+		childCtx := WithScopedTags(parentCtx, opentracing.Tags{"tenant": "globex"})
+		child, _ := StartSpanFromContext(childCtx, tracer, "child", opentracing.Tag{Key: "request.id", Value: "r2"})
+		child.Finish()
+		parent.Finish()
+
+		Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(2))
+		childRaw := fakeRecorder.RecordSpanArgsForCall(0)
+		Expect(childRaw.Tags).To(HaveKeyWithValue("tenant", "globex"))
+		Expect(childRaw.Tags).To(HaveKeyWithValue("request.id", "r2"))
+		parentRaw := fakeRecorder.RecordSpanArgsForCall(1)
+		Expect(parentRaw.Tags).To(HaveKeyWithValue("tenant", "acme"))
+		Expect(parentRaw.Tags).To(HaveKeyWithValue("request.id", "r1"))
+		Expect(ScopedTags(ctx)).To(HaveKeyWithValue("tenant", "acme"))
+	})
+})
@@ -0,0 +1,149 @@
+//go:build go1.21
+// +build go1.21
+
+package lightstep
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// Default attribute keys of the IDs added by the handler returned by
+// NewSlogHandler. They match the pprof labels set by DoWithProfilerLabels.
+const (
+	SlogTraceIDKey = ProfilerLabelTraceID
+	SlogSpanIDKey  = ProfilerLabelSpanID
+)
+
+// SlogHandlerOptions configures NewSlogHandler.
+type SlogHandlerOptions struct {
+	// TraceIDKey and SpanIDKey name the attributes carrying the hex trace
+	// and span IDs. They default to SlogTraceIDKey and SlogSpanIDKey.
+	TraceIDKey string
+	SpanIDKey  string
+	// MirrorErrors logs records of level slog.LevelError and above on the
+	// span in their context, with the "error" event, so that they appear
+	// in the trace.
+	MirrorErrors bool
+}
+
+// NewSlogHandler returns a slog.Handler that adds the trace and span IDs of
+// the span in each record's context to the record before passing it to
+// next, so that log lines can be correlated with traces. Records logged
+// without a context, or whose context carries no span created by a
+// LightStep Tracer, are passed on unchanged. Like all attributes, the IDs
+// are qualified by groups opened with WithGroup.
+func NewSlogHandler(next slog.Handler, opts SlogHandlerOptions) slog.Handler {
+	if opts.TraceIDKey == "" {
+		opts.TraceIDKey = SlogTraceIDKey
+	}
+	if opts.SpanIDKey == "" {
+		opts.SpanIDKey = SlogSpanIDKey
+	}
+	return &slogHandler{next: next, opts: opts}
+}
+
+type slogHandler struct {
+	next slog.Handler
+	opts SlogHandlerOptions
+
+	// attrs holds the attributes added with WithAttrs, qualified by the
+	// groups open when they were added, to be mirrored on spans along
+	// with each record's own attributes.
+	attrs  []log.Field
+	groups string
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return h.next.Handle(ctx, r)
+	}
+	sc, ok := span.Context().(SpanContext)
+	if !ok {
+		return h.next.Handle(ctx, r)
+	}
+
+	if h.opts.MirrorErrors && r.Level >= slog.LevelError {
+		fields := append([]log.Field{
+			log.String("event", "error"),
+			log.String("message", r.Message),
+		}, h.attrs...)
+		r.Attrs(func(attr slog.Attr) bool {
+			fields = appendSlogFields(fields, h.groups, attr)
+			return true
+		})
+		span.LogFields(fields...)
+	}
+
+	r = r.Clone()
+	r.AddAttrs(
+		slog.String(h.opts.TraceIDKey, formatTraceID(sc.TraceIDHigh, sc.TraceID)),
+		slog.String(h.opts.SpanIDKey, strconv.FormatUint(sc.SpanID, 16)),
+	)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	if h.opts.MirrorErrors {
+		clone.attrs = append([]log.Field(nil), h.attrs...)
+		for _, attr := range attrs {
+			clone.attrs = appendSlogFields(clone.attrs, h.groups, attr)
+		}
+	}
+	return &clone
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.groups = h.groups + name + "."
+	return &clone
+}
+
+// appendSlogFields appends attr to fields as span log fields, flattening
+// groups into dotted keys under prefix.
+func appendSlogFields(fields []log.Field, prefix string, attr slog.Attr) []log.Field {
+	value := attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	key := prefix + attr.Key
+	switch value.Kind() {
+	case slog.KindGroup:
+		if attr.Key != "" {
+			prefix = key + "."
+		}
+		for _, member := range value.Group() {
+			fields = appendSlogFields(fields, prefix, member)
+		}
+		return fields
+	case slog.KindString:
+		return append(fields, log.String(key, value.String()))
+	case slog.KindInt64:
+		return append(fields, log.Int64(key, value.Int64()))
+	case slog.KindUint64:
+		return append(fields, log.Uint64(key, value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, log.Float64(key, value.Float64()))
+	case slog.KindBool:
+		return append(fields, log.Bool(key, value.Bool()))
+	}
+	if err, ok := value.Any().(error); ok {
+		return append(fields, log.String(key, err.Error()))
+	}
+	return append(fields, log.String(key, value.String()))
+}
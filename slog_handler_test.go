@@ -0,0 +1,96 @@
+//go:build go1.21 && !lightstep_nogrpc && !lightstep_constrained && !js
+// +build go1.21,!lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	"github.com/lightstep/lightstep-tracer-go/lightstepfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("NewSlogHandler", func() {
+	var tracer Tracer
+	var fakeRecorder *lightstepfakes.FakeSpanRecorder
+	var output *bytes.Buffer
+
+	BeforeEach(func() {
+		fakeRecorder = new(lightstepfakes.FakeSpanRecorder)
+		tracer = NewTracer(Options{
+			AccessToken: "ACCESS_TOKEN",
+			ConnFactory: fakeCollectorConnection(),
+			Recorder:    fakeRecorder,
+		})
+		output = new(bytes.Buffer)
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	record := func() map[string]interface{} {
+		var line map[string]interface{}
+		Expect(json.Unmarshal(output.Bytes(), &line)).To(Succeed())
+		return line
+	}
+
+	It("adds the IDs of the span in the record's context", func() {
+		logger := slog.New(NewSlogHandler(slog.NewJSONHandler(output, nil), SlogHandlerOptions{}))
+		span := tracer.StartSpan("checkout")
+		sc := span.Context().(SpanContext)
+
+		logger.InfoContext(opentracing.ContextWithSpan(context.Background(), span), "charged")
+		line := record()
+		Expect(line).To(HaveKeyWithValue(SlogTraceIDKey, strconv.FormatUint(sc.TraceID, 16)))
+		Expect(line).To(HaveKeyWithValue(SlogSpanIDKey, strconv.FormatUint(sc.SpanID, 16)))
+
+		output.Reset()
+		logger.Info("idle")
+		Expect(record()).NotTo(HaveKey(SlogTraceIDKey))
+	})
+
+	It("adds the full 128-bit trace ID", func() {
+		logger := slog.New(NewSlogHandler(slog.NewJSONHandler(output, nil), SlogHandlerOptions{}))
+		parent := SpanContext{TraceIDHigh: 0x1, TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x2}
+		span := tracer.StartSpan("checkout", opentracing.ChildOf(parent))
+
+		logger.InfoContext(opentracing.ContextWithSpan(context.Background(), span), "charged")
+		Expect(record()).To(HaveKeyWithValue(SlogTraceIDKey, "1a1b2c3d4e5f60718"))
+	})
+
+	It("mirrors errors as span logs", func() {
+		logger := slog.New(NewSlogHandler(slog.NewJSONHandler(output, nil), SlogHandlerOptions{
+			TraceIDKey:   "trace",
+			MirrorErrors: true,
+		})).With("tenant", "acme").WithGroup("payment")
+		span := tracer.StartSpan("checkout")
+		ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+		logger.WarnContext(ctx, "slow")
+		logger.ErrorContext(ctx, "declined", "attempt", 2)
+		span.Finish()
+
+		Expect(output.String()).To(ContainSubstring(`"trace":`))
+		Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(1))
+		logs := fakeRecorder.RecordSpanArgsForCall(0).Logs
+		Expect(logs).To(HaveLen(1))
+		fields := map[string]interface{}{}
+		for _, field := range logs[0].Fields {
+			fields[field.Key()] = field.Value()
+		}
+		Expect(fields).To(Equal(map[string]interface{}{
+			"event":           "error",
+			"message":         "declined",
+			"tenant":          "acme",
+			"payment.attempt": int64(2),
+		}))
+	})
+})
@@ -16,10 +16,22 @@ type spanImpl struct {
 	raw        RawSpan
 	// The number of logs dropped because of MaxLogsPerSpan.
 	numDroppedLogs int
+	// Allocated with the span, and referenced by raw.Context.
+	carrierCache carrierCache
+	// The start time, with its monotonic clock reading if the tracer took
+	// it. raw.Start holds the wall clock time only.
+	started time.Time
+	// The phases started by StartPhase and not yet ended, innermost last.
+	phases []phase
+	// The span's runtime/trace task, if Options.RuntimeTrace is set and
+	// the runtime tracer was running when the span started.
+	runtimeTask *runtimeTask
+	// The context deadline recorded as DeadlineRemainingAtFinishKey, see
+	// StartSpanFromContextWithDeadline.
+	deadline time.Time
 }
 
-func newSpan(operationName string, tracer *tracerImpl, sso []ot.StartSpanOption) *spanImpl {
-	opts := newStartSpanOptions(sso)
+func newSpan(operationName string, tracer *tracerImpl, opts startSpanOptions) *spanImpl {
 
 	// Start time.
 	startTime := opts.Options.StartTime
@@ -27,8 +39,8 @@ func newSpan(operationName string, tracer *tracerImpl, sso []ot.StartSpanOption)
 		startTime = time.Now()
 	}
 
-	// Build the new span. This is the only allocation: We'll return this as
-	// an opentracing.Span.
+	// Build the new span. This is the only allocation, besides the copy of
+	// the tags: We'll return this as an opentracing.Span.
 	sp := &spanImpl{}
 
 	// It's meaningless to provide either SpanID or ParentSpanID
@@ -39,41 +51,77 @@ func newSpan(operationName string, tracer *tracerImpl, sso []ot.StartSpanOption)
 		sp.raw.ParentSpanID = opts.SetParentSpanID
 	}
 
-	// Look for a parent in the list of References.
-	//
-	// TODO: would be nice if we did something with all References, not just
-	//       the first one.
-ReferencesLoop:
-	for _, ref := range opts.Options.References {
-		switch ref.Type {
-		case ot.ChildOfRef, ot.FollowsFromRef:
-			refCtx := ref.ReferencedContext.(SpanContext)
-			sp.raw.Context.TraceID = refCtx.TraceID
-			sp.raw.ParentSpanID = refCtx.SpanID
-
-			if l := len(refCtx.Baggage); l > 0 {
-				sp.raw.Context.Baggage = make(map[string]string, l)
-				for k, v := range refCtx.Baggage {
-					sp.raw.Context.Baggage[k] = v
-				}
-			}
-			break ReferencesLoop
-		}
+	// Record the References, and take the trace and baggage from them,
+	// see spanReferences for the choice of parent.
+	references, contexts, parent := spanReferences(opts.Options.References)
+	if parent >= 0 {
+		refCtx := contexts[parent]
+		sp.raw.Context.TraceID = refCtx.TraceID
+		sp.raw.ParentSpanID = refCtx.SpanID
+		sp.raw.Context.passThrough = refCtx.passThrough
+		sp.raw.Context.TraceIDHigh = refCtx.TraceIDHigh
+		sp.raw.Context.traceState = refCtx.traceState
+		sp.raw.Context.Baggage = referencedBaggage(contexts, parent)
 	}
+	sp.raw.References = references
 
 	if sp.raw.Context.TraceID == 0 {
 		// TraceID not set by parent reference or explicitly
 		sp.raw.Context.TraceID, sp.raw.Context.SpanID = genSeededGUID2()
+		if tracer.opts.TraceID128Bit {
+			sp.raw.Context.TraceIDHigh = genSeededGUID()
+		}
 	} else if sp.raw.Context.SpanID == 0 {
 		// TraceID set but SpanID not set
 		sp.raw.Context.SpanID = genSeededGUID()
 	}
 
+	if len(contexts) > 1 {
+		// Baggage merged from several references may exceed the limits.
+		sp.raw.Context = tracer.opts.BaggageLimits.limit(sp.raw.Context)
+	}
+	sp.raw.Context.cache = &sp.carrierCache
+
+	if operationName == "" && tracer.opts.InferOperationName {
+		operationName = callerFunctionName()
+	}
+
 	sp.tracer = tracer
 	sp.raw.Operation = operationName
-	sp.raw.Start = startTime
+	sp.started = startTime
+	sp.raw.Start = startTime.Round(0)
 	sp.raw.Duration = -1
-	sp.raw.Tags = opts.Options.Tags
+	// The tags are copied, as the span adds its own and the caller may share
+	// or reuse its map.
+	if len(opts.Options.Tags) > 0 {
+		sp.raw.Tags = make(ot.Tags, len(opts.Options.Tags))
+		for key, value := range opts.Options.Tags {
+			sp.raw.Tags[key] = value
+		}
+	}
+	sp.raw.mustDeliver = opts.MustDeliver
+	sp.raw.Destination = opts.Destination
+	sp.raw.indexedTags = opts.IndexedTags
+	sp.deadline = opts.Deadline
+
+	debug := sp.raw.Context.isDebug()
+	if debug {
+		sp.enableDebugLocked()
+	}
+	if tracer.opts.StartStackFrames > 0 && (debug || sampledAt(tracer.opts.StartStackSampleRate)) {
+		if sp.raw.Tags == nil {
+			sp.raw.Tags = ot.Tags{}
+		}
+		sp.raw.Tags[StartStackKey] = captureStack(tracer.opts.StartStackFrames)
+	}
+
+	if tracer.runtimeTasks != nil {
+		sp.runtimeTask = tracer.runtimeTasks.start(&sp.raw)
+	}
+
+	if tracer.opts.OnSpanStart != nil {
+		tracer.opts.OnSpanStart(sp, opts.Options)
+	}
 	return sp
 }
 
@@ -183,11 +231,28 @@ func rotateLogBuffer(buf []ot.LogRecord, pos int) {
 }
 
 func (s *spanImpl) FinishWithOptions(opts ot.FinishOptions) {
+	s.finishWithOptions(opts, nil)
+}
+
+// FinishAsync finishes the span, returning a handle that is resolved once the
+// span has been handed to the transport or dropped.
+func (s *spanImpl) FinishAsync() FinishHandle {
+	handle := newFinishHandle()
+	s.finishWithOptions(ot.FinishOptions{}, handle)
+	return handle
+}
+
+func (s *spanImpl) finishWithOptions(opts ot.FinishOptions, handle *finishHandle) {
 	finishTime := opts.FinishTime
 	if finishTime.IsZero() {
 		finishTime = time.Now()
 	}
-	duration := finishTime.Sub(s.raw.Start)
+	// Sub uses the monotonic clock when both times were taken by the
+	// tracer, so wall clock adjustments while the span runs don't skew it.
+	duration := finishTime.Sub(s.started)
+	if duration < 0 {
+		duration = 0
+	}
 
 	s.Lock()
 	defer s.Unlock()
@@ -195,9 +260,23 @@ func (s *spanImpl) FinishWithOptions(opts ot.FinishOptions) {
 	// If the duration is already set, this span has already been finished.
 	// Return so we don't double submit the span.
 	if s.raw.Duration >= 0 {
+		if handle != nil {
+			handle.resolve(ErrSpanAlreadyFinished)
+		}
 		return
 	}
 
+	for len(s.phases) > 0 {
+		s.endPhaseLocked(finishTime)
+	}
+
+	if !s.deadline.IsZero() {
+		if s.raw.Tags == nil {
+			s.raw.Tags = ot.Tags{}
+		}
+		s.raw.Tags[DeadlineRemainingAtFinishKey] = durationMillis(s.deadline.Sub(finishTime))
+	}
+
 	for _, lr := range opts.LogRecords {
 		s.appendLog(lr)
 	}
@@ -228,6 +307,11 @@ func (s *spanImpl) FinishWithOptions(opts ot.FinishOptions) {
 	}
 
 	s.raw.Duration = duration
+	s.raw.finishHandle = handle
+
+	if s.runtimeTask != nil {
+		s.tracer.runtimeTasks.end(s.raw.Context.SpanID, s.runtimeTask)
+	}
 
 	s.tracer.RecordSpan(s.raw)
 }
@@ -236,15 +320,22 @@ func (s *spanImpl) Tracer() ot.Tracer {
 	return s.tracer
 }
 
+// Context returns the span's current SpanContext. SetBaggageItem replaces
+// it, rather than modifying it, so the returned context can be shared.
 func (s *spanImpl) Context() ot.SpanContext {
+	s.Lock()
+	defer s.Unlock()
 	return s.raw.Context
 }
 
 func (s *spanImpl) SetBaggageItem(key, val string) ot.Span {
-
 	s.Lock()
-	defer s.Unlock()
-	s.raw.Context = s.raw.Context.WithBaggageItem(key, val)
+	var event Event
+	s.raw.Context, event = s.tracer.opts.BaggageLimits.setItem(s.raw.Context, key, val)
+	s.Unlock()
+	if event != nil {
+		emitEvent(event)
+	}
 	return s
 }
 
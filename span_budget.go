@@ -0,0 +1,66 @@
+package lightstep
+
+import (
+	"sync"
+)
+
+// TraceTruncatedKey tags the last span a trace reports from this process
+// once it reaches Options.MaxSpansPerTrace.
+const TraceTruncatedKey = "lightstep.trace_truncated"
+
+// spanBudgetMaxTraces bounds the number of traces whose span counts are
+// remembered by a span budget at once, per generation.
+const spanBudgetMaxTraces = 10000
+
+// spanBudget counts the spans each trace reports. Counts are kept in two
+// generations: when the current one is full, it becomes the previous one,
+// and counts are forgotten for traces not seen since.
+type spanBudget struct {
+	max int
+
+	lock     sync.Mutex
+	current  map[uint64]int
+	previous map[uint64]int
+}
+
+// newSpanBudget drops the spans of a trace after the first max, and tags
+// the last span kept with TraceTruncatedKey. Spans started with MustDeliver
+// are always kept.
+func newSpanBudget(max int) spanProcessor {
+	budget := &spanBudget{max: max, current: make(map[uint64]int)}
+	return budget.process
+}
+
+func (b *spanBudget) process(span *RawSpan) bool {
+	count := b.count(span.Context.TraceID)
+	switch {
+	case count < b.max:
+		return true
+	case count == b.max:
+		tags := make(map[string]interface{}, len(span.Tags)+1)
+		for k, v := range span.Tags {
+			tags[k] = v
+		}
+		tags[TraceTruncatedKey] = true
+		span.Tags = tags
+		return true
+	}
+	return span.mustDeliver
+}
+
+// count records a span of traceID and returns the number recorded so far.
+func (b *spanBudget) count(traceID uint64) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	count, ok := b.current[traceID]
+	if !ok {
+		count = b.previous[traceID]
+		if len(b.current) >= spanBudgetMaxTraces {
+			b.previous, b.current = b.current, make(map[uint64]int)
+		}
+	}
+	count++
+	b.current[traceID] = count
+	return count
+}
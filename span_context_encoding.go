@@ -0,0 +1,152 @@
+package lightstep
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	lightstep "github.com/lightstep/lightstep-tracer-go/lightsteppb"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// MarshalText encodes the context's trace ID, span ID, baggage and W3C
+// tracestate, for storing it in a database, a cookie, or a job payload. The
+// encoding is stable: the same context always encodes to the same text. It
+// is the hex trace ID, of 16 digits or 32 for a 128-bit trace ID, and the
+// 16-digit hex span ID, separated by "-", followed by the baggage, if any,
+// as a "?" and a URL query with sorted keys, and by the tracestate, if any,
+// as a "#" and the query escaped tracestate:
+//
+//	00000000075bcd15-000000003ade68b1?user=alice#vendor%3Dvalue
+//
+// Carrier fields kept by Options.PassThroughKeys are not encoded.
+func (c SpanContext) MarshalText() ([]byte, error) {
+	text := fmt.Sprintf("%016x-%016x", c.TraceID, c.SpanID)
+	if c.TraceIDHigh != 0 {
+		text = fmt.Sprintf("%016x%016x-%016x", c.TraceIDHigh, c.TraceID, c.SpanID)
+	}
+	if len(c.Baggage) > 0 {
+		baggage := make(url.Values, len(c.Baggage))
+		for k, v := range c.Baggage {
+			baggage.Set(k, v)
+		}
+		text += "?" + baggage.Encode()
+	}
+	if c.traceState != "" {
+		text += "#" + url.QueryEscape(c.traceState)
+	}
+	return []byte(text), nil
+}
+
+// UnmarshalText decodes a context encoded by MarshalText. It returns
+// opentracing.ErrSpanContextCorrupted if text is malformed.
+func (c *SpanContext) UnmarshalText(text []byte) error {
+	ids, query, traceState := string(text), "", ""
+	if i := strings.IndexByte(ids, '#'); i >= 0 {
+		var err error
+		if traceState, err = url.QueryUnescape(ids[i+1:]); err != nil {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		ids = ids[:i]
+	}
+	if i := strings.IndexByte(ids, '?'); i >= 0 {
+		ids, query = ids[:i], ids[i+1:]
+	}
+
+	sep := strings.IndexByte(ids, '-')
+	if sep < 0 {
+		return opentracing.ErrSpanContextCorrupted
+	}
+	traceIDHigh, traceID, err := parseTraceID(ids[:sep])
+	if err != nil {
+		return opentracing.ErrSpanContextCorrupted
+	}
+	spanID, err := strconv.ParseUint(ids[sep+1:], 16, 64)
+	if err != nil {
+		return opentracing.ErrSpanContextCorrupted
+	}
+
+	var baggage map[string]string
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		baggage = make(map[string]string, len(values))
+		for k := range values {
+			baggage[k] = values.Get(k)
+		}
+	}
+
+	*c = SpanContext{TraceID: traceID, TraceIDHigh: traceIDHigh, SpanID: spanID, Baggage: baggage, traceState: traceState}
+	return nil
+}
+
+// Fields of the BinaryCarrier message written by MarshalBinary for what the
+// message has no place for. Readers of the message skip them as unknown
+// fields.
+const (
+	binaryTraceIDHighField = 15
+	binaryTraceStateField  = 16
+)
+
+// MarshalBinary encodes the context's trace ID, span ID, baggage and W3C
+// tracestate as the protobuf message used by the Binary carrier format,
+// without its base64 encoding. The upper half of a 128-bit trace ID and the
+// tracestate are added as fields of their own. The encoding is
+// deterministic.
+func (c SpanContext) MarshalBinary() ([]byte, error) {
+	var buf proto.Buffer
+	buf.SetDeterministic(true)
+	err := buf.Marshal(&lightstep.BinaryCarrier{
+		BasicCtx: &lightstep.BasicTracerCarrier{
+			TraceId:      c.TraceID,
+			SpanId:       c.SpanID,
+			Sampled:      true,
+			BaggageItems: c.Baggage,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	e := protoEncoder{buf: buf.Bytes()}
+	if c.TraceIDHigh != 0 {
+		e.fixed64(binaryTraceIDHighField, c.TraceIDHigh)
+	}
+	if c.traceState != "" {
+		e.string(binaryTraceStateField, c.traceState)
+	}
+	return e.bytes(), nil
+}
+
+// UnmarshalBinary decodes a context encoded by MarshalBinary.
+func (c *SpanContext) UnmarshalBinary(data []byte) error {
+	pb := &lightstep.BinaryCarrier{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return err
+	}
+	if pb.BasicCtx == nil {
+		return opentracing.ErrSpanContextCorrupted
+	}
+	decoded := SpanContext{
+		TraceID: pb.BasicCtx.TraceId,
+		SpanID:  pb.BasicCtx.SpanId,
+		Baggage: pb.BasicCtx.BaggageItems,
+	}
+	err := walkProtoFields(data, func(field int, wire int, value uint64, bytes []byte) error {
+		switch {
+		case field == binaryTraceIDHighField && wire == protoWireFixed64:
+			decoded.TraceIDHigh = value
+		case field == binaryTraceStateField && wire == protoWireBytes:
+			decoded.traceState = string(bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*c = decoded
+	return nil
+}
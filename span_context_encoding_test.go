@@ -0,0 +1,107 @@
+package lightstep_test
+
+import (
+	"encoding"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("SpanContext encoding", func() {
+	var sc SpanContext
+
+	BeforeEach(func() {
+		sc = SpanContext{
+			TraceID: 123456789,
+			SpanID:  987654321,
+			Baggage: map[string]string{"user": "alice", "plan": "free & clear"},
+		}
+	})
+
+	It("implements the encoding interfaces", func() {
+		var _ encoding.TextMarshaler = sc
+		var _ encoding.TextUnmarshaler = &sc
+		var _ encoding.BinaryMarshaler = sc
+		var _ encoding.BinaryUnmarshaler = &sc
+	})
+
+	Context("as text", func() {
+		It("has a stable encoding", func() {
+			text, err := sc.MarshalText()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(text)).To(Equal("00000000075bcd15-000000003ade68b1?plan=free+%26+clear&user=alice"))
+		})
+
+		It("round trips", func() {
+			text, err := sc.MarshalText()
+			Expect(err).NotTo(HaveOccurred())
+
+			var decoded SpanContext
+			Expect(decoded.UnmarshalText(text)).To(Succeed())
+			Expect(decoded.TraceID).To(Equal(sc.TraceID))
+			Expect(decoded.SpanID).To(Equal(sc.SpanID))
+			Expect(decoded.Baggage).To(Equal(sc.Baggage))
+		})
+
+		It("encodes all 32 digits of 128-bit trace IDs", func() {
+			sc.TraceIDHigh = 0xabc
+			text, err := sc.MarshalText()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(text)).To(HavePrefix("0000000000000abc00000000075bcd15-000000003ade68b1?"))
+
+			var decoded SpanContext
+			Expect(decoded.UnmarshalText(text)).To(Succeed())
+			Expect(decoded.TraceIDHigh).To(Equal(sc.TraceIDHigh))
+			Expect(decoded.TraceID).To(Equal(sc.TraceID))
+			Expect(decoded.SpanID).To(Equal(sc.SpanID))
+		})
+
+		It("omits empty baggage", func() {
+			text, err := SpanContext{TraceID: 1, SpanID: 2}.MarshalText()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(text)).To(Equal("0000000000000001-0000000000000002"))
+		})
+
+		It("rejects malformed text", func() {
+			var decoded SpanContext
+			for _, text := range []string{"", "1", "x-1", "1-y", "1-2?%zz"} {
+				Expect(decoded.UnmarshalText([]byte(text))).To(Equal(opentracing.ErrSpanContextCorrupted), text)
+			}
+		})
+	})
+
+	Context("as binary", func() {
+		It("has a stable encoding", func() {
+			first, err := sc.MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+			for i := 0; i < 10; i++ {
+				Expect(sc.MarshalBinary()).To(Equal(first))
+			}
+		})
+
+		It("round trips", func() {
+			data, err := sc.MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+
+			var decoded SpanContext
+			Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+			Expect(decoded.TraceID).To(Equal(sc.TraceID))
+			Expect(decoded.SpanID).To(Equal(sc.SpanID))
+			Expect(decoded.Baggage).To(Equal(sc.Baggage))
+		})
+
+		It("round trips 128-bit trace IDs", func() {
+			sc.TraceIDHigh = 0xabc
+			data, err := sc.MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+
+			var decoded SpanContext
+			Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+			Expect(decoded.TraceIDHigh).To(Equal(sc.TraceIDHigh))
+			Expect(decoded.TraceID).To(Equal(sc.TraceID))
+			Expect(decoded.SpanID).To(Equal(sc.SpanID))
+		})
+	})
+})
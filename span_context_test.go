@@ -0,0 +1,86 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("SpanContext", func() {
+	base := SpanContext{TraceID: 1, SpanID: 2, Baggage: map[string]string{"tenant": "acme"}}
+
+	Describe("WithBaggage", func() {
+		It("returns a copy with the items set", func() {
+			items := map[string]string{"tenant": "initech", "region": "eu"}
+			derived := base.WithBaggage(items)
+
+			Expect(derived.TraceID).To(Equal(base.TraceID))
+			Expect(derived.SpanID).To(Equal(base.SpanID))
+			Expect(derived.Baggage).To(Equal(map[string]string{"tenant": "initech", "region": "eu"}))
+			Expect(base.Baggage).To(Equal(map[string]string{"tenant": "acme"}))
+
+			items["region"] = "us"
+			Expect(derived.Baggage["region"]).To(Equal("eu"))
+		})
+
+		It("copies contexts without baggage", func() {
+			derived := SpanContext{TraceID: 1, SpanID: 2}.WithBaggageItem("tenant", "acme")
+			Expect(derived.Baggage).To(Equal(map[string]string{"tenant": "acme"}))
+		})
+	})
+
+	Describe("the contexts of spans", func() {
+		var tracer Tracer
+
+		BeforeEach(func() {
+			tracer = NewTracer(Options{AccessToken: "ACCESS_TOKEN", ConnFactory: fakeCollectorConnection()})
+		})
+
+		AfterEach(func() {
+			closeTestTracer(tracer)
+		})
+
+		It("aren't changed by setting the span's baggage", func() {
+			span := tracer.StartSpan("span")
+			span.SetBaggageItem("tenant", "acme")
+			before := span.Context().(SpanContext)
+
+			span.SetBaggageItem("tenant", "initech")
+			Expect(before.Baggage["tenant"]).To(Equal("acme"))
+			Expect(span.BaggageItem("tenant")).To(Equal("initech"))
+
+			carrier := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(before, opentracing.TextMap, carrier)).To(Succeed())
+			Expect(carrier["ot-baggage-tenant"]).To(Equal("acme"))
+		})
+
+		It("can be shared while baggage is set", func() {
+			span := tracer.StartSpan("span")
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(2)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						span.SetBaggageItem(fmt.Sprint("key", i), fmt.Sprint(j))
+					}
+				}(i)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						tracer.Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier{})
+					}
+				}()
+			}
+			wg.Wait()
+			Expect(span.Context().(SpanContext).Baggage).To(HaveLen(4))
+		})
+	})
+})
@@ -0,0 +1,23 @@
+package lightstep
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// estimateConverter uses the default truncation limits, so estimates can be
+// made without access to a Tracer's Options.
+var estimateConverter = newProtoConverter(Options{
+	MaxLogKeyLen:   DefaultMaxLogKeyLen,
+	MaxLogValueLen: DefaultMaxLogValueLen,
+})
+
+// EstimateSpanBytes returns the number of bytes the span adds to a report
+// sent by the gRPC or HTTP transports, using the default log truncation
+// limits. It is intended for capacity planning, e.g. to forecast collector
+// load from instrumentation changes, and is too expensive to call for every
+// span in production.
+func EstimateSpanBytes(span RawSpan) int {
+	size := proto.Size(estimateConverter.toSpan(span, &reportBuffer{}))
+	// Each span is a length-delimited field of the ReportRequest.
+	return 1 + proto.SizeVarint(uint64(size)) + size
+}
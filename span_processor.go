@@ -0,0 +1,93 @@
+package lightstep
+
+import (
+	"path"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// spanProcessor inspects or rewrites a finished span before it is buffered.
+// It returns false if the span should be dropped.
+type spanProcessor func(*RawSpan) bool
+
+// newSpanProcessors returns the built-in processors enabled by opts, in the
+// order they run.
+func newSpanProcessors(opts Options) []spanProcessor {
+	var processors []spanProcessor
+	if opts.ClampStartTimes {
+		processors = append(processors, newStartTimeClamper(opts.MaxStartTimeSkew))
+	}
+	if opts.CallerSampling.enabled() {
+		processors = append(processors, newCallerSampler(opts.CallerSampling))
+	}
+	if opts.MinSpanDuration > 0 {
+		processors = append(processors, newDurationFilter(opts.MinSpanDuration))
+	}
+	if opts.MaxSpansPerTrace > 0 {
+		processors = append(processors, newSpanBudget(opts.MaxSpansPerTrace))
+	}
+	if opts.AttachmentStore != nil {
+		processors = append(processors, newAttachmentSpiller(opts.AttachmentStore, opts.AttachmentThreshold))
+	}
+	if len(opts.TagAllowList) > 0 || len(opts.TagDenyList) > 0 {
+		processors = append(processors, newTagFilter(opts.TagAllowList, opts.TagDenyList))
+	}
+	if opts.ValidateSpans {
+		processors = append(processors, newSpanValidator(opts.MaxLogKeyLen, opts.MaxLogValueLen))
+	}
+	return processors
+}
+
+// newDurationFilter drops the spans shorter than min, unless they are roots,
+// errors, or must be delivered.
+func newDurationFilter(min time.Duration) spanProcessor {
+	return func(span *RawSpan) bool {
+		if span.Duration >= min || span.ParentSpanID == 0 || span.mustDeliver {
+			return true
+		}
+		failed, _ := span.Tags[string(ext.Error)].(bool)
+		return failed
+	}
+}
+
+// newTagFilter removes the span tags whose keys are not matched by allow
+// (when it is not empty) or are matched by deny.
+func newTagFilter(allow, deny []string) spanProcessor {
+	return func(span *RawSpan) bool {
+		var filtered map[string]interface{}
+		for key := range span.Tags {
+			if tagKeyAllowed(key, allow, deny) {
+				continue
+			}
+			if filtered == nil {
+				// Copy, as the tags may be shared with a SpanRecorder.
+				filtered = make(map[string]interface{}, len(span.Tags))
+				for k, v := range span.Tags {
+					filtered[k] = v
+				}
+			}
+			delete(filtered, key)
+		}
+		if filtered != nil {
+			span.Tags = filtered
+		}
+		return true
+	}
+}
+
+func tagKeyAllowed(key string, allow, deny []string) bool {
+	if len(allow) > 0 && !matchesAnyPattern(key, allow) {
+		return false
+	}
+	return !matchesAnyPattern(key, deny)
+}
+
+func matchesAnyPattern(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package lightstep
+
+import (
+	ot "github.com/opentracing/opentracing-go"
+)
+
+// SpanReference is a reference from a span to another, as given by the
+// References of the span's StartSpanOptions.
+type SpanReference struct {
+	Type    ot.SpanReferenceType
+	TraceID uint64
+	// TraceIDHigh is the upper half of a 128-bit trace ID, or 0.
+	TraceIDHigh uint64
+	SpanID      uint64
+}
+
+// spanReferences returns the references of a span started with refs, the
+// span contexts they refer to, and the index of the span's parent among
+// them, or -1 if it has none. References of unknown types, or to the span
+// contexts of other tracers, are ignored.
+//
+// The parent is the first ChildOf reference, or if there is none, the
+// first FollowsFrom reference: a span that follows from others but is the
+// child of one belongs in its parent's trace, whatever the order of the
+// references.
+func spanReferences(refs []ot.SpanReference) ([]SpanReference, []SpanContext, int) {
+	var references []SpanReference
+	var contexts []SpanContext
+	parent := -1
+	for _, ref := range refs {
+		if ref.Type != ot.ChildOfRef && ref.Type != ot.FollowsFromRef {
+			continue
+		}
+		refCtx, ok := ref.ReferencedContext.(SpanContext)
+		if !ok || refCtx.TraceID == 0 {
+			continue
+		}
+		if parent < 0 || (ref.Type == ot.ChildOfRef && references[parent].Type != ot.ChildOfRef) {
+			parent = len(references)
+		}
+		references = append(references, SpanReference{Type: ref.Type, TraceID: refCtx.TraceID, TraceIDHigh: refCtx.TraceIDHigh, SpanID: refCtx.SpanID})
+		contexts = append(contexts, refCtx)
+	}
+	return references, contexts, parent
+}
+
+// referencedBaggage returns the union of the baggage of contexts. Where
+// they disagree, the parent's baggage wins, followed by the earlier
+// references'.
+func referencedBaggage(contexts []SpanContext, parent int) map[string]string {
+	var baggage map[string]string
+	add := func(sc SpanContext) {
+		for k, v := range sc.Baggage {
+			if baggage == nil {
+				baggage = make(map[string]string, len(sc.Baggage))
+			}
+			if _, found := baggage[k]; !found {
+				baggage[k] = v
+			}
+		}
+	}
+	add(contexts[parent])
+	for i, sc := range contexts {
+		if i != parent {
+			add(sc)
+		}
+	}
+	return baggage
+}
+
+// parentReference returns the index of the span's parent among its
+// References, or -1 if it isn't one of them.
+func (r RawSpan) parentReference() int {
+	if r.ParentSpanID == 0 {
+		return -1
+	}
+	for i, ref := range r.References {
+		if ref.SpanID == r.ParentSpanID && ref.TraceID == r.Context.TraceID {
+			return i
+		}
+	}
+	return -1
+}
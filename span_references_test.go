@@ -0,0 +1,112 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("starting spans with several references", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	reportedSpan := func(operation string) *cpb.Span {
+		tracer.Flush(context.Background())
+		spans := fakeClient.SpansByOperation(operation)
+		Expect(spans).To(HaveLen(1))
+		return spans[0]
+	}
+
+	It("chooses the first ChildOf reference as the parent, whatever its position", func() {
+		batch := tracer.StartSpan("batch")
+		caller := tracer.StartSpan("caller")
+		other := tracer.StartSpan("other caller")
+		span := tracer.StartSpan("span",
+			opentracing.FollowsFrom(batch.Context()),
+			opentracing.ChildOf(caller.Context()),
+			opentracing.ChildOf(other.Context()))
+		span.Finish()
+
+		callerCtx := caller.Context().(SpanContext)
+		Expect(span.Context().(SpanContext).TraceID).To(Equal(callerCtx.TraceID))
+
+		refs := reportedSpan("span").GetReferences()
+		Expect(refs).To(HaveLen(3))
+		Expect(refs[0].GetRelationship()).To(Equal(cpb.Reference_CHILD_OF))
+		Expect(refs[0].GetSpanContext().GetSpanId()).To(Equal(callerCtx.SpanID))
+		Expect(refs[1].GetRelationship()).To(Equal(cpb.Reference_FOLLOWS_FROM))
+		Expect(refs[1].GetSpanContext().GetSpanId()).To(Equal(batch.Context().(SpanContext).SpanID))
+		Expect(refs[1].GetSpanContext().GetTraceId()).To(Equal(batch.Context().(SpanContext).TraceID))
+		Expect(refs[2].GetRelationship()).To(Equal(cpb.Reference_CHILD_OF))
+		Expect(refs[2].GetSpanContext().GetSpanId()).To(Equal(other.Context().(SpanContext).SpanID))
+	})
+
+	It("falls back to the first FollowsFrom reference", func() {
+		first := tracer.StartSpan("first")
+		second := tracer.StartSpan("second")
+		span := tracer.StartSpan("span",
+			opentracing.FollowsFrom(first.Context()),
+			opentracing.FollowsFrom(second.Context()))
+		span.Finish()
+
+		Expect(span.Context().(SpanContext).TraceID).To(Equal(first.Context().(SpanContext).TraceID))
+		refs := reportedSpan("span").GetReferences()
+		Expect(refs).To(HaveLen(2))
+		Expect(refs[0].GetRelationship()).To(Equal(cpb.Reference_FOLLOWS_FROM))
+		Expect(refs[0].GetSpanContext().GetSpanId()).To(Equal(first.Context().(SpanContext).SpanID))
+	})
+
+	It("merges the baggage of every reference, preferring the parent's", func() {
+		parent := tracer.StartSpan("parent")
+		parent.SetBaggageItem("tenant", "parent")
+		follows := tracer.StartSpan("follows")
+		follows.SetBaggageItem("tenant", "follows")
+		follows.SetBaggageItem("batch", "7")
+
+		span := tracer.StartSpan("span",
+			opentracing.FollowsFrom(follows.Context()),
+			opentracing.ChildOf(parent.Context()))
+		Expect(span.BaggageItem("tenant")).To(Equal("parent"))
+		Expect(span.BaggageItem("batch")).To(Equal("7"))
+	})
+
+	It("ignores the span contexts of other tracers", func() {
+		foreign := opentracing.NoopTracer{}.StartSpan("foreign")
+		parent := tracer.StartSpan("parent")
+		var span opentracing.Span
+		Expect(func() {
+			span = tracer.StartSpan("span",
+				opentracing.ChildOf(foreign.Context()),
+				opentracing.ChildOf(parent.Context()))
+		}).ToNot(Panic())
+		span.Finish()
+
+		refs := reportedSpan("span").GetReferences()
+		Expect(refs).To(HaveLen(1))
+		Expect(refs[0].GetSpanContext().GetSpanId()).To(Equal(parent.Context().(SpanContext).SpanID))
+	})
+})
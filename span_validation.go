@@ -0,0 +1,87 @@
+package lightstep
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// newSpanValidator checks finished spans against the constraints of the
+// collector protocol and emits an EventInvalidSpan for each span that
+// breaks them. Spans are reported unchanged.
+func newSpanValidator(maxKeyLen, maxValueLen int) spanProcessor {
+	return func(span *RawSpan) bool {
+		if violations := validateSpan(span, maxKeyLen, maxValueLen); len(violations) > 0 {
+			emitEvent(newEventInvalidSpan(span, violations))
+		}
+		return true
+	}
+}
+
+func validateSpan(span *RawSpan, maxKeyLen, maxValueLen int) []string {
+	var violations []string
+	violate := func(format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	if span.Context.TraceID == 0 {
+		violate("trace ID is zero")
+	}
+	if span.Context.SpanID == 0 {
+		violate("span ID is zero")
+	}
+	if span.Operation == "" {
+		violate("operation name is empty")
+	} else if !utf8.ValidString(span.Operation) {
+		violate("operation name is not valid UTF-8")
+	}
+	if span.Start.IsZero() {
+		violate("start time is not set")
+	}
+	if span.Duration < 0 {
+		violate("duration %v is negative", span.Duration)
+	}
+
+	for key, value := range span.Tags {
+		switch {
+		case key == "":
+			violate("tag key is empty")
+		case !utf8.ValidString(key):
+			violate("tag key %q is not valid UTF-8", key)
+		}
+		if s, ok := value.(string); ok && !utf8.ValidString(s) {
+			violate("value of tag %q is not valid UTF-8", key)
+		}
+	}
+
+	for key, value := range span.Context.Baggage {
+		if !utf8.ValidString(key) {
+			violate("baggage key %q is not valid UTF-8", key)
+		}
+		if !utf8.ValidString(value) {
+			violate("value of baggage item %q is not valid UTF-8", key)
+		}
+	}
+
+	for _, log := range span.Logs {
+		for _, field := range log.Fields {
+			key := field.Key()
+			switch {
+			case !utf8.ValidString(key):
+				violate("log field key %q is not valid UTF-8", key)
+			case len(key) > maxKeyLen:
+				violate("log field key %q is longer than %d bytes and will be truncated", key, maxKeyLen)
+			}
+			if s, ok := field.Value().(string); ok {
+				switch {
+				case !utf8.ValidString(s):
+					violate("value of log field %q is not valid UTF-8", key)
+				case len(s) > maxValueLen:
+					violate("value of log field %q is longer than %d bytes and will be truncated", key, maxValueLen)
+				}
+			}
+		}
+	}
+	sort.Strings(violations)
+	return violations
+}
@@ -0,0 +1,80 @@
+package lightstep
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+var _ = Describe("validateSpan", func() {
+	var span RawSpan
+
+	BeforeEach(func() {
+		span = RawSpan{
+			Context:   SpanContext{TraceID: 1, SpanID: 2, Baggage: map[string]string{"user": "alice"}},
+			Operation: "op",
+			Start:     time.Now(),
+			Duration:  time.Millisecond,
+			Tags:      opentracing.Tags{"component": "db", "rows": 3},
+			Logs: []opentracing.LogRecord{{
+				Timestamp: time.Now(),
+				Fields:    []log.Field{log.String("event", "query"), log.Int("attempt", 1)},
+			}},
+		}
+	})
+
+	It("accepts a valid span", func() {
+		Expect(validateSpan(&span, 8, 16)).To(BeEmpty())
+	})
+
+	It("reports missing required fields", func() {
+		span.Context.TraceID = 0
+		span.Context.SpanID = 0
+		span.Operation = ""
+		span.Start = time.Time{}
+		span.Duration = -time.Second
+		Expect(validateSpan(&span, 8, 16)).To(ConsistOf(
+			"trace ID is zero",
+			"span ID is zero",
+			"operation name is empty",
+			"start time is not set",
+			"duration -1s is negative",
+		))
+	})
+
+	It("reports strings that are not valid UTF-8", func() {
+		span.Operation = "op\xff"
+		span.Tags["bad\xff"] = "ok"
+		span.Tags["value"] = "bad\xff"
+		span.Context.Baggage["user"] = "bad\xff"
+		span.Logs[0].Fields = append(span.Logs[0].Fields, log.String("message", "bad\xff"))
+		Expect(validateSpan(&span, 8, 16)).To(ConsistOf(
+			"operation name is not valid UTF-8",
+			`tag key "bad\xff" is not valid UTF-8`,
+			`value of tag "value" is not valid UTF-8`,
+			`value of baggage item "user" is not valid UTF-8`,
+			`value of log field "message" is not valid UTF-8`,
+		))
+	})
+
+	It("reports log fields that will be truncated", func() {
+		span.Logs[0].Fields = append(span.Logs[0].Fields, log.String("long_key", strings.Repeat("x", 17)))
+		Expect(validateSpan(&span, 7, 16)).To(ConsistOf(
+			`log field key "long_key" is longer than 7 bytes and will be truncated`,
+			`value of log field "long_key" is longer than 16 bytes and will be truncated`,
+		))
+	})
+
+	It("identifies the span in the event", func() {
+		event := newEventInvalidSpan(&span, []string{"a", "b"})
+		Expect(event.String()).To(Equal(`span "op" (trace 1, span 2) is invalid: a; b`))
+
+		span.Context.TraceIDHigh = 0xabc
+		event = newEventInvalidSpan(&span, []string{"a"})
+		Expect(event.TraceID()).To(Equal("abc0000000000000001"))
+	})
+})
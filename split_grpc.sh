@@ -0,0 +1,61 @@
+#!/bin/sh
+
+# Moves the gRPC service of a file generated by protoc-gen-go with
+# plugins=grpc, and its imports, to a _grpc.pb.go file beside it, which
+# grpc_build_tag.sh excludes from builds without the gRPC transport. The
+# messages stay in the original file, which those builds still need.
+
+set -e
+
+for file in "$@"; do
+	grpc_file="${file%.pb.go}_grpc.pb.go"
+	awk -v grpc_file="$grpc_file" '
+	NR <= 2 { header = header $0 "\n" }
+	/^package / { package = $0 }
+
+	# The import block of context and grpc.
+	/^import \($/ { block = $0 "\n"; in_import = 1; next }
+	in_import {
+		block = block $0 "\n"
+		if ($0 == ")") {
+			in_import = 0
+			if (block ~ /"google\.golang\.org\/grpc"/) {
+				imports = block
+				skip_blank = 1
+			} else {
+				printf "%s", block
+			}
+		}
+		next
+	}
+	skip_blank { skip_blank = 0; if ($0 == "") next }
+
+	# The service, from its reference imports to the registration of the file.
+	/^\/\/ Reference imports/ { pending = $0; next }
+	pending != "" {
+		if ($0 == "var _ context.Context") {
+			in_service = 1
+			service = pending "\n" $0 "\n"
+		} else {
+			print pending
+			print
+		}
+		pending = ""
+		next
+	}
+	in_service && /^func init\(\) \{ proto\.RegisterFile/ { in_service = 0 }
+	in_service { service = service $0 "\n"; next }
+
+	{ print }
+
+	END {
+		if (service == "") {
+			print "split_grpc.sh: no gRPC service found" > "/dev/stderr"
+			exit 1
+		}
+		sub(/\n+$/, "\n", service)
+		printf "%s\n%s\n\n%s\n%s", header, package, imports, service > grpc_file
+	}' "$file" > "$file.tmp" || { rm -f "$file.tmp"; exit 1; }
+	mv "$file.tmp" "$file"
+	"$(dirname "$0")/grpc_build_tag.sh" "$grpc_file"
+done
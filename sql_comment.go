@@ -0,0 +1,38 @@
+package lightstep
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// SQLComment returns query with a sqlcommenter-style comment appended,
+// carrying the W3C traceparent of the span in ctx, such as
+//
+//	SELECT * FROM users /*traceparent='00-0000000000000000a1b2c3d4e5f60718-0102030405060708-01'*/
+//
+// so that database slow-query logs can be tied back to traces. The query is
+// returned unchanged if ctx carries no span created by a LightStep Tracer,
+// or if the query already ends with a comment, which sqlcommenter leaves
+// alone. Call it on queries passed to database/sql, for example
+// db.QueryContext(ctx, lightstep.SQLComment(ctx, query)).
+//
+// Comments make otherwise identical queries differ, which defeats prepared
+// statement caches keyed on the query text.
+func SQLComment(ctx context.Context, query string) string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return query
+	}
+	sc, ok := span.Context().(SpanContext)
+	if !ok {
+		return query
+	}
+	trimmed := strings.TrimRight(query, " \t\r\n;")
+	if strings.HasSuffix(trimmed, "*/") {
+		return query
+	}
+	return fmt.Sprintf("%s /*traceparent='00-%016x%016x-%016x-01'*/", trimmed, sc.TraceIDHigh, sc.TraceID, sc.SpanID)
+}
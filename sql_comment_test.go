@@ -0,0 +1,37 @@
+package lightstep_test
+
+import (
+	"context"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("SQLComment", func() {
+	It("appends the traceparent of the span in the context", func() {
+		ctx := contextWithSpanContext(SpanContext{TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x0102030405060708})
+
+		Expect(SQLComment(ctx, "SELECT * FROM users;")).To(Equal(
+			"SELECT * FROM users /*traceparent='00-0000000000000000a1b2c3d4e5f60718-0102030405060708-01'*/"))
+	})
+
+	It("appends the full 128-bit trace ID", func() {
+		ctx := contextWithSpanContext(SpanContext{TraceIDHigh: 0x0102030405060708, TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x1})
+
+		Expect(SQLComment(ctx, "SELECT 1")).To(Equal(
+			"SELECT 1 /*traceparent='00-0102030405060708a1b2c3d4e5f60718-0000000000000001-01'*/"))
+	})
+
+	It("leaves queries without a LightStep span or with a comment unchanged", func() {
+		Expect(SQLComment(context.Background(), "SELECT 1")).To(Equal("SELECT 1"))
+
+		span := opentracing.NoopTracer{}.StartSpan("query")
+		ctx := opentracing.ContextWithSpan(context.Background(), span)
+		Expect(SQLComment(ctx, "SELECT 1")).To(Equal("SELECT 1"))
+
+		ctx = contextWithSpanContext(SpanContext{TraceID: 1, SpanID: 2})
+		Expect(SQLComment(ctx, "SELECT 1 /*app='web'*/")).To(Equal("SELECT 1 /*app='web'*/"))
+	})
+})
@@ -0,0 +1,64 @@
+package lightstep
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Frames belonging to these packages are skipped when capturing the call
+// stack, so that the captured frames begin at the instrumented code.
+var skippedStackPackages = []string{
+	"github.com/lightstep/lightstep-tracer-go.",
+	"github.com/opentracing/opentracing-go.",
+}
+
+// captureStack returns up to maxFrames frames of the calling goroutine's
+// stack, one "function file:line" entry per line, omitting frames that belong
+// to the tracer or to the OpenTracing API.
+func captureStack(maxFrames int) string {
+	// Over-allocate so that skipped frames don't eat into maxFrames.
+	pcs := make([]uintptr, maxFrames+16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	lines := make([]string, 0, maxFrames)
+	for len(lines) < maxFrames {
+		frame, more := frames.Next()
+		if !isSkippedStackFrame(frame.Function) {
+			lines = append(lines, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// callerFunctionName returns the "package.Function" name of the first
+// function on the calling goroutine's stack outside of the tracer or the
+// OpenTracing API, or the empty string if there is none.
+func callerFunctionName() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isSkippedStackFrame(frame.Function) {
+			// Trim the import path, keeping the package name.
+			return frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func isSkippedStackFrame(function string) bool {
+	for _, prefix := range skippedStackPackages {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
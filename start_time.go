@@ -0,0 +1,44 @@
+package lightstep
+
+import (
+	"time"
+)
+
+// StartTimeClampedKey tags a span whose start time was clamped by
+// Options.ClampStartTimes with its original start, in RFC 3339 format.
+const StartTimeClampedKey = "lightstep.start_time_clamped"
+
+// DefaultMaxStartTimeSkew is the default Options.MaxStartTimeSkew.
+const DefaultMaxStartTimeSkew = time.Minute
+
+// processStart approximates the time the process started.
+var processStart = time.Now()
+
+// newStartTimeClamper clamps the start of spans that started more than
+// maxSkew in the future, or before the process started: a span from the
+// future ends at the time it's finished, keeping its duration, and an
+// earlier span starts with the process.
+func newStartTimeClamper(maxSkew time.Duration) spanProcessor {
+	return func(span *RawSpan) bool {
+		now := time.Now()
+		var clamped time.Time
+		switch {
+		case span.Start.After(now.Add(maxSkew)):
+			clamped = now.Add(-span.Duration)
+		case span.Start.Before(processStart.Add(-maxSkew)):
+			clamped = processStart
+		default:
+			return true
+		}
+
+		tags := make(map[string]interface{}, len(span.Tags)+1)
+		for k, v := range span.Tags {
+			tags[k] = v
+		}
+		tags[StartTimeClampedKey] = span.Start.Format(time.RFC3339Nano)
+		emitEvent(newEventStartTimeClamped(span, clamped))
+		span.Tags = tags
+		span.Start = clamped.Round(0)
+		return true
+	}
+}
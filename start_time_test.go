@@ -0,0 +1,127 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("ClampStartTimes", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			ClampStartTimes:    true,
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	reportedSpan := func() *cpb.Span {
+		tracer.Flush(context.Background())
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		_, req, _ := fakeClient.ReportArgsForCall(0)
+		Expect(req.GetSpans()).To(HaveLen(1))
+		return req.GetSpans()[0]
+	}
+
+	clampedTag := func(span *cpb.Span) string {
+		for _, tag := range span.GetTags() {
+			if tag.GetKey() == StartTimeClampedKey {
+				return tag.GetStringValue()
+			}
+		}
+		return ""
+	}
+
+	// clampedEvent returns the EventStartTimeClamped emitted, if any.
+	clampedEvent := func() EventStartTimeClamped {
+		for {
+			select {
+			case event := <-eventChan:
+				if clamped, ok := event.(EventStartTimeClamped); ok {
+					return clamped
+				}
+			default:
+				return nil
+			}
+		}
+	}
+
+	It("moves spans from the future to end when finished", func() {
+		start := time.Now().Add(time.Hour)
+		span := tracer.StartSpan("future", opentracing.StartTime(start))
+		span.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(5 * time.Second)})
+		finished := time.Now()
+
+		reported := reportedSpan()
+		reportedStart := time.Unix(reported.GetStartTimestamp().GetSeconds(), int64(reported.GetStartTimestamp().GetNanos()))
+		Expect(reportedStart).To(BeTemporally("~", finished.Add(-5*time.Second), time.Second))
+		Expect(reported.GetDurationMicros()).To(Equal(uint64(5000000)))
+		Expect(clampedTag(reported)).To(Equal(start.Format(time.RFC3339Nano)))
+
+		event := clampedEvent()
+		Expect(event).ToNot(BeNil())
+		Expect(event.OperationName()).To(Equal("future"))
+		Expect(event.Start()).To(BeTemporally("==", start))
+		Expect(event.Clamped()).To(BeTemporally("~", reportedStart, time.Microsecond))
+	})
+
+	It("moves spans from before the process started to its start", func() {
+		start := time.Now().Add(-24 * time.Hour)
+		tracer.StartSpan("imported", opentracing.StartTime(start)).Finish()
+
+		reported := reportedSpan()
+		reportedStart := time.Unix(reported.GetStartTimestamp().GetSeconds(), int64(reported.GetStartTimestamp().GetNanos()))
+		Expect(reportedStart).To(BeTemporally(">", start.Add(23*time.Hour)))
+		Expect(reportedStart).To(BeTemporally("<=", time.Now()))
+		Expect(clampedTag(reported)).To(Equal(start.Format(time.RFC3339Nano)))
+		Expect(clampedEvent()).ToNot(BeNil())
+	})
+
+	It("identifies 128-bit traces in the event", func() {
+		parent := SpanContext{TraceIDHigh: 0xabc, TraceID: 0x123, SpanID: 1}
+		tracer.StartSpan("imported", opentracing.ChildOf(parent), opentracing.StartTime(time.Now().Add(-24*time.Hour))).Finish()
+
+		reportedSpan()
+		event := clampedEvent()
+		Expect(event).ToNot(BeNil())
+		Expect(event.TraceID()).To(Equal("abc0000000000000123"))
+	})
+
+	It("tolerates clock skew", func() {
+		tracer.StartSpan("skewed", opentracing.StartTime(time.Now().Add(10*time.Second))).Finish()
+
+		Expect(clampedTag(reportedSpan())).To(BeEmpty())
+		Expect(clampedEvent()).To(BeNil())
+	})
+
+	It("rejects a negative MaxStartTimeSkew", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", MaxStartTimeSkew: -time.Second}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
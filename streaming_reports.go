@@ -0,0 +1,32 @@
+package lightstep
+
+import (
+	"time"
+)
+
+// DefaultStreamingLinger is the default Options.StreamingLinger.
+const DefaultStreamingLinger = 20 * time.Millisecond
+
+// signalSpanReady wakes the report loop after a span is buffered, if
+// Options.StreamingReports is set. It never blocks: a pending signal
+// already covers the span.
+func (tracer *tracerImpl) signalSpanReady() {
+	if tracer.spanReady == nil {
+		return
+	}
+	select {
+	case tracer.spanReady <- struct{}{}:
+	default:
+	}
+}
+
+// shouldStreamLocked reports whether the buffered spans can be sent as soon
+// as they linger, see Options.StreamingReports. After a failed report, or
+// while the circuit breaker is open, retries are left to the periodic
+// reports.
+func (tracer *tracerImpl) shouldStreamLocked(now time.Time) bool {
+	if tracer.disabled || len(tracer.buffer.rawSpans) == 0 || tracer.lastReportFailed {
+		return false
+	}
+	return tracer.breaker == nil || tracer.breaker.allow(now)
+}
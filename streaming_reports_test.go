@@ -0,0 +1,78 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StreamingReports", func() {
+	var tracer Tracer
+	var opts Options
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			StreamingReports:   true,
+			StreamingLinger:    10 * time.Millisecond,
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("reports spans shortly after they finish", func() {
+		tracer.StartSpan("first").Finish()
+		Eventually(fakeClient.ReportCallCount).Should(Equal(1))
+
+		tracer.StartSpan("second").Finish()
+		Eventually(fakeClient.ReportCallCount).Should(Equal(2))
+		Expect(fakeClient.SpansByOperation("second")).To(HaveLen(1))
+	})
+
+	It("leaves retries to the periodic reports after a failure", func() {
+		fakeClient.ReportReturnsOnCall(0, nil, errors.New("collector unavailable"))
+
+		tracer.StartSpan("failed").Finish()
+		Eventually(fakeClient.ReportCallCount).Should(Equal(1))
+
+		tracer.StartSpan("waiting").Finish()
+		Consistently(fakeClient.ReportCallCount, 100*time.Millisecond).Should(Equal(1))
+	})
+
+	Context("when disabled", func() {
+		BeforeEach(func() {
+			opts.StreamingReports = false
+		})
+
+		It("waits for the reporting period", func() {
+			tracer.StartSpan("span").Finish()
+			Consistently(fakeClient.ReportCallCount, 100*time.Millisecond).Should(BeZero())
+		})
+	})
+
+	It("rejects a negative linger", func() {
+		opts := Options{AccessToken: "token", StreamingLinger: -time.Second}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
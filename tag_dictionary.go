@@ -0,0 +1,98 @@
+package lightstep
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+)
+
+// Keys of the tag dictionary encoding, see Options.ExperimentalTagDictionary.
+// Dictionary entry i is reported as a reporter tag keyed
+// TagDictionaryKeyPrefix + i + "/" + the tag's key, with the tag's value.
+// Spans replace the tags found in the dictionary by a TagRefsKey tag listing
+// their entries' indexes, separated by commas.
+const (
+	TagDictionaryKeyPrefix = "lightstep.tag_dictionary/"
+	TagRefsKey             = "lightstep.tag_refs"
+)
+
+// tagDictionaryMinBytes is the encoded size a tag must exceed to be worth
+// replacing by a reference.
+const tagDictionaryMinBytes = 8
+
+// tagEntry identifies a tag by its key and value.
+type tagEntry struct {
+	key         string
+	stringValue string
+	intValue    int64
+	doubleValue float64
+	boolValue   bool
+	kind        int
+}
+
+func newTagEntry(tag *cpb.KeyValue) (tagEntry, bool) {
+	entry := tagEntry{key: tag.Key}
+	switch value := tag.Value.(type) {
+	case *cpb.KeyValue_StringValue:
+		entry.kind, entry.stringValue = 1, value.StringValue
+	case *cpb.KeyValue_IntValue:
+		entry.kind, entry.intValue = 2, value.IntValue
+	case *cpb.KeyValue_DoubleValue:
+		entry.kind, entry.doubleValue = 3, value.DoubleValue
+	case *cpb.KeyValue_BoolValue:
+		entry.kind, entry.boolValue = 4, value.BoolValue
+	default:
+		return entry, false
+	}
+	return entry, true
+}
+
+// applyTagDictionary moves the tags repeated by several spans of req into a
+// dictionary of reporter tags, replacing them in each span by a reference.
+func applyTagDictionary(req *cpb.ReportRequest) {
+	counts := map[tagEntry]int{}
+	for _, span := range req.Spans {
+		for _, tag := range span.Tags {
+			if entry, ok := newTagEntry(tag); ok {
+				counts[entry]++
+			}
+		}
+	}
+
+	indexes := map[tagEntry]int{}
+	var dictionary []*cpb.KeyValue
+	for _, span := range req.Spans {
+		var refs []string
+		tags := span.Tags[:0]
+		for _, tag := range span.Tags {
+			entry, ok := newTagEntry(tag)
+			if !ok || counts[entry] < 2 || proto.Size(tag) <= tagDictionaryMinBytes {
+				tags = append(tags, tag)
+				continue
+			}
+			index, found := indexes[entry]
+			if !found {
+				index = len(dictionary)
+				indexes[entry] = index
+				dictionary = append(dictionary, &cpb.KeyValue{
+					Key:   TagDictionaryKeyPrefix + strconv.Itoa(index) + "/" + tag.Key,
+					Value: tag.Value,
+				})
+			}
+			refs = append(refs, strconv.Itoa(index))
+		}
+		if len(refs) > 0 {
+			tags = append(tags, &cpb.KeyValue{
+				Key:   TagRefsKey,
+				Value: &cpb.KeyValue_StringValue{StringValue: strings.Join(refs, ",")},
+			})
+		}
+		span.Tags = tags
+	}
+
+	if len(dictionary) > 0 {
+		req.Reporter.Tags = append(req.Reporter.Tags, dictionary...)
+	}
+}
@@ -0,0 +1,144 @@
+package lightstep
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// DefaultTailSamplingMaxTraces is the default TailSamplingOptions.MaxTraces.
+const DefaultTailSamplingMaxTraces = 1000
+
+// TailSamplingOptions configures in-process tail sampling. The finished
+// spans of each trace are held for Window, then all of them are reported if
+// any was tagged as an error, lasted at least LatencyThreshold, or was
+// started with MustDeliver. Otherwise the trace is kept with probability
+// SampleRate. The decision only covers the spans this process holds: spans
+// that finish after their trace is decided are sampled as a new trace.
+type TailSamplingOptions struct {
+	// Window is how long the spans of a trace are held, from when the first
+	// of them finishes. Zero disables tail sampling.
+	Window time.Duration `yaml:"window"`
+
+	// LatencyThreshold keeps the traces with a span at least this long.
+	// Zero disables the latency check.
+	LatencyThreshold time.Duration `yaml:"latency_threshold"`
+
+	// SampleRate is the fraction (between 0.0 and 1.0) of the remaining
+	// traces that are kept. It is applied to the trace ID, so processes
+	// sampling at the same rate keep the same traces.
+	SampleRate float64 `yaml:"sample_rate"`
+
+	// MaxTraces is the number of traces held at once. When it is exceeded,
+	// the trace held the longest is decided early.
+	MaxTraces int `yaml:"max_traces"`
+}
+
+func (o TailSamplingOptions) validate() error {
+	if o.Window < 0 || o.LatencyThreshold < 0 {
+		return validationErrorTailSamplingDuration
+	}
+	if o.SampleRate < 0 || o.SampleRate > 1 {
+		return validationErrorTailSamplingRate
+	}
+	return nil
+}
+
+// tailSampler holds finished spans by trace until their trace is decided.
+type tailSampler struct {
+	opts TailSamplingOptions
+
+	lock   sync.Mutex
+	traces map[uint64]*heldTrace
+	// queue holds the IDs of the held traces in the order they arrived,
+	// which is also the order of their deadlines.
+	queue []uint64
+}
+
+type heldTrace struct {
+	deadline time.Time
+	spans    []RawSpan
+	keep     bool
+}
+
+func newTailSampler(opts TailSamplingOptions) *tailSampler {
+	return &tailSampler{
+		opts:   opts,
+		traces: make(map[uint64]*heldTrace),
+	}
+}
+
+// add holds span until its trace is decided. If holding it exceeds
+// MaxTraces, the oldest trace is decided and its spans are returned.
+func (s *tailSampler) add(span RawSpan, now time.Time) (kept, dropped []RawSpan) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	traceID := span.Context.TraceID
+	trace, ok := s.traces[traceID]
+	if !ok {
+		trace = &heldTrace{deadline: now.Add(s.opts.Window)}
+		s.traces[traceID] = trace
+		s.queue = append(s.queue, traceID)
+	}
+	trace.spans = append(trace.spans, span)
+	trace.keep = trace.keep || s.interesting(span)
+
+	if len(s.traces) > s.opts.MaxTraces {
+		return s.decideLocked(1)
+	}
+	return nil, nil
+}
+
+// release decides the traces whose window has passed.
+func (s *tailSampler) release(now time.Time) (kept, dropped []RawSpan) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	n := 0
+	for _, traceID := range s.queue {
+		if now.Before(s.traces[traceID].deadline) {
+			break
+		}
+		n++
+	}
+	return s.decideLocked(n)
+}
+
+// releaseAll decides every held trace.
+func (s *tailSampler) releaseAll() (kept, dropped []RawSpan) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.decideLocked(len(s.queue))
+}
+
+// decideLocked decides the n oldest traces.
+func (s *tailSampler) decideLocked(n int) (kept, dropped []RawSpan) {
+	for _, traceID := range s.queue[:n] {
+		trace := s.traces[traceID]
+		delete(s.traces, traceID)
+		if trace.keep || s.sampled(traceID) {
+			kept = append(kept, trace.spans...)
+		} else {
+			dropped = append(dropped, trace.spans...)
+		}
+	}
+	s.queue = s.queue[n:]
+	return kept, dropped
+}
+
+func (s *tailSampler) interesting(span RawSpan) bool {
+	if span.mustDeliver {
+		return true
+	}
+	if s.opts.LatencyThreshold > 0 && span.Duration >= s.opts.LatencyThreshold {
+		return true
+	}
+	failed, _ := span.Tags[string(ext.Error)].(bool)
+	return failed
+}
+
+func (s *tailSampler) sampled(traceID uint64) bool {
+	return traceSampled(traceID, s.opts.SampleRate)
+}
@@ -0,0 +1,92 @@
+package lightstep
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("tailSampler", func() {
+	var sampler *tailSampler
+	var now time.Time
+
+	span := func(traceID, spanID uint64, duration time.Duration, tags opentracing.Tags) RawSpan {
+		return RawSpan{
+			Context:  SpanContext{TraceID: traceID, SpanID: spanID},
+			Duration: duration,
+			Tags:     tags,
+		}
+	}
+
+	spanIDs := func(spans []RawSpan) []uint64 {
+		var ids []uint64
+		for _, s := range spans {
+			ids = append(ids, s.Context.SpanID)
+		}
+		return ids
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		sampler = newTailSampler(TailSamplingOptions{
+			Window:           time.Second,
+			LatencyThreshold: 100 * time.Millisecond,
+			MaxTraces:        10,
+		})
+	})
+
+	It("holds spans until their window has passed", func() {
+		kept, dropped := sampler.add(span(1, 1, 0, nil), now)
+		Expect(kept).To(BeEmpty())
+		Expect(dropped).To(BeEmpty())
+
+		kept, dropped = sampler.release(now.Add(time.Second - 1))
+		Expect(kept).To(BeEmpty())
+		Expect(dropped).To(BeEmpty())
+
+		_, dropped = sampler.release(now.Add(time.Second))
+		Expect(spanIDs(dropped)).To(Equal([]uint64{1}))
+	})
+
+	It("keeps every span of a trace with an error", func() {
+		sampler.add(span(1, 1, 0, nil), now)
+		sampler.add(span(1, 2, 0, opentracing.Tags{"error": true}), now)
+		sampler.add(span(2, 3, 0, nil), now)
+
+		kept, dropped := sampler.release(now.Add(time.Second))
+		Expect(spanIDs(kept)).To(Equal([]uint64{1, 2}))
+		Expect(spanIDs(dropped)).To(Equal([]uint64{3}))
+	})
+
+	It("keeps every span of a slow trace", func() {
+		sampler.add(span(1, 1, time.Millisecond, nil), now)
+		sampler.add(span(1, 2, time.Second, nil), now)
+
+		kept, _ := sampler.releaseAll()
+		Expect(spanIDs(kept)).To(Equal([]uint64{1, 2}))
+	})
+
+	It("samples the other traces by trace ID", func() {
+		sampler.opts.SampleRate = 0.5
+		sampler.add(span(1, 1, 0, nil), now)
+		sampler.add(span(1<<63-1, 2, 0, nil), now)
+
+		kept, dropped := sampler.releaseAll()
+		Expect(spanIDs(kept)).To(Equal([]uint64{1}))
+		Expect(spanIDs(dropped)).To(Equal([]uint64{2}))
+	})
+
+	It("decides the oldest trace early when holding too many", func() {
+		for traceID := uint64(1); traceID <= 11; traceID++ {
+			_, dropped := sampler.add(span(traceID, traceID, 0, nil), now)
+			if traceID <= 10 {
+				Expect(dropped).To(BeEmpty())
+			} else {
+				Expect(spanIDs(dropped)).To(Equal([]uint64{1}))
+			}
+		}
+		Expect(sampler.traces).To(HaveLen(10))
+	})
+})
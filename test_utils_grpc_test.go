@@ -0,0 +1,32 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+)
+
+//////////////////
+// GRPC HELPERS //
+//////////////////
+
+func getReportedGRPCSpans(fakeClient *cpbfakes.FakeCollectorServiceClient) []*cpb.Span {
+	return append(make([]*cpb.Span, 0), fakeClient.Spans()...)
+}
+
+func fakeGrpcConnection(fakeClient *cpbfakes.FakeCollectorServiceClient) ConnectorFactory {
+	return func() (interface{}, Connection, error) {
+		return fakeClient, new(dummyConnection), nil
+	}
+}
+
+// fakeCollectorConnection connects to a fake collector that accepts every
+// report, for specs that don't look at what is reported.
+func fakeCollectorConnection() ConnectorFactory {
+	fakeClient := new(cpbfakes.FakeCollectorServiceClient)
+	fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+	return fakeGrpcConnection(fakeClient)
+}
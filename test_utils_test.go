@@ -9,7 +9,6 @@ import (
 	ot "github.com/opentracing/opentracing-go"
 
 	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
-	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
 
 	"github.com/lightstep/lightstep-tracer-go/lightstep_thrift"
 	thriftfakes "github.com/lightstep/lightstep-tracer-go/lightstep_thrift/lightstep_thriftfakes"
@@ -27,6 +26,22 @@ func closeTestTracer(tracer ot.Tracer) {
 	Eventually(complete).Should(BeClosed())
 }
 
+// contextSpan is a span with a given context, for testing helpers that only
+// read the span in a context.Context without starting a tracer.
+type contextSpan struct {
+	ot.Span
+	sc ot.SpanContext
+}
+
+func (s contextSpan) Context() ot.SpanContext {
+	return s.sc
+}
+
+// contextWithSpanContext returns a context carrying a span with sc.
+func contextWithSpanContext(sc ot.SpanContext) context.Context {
+	return ot.ContextWithSpan(context.Background(), contextSpan{Span: ot.NoopTracer{}.StartSpan(""), sc: sc})
+}
+
 func startNSpans(n int, tracer ot.Tracer) {
 	for i := 0; i < n; i++ {
 		tracer.StartSpan(string(i)).Finish()
@@ -124,42 +139,16 @@ func KeyValue(key string, value interface{}, storeAsJson ...bool) *cpb.KeyValue
 	return tag
 }
 
-//////////////////
-// GRPC HELPERS //
-//////////////////
-
-func getReportedGRPCSpans(fakeClient *cpbfakes.FakeCollectorServiceClient) []*cpb.Span {
-	callCount := fakeClient.ReportCallCount()
-	spans := make([]*cpb.Span, 0)
-	for i := 0; i < callCount; i++ {
-		_, report, _ := fakeClient.ReportArgsForCall(i)
-		spans = append(spans, report.GetSpans()...)
-	}
-	return spans
-}
-
 type dummyConnection struct{}
 
 func (*dummyConnection) Close() error { return nil }
 
-func fakeGrpcConnection(fakeClient *cpbfakes.FakeCollectorServiceClient) ConnectorFactory {
-	return func() (interface{}, Connection, error) {
-		return fakeClient, new(dummyConnection), nil
-	}
-}
-
 ////////////////////
 // THRIFT HELPERS //
 ////////////////////
 
 func getReportedThriftSpans(fakeClient *thriftfakes.FakeReportingService) []*lightstep_thrift.SpanRecord {
-	callCount := fakeClient.ReportCallCount()
-	spans := make([]*lightstep_thrift.SpanRecord, 0)
-	for i := 0; i < callCount; i++ {
-		_, report := fakeClient.ReportArgsForCall(i)
-		spans = append(spans, report.GetSpanRecords()...)
-	}
-	return spans
+	return append(make([]*lightstep_thrift.SpanRecord, 0), fakeClient.Spans()...)
 }
 
 func fakeThriftConnectionFactory(fakeClient lightstep_thrift.ReportingService) ConnectorFactory {
@@ -167,3 +156,12 @@ func fakeThriftConnectionFactory(fakeClient lightstep_thrift.ReportingService) C
 		return fakeClient, new(dummyConnection), nil
 	}
 }
+
+type memoryAttachmentStore struct {
+	attachments []Attachment
+}
+
+func (store *memoryAttachmentStore) Store(attachment Attachment) (string, error) {
+	store.attachments = append(store.attachments, attachment)
+	return fmt.Sprint("attachment-", len(store.attachments)), nil
+}
@@ -1,8 +1,12 @@
+//go:build !lightstep_nothrift && !lightstep_constrained
+// +build !lightstep_nothrift,!lightstep_constrained
+
 package lightstep
 
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/lightstep/lightstep-tracer-go/lightstep_thrift"
 	"github.com/opentracing/opentracing-go/log"
@@ -23,21 +27,17 @@ type thriftLogFieldEncoder struct {
 }
 
 func (lfe *thriftLogFieldEncoder) EmitString(key, value string) {
-	if len(key) > lfe.recorder.maxLogMessageLen {
-		key = key[:(lfe.recorder.maxLogKeyLen-1)] + ellipsis
-	}
-
-	if len(value) > lfe.recorder.maxLogMessageLen {
-		value = value[:(lfe.recorder.maxLogMessageLen-1)] + ellipsis
-	}
-
-	lfe.logRecord.Fields = append(lfe.logRecord.Fields, &lightstep_thrift.KeyValue{
-		Key:   key,
-		Value: value,
-	})
+	key, _ = lfe.recorder.truncator.truncate(key, lfe.recorder.maxLogKeyLen)
+	lfe.emitSafeValue(key, value)
 }
 
 func (lfe *thriftLogFieldEncoder) EmitObject(key string, value interface{}) {
+	if lfe.recorder.formatValues {
+		if formatted, ok := formatValue(value); ok {
+			lfe.EmitString(key, fmt.Sprint(formatted))
+			return
+		}
+	}
 	var thriftPayload string
 	jsonString, err := json.Marshal(value)
 	if err != nil {
@@ -45,13 +45,21 @@ func (lfe *thriftLogFieldEncoder) EmitObject(key string, value interface{}) {
 	} else {
 		thriftPayload = string(jsonString)
 	}
-	if len(thriftPayload) > lfe.recorder.maxLogMessageLen {
-		thriftPayload = thriftPayload[:(lfe.recorder.maxLogMessageLen-1)] + ellipsis
-	}
+	lfe.emitSafeValue(key, thriftPayload)
+}
+
+func (lfe *thriftLogFieldEncoder) emitSafeValue(key, value string) {
+	truncated, ok := lfe.recorder.truncator.truncate(value, lfe.recorder.maxLogMessageLen)
 	lfe.logRecord.Fields = append(lfe.logRecord.Fields, &lightstep_thrift.KeyValue{
 		Key:   key,
-		Value: thriftPayload,
+		Value: truncated,
 	})
+	if ok && lfe.recorder.reportTruncatedLength {
+		lfe.logRecord.Fields = append(lfe.logRecord.Fields, &lightstep_thrift.KeyValue{
+			Key:   key + LogTruncatedLengthSuffix,
+			Value: strconv.Itoa(len(value)),
+		})
+	}
 }
 
 func (lfe *thriftLogFieldEncoder) EmitBool(key string, value bool) {
@@ -0,0 +1,56 @@
+package lightstep
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// TraceIDHighKey is the tag that reports the upper half of a 128-bit trace ID,
+// in hex, to collectors whose protocol has 64-bit trace IDs. The OTLP
+// transport reports the full trace ID instead.
+const TraceIDHighKey = "lightstep.trace_id_high"
+
+var errTraceIDLength = errors.New("trace ID longer than 128 bits")
+
+// formatTraceID returns the hex form of a trace ID, including the high half
+// of 128-bit IDs.
+func formatTraceID(high, low uint64) string {
+	if high == 0 {
+		return strconv.FormatUint(low, 16)
+	}
+	return fmt.Sprintf("%x%016x", high, low)
+}
+
+// parseTraceID parses a hex trace ID of up to 32 digits.
+func parseTraceID(s string) (high, low uint64, err error) {
+	if len(s) > 32 {
+		return 0, 0, errTraceIDLength
+	}
+	if len(s) > 16 {
+		if high, err = strconv.ParseUint(s[:len(s)-16], 16, 64); err != nil {
+			return 0, 0, err
+		}
+		s = s[len(s)-16:]
+	}
+	low, err = strconv.ParseUint(s, 16, 64)
+	return high, low, err
+}
+
+// traceID64BitPropagator injects the lower 64 bits of trace IDs only, see
+// Options.Propagate64BitTraceIDs.
+type traceID64BitPropagator struct {
+	Propagator
+}
+
+func (p traceID64BitPropagator) Inject(spanContext opentracing.SpanContext, carrier interface{}) error {
+	if sc, ok := spanContext.(SpanContext); ok && sc.TraceIDHigh != 0 {
+		// The cached carriers hold the full trace ID.
+		sc.TraceIDHigh = 0
+		sc.cache = nil
+		spanContext = sc
+	}
+	return p.Propagator.Inject(spanContext, carrier)
+}
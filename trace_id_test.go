@@ -0,0 +1,112 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("128-bit trace IDs", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var opts Options
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			TraceID128Bit:      true,
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("starts traces with 128-bit trace IDs", func() {
+		root := tracer.StartSpan("root")
+		child := tracer.StartSpan("child", opentracing.ChildOf(root.Context()))
+
+		rootContext := root.Context().(SpanContext)
+		Expect(rootContext.TraceIDHigh).ToNot(BeZero())
+		Expect(child.Context().(SpanContext).TraceIDHigh).To(Equal(rootContext.TraceIDHigh))
+		Expect(child.Context().(SpanContext).TraceID).To(Equal(rootContext.TraceID))
+	})
+
+	It("propagates them in the LightStep format", func() {
+		sc := SpanContext{TraceIDHigh: 0x1, TraceID: 0xa1b2c3d4e5f60718, SpanID: 0x2}
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+		Expect(carrier["ot-tracer-traceid"]).To(Equal("1a1b2c3d4e5f60718"))
+
+		extracted, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(extracted.(SpanContext).TraceIDHigh).To(Equal(uint64(0x1)))
+		Expect(extracted.(SpanContext).TraceID).To(Equal(uint64(0xa1b2c3d4e5f60718)))
+	})
+
+	It("rejects LightStep trace IDs longer than 128 bits", func() {
+		carrier := opentracing.TextMapCarrier{
+			"ot-tracer-traceid": "1" + fmt.Sprintf("%032x", 1),
+			"ot-tracer-spanid":  "2",
+			"ot-tracer-sampled": "true",
+		}
+		_, err := tracer.Extract(opentracing.TextMap, carrier)
+		Expect(err).To(Equal(opentracing.ErrSpanContextCorrupted))
+	})
+
+	It("reports the upper half in a tag", func() {
+		span := tracer.StartSpan("root")
+		high := span.Context().(SpanContext).TraceIDHigh
+		span.Finish()
+		tracer.Flush(context.Background())
+
+		Expect(fakeClient.ReportCallCount()).To(Equal(1))
+		_, req, _ := fakeClient.ReportArgsForCall(0)
+		Expect(req.GetSpans()).To(HaveLen(1))
+		var reported string
+		for _, tag := range req.GetSpans()[0].GetTags() {
+			if tag.GetKey() == TraceIDHighKey {
+				reported = tag.GetStringValue()
+			}
+		}
+		Expect(reported).To(Equal(fmt.Sprintf("%x", high)))
+	})
+
+	Context("with Propagate64BitTraceIDs", func() {
+		BeforeEach(func() {
+			opts.Propagate64BitTraceIDs = true
+			opts.Propagators = []string{PropagatorLightStep, PropagatorTraceContext}
+		})
+
+		It("injects 64-bit trace IDs for legacy peers", func() {
+			span := tracer.StartSpan("root")
+			defer span.Finish()
+			sc := span.Context().(SpanContext)
+			carrier := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+
+			Expect(carrier["ot-tracer-traceid"]).To(Equal(fmt.Sprintf("%x", sc.TraceID)))
+			Expect(carrier["traceparent"]).To(Equal(fmt.Sprintf("00-%016x%016x-%016x-01", sc.TraceIDHigh, sc.TraceID, sc.SpanID)))
+		})
+	})
+})
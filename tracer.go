@@ -3,7 +3,9 @@ package lightstep
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"runtime"
@@ -27,6 +29,12 @@ type Tracer interface {
 	Options() Options
 	// Disable prevents the tracer from recording spans or flushing
 	Disable()
+	// Connect blocks until the connection to the LightStep collector is
+	// established, so the first report is not delayed by dialing
+	Connect(context.Context) error
+	// DumpBuffer writes the spans waiting to be reported to the writer as
+	// JSON, to diagnose spans that never reach the collector
+	DumpBuffer(io.Writer) error
 }
 
 // Implements the `Tracer` interface. Buffers spans and forwards the to a Lightstep collector.
@@ -42,10 +50,48 @@ type tracerImpl struct {
 	reporterID uint64 // the LightStep tracer guid
 	opts       Options
 
+	// processors run, in order, on every finished span.
+	processors []spanProcessor
+	// unsampledFastPath starts the spans of traces dropped by caller
+	// sampling as unsampledSpans, see startUnsampledSpan.
+	unsampledFastPath bool
+	// tailSampler holds finished spans until their trace is sampled, if
+	// Options.TailSampling is enabled.
+	tailSampler *tailSampler
+	// memoryLimiter samples traces under memory pressure, if
+	// Options.MaxMemoryBytes is set. It is used under `lock`.
+	memoryLimiter *memoryLimiter
+
+	// runtimeTasks tracks the runtime/trace tasks of unfinished spans, if
+	// Options.RuntimeTrace is set.
+	runtimeTasks *runtimeTasks
+
+	// forwarder accepts spans from other processes, if Options.Forwarder
+	// is enabled.
+	forwarder *forwarder
+
+	// destinations are the tracers of Options.Destinations, by name, and
+	// destination names the destination of a tracer among them.
+	destinations map[string]*tracerImpl
+	destination  string
+
+	// propagation counts Inject and Extract calls, under its own lock.
+	propagation propagationCounter
+	// propagators inject and extract span contexts, by carrier format.
+	propagators map[ot.BuiltinFormat]Propagator
+
+	// effectiveConfig is the redacted Options, if they are to be reported.
+	effectiveConfig string
+
 	// report loop management
 	closeOnce               sync.Once
 	closeReportLoopChannel  chan struct{}
 	reportLoopClosedChannel chan struct{}
+	// spanReady wakes the report loop when a span is buffered, if
+	// Options.StreamingReports is set.
+	spanReady chan struct{}
+	// watchdog tracks the report loop, if Options.Watchdog is enabled.
+	watchdog *reportLoopWatchdog
 
 	//////////////////////////////////////////////////////////
 	// MUTABLE MUTABLE MUTABLE MUTABLE MUTABLE MUTABLE MUTABLE
@@ -66,6 +112,25 @@ type tracerImpl struct {
 	flushingLock      sync.Mutex
 	reportInFlight    bool
 	lastReportAttempt time.Time
+	reportingPeriod   time.Duration
+	reportLatencies   latencyHistogram
+
+	// Retry state, see Options.Backoff.
+	reportBackoff    backoffState
+	reconnectBackoff backoffState
+	nextReconnect    time.Time
+	lastReportFailed bool
+
+	// breaker suppresses reports after consecutive failures, if
+	// Options.CircuitBreaker is enabled.
+	breaker *circuitBreaker
+
+	// The capabilities advertised in the last successful report response,
+	// nil until one has been received.
+	collectorCapabilities capabilitySet
+
+	// Whether a report carrying effectiveConfig has been accepted.
+	configReported bool
 
 	// We allow our remote peer to disable this instrumentation at any
 	// time, turning all potentially costly runtime operations into
@@ -92,18 +157,58 @@ func NewTracer(opts Options) Tracer {
 	attributes[TracerPlatformKey] = TracerPlatformValue
 	attributes[TracerPlatformVersionKey] = runtime.Version()
 	attributes[TracerVersionKey] = TracerVersionValue
+	attributes[TracerProtocolVersionKey] = TracerProtocolVersionValue
+	capabilities := tracerCapabilities
+	if opts.ExperimentalTagDictionary {
+		capabilities = append(capabilities[:len(capabilities):len(capabilities)], CapabilityTagDictionary)
+	}
+	attributes[TracerCapabilitiesKey] = joinCapabilities(capabilities)
+
+	reporterID := genSeededGUID()
+	if opts.ReporterIDFile != "" {
+		reporterID, err = loadOrCreateReporterID(opts.ReporterIDFile)
+		if err != nil {
+			emitEvent(newEventReporterIDError(err, reporterID))
+		}
+	}
 
 	now := time.Now()
 	impl := &tracerImpl{
 		opts:                    opts,
-		reporterID:              genSeededGUID(),
-		buffer:                  newSpansBuffer(opts.MaxBufferedSpans),
-		flushing:                newSpansBuffer(opts.MaxBufferedSpans),
+		reporterID:              reporterID,
+		processors:              newSpanProcessors(opts),
+		reportingPeriod:         opts.ReportingPeriod,
+		propagators:             newPropagators(opts),
+		buffer:                  newSpansBuffer(opts.MaxBufferedSpans, opts.MaxBufferedPrioritySpans),
+		flushing:                newSpansBuffer(opts.MaxBufferedSpans, opts.MaxBufferedPrioritySpans),
 		closeReportLoopChannel:  make(chan struct{}),
 		reportLoopClosedChannel: make(chan struct{}),
 	}
 
 	impl.buffer.setCurrent(now)
+	if opts.TailSampling.Window > 0 {
+		impl.tailSampler = newTailSampler(opts.TailSampling)
+	}
+	if opts.CircuitBreaker.FailureThreshold > 0 {
+		impl.breaker = &circuitBreaker{opts: opts.CircuitBreaker}
+	}
+	if opts.StreamingReports {
+		impl.spanReady = make(chan struct{}, 1)
+	}
+	if opts.MaxMemoryBytes > 0 {
+		impl.memoryLimiter = &memoryLimiter{maxBytes: opts.MaxMemoryBytes, sampleRate: opts.MemoryPressureSampleRate}
+	}
+	if opts.RuntimeTrace {
+		impl.runtimeTasks = &runtimeTasks{}
+	}
+	impl.unsampledFastPath = opts.CallerSampling.enabled() && opts.Recorder == nil &&
+		opts.OnSpanStart == nil && len(opts.Destinations) == 0
+	if opts.Watchdog.enabled() {
+		impl.watchdog = newReportLoopWatchdog(now)
+	}
+	if opts.ReportEffectiveConfig {
+		impl.effectiveConfig = opts.String()
+	}
 
 	impl.client, err = newCollectorClient(opts, impl.reporterID, attributes)
 	if err != nil {
@@ -111,14 +216,32 @@ func NewTracer(opts Options) Tracer {
 		return nil
 	}
 
-	conn, err := impl.client.ConnectClient()
+	conn, err := impl.connectClient()
 	if err != nil {
 		emitEvent(newEventStartError(err))
 		return nil
 	}
 	impl.connection = conn
 
-	go impl.reportLoop()
+	if len(opts.Destinations) > 0 {
+		var started bool
+		if impl.destinations, started = startDestinations(opts); !started {
+			conn.Close()
+			return nil
+		}
+	}
+
+	go impl.reportLoop(0)
+	if impl.watchdog != nil {
+		go impl.watchReportLoop()
+	}
+
+	if opts.Forwarder.Address != "" {
+		impl.forwarder, err = startForwarder(opts.Forwarder, impl.RecordSpan)
+		if err != nil {
+			emitEvent(newEventForwarderError(err))
+		}
+	}
 
 	return impl
 }
@@ -131,60 +254,163 @@ func (tracer *tracerImpl) StartSpan(
 	operationName string,
 	sso ...ot.StartSpanOption,
 ) ot.Span {
-	return newSpan(operationName, tracer, sso)
+	opts := newStartSpanOptions(sso)
+	if tracer.unsampledFastPath {
+		if span, ok := tracer.startUnsampledSpan(opts); ok {
+			return span
+		}
+	}
+	return newSpan(operationName, tracer, opts)
 }
 
 func (tracer *tracerImpl) Inject(sc ot.SpanContext, format interface{}, carrier interface{}) error {
-	switch format {
-	case ot.TextMap, ot.HTTPHeaders:
-		return theTextMapPropagator.Inject(sc, carrier)
-	case ot.Binary:
-		return theBinaryPropagator.Inject(sc, carrier)
+	err := tracer.inject(sc, format, carrier)
+	tracer.propagation.recordInject(format, err)
+	return err
+}
+
+func (tracer *tracerImpl) inject(sc ot.SpanContext, format interface{}, carrier interface{}) error {
+	propagator, found := tracer.propagator(format)
+	if !found {
+		return ot.ErrUnsupportedFormat
 	}
-	return ot.ErrUnsupportedFormat
+	return propagator.Inject(sc, carrier)
 }
 
 func (tracer *tracerImpl) Extract(format interface{}, carrier interface{}) (ot.SpanContext, error) {
-	switch format {
-	case ot.TextMap, ot.HTTPHeaders:
-		return theTextMapPropagator.Extract(carrier)
-	case ot.Binary:
-		return theBinaryPropagator.Extract(carrier)
+	sc, err := tracer.extract(format, carrier)
+	tracer.propagation.recordExtract(format, err)
+	if lsContext, ok := sc.(SpanContext); ok && err == nil {
+		sc = tracer.opts.BaggageLimits.limit(lsContext)
 	}
-	return nil, ot.ErrUnsupportedFormat
+	return sc, err
 }
 
-func (tracer *tracerImpl) reconnectClient(now time.Time) {
+// PropagationStats returns the counts of Inject and Extract calls.
+func (tracer *tracerImpl) PropagationStats() PropagationStats {
+	return tracer.propagation.stats()
+}
+
+// CollectorSupports reports whether the collector advertised a capability
+// in its last report response. It is false until the first successful report.
+func (tracer *tracerImpl) CollectorSupports(capability Capability) bool {
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+	return tracer.collectorCapabilities[capability]
+}
+
+func (tracer *tracerImpl) extract(format interface{}, carrier interface{}) (ot.SpanContext, error) {
+	propagator, found := tracer.propagator(format)
+	if !found {
+		return nil, ot.ErrUnsupportedFormat
+	}
+	return propagator.Extract(carrier)
+}
+
+func (tracer *tracerImpl) propagator(format interface{}) (Propagator, bool) {
+	builtin, ok := format.(ot.BuiltinFormat)
+	if !ok {
+		return nil, false
+	}
+	propagator, found := tracer.propagators[builtin]
+	return propagator, found
+}
+
+// connectClient connects the client, calling the Options.ReportTrace dial
+// hooks.
+// transportName names the transport the tracer's client currently reports
+// with.
+func (tracer *tracerImpl) transportName() string {
+	if transport := activeTransport(tracer.client); transport != "" {
+		return transport
+	}
+	return transportName(tracer.opts)
+}
+
+func (tracer *tracerImpl) connectClient() (Connection, error) {
+	transport := tracer.transportName()
+	tracer.opts.ReportTrace.dialStart(transport)
 	conn, err := tracer.client.ConnectClient()
+	tracer.opts.ReportTrace.dialDone(transport, err)
+	return conn, err
+}
+
+func (tracer *tracerImpl) reconnectClient(now time.Time) {
+	conn, err := tracer.connectClient()
 	if err != nil {
 		emitEvent(newEventConnectionError(err))
+		if tracer.opts.Backoff != nil {
+			tracer.lock.Lock()
+			tracer.nextReconnect = now.Add(tracer.reconnectBackoff.fail(tracer.opts.Backoff))
+			tracer.lock.Unlock()
+		}
 	} else {
 		tracer.lock.Lock()
 		oldConn := tracer.connection
 		tracer.connection = conn
+		tracer.reconnectBackoff.reset()
 		tracer.lock.Unlock()
 
 		oldConn.Close()
 	}
 }
 
+// Connect establishes the connection to the collector ahead of the first
+// report. It returns an error, and emits an EventConnectionError, if the
+// connection is not ready before ctx is done.
+func (tracer *tracerImpl) Connect(ctx context.Context) error {
+	tracer.lock.Lock()
+	conn := tracer.connection
+	tracer.lock.Unlock()
+
+	if conn == nil {
+		return flushErrorTracerClosed
+	}
+
+	waiter, ok := tracer.client.(connectionWaiter)
+	if !ok {
+		return nil
+	}
+	err := waiter.waitForConnection(ctx, conn)
+	if err != nil {
+		emitEvent(newEventConnectionError(err))
+	}
+	return err
+}
+
 // Close flushes and then terminates the LightStep collector. Close may only be
 // called once; subsequent calls to Close are no-ops.
 func (tracer *tracerImpl) Close(ctx context.Context) {
 	tracer.closeOnce.Do(func() {
+		// buffer the spans of forwarded requests in progress
+		if tracer.forwarder != nil {
+			if err := tracer.forwarder.Close(ctx); err != nil {
+				emitEvent(newEventForwarderError(err))
+			}
+		}
+
 		// notify report loop that we are closing
 		close(tracer.closeReportLoopChannel)
 		select {
 		case <-tracer.reportLoopClosedChannel:
-			tracer.Flush(ctx)
+			if tracer.tailSampler != nil {
+				tracer.bufferSampledSpans(tracer.tailSampler.releaseAll())
+			}
+			tracer.flush(ctx)
 		case <-ctx.Done():
 			return
 		}
 
+		for _, destination := range tracer.destinations {
+			destination.Close(ctx)
+		}
+
 		// now its safe to close the connection
 		tracer.lock.Lock()
 		conn := tracer.connection
 		tracer.connection = nil
+		// spans the final flush could not send will never be reported
+		resolveSpans(tracer.buffer.rawSpans, ErrSpanDropped)
 		tracer.lock.Unlock()
 
 		if conn != nil {
@@ -198,24 +424,88 @@ func (tracer *tracerImpl) Close(ctx context.Context) {
 
 // RecordSpan records a finished Span.
 func (tracer *tracerImpl) RecordSpan(raw RawSpan) {
+	if tracer.destination == "" {
+		if name := tracer.routeSpan(&raw); name != "" {
+			tracer.recordDestinationSpan(name, raw)
+			return
+		}
+	}
+
+	for _, process := range tracer.processors {
+		if !process(&raw) {
+			resolveSpans([]RawSpan{raw}, ErrSpanDropped)
+			return
+		}
+	}
+
+	if tracer.tailSampler != nil {
+		tracer.bufferSampledSpans(tracer.tailSampler.add(raw, time.Now()))
+		return
+	}
+	tracer.bufferSpan(raw)
+}
+
+// bufferSampledSpans buffers the spans kept by the tail sampler.
+func (tracer *tracerImpl) bufferSampledSpans(kept, dropped []RawSpan) {
+	for _, raw := range kept {
+		tracer.bufferSpan(raw)
+	}
+	resolveSpans(dropped, ErrSpanDropped)
+}
+
+func (tracer *tracerImpl) bufferSpan(raw RawSpan) {
+	if tracer.memoryLimiter != nil {
+		raw.memoryBytes = estimateSpanMemory(&raw)
+	}
+
 	tracer.lock.Lock()
 
 	// Early-out for disabled runtimes
 	if tracer.disabled {
 		tracer.lock.Unlock()
+		resolveSpans([]RawSpan{raw}, ErrSpanDropped)
 		return
 	}
 
+	var pressureEvent Event
+	if tracer.memoryLimiter != nil {
+		var keep bool
+		keep, pressureEvent = tracer.memoryLimiter.admit(&raw, tracer.buffer.memoryBytes+tracer.flushing.memoryBytes)
+		if !keep {
+			tracer.buffer.droppedSpanCount++
+			tracer.lock.Unlock()
+			if pressureEvent != nil {
+				emitEvent(pressureEvent)
+			}
+			resolveSpans([]RawSpan{raw}, ErrSpanDropped)
+			return
+		}
+	}
+
 	tracer.buffer.addSpan(raw)
 	tracer.lock.Unlock()
+	tracer.signalSpanReady()
+
+	if pressureEvent != nil {
+		emitEvent(pressureEvent)
+	}
 
 	if tracer.opts.Recorder != nil {
 		tracer.opts.Recorder.RecordSpan(raw)
 	}
 }
 
-// Flush sends all buffered data to the collector.
+// Flush sends all buffered data to the collector, and that of
+// Options.Destinations to theirs.
 func (tracer *tracerImpl) Flush(ctx context.Context) {
+	tracer.flush(ctx)
+	for _, destination := range tracer.destinations {
+		destination.flush(ctx)
+	}
+}
+
+// flush sends the tracer's buffered data to its collector.
+func (tracer *tracerImpl) flush(ctx context.Context) {
 	tracer.flushingLock.Lock()
 	defer tracer.flushingLock.Unlock()
 
@@ -232,28 +522,126 @@ func (tracer *tracerImpl) Flush(ctx context.Context) {
 		errorEvent := newEventFlushError(err, FlushErrorTranslate)
 		emitEvent(errorEvent)
 		// call postflush to prevent the tracer from going into an invalid state.
-		emitEvent(tracer.postFlush(errorEvent))
+		emitEvent(tracer.postFlush(errorEvent, nil))
+		return
+	}
+
+	if tracer.opts.DryRun {
+		tracer.recordDryRun(req)
+		statusReportEvent := tracer.postFlush(nil, nil)
+		statusReportEvent.SetPayloadBytes(req.size())
+		emitEvent(statusReportEvent)
 		return
 	}
 
 	var reportErrorEvent *eventFlushError
+	var partialSuccessEvent *eventReportPartialSuccess
+	var reportErr error
+	transport := tracer.transportName()
+	sentSpans := len(tracer.flushing.rawSpans)
+	tracer.opts.ReportTrace.reportStart(transport, sentSpans, req)
+	reportStart := time.Now()
 	resp, err := tracer.client.Report(ctx, req)
+	latency := time.Since(reportStart)
 	if err != nil {
 		reportErrorEvent = newEventFlushError(err, FlushErrorTransport)
-	} else if len(resp.GetErrors()) > 0 {
-		reportErrorEvent = newEventFlushError(fmt.Errorf(resp.GetErrors()[0]), FlushErrorReport)
+	} else if errs := resp.GetErrors(); len(errs) > 0 {
+		if rejected, ok := responseRejectedSpans(resp); ok {
+			// the collector's answer is final: it accepted the spans it
+			// didn't reject, and would reject the others again, so the
+			// report isn't retried.
+			partialSuccessEvent = newEventReportPartialSuccess(errs, int(rejected))
+		} else {
+			reportErrorEvent = newEventFlushError(errors.New(errs[0]), FlushErrorReport)
+		}
 	}
 
 	if reportErrorEvent != nil {
+		reportErr = reportErrorEvent
 		emitEvent(reportErrorEvent)
 	}
-	emitEvent(tracer.postFlush(reportErrorEvent))
+	tracer.opts.ReportTrace.reportDone(transport, reportErr)
+
+	var capabilitiesEvent, breakerEvent Event
+	tracer.lock.Lock()
+	if tracer.breaker != nil {
+		// Only transport errors open the breaker: a collector that
+		// answers, even rejecting every span, is reachable.
+		breakerEvent = tracer.breaker.record(err != nil, time.Now())
+	}
+	tracer.adaptReportingPeriod(latency, reportErr)
+	reportingPeriod := tracer.reportingPeriod
+	tracer.lastReportFailed = reportErr != nil
+	if reportErr == nil {
+		tracer.reportBackoff.reset()
+		if caps, ok := collectorCapabilities(resp); ok && !caps.equal(tracer.collectorCapabilities) {
+			tracer.collectorCapabilities = caps
+			capabilitiesEvent = newEventCollectorCapabilities(caps.list())
+		}
+	} else if tracer.opts.Backoff != nil {
+		tracer.reportBackoff.fail(tracer.opts.Backoff)
+	}
+	tracer.lock.Unlock()
+
+	if capabilitiesEvent != nil {
+		emitEvent(capabilitiesEvent)
+	}
+	if breakerEvent != nil {
+		emitEvent(breakerEvent)
+	}
+	if partialSuccessEvent != nil {
+		emitEvent(partialSuccessEvent)
+	}
+
+	statusReportEvent := tracer.postFlush(reportErrorEvent, partialSuccessEvent)
+	if reportErrorEvent == nil {
+		statusReportEvent.SetPayloadBytes(req.size())
+	}
+	statusReportEvent.SetReportLatency(latency, reportingPeriod)
+	emitEvent(statusReportEvent)
 
 	if err == nil && resp.Disable() {
 		tracer.Disable()
 	}
 }
 
+// recordDryRun hands a report that will not be sent to the ReportRecorder,
+// if one is configured. A report for a pool of collectors, or for tenants,
+// is recorded once per collector and access token.
+func (tracer *tracerImpl) recordDryRun(req reportRequest) {
+	recorder, ok := tracer.opts.Recorder.(ReportRecorder)
+	if !ok {
+		return
+	}
+
+	if req.shards == nil {
+		req.spanCount = len(tracer.flushing.rawSpans)
+	}
+	recordReports(recorder, req)
+}
+
+// recordReports records req, or each of its shards.
+func recordReports(recorder ReportRecorder, req reportRequest) {
+	if req.shards != nil {
+		for _, shard := range req.shards {
+			if !shard.empty() {
+				recordReports(recorder, shard)
+			}
+		}
+		return
+	}
+
+	payload, err := req.payload()
+	if err != nil {
+		emitEvent(newEventFlushError(err, FlushErrorTranslate))
+		return
+	}
+	recorder.RecordReport(ReportPreview{
+		Spans:   req.spanCount,
+		Payload: payload,
+	})
+}
+
 // preFlush handles lock-protected data manipulation before flushing
 func (tracer *tracerImpl) preFlush() *eventFlushError {
 	tracer.lock.Lock()
@@ -268,16 +656,30 @@ func (tracer *tracerImpl) preFlush() *eventFlushError {
 	}
 
 	now := time.Now()
+	if tracer.opts.BufferedSpanTTL > 0 {
+		resolveSpans(tracer.buffer.expire(now.Add(-tracer.opts.BufferedSpanTTL)), ErrSpanDropped)
+	}
+	if tracer.breaker != nil && !tracer.breaker.allow(now) {
+		return newEventFlushError(flushErrorCircuitOpen, FlushErrorCircuitOpen)
+	}
 	tracer.buffer, tracer.flushing = tracer.flushing, tracer.buffer
 	tracer.reportInFlight = true
 	tracer.flushing.setFlushing(now)
 	tracer.buffer.setCurrent(now)
+	tracer.flushing.tagDictionary = tracer.opts.ExperimentalTagDictionary &&
+		tracer.collectorCapabilities[CapabilityTagDictionary]
+	tracer.flushing.indexedTags = tracer.collectorCapabilities[CapabilityIndexedTags]
+	if tracer.effectiveConfig != "" && !tracer.configReported {
+		tracer.flushing.attributes = map[string]string{EffectiveConfigKey: tracer.effectiveConfig}
+	}
 	tracer.lastReportAttempt = now
 	return nil
 }
 
-// postFlush handles lock-protected data manipulation after flushing
-func (tracer *tracerImpl) postFlush(flushEventError *eventFlushError) *eventStatusReport {
+// postFlush handles lock-protected data manipulation after flushing. The
+// spans of a report the collector rejected spans of aren't retried, but
+// their FinishHandles are resolved with an error, see rejectionError.
+func (tracer *tracerImpl) postFlush(flushEventError *eventFlushError, partialSuccess *eventReportPartialSuccess) *eventStatusReport {
 	tracer.lock.Lock()
 	defer tracer.lock.Unlock()
 
@@ -290,22 +692,37 @@ func (tracer *tracerImpl) postFlush(flushEventError *eventFlushError) *eventStat
 		int(tracer.flushing.droppedSpanCount+tracer.buffer.droppedSpanCount),
 		int(tracer.flushing.logEncoderErrorCount+tracer.buffer.logEncoderErrorCount),
 	)
+	statusReportEvent.SetExpiredSpans(int(tracer.flushing.expiredSpanCount + tracer.buffer.expiredSpanCount))
 
 	if flushEventError == nil {
+		if tracer.flushing.attributes != nil {
+			tracer.configReported = true
+		}
+		if partialSuccess != nil {
+			statusReportEvent.SetRejectedSpans(partialSuccess.rejectedSpans)
+			resolveSpans(tracer.flushing.rawSpans, rejectionError(partialSuccess.rejectedSpans, len(tracer.flushing.rawSpans)))
+		} else {
+			resolveSpans(tracer.flushing.rawSpans, nil)
+		}
 		tracer.flushing.clear()
 		return statusReportEvent
 	}
 
+	var sentSpans int
 	switch flushEventError.State() {
 	case FlushErrorTranslate:
 		// When there's a translation error, we do not want to retry.
+		resolveSpans(tracer.flushing.rawSpans, flushEventError.Err())
 		tracer.flushing.clear()
 	default:
 		// Restore the records that did not get sent correctly
+		if partial, ok := flushEventError.Err().(*partialReportError); ok {
+			sentSpans = tracer.flushing.retainFailed(partial)
+		}
 		tracer.buffer.mergeFrom(&tracer.flushing)
 	}
 
-	statusReportEvent.SetSentSpans(0)
+	statusReportEvent.SetSentSpans(sentSpans)
 
 	return statusReportEvent
 }
@@ -317,6 +734,7 @@ func (tracer *tracerImpl) Disable() {
 		return
 	}
 	tracer.disabled = true
+	resolveSpans(tracer.buffer.rawSpans, ErrSpanDropped)
 	tracer.buffer.clear()
 	tracer.lock.Unlock()
 
@@ -338,38 +756,93 @@ func (tracer *tracerImpl) Disable() {
 // peers).
 
 func (tracer *tracerImpl) shouldFlushLocked(now time.Time) bool {
-	if now.Add(tracer.opts.MinReportingPeriod).Sub(tracer.lastReportAttempt) > tracer.opts.ReportingPeriod {
+	if tracer.breaker != nil && !tracer.breaker.allow(now) {
+		return false
+	}
+	if tracer.opts.ManualReports.Enabled {
+		return tracer.manualReportDueLocked(now)
+	}
+
+	period := tracer.reportingPeriod
+	if tracer.reportBackoff.delay > period {
+		period = tracer.reportBackoff.delay
+	}
+
+	if now.Add(tracer.opts.MinReportingPeriod).Sub(tracer.lastReportAttempt) > period {
 		return true
 	} else if tracer.buffer.isHalfFull() {
 		return true
+	} else if tracer.buffer.prioritySpanCount > 0 {
+		return true
 	}
 	return false
 }
 
-func (tracer *tracerImpl) reportLoop() {
-	tickerChan := time.Tick(tracer.opts.MinReportingPeriod)
+// reportLoopFlush flushes from the report loop of generation, recording
+// the flush with the watchdog.
+func (tracer *tracerImpl) reportLoopFlush(generation int) {
+	tracer.watchdog.flushStart()
+	completed := false
+	defer func() { tracer.watchdog.flushEnd(generation, completed) }()
+	tracer.flush(context.Background())
+	completed = true
+}
+
+// reportLoop reports spans in the background until the tracer is closed or
+// disabled. generation numbers the loop for the watchdog, which replaces a
+// stalled loop with one of the next generation.
+func (tracer *tracerImpl) reportLoop(generation int) {
+	if tracer.watchdog != nil {
+		defer tracer.watchdog.recoverReportLoop(generation)
+	}
+	ticker := time.NewTicker(tracer.opts.MinReportingPeriod)
+	defer ticker.Stop()
+	// linger fires when the spans buffered since the last report are due
+	// to be streamed, see Options.StreamingReports.
+	var linger <-chan time.Time
 	for {
 		select {
-		case <-tickerChan:
+		case <-tracer.spanReady:
+			if linger == nil {
+				linger = time.After(tracer.opts.StreamingLinger)
+			}
+		case <-linger:
+			linger = nil
+			tracer.lock.Lock()
+			shouldStream := tracer.shouldStreamLocked(time.Now())
+			tracer.lock.Unlock()
+			if shouldStream {
+				tracer.reportLoopFlush(generation)
+			}
+		case <-ticker.C:
 			now := time.Now()
+			if !tracer.watchdog.tick(generation, now) {
+				return
+			}
 
 			tracer.lock.Lock()
 			disabled := tracer.disabled
-			reconnect := !tracer.reportInFlight && tracer.client.ShouldReconnect()
+			reconnect := !tracer.reportInFlight && !now.Before(tracer.nextReconnect) && tracer.client.ShouldReconnect()
 			shouldFlush := tracer.shouldFlushLocked(now)
 			tracer.lock.Unlock()
 
 			if disabled {
+				tracer.watchdog.stop(generation)
 				return
 			}
+			if tracer.tailSampler != nil {
+				tracer.bufferSampledSpans(tracer.tailSampler.release(now))
+			}
 			if shouldFlush {
-				tracer.Flush(context.Background())
+				tracer.reportLoopFlush(generation)
 			}
 			if reconnect {
 				tracer.reconnectClient(now)
 			}
 		case <-tracer.closeReportLoopChannel:
-			close(tracer.reportLoopClosedChannel)
+			if tracer.watchdog.stop(generation) {
+				close(tracer.reportLoopClosedChannel)
+			}
 			return
 		}
 	}
@@ -2,6 +2,7 @@ package lightstep
 
 import (
 	"context"
+	"io"
 
 	opentracing "github.com/opentracing/opentracing-go"
 )
@@ -79,3 +80,55 @@ func GetLightStepReporterID(tracer opentracing.Tracer) (uint64, error) {
 		return 0, newEventUnsupportedTracer(tracer)
 	}
 }
+
+// Connect establishes the tracer's connection to the collector ahead of the
+// first report.
+func Connect(ctx context.Context, tracer opentracing.Tracer) error {
+	switch lsTracer := tracer.(type) {
+	case Tracer:
+		return lsTracer.Connect(ctx)
+	case *tracerv0_14:
+		return Connect(ctx, lsTracer.Tracer)
+	default:
+		return newEventUnsupportedTracer(tracer)
+	}
+}
+
+// DumpBuffer writes the spans the tracer has not yet reported to w as JSON,
+// see BufferDump.
+func DumpBuffer(tracer opentracing.Tracer, w io.Writer) error {
+	switch lsTracer := tracer.(type) {
+	case Tracer:
+		return lsTracer.DumpBuffer(w)
+	case *tracerv0_14:
+		return DumpBuffer(lsTracer.Tracer, w)
+	default:
+		return newEventUnsupportedTracer(tracer)
+	}
+}
+
+// GetPropagationStats returns the counts of the tracer's Inject and Extract
+// calls, by carrier format and outcome.
+func GetPropagationStats(tracer opentracing.Tracer) (PropagationStats, error) {
+	switch lsTracer := tracer.(type) {
+	case *tracerImpl:
+		return lsTracer.PropagationStats(), nil
+	case *tracerv0_14:
+		return GetPropagationStats(lsTracer.Tracer)
+	default:
+		return PropagationStats{}, newEventUnsupportedTracer(tracer)
+	}
+}
+
+// CollectorSupports reports whether the tracer's collector accepts an
+// optional payload feature. It is false until the first successful report.
+func CollectorSupports(tracer opentracing.Tracer, capability Capability) (bool, error) {
+	switch lsTracer := tracer.(type) {
+	case *tracerImpl:
+		return lsTracer.CollectorSupports(capability), nil
+	case *tracerv0_14:
+		return CollectorSupports(lsTracer.Tracer, capability)
+	default:
+		return false, newEventUnsupportedTracer(tracer)
+	}
+}
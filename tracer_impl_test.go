@@ -1,3 +1,6 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
 package lightstep
 
 import (
@@ -86,6 +89,14 @@ func fakeGrpcConnection(fakeClient *cpbfakes.FakeCollectorServiceClient) Connect
 	}
 }
 
+// fakeCollectorConnection connects to a fake collector that accepts every
+// report, for specs that don't look at what is reported.
+func fakeCollectorConnection() ConnectorFactory {
+	fakeClient := new(cpbfakes.FakeCollectorServiceClient)
+	fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+	return fakeGrpcConnection(fakeClient)
+}
+
 type fakeCollectorClient struct {
 	realClient      collectorClient
 	report          func(context.Context, reportRequest) (collectorResponse, error)
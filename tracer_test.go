@@ -1,12 +1,26 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
 package lightstep_test
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 
 	. "github.com/lightstep/lightstep-tracer-go"
@@ -16,6 +30,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 )
 
 var _ = Describe("Tracer", func() {
@@ -328,6 +343,889 @@ var _ = Describe("Tracer", func() {
 		})
 	})
 
+	Describe("StartStackFrames", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:      accessToken,
+				ConnFactory:      fakeConn,
+				Recorder:         fakeRecorder,
+				StartStackFrames: 2,
+			}
+		})
+
+		It("tags the span with the caller's stack", func() {
+			tracer.StartSpan("span").Finish()
+			Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(1))
+
+			stack, ok := fakeRecorder.RecordSpanArgsForCall(0).Tags[StartStackKey].(string)
+			Expect(ok).To(BeTrue())
+			Expect(strings.Split(stack, "\n")).To(HaveLen(2))
+			Expect(stack).To(ContainSubstring("lightstep-tracer-go_test"))
+			Expect(stack).NotTo(ContainSubstring("newSpan"))
+		})
+
+		It("doesn't add the stack to the caller's tags", func() {
+			tags := opentracing.Tags{"key": "value"}
+			tracer.StartSpan("span", tags).Finish()
+			tracer.StartSpan("span", tags).Finish()
+
+			Expect(tags).To(Equal(opentracing.Tags{"key": "value"}))
+			Expect(fakeRecorder.RecordSpanArgsForCall(1).Tags).To(HaveKeyWithValue("key", "value"))
+		})
+	})
+
+	Describe("InferOperationName", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:        accessToken,
+				ConnFactory:        fakeConn,
+				Recorder:           fakeRecorder,
+				InferOperationName: true,
+			}
+		})
+
+		It("names spans without an operation name after the caller", func() {
+			tracer.StartSpan("").Finish()
+			Expect(fakeRecorder.RecordSpanArgsForCall(0).Operation).To(HavePrefix("lightstep-tracer-go_test."))
+		})
+
+		It("keeps explicit operation names", func() {
+			tracer.StartSpan("span").Finish()
+			Expect(fakeRecorder.RecordSpanArgsForCall(0).Operation).To(Equal("span"))
+		})
+	})
+
+	Describe("payload accounting", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+				Recorder:    fakeRecorder,
+			}
+		})
+
+		It("reports the size of each sent report", func() {
+			tracer.StartSpan("span").Finish()
+			tracer.Flush(context.Background())
+
+			event := <-eventChan
+			statusReport, ok := event.(EventStatusReport)
+			Expect(ok).To(BeTrue())
+
+			_, request, _ := fakeClient.ReportArgsForCall(0)
+			Expect(statusReport.PayloadBytes()).To(Equal(proto.Size(request)))
+		})
+
+		It("estimates the size of individual spans", func() {
+			span := tracer.StartSpan("span")
+			span.SetTag("key", "value")
+			span.Finish()
+			tracer.Flush(context.Background())
+
+			_, request, _ := fakeClient.ReportArgsForCall(0)
+			raw := fakeRecorder.RecordSpanArgsForCall(0)
+			spanBytes := proto.Size(request) - proto.Size(&cpb.ReportRequest{
+				Reporter:        request.Reporter,
+				Auth:            request.Auth,
+				InternalMetrics: request.InternalMetrics,
+			})
+			Expect(EstimateSpanBytes(raw)).To(Equal(spanBytes))
+		})
+	})
+
+	Describe("DryRun", func() {
+		var reportRecorder *testReportRecorder
+
+		BeforeEach(func() {
+			reportRecorder = &testReportRecorder{FakeSpanRecorder: fakeRecorder}
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+				Recorder:    reportRecorder,
+				DryRun:      true,
+			}
+		})
+
+		It("serializes reports without sending them", func() {
+			tracer.StartSpan("span").Finish()
+			tracer.Flush(context.Background())
+
+			Expect(fakeClient.ReportCallCount()).To(BeZero())
+			previews := reportRecorder.Previews()
+			Expect(previews).To(HaveLen(1))
+			Expect(previews[0].Spans).To(Equal(1))
+
+			var request cpb.ReportRequest
+			Expect(proto.Unmarshal(previews[0].Payload, &request)).To(Succeed())
+			Expect(request.GetSpans()).To(HaveLen(1))
+			Expect(request.GetSpans()[0].GetOperationName()).To(Equal("span"))
+		})
+
+		It("counts the spans that would have been sent", func() {
+			tracer.StartSpan("span").Finish()
+			tracer.Flush(context.Background())
+
+			event := <-eventChan
+			statusReport, ok := event.(EventStatusReport)
+			Expect(ok).To(BeTrue())
+			Expect(statusReport.SentSpans()).To(Equal(1))
+		})
+	})
+
+	Describe("FinishAsync", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:      accessToken,
+				ConnFactory:      fakeConn,
+				MaxBufferedSpans: 1,
+			}
+		})
+
+		It("resolves once the span is reported", func() {
+			handle := FinishAsync(tracer.StartSpan("span"))
+			Consistently(handle.Done()).ShouldNot(BeClosed())
+
+			tracer.Flush(context.Background())
+			Expect(handle.Done()).To(BeClosed())
+			Expect(handle.Err()).To(BeNil())
+		})
+
+		It("stays pending while reports are retried", func() {
+			fakeClient.ReportReturnsOnCall(0, nil, errors.New("fail"))
+			handle := FinishAsync(tracer.StartSpan("span"))
+
+			tracer.Flush(context.Background())
+			Expect(handle.Done()).NotTo(BeClosed())
+
+			tracer.Flush(context.Background())
+			Expect(handle.Err()).To(BeNil())
+		})
+
+		It("resolves with an error when the span is dropped", func() {
+			tracer.StartSpan("fills the buffer").Finish()
+			handle := FinishAsync(tracer.StartSpan("span"))
+			Expect(handle.Err()).To(Equal(ErrSpanDropped))
+		})
+
+		It("resolves with an error when the span was already finished", func() {
+			span := tracer.StartSpan("span")
+			span.Finish()
+			Expect(FinishAsync(span).Err()).To(Equal(ErrSpanAlreadyFinished))
+		})
+	})
+
+	Describe("MustDeliver", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:              accessToken,
+				ConnFactory:              fakeConn,
+				MaxBufferedSpans:         1,
+				MaxBufferedPrioritySpans: 1,
+			}
+		})
+
+		It("is buffered when the buffer is full of regular spans", func() {
+			tracer.StartSpan("regular").Finish()
+			tracer.StartSpan("dropped").Finish()
+			tracer.StartSpan("audit", MustDeliver{}).Finish()
+			tracer.Flush(context.Background())
+
+			spans := getReportedGRPCSpans(fakeClient)
+			Expect(spans).To(HaveLen(2))
+			Expect(spans[0].GetOperationName()).To(Equal("regular"))
+			Expect(spans[1].GetOperationName()).To(Equal("audit"))
+		})
+
+		It("is kept over regular spans when a report is retried", func() {
+			fakeClient.ReportReturnsOnCall(0, nil, errors.New("fail"))
+			tracer.StartSpan("audit", MustDeliver{}).Finish()
+			tracer.Flush(context.Background())
+
+			tracer.StartSpan("regular").Finish()
+			tracer.StartSpan("dropped").Finish()
+			tracer.Flush(context.Background())
+
+			_, request, _ := fakeClient.ReportArgsForCall(1)
+			var names []string
+			for _, span := range request.GetSpans() {
+				names = append(names, span.GetOperationName())
+			}
+			Expect(names).To(ConsistOf("regular", "audit"))
+		})
+	})
+
+	Describe("TagAllowList and TagDenyList", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:  accessToken,
+				ConnFactory:  fakeConn,
+				Recorder:     fakeRecorder,
+				TagAllowList: []string{"http.*", "user.*"},
+				TagDenyList:  []string{"user.email"},
+			}
+		})
+
+		It("only reports allowed tags", func() {
+			span := tracer.StartSpan("span")
+			span.SetTag("http.method", "GET")
+			span.SetTag("user.id", 42)
+			span.SetTag("user.email", "someone@example.com")
+			span.SetTag("db.statement", "SELECT 1")
+			span.Finish()
+
+			Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(1))
+			raw := fakeRecorder.RecordSpanArgsForCall(0)
+			Expect(raw.Tags).To(HaveLen(2))
+			Expect(raw.Tags).To(HaveKey("http.method"))
+			Expect(raw.Tags).To(HaveKey("user.id"))
+		})
+	})
+
+	Describe("span timing", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+				Recorder:    fakeRecorder,
+			}
+		})
+
+		It("records a wall clock start time", func() {
+			tracer.StartSpan("span").Finish()
+
+			raw := fakeRecorder.RecordSpanArgsForCall(0)
+			Expect(raw.Start.String()).NotTo(ContainSubstring("m="))
+			Expect(raw.Duration).To(BeNumerically(">=", 0))
+			Expect(raw.FinishTime()).To(Equal(raw.Start.Add(raw.Duration)))
+		})
+
+		It("never records a negative duration", func() {
+			start := time.Now().Add(time.Hour)
+			span := tracer.StartSpan("span", opentracing.StartTime(start))
+			span.Finish()
+			span.Finish()
+
+			Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(1))
+			Expect(fakeRecorder.RecordSpanArgsForCall(0).Duration).To(BeZero())
+		})
+	})
+
+	Describe("MinSpanDuration", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:     accessToken,
+				ConnFactory:     fakeConn,
+				Recorder:        fakeRecorder,
+				MinSpanDuration: time.Second,
+			}
+		})
+
+		It("drops short child spans unless they failed", func() {
+			start := time.Now()
+			finish := func(span opentracing.Span, d time.Duration) {
+				span.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(d)})
+			}
+			root := tracer.StartSpan("root", opentracing.StartTime(start))
+			child := func(name string) opentracing.Span {
+				return tracer.StartSpan(name, opentracing.ChildOf(root.Context()), opentracing.StartTime(start))
+			}
+
+			finish(child("short"), time.Millisecond)
+			finish(child("long"), 2*time.Second)
+			failed := child("failed")
+			ext.Error.Set(failed, true)
+			finish(failed, time.Millisecond)
+			finish(root, time.Millisecond)
+
+			var names []string
+			for i := 0; i < fakeRecorder.RecordSpanCallCount(); i++ {
+				names = append(names, fakeRecorder.RecordSpanArgsForCall(i).Operation)
+			}
+			Expect(names).To(ConsistOf("long", "failed", "root"))
+		})
+	})
+
+	Describe("MaxSpansPerTrace", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:      accessToken,
+				ConnFactory:      fakeConn,
+				Recorder:         fakeRecorder,
+				MaxSpansPerTrace: 3,
+			}
+		})
+
+		It("truncates traces with too many spans", func() {
+			root := tracer.StartSpan("root")
+			for i := 0; i < 5; i++ {
+				tracer.StartSpan("loop", opentracing.ChildOf(root.Context())).Finish()
+			}
+			tracer.StartSpan("important", opentracing.ChildOf(root.Context()), MustDeliver{}).Finish()
+			root.Finish()
+			tracer.StartSpan("other trace").Finish()
+
+			Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(5))
+			var truncated []string
+			for i := 0; i < fakeRecorder.RecordSpanCallCount(); i++ {
+				raw := fakeRecorder.RecordSpanArgsForCall(i)
+				if raw.Tags[TraceTruncatedKey] == true {
+					truncated = append(truncated, raw.Operation)
+				}
+			}
+			Expect(truncated).To(Equal([]string{"loop"}))
+			Expect(fakeRecorder.RecordSpanArgsForCall(3).Operation).To(Equal("important"))
+		})
+	})
+
+	Describe("TailSampling", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:  accessToken,
+				ConnFactory:  fakeConn,
+				Recorder:     fakeRecorder,
+				TailSampling: TailSamplingOptions{Window: time.Minute},
+			}
+		})
+
+		It("holds spans until their trace is decided, and keeps failed traces", func() {
+			ok := tracer.StartSpan("ok")
+			ok.Finish()
+			root := tracer.StartSpan("root")
+			child := tracer.StartSpan("child", opentracing.ChildOf(root.Context()))
+			ext.Error.Set(child, true)
+			child.Finish()
+			root.Finish()
+			Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(0))
+
+			Close(context.Background(), tracer)
+
+			var names []string
+			for i := 0; i < fakeRecorder.RecordSpanCallCount(); i++ {
+				names = append(names, fakeRecorder.RecordSpanArgsForCall(i).Operation)
+			}
+			Expect(names).To(ConsistOf("child", "root"))
+		})
+	})
+
+	Describe("Forwarder", func() {
+		var dir string
+		var client *http.Client
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "forwarder")
+			Expect(err).NotTo(HaveOccurred())
+			socket := filepath.Join(dir, "spans.sock")
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+				Recorder:    fakeRecorder,
+				Forwarder:   ForwarderOptions{Address: socket},
+			}
+			client = &http.Client{Transport: &http.Transport{
+				Dial: func(_, _ string) (net.Conn, error) {
+					return net.Dial("unix", socket)
+				},
+			}}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		post := func(body string) int {
+			resp, err := client.Post("http://forwarder"+ForwarderPath, "application/json", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		It("records spans posted by other processes", func() {
+			Expect(post(`[{
+				"trace_id": "ffffffffffffffff", "span_id": "2", "parent_span_id": "1",
+				"operation": "forwarded", "start": "2018-01-02T03:04:05Z", "duration_micros": 1500,
+				"tags": {"count": 3, "ratio": 0.5, "ok": true},
+				"logs": [{"timestamp": "2018-01-02T03:04:05.001Z", "fields": {"event": "retry"}}]
+			}]`)).To(Equal(http.StatusAccepted))
+
+			Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(1))
+			raw := fakeRecorder.RecordSpanArgsForCall(0)
+			Expect(raw.Context.TraceID).To(Equal(uint64(math.MaxUint64)))
+			Expect(raw.ParentSpanID).To(Equal(uint64(1)))
+			Expect(raw.Operation).To(Equal("forwarded"))
+			Expect(raw.Duration).To(Equal(1500 * time.Microsecond))
+			Expect(raw.Tags).To(Equal(opentracing.Tags{"count": int64(3), "ratio": 0.5, "ok": true}))
+			Expect(raw.Logs).To(HaveLen(1))
+			Expect(raw.Logs[0].Fields[0].Value()).To(Equal("retry"))
+		})
+
+		It("rejects malformed spans", func() {
+			Expect(post(`[{"trace_id": "xyz", "span_id": "2"}]`)).To(Equal(http.StatusBadRequest))
+			Expect(post(`not json`)).To(Equal(http.StatusBadRequest))
+			Expect(fakeRecorder.RecordSpanCallCount()).To(Equal(0))
+		})
+
+		It("round trips spans recorded by this package", func() {
+			span := tracer.StartSpan("local", opentracing.Tag{Key: "component", Value: "worker"})
+			span.LogKV("event", "done")
+			span.Finish()
+			raw := fakeRecorder.RecordSpanArgsForCall(0)
+
+			forwarded, err := NewForwardedSpan(raw).RawSpan()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(forwarded.Context.TraceID).To(Equal(raw.Context.TraceID))
+			Expect(forwarded.Operation).To(Equal("local"))
+			Expect(forwarded.Tags["component"]).To(Equal("worker"))
+			Expect(forwarded.Logs[0].Fields[0].Value()).To(Equal("done"))
+		})
+	})
+
+	Describe("OnSpanStart", func() {
+		var startOptions []opentracing.StartSpanOptions
+
+		BeforeEach(func() {
+			startOptions = nil
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+				Recorder:    fakeRecorder,
+				OnSpanStart: func(span opentracing.Span, options opentracing.StartSpanOptions) {
+					startOptions = append(startOptions, options)
+					span.SetOperationName("framework." + options.Tags["route"].(string))
+					span.SetTag("framework", "test")
+				},
+			}
+		})
+
+		It("lets the hook tag and rename spans as they start", func() {
+			tracer.StartSpan("span", opentracing.Tag{Key: "route", Value: "checkout"}).Finish()
+
+			Expect(startOptions).To(HaveLen(1))
+			raw := fakeRecorder.RecordSpanArgsForCall(0)
+			Expect(raw.Operation).To(Equal("framework.checkout"))
+			Expect(raw.Tags).To(HaveKeyWithValue("framework", "test"))
+		})
+	})
+
+	Describe("AttachmentStore", func() {
+		var store *memoryAttachmentStore
+
+		BeforeEach(func() {
+			store = &memoryAttachmentStore{}
+			opts = Options{
+				AccessToken:         accessToken,
+				ConnFactory:         fakeConn,
+				Recorder:            fakeRecorder,
+				AttachmentStore:     store,
+				AttachmentThreshold: 8,
+			}
+		})
+
+		It("reports large log values by reference", func() {
+			span := tracer.StartSpan("span")
+			span.LogKV("small", "value", "large", "a very long value")
+			span.Finish()
+
+			Expect(store.attachments).To(HaveLen(1))
+			Expect(store.attachments[0].Key).To(Equal("large"))
+			Expect(store.attachments[0].Value).To(Equal("a very long value"))
+
+			raw := fakeRecorder.RecordSpanArgsForCall(0)
+			Expect(store.attachments[0].SpanID).To(Equal(raw.Context.SpanID))
+			Expect(raw.Tags).To(HaveKeyWithValue(AttachmentsKey, 1))
+			fields := raw.Logs[0].Fields
+			Expect(fields[0].Value()).To(Equal("value"))
+			Expect(fields[1].Value()).To(Equal("attachment-1"))
+		})
+	})
+
+	Describe("Connect", func() {
+		var server *httptest.Server
+		var headRequests chan string
+
+		BeforeEach(func() {
+			headRequests = make(chan string, 10)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					headRequests <- r.URL.Path
+				}
+			}))
+			serverURL, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			port, err := strconv.Atoi(serverURL.Port())
+			Expect(err).NotTo(HaveOccurred())
+
+			opts = Options{
+				AccessToken: accessToken,
+				Collector:   Endpoint{Host: serverURL.Hostname(), Port: port, Plaintext: true},
+				UseHttp:     true,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("opens a connection to the collector", func() {
+			Expect(Connect(context.Background(), tracer)).To(Succeed())
+			Expect(headRequests).To(Receive(Equal("/api/v2/reports")))
+		})
+
+		It("fails once the tracer is closed", func() {
+			closeTestTracer(tracer)
+			Expect(tracer.Connect(context.Background())).NotTo(Succeed())
+		})
+	})
+
+	Describe("GRPCFallbackToHttp", func() {
+		var server *httptest.Server
+		var httpReports chan *cpb.ReportRequest
+
+		var reportTransports chan string
+
+		BeforeEach(func() {
+			httpReports = make(chan *cpb.ReportRequest, 10)
+			reportTransports = make(chan string, 10)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				request := &cpb.ReportRequest{}
+				if err := proto.Unmarshal(body, request); err == nil {
+					httpReports <- request
+				}
+				response, _ := proto.Marshal(&cpb.ReportResponse{})
+				w.Write(response)
+			}))
+			serverURL, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			port, err := strconv.Atoi(serverURL.Port())
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClient.ReportReturns(nil, errors.New("blocked"))
+			opts = Options{
+				AccessToken:        accessToken,
+				ConnFactory:        fakeConn,
+				Collector:          Endpoint{Host: serverURL.Hostname(), Port: port, Plaintext: true},
+				GRPCFallbackToHttp: true,
+				GRPCFallbackAfter:  2,
+				ReportTrace: ReportTrace{
+					OnReportStart: func(transport string, spans, payloadBytes int) {
+						reportTransports <- transport
+					},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("switches to HTTP after repeated gRPC failures", func() {
+			tracer.StartSpan("span").Finish()
+			tracer.Flush(context.Background())
+			Expect(httpReports).To(BeEmpty())
+			tracer.Flush(context.Background())
+			Expect(fakeClient.ReportCallCount()).To(Equal(2))
+
+			Eventually(func() bool {
+				select {
+				case event := <-eventChan:
+					_, ok := event.(EventTransportFallback)
+					return ok
+				default:
+					return false
+				}
+			}).Should(BeTrue())
+
+			tracer.Flush(context.Background())
+			Expect(fakeClient.ReportCallCount()).To(Equal(2))
+
+			var request *cpb.ReportRequest
+			Expect(httpReports).To(Receive(&request))
+			Expect(request.GetSpans()).To(HaveLen(1))
+		})
+
+		It("names the transport it switched to in the ReportTrace hooks", func() {
+			tracer.StartSpan("span").Finish()
+			tracer.Flush(context.Background())
+			tracer.Flush(context.Background())
+			tracer.Flush(context.Background())
+
+			Expect(reportTransports).To(Receive(Equal("gRPC")))
+			Expect(reportTransports).To(Receive(Equal("gRPC")))
+			Expect(reportTransports).To(Receive(Equal("HTTP")))
+		})
+	})
+
+	Describe("PropagationStats", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+			}
+		})
+
+		It("counts inject and extract outcomes by format", func() {
+			headers := opentracing.HTTPHeadersCarrier{}
+			span := tracer.StartSpan("span")
+			Expect(tracer.Inject(span.Context(), opentracing.HTTPHeaders, headers)).To(Succeed())
+			span.Finish()
+
+			_, err := tracer.Extract(opentracing.HTTPHeaders, headers)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier{})
+			Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+			_, err = tracer.Extract(opentracing.Binary, "not a reader")
+			Expect(err).To(HaveOccurred())
+
+			stats, err := GetPropagationStats(tracer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.Inject).To(Equal(map[string]PropagationCounts{
+				"http_headers": {Succeeded: 1},
+			}))
+			Expect(stats.Extract).To(Equal(map[string]PropagationCounts{
+				"http_headers": {Succeeded: 1, NotFound: 1},
+				"binary":       {Failed: 1},
+			}))
+		})
+	})
+
+	Describe("repeated Inject", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+			}
+		})
+
+		It("reflects baggage added between injects", func() {
+			span := tracer.StartSpan("span")
+			defer span.Finish()
+
+			first := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(span.Context(), opentracing.TextMap, first)).To(Succeed())
+			again := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(span.Context(), opentracing.TextMap, again)).To(Succeed())
+			Expect(again).To(Equal(first))
+
+			span.SetBaggageItem("user", "42")
+			var binary string
+			Expect(tracer.Inject(span.Context(), opentracing.Binary, &binary)).To(Succeed())
+			withBaggage := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(span.Context(), opentracing.TextMap, withBaggage)).To(Succeed())
+			Expect(withBaggage).To(HaveKeyWithValue("ot-baggage-user", "42"))
+
+			extracted, err := tracer.Extract(opentracing.Binary, binary)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extracted.(SpanContext).Baggage).To(HaveKeyWithValue("user", "42"))
+		})
+	})
+
+	Describe("PassThroughKeys", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:     accessToken,
+				ConnFactory:     fakeConn,
+				PassThroughKeys: []string{"tracestate", "x-b3-*"},
+			}
+		})
+
+		It("carries matching headers from extracted contexts to injected children", func() {
+			incoming := opentracing.TextMapCarrier{}
+			parent := tracer.StartSpan("parent")
+			Expect(tracer.Inject(parent.Context(), opentracing.TextMap, incoming)).To(Succeed())
+			parent.Finish()
+			incoming["tracestate"] = "vendor=abc"
+			incoming["x-b3-sampled"] = "1"
+			incoming["x-other"] = "dropped"
+
+			extracted, err := tracer.Extract(opentracing.TextMap, incoming)
+			Expect(err).NotTo(HaveOccurred())
+
+			child := tracer.StartSpan("child", opentracing.ChildOf(extracted))
+			defer child.Finish()
+
+			outgoing := opentracing.TextMapCarrier{}
+			Expect(tracer.Inject(child.Context(), opentracing.TextMap, outgoing)).To(Succeed())
+			Expect(outgoing).To(HaveKeyWithValue("tracestate", "vendor=abc"))
+			Expect(outgoing).To(HaveKeyWithValue("x-b3-sampled", "1"))
+			Expect(outgoing).NotTo(HaveKey("x-other"))
+		})
+
+		Context("with other propagation formats", func() {
+			BeforeEach(func() {
+				opts.PassThroughKeys = []string{"x-vendor-*"}
+				opts.Propagators = []string{PropagatorTraceContext}
+			})
+
+			It("carries matching headers from extracted contexts to injected children", func() {
+				incoming := opentracing.TextMapCarrier{
+					"traceparent": "00-0000000000000000000000000000abcd-00000000000000ef-01",
+					"x-vendor-id": "abc",
+					"x-other":     "dropped",
+				}
+				extracted, err := tracer.Extract(opentracing.TextMap, incoming)
+				Expect(err).NotTo(HaveOccurred())
+
+				child := tracer.StartSpan("child", opentracing.ChildOf(extracted))
+				defer child.Finish()
+
+				outgoing := opentracing.TextMapCarrier{}
+				Expect(tracer.Inject(child.Context(), opentracing.TextMap, outgoing)).To(Succeed())
+				Expect(outgoing).To(HaveKey("traceparent"))
+				Expect(outgoing).To(HaveKeyWithValue("x-vendor-id", "abc"))
+				Expect(outgoing).NotTo(HaveKey("x-other"))
+			})
+		})
+	})
+
+	Describe("capabilities", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken: accessToken,
+				ConnFactory: fakeConn,
+			}
+			fakeClient.ReportReturns(&cpb.ReportResponse{
+				Infos: []string{"capabilities: indexed_tags,future_feature"},
+			}, nil)
+		})
+
+		It("advertises the tracer's capabilities in reports", func() {
+			tracer.StartSpan("span").Finish()
+			tracer.Flush(context.Background())
+
+			Expect(fakeClient.ReportCallCount()).To(Equal(1))
+			_, request, _ := fakeClient.ReportArgsForCall(0)
+			var advertised string
+			for _, tag := range request.GetReporter().GetTags() {
+				if tag.GetKey() == TracerCapabilitiesKey {
+					advertised = tag.GetStringValue()
+				}
+			}
+			Expect(advertised).To(Equal("indexed_tags"))
+		})
+
+		It("records the capabilities the collector advertises", func() {
+			supported, err := CollectorSupports(tracer, CapabilityIndexedTags)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supported).To(BeFalse())
+
+			tracer.StartSpan("span").Finish()
+			tracer.Flush(context.Background())
+
+			Expect(CollectorSupports(tracer, CapabilityIndexedTags)).To(BeTrue())
+			Expect(CollectorSupports(tracer, CapabilityTagDictionary)).To(BeFalse())
+
+			var event EventCollectorCapabilities
+			Eventually(func() bool {
+				select {
+				case e := <-eventChan:
+					event, _ = e.(EventCollectorCapabilities)
+					return event != nil
+				default:
+					return false
+				}
+			}).Should(BeTrue())
+			Expect(event.Capabilities()).To(Equal([]Capability{"future_feature", CapabilityIndexedTags}))
+		})
+	})
+
+	Describe("ExperimentalTagDictionary", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:               accessToken,
+				ConnFactory:               fakeConn,
+				ExperimentalTagDictionary: true,
+			}
+			fakeClient.ReportReturns(&cpb.ReportResponse{
+				Infos: []string{"capabilities: tag_dictionary"},
+			}, nil)
+		})
+
+		report := func() *cpb.ReportRequest {
+			for i := 0; i < 3; i++ {
+				tracer.StartSpan("span", opentracing.Tags{
+					"service.version": "2024.06.01-8f3c2a1",
+					"attempt":         i,
+				}).Finish()
+			}
+			tracer.Flush(context.Background())
+			_, request, _ := fakeClient.ReportArgsForCall(fakeClient.ReportCallCount() - 1)
+			return request
+		}
+
+		tagKeys := func(tags []*cpb.KeyValue) []string {
+			var keys []string
+			for _, tag := range tags {
+				keys = append(keys, tag.GetKey())
+			}
+			return keys
+		}
+
+		It("sends repeated tags once collectors support it", func() {
+			request := report()
+			Expect(tagKeys(request.GetReporter().GetTags())).To(ContainElement(TracerCapabilitiesKey))
+			for _, tag := range request.GetReporter().GetTags() {
+				if tag.GetKey() == TracerCapabilitiesKey {
+					Expect(tag.GetStringValue()).To(ContainSubstring(string(CapabilityTagDictionary)))
+				}
+			}
+			for _, span := range request.GetSpans() {
+				Expect(tagKeys(span.GetTags())).To(ContainElement("service.version"))
+			}
+
+			request = report()
+			Expect(tagKeys(request.GetReporter().GetTags())).To(ContainElement(TagDictionaryKeyPrefix + "0/service.version"))
+			for _, span := range request.GetSpans() {
+				Expect(tagKeys(span.GetTags())).NotTo(ContainElement("service.version"))
+				Expect(tagKeys(span.GetTags())).To(ContainElement("attempt"))
+				for _, tag := range span.GetTags() {
+					if tag.GetKey() == TagRefsKey {
+						Expect(tag.GetStringValue()).To(Equal("0"))
+					}
+				}
+			}
+		})
+	})
+
+	Describe("ReportEffectiveConfig", func() {
+		BeforeEach(func() {
+			opts = Options{
+				AccessToken:           accessToken,
+				ConnFactory:           fakeConn,
+				ReportEffectiveConfig: true,
+			}
+		})
+
+		reportedConfig := func(call int) (string, bool) {
+			_, request, _ := fakeClient.ReportArgsForCall(call)
+			for _, tag := range request.GetReporter().GetTags() {
+				if tag.GetKey() == EffectiveConfigKey {
+					return tag.GetStringValue(), true
+				}
+			}
+			return "", false
+		}
+
+		It("sends the redacted options until a report succeeds", func() {
+			fakeClient.ReportReturnsOnCall(0, nil, errors.New("fail"))
+			for i := 0; i < 3; i++ {
+				tracer.StartSpan("span").Finish()
+				tracer.Flush(context.Background())
+			}
+			Expect(fakeClient.ReportCallCount()).To(Equal(3))
+
+			config, found := reportedConfig(0)
+			Expect(found).To(BeTrue())
+			Expect(config).To(ContainSubstring(`"ReportEffectiveConfig":true`))
+			Expect(config).NotTo(ContainSubstring(accessToken))
+
+			_, found = reportedConfig(1)
+			Expect(found).To(BeTrue())
+			_, found = reportedConfig(2)
+			Expect(found).To(BeFalse())
+		})
+	})
+
 	Describe("provides its ReporterID", func() {
 		BeforeEach(func() {
 			opts = Options{
@@ -345,6 +1243,24 @@ var _ = Describe("Tracer", func() {
 	})
 })
 
+type testReportRecorder struct {
+	*lightstepfakes.FakeSpanRecorder
+	lock     sync.Mutex
+	previews []ReportPreview
+}
+
+func (r *testReportRecorder) RecordReport(preview ReportPreview) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.previews = append(r.previews, preview)
+}
+
+func (r *testReportRecorder) Previews() []ReportPreview {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.previews
+}
+
 var _ = Describe("UnsupportedTracer", func() {
 	type unsupportedTracer struct {
 		opentracing.Tracer
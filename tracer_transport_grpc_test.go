@@ -1,3 +1,6 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
 package lightstep_test
 
 import (
@@ -27,3 +30,12 @@ func (fakeClient *cpbfakesFakeClient) getSpans() []*cpb.Span {
 func (fakeClient *cpbfakesFakeClient) GetSpansLen() int {
 	return len(fakeClient.getSpans())
 }
+
+var _ = describeTracerTransport("grpc", func(options *Options) fakeCollectorClient {
+	options.UseGRPC = true
+	return newGrpcFakeClient()
+},
+	thatSupportsBaggage(),
+	thatSupportsReference(),
+	thatSupportsTypedValues(),
+)
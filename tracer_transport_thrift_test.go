@@ -1,3 +1,6 @@
+//go:build !lightstep_nothrift && !lightstep_constrained
+// +build !lightstep_nothrift,!lightstep_constrained
+
 package lightstep_test
 
 import (
@@ -88,3 +91,8 @@ func newThriftFakeClient() fakeCollectorClient {
 	fakeClient.ReportReturns(&lightstep_thrift.ReportResponse{}, nil)
 	return &thriftFakeClient{FakeReportingService: *fakeClient}
 }
+
+var _ = describeTracerTransport("thrift", func(options *Options) fakeCollectorClient {
+	options.UseThrift = true
+	return newThriftFakeClient()
+})
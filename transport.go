@@ -0,0 +1,158 @@
+package lightstep
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+)
+
+var (
+	validationErrorTransportFactory = fmt.Errorf("Options invalid: CustomTransportFactory and CustomTransport must not be set together")
+	validationErrorTransportMixed   = fmt.Errorf("Options invalid: a custom transport must not be set with Agent, Collectors, FailoverCollectors or TenantAccessTokens")
+)
+
+// A Transport sends the reports of a tracer to a destination other than the
+// LightStep collector, such as a message queue or an internal ingest API,
+// while the tracer keeps buffering spans, flushing them, retrying failed
+// reports and emitting events as it does for its built-in transports. See
+// Options.CustomTransportFactory.
+type Transport interface {
+	// Report sends a report. If it returns an error, or a response with
+	// errors, the report failed, and its spans are retried by a later
+	// report; the first error of the response is reported by an
+	// EventFlushError. The commands of the response can disable the
+	// tracer. It isn't called concurrently.
+	Report(ctx context.Context, req *cpb.ReportRequest) (*cpb.ReportResponse, error)
+	// Close is called once, when the tracer is closed.
+	Close() error
+}
+
+// A TransportFactory creates the Transport of a tracer, given the tracer's
+// Options with their defaults set.
+type TransportFactory func(opts Options) (Transport, error)
+
+var transportFactories = struct {
+	sync.RWMutex
+	factories map[string]TransportFactory
+}{
+	factories: map[string]TransportFactory{},
+}
+
+// RegisterTransportFactory makes factory available to Options.CustomTransport
+// under name, replacing any factory of the same name, so that a custom
+// transport can be selected by configuration. It is typically called from
+// an init function.
+func RegisterTransportFactory(name string, factory TransportFactory) {
+	transportFactories.Lock()
+	defer transportFactories.Unlock()
+	transportFactories.factories[name] = factory
+}
+
+// lookupTransportFactory returns the factory registered under name.
+func lookupTransportFactory(name string) (TransportFactory, bool) {
+	transportFactories.RLock()
+	defer transportFactories.RUnlock()
+	factory, found := transportFactories.factories[name]
+	return factory, found
+}
+
+// customTransportFactory returns the factory opts select, or nil if they
+// select a built-in transport.
+func customTransportFactory(opts Options) TransportFactory {
+	if opts.CustomTransportFactory != nil {
+		return opts.CustomTransportFactory
+	}
+	if opts.CustomTransport != "" {
+		factory, _ := lookupTransportFactory(opts.CustomTransport)
+		return factory
+	}
+	return nil
+}
+
+func validateCustomTransport(opts Options) error {
+	if opts.CustomTransportFactory == nil && opts.CustomTransport == "" {
+		return nil
+	}
+	if opts.CustomTransportFactory != nil && opts.CustomTransport != "" {
+		return validationErrorTransportFactory
+	}
+	if opts.CustomTransport != "" {
+		if _, found := lookupTransportFactory(opts.CustomTransport); !found {
+			return fmt.Errorf("Options invalid: unknown CustomTransport %q", opts.CustomTransport)
+		}
+	}
+	if opts.Agent.Address != "" || len(opts.Collectors) > 0 || len(opts.FailoverCollectors) > 0 || len(opts.TenantAccessTokens) > 0 {
+		return validationErrorTransportMixed
+	}
+	return nil
+}
+
+// customCollectorClient reports through a Transport.
+type customCollectorClient struct {
+	// auth and runtime information
+	reporterID  uint64
+	accessToken string
+	attributes  map[string]string
+
+	transport Transport
+
+	converter *protoConverter
+}
+
+func newCustomCollectorClient(factory TransportFactory, opts Options, reporterID uint64, attributes map[string]string) (collectorClient, error) {
+	transport, err := factory(opts)
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		return nil, fmt.Errorf("TransportFactory returned a nil Transport")
+	}
+	client := &customCollectorClient{
+		reporterID:  reporterID,
+		accessToken: opts.AccessToken,
+		attributes:  attributes,
+		transport:   transport,
+		converter:   newProtoConverter(opts),
+	}
+	if opts.MaxReportBytes > 0 {
+		return &splittingCollectorClient{collectorClient: client, maxBytes: opts.MaxReportBytes}, nil
+	}
+	return client, nil
+}
+
+// ConnectClient returns the Transport, which the tracer closes when it is
+// closed.
+func (client *customCollectorClient) ConnectClient() (Connection, error) {
+	return client.transport, nil
+}
+
+func (client *customCollectorClient) ShouldReconnect() bool {
+	return false
+}
+
+func (client *customCollectorClient) Translate(_ context.Context, buffer *reportBuffer) (reportRequest, error) {
+	return reportRequest{
+		protoRequest: client.converter.toReportRequest(
+			client.reporterID,
+			client.attributes,
+			client.accessToken,
+			buffer,
+		),
+	}, nil
+}
+
+func (client *customCollectorClient) Report(ctx context.Context, req reportRequest) (collectorResponse, error) {
+	if req.protoRequest == nil {
+		return nil, fmt.Errorf("protoRequest cannot be null")
+	}
+	resp, err := client.transport.Report(ctx, req.protoRequest)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		resp = &cpb.ReportResponse{}
+	}
+	return resp, nil
+}
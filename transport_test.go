@@ -0,0 +1,146 @@
+package lightstep_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// recordingTransport is a Transport that keeps the reports it is given.
+type recordingTransport struct {
+	sync.Mutex
+	reports []*cpb.ReportRequest
+	resp    *cpb.ReportResponse
+	err     error
+	closed  bool
+}
+
+func (t *recordingTransport) Report(_ context.Context, req *cpb.ReportRequest) (*cpb.ReportResponse, error) {
+	t.Lock()
+	defer t.Unlock()
+	if t.err != nil {
+		return nil, t.err
+	}
+	t.reports = append(t.reports, req)
+	return t.resp, nil
+}
+
+func (t *recordingTransport) Close() error {
+	t.Lock()
+	defer t.Unlock()
+	t.closed = true
+	return nil
+}
+
+func (t *recordingTransport) spans() []*cpb.Span {
+	t.Lock()
+	defer t.Unlock()
+	var spans []*cpb.Span
+	for _, req := range t.reports {
+		spans = append(spans, req.GetSpans()...)
+	}
+	return spans
+}
+
+var _ = Describe("custom transports", func() {
+	var tracer Tracer
+	var opts Options
+	var transport *recordingTransport
+	var factoryOpts Options
+
+	BeforeEach(func() {
+		transport = &recordingTransport{}
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			CustomTransportFactory: func(o Options) (Transport, error) {
+				factoryOpts = o
+				return transport, nil
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	It("reports through the transport", func() {
+		tracer.StartSpan("custom").Finish()
+		tracer.Flush(context.Background())
+
+		Expect(factoryOpts.AccessToken).To(Equal("ACCESS_TOKEN"))
+		Expect(transport.reports).To(HaveLen(1))
+		Expect(transport.reports[0].GetAuth().GetAccessToken()).To(Equal("ACCESS_TOKEN"))
+		Expect(transport.spans()).To(HaveLen(1))
+		Expect(transport.spans()[0].GetOperationName()).To(Equal("custom"))
+	})
+
+	It("closes the transport with the tracer", func() {
+		closeTestTracer(tracer)
+		transport.Lock()
+		defer transport.Unlock()
+		Expect(transport.closed).To(BeTrue())
+	})
+
+	Context("when the transport fails", func() {
+		BeforeEach(func() {
+			transport.err = errors.New("queue unavailable")
+		})
+
+		It("retries the spans with the next report", func() {
+			tracer.StartSpan("retried").Finish()
+			tracer.Flush(context.Background())
+			Expect(transport.spans()).To(BeEmpty())
+
+			transport.Lock()
+			transport.err = nil
+			transport.Unlock()
+			tracer.Flush(context.Background())
+			Expect(transport.spans()).To(HaveLen(1))
+		})
+	})
+
+	Context("when the transport is registered", func() {
+		BeforeEach(func() {
+			RegisterTransportFactory("recording", func(Options) (Transport, error) {
+				return transport, nil
+			})
+			opts.CustomTransportFactory = nil
+			opts.CustomTransport = "recording"
+		})
+
+		It("reports through the transport it names", func() {
+			tracer.StartSpan("registered").Finish()
+			tracer.Flush(context.Background())
+			Expect(transport.spans()).To(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("custom transport options", func() {
+	It("rejects an unregistered transport", func() {
+		opts := Options{AccessToken: "ACCESS_TOKEN", CustomTransport: "unregistered"}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+
+	It("rejects a custom transport with the agent", func() {
+		opts := Options{
+			AccessToken:            "ACCESS_TOKEN",
+			CustomTransportFactory: func(Options) (Transport, error) { return &recordingTransport{}, nil },
+			Agent:                  AgentOptions{Address: "127.0.0.1:8361"},
+		}
+		Expect(opts.Validate()).To(HaveOccurred())
+	})
+})
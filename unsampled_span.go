@@ -0,0 +1,106 @@
+package lightstep
+
+import (
+	"strings"
+	"sync"
+
+	ot "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// startUnsampledSpan returns a span that records nothing if
+// Options.CallerSampling drops the trace of a span started with opts, and
+// false otherwise. The decision otherwise made when the span finishes is
+// made as it starts, so that the spans of unsampled callers cost about half
+// as much as sampled spans, see BenchmarkSpan_Unsampled. It is only used
+// when nothing else sees the spans: without Options.Recorder,
+// Options.OnSpanStart or Options.Destinations.
+func (tracer *tracerImpl) startUnsampledSpan(opts startSpanOptions) (ot.Span, bool) {
+	if opts.MustDeliver {
+		return nil, false
+	}
+	var sc SpanContext
+	_, contexts, parent := spanReferences(opts.Options.References)
+	if parent >= 0 {
+		refCtx := contexts[parent]
+		sc.TraceID = refCtx.TraceID
+		sc.TraceIDHigh = refCtx.TraceIDHigh
+		sc.passThrough = refCtx.passThrough
+		sc.traceState = refCtx.traceState
+		sc.Baggage = referencedBaggage(contexts, parent)
+	} else if opts.SetTraceID != 0 {
+		sc.TraceID = opts.SetTraceID
+	} else {
+		sc.TraceID = genSeededGUID()
+	}
+
+	rate, ok := tracer.opts.CallerSampling.SampleRates[sc.Baggage[strings.ToLower(tracer.opts.CallerSampling.BaggageKey)]]
+	if !ok || sc.isDebug() || traceSampled(sc.TraceID, rate) {
+		return nil, false
+	}
+	if sc.TraceIDHigh == 0 && parent < 0 && tracer.opts.TraceID128Bit {
+		sc.TraceIDHigh = genSeededGUID()
+	}
+	sc.SpanID = genSeededGUID()
+	if len(contexts) > 1 {
+		// Baggage merged from several references may exceed the limits.
+		sc = tracer.opts.BaggageLimits.limit(sc)
+	}
+	return &unsampledSpan{tracer: tracer, context: sc}, true
+}
+
+// unsampledSpan is a span of a trace that isn't sampled. It keeps its
+// SpanContext, so that its descendants continue the trace unsampled, but
+// discards its tags and logs.
+//
+// The spans aren't pooled: OpenTracing allows Context() after Finish, for
+// example to start a FollowsFrom span, so a finished span can't be reused.
+// Pooling would only save the span's own allocation, about a fifth of its
+// cost.
+type unsampledSpan struct {
+	tracer *tracerImpl
+
+	lock    sync.Mutex
+	context SpanContext
+}
+
+func (s *unsampledSpan) Finish()                                 {}
+func (s *unsampledSpan) FinishWithOptions(ot.FinishOptions)      {}
+func (s *unsampledSpan) SetOperationName(string) ot.Span         { return s }
+func (s *unsampledSpan) SetTag(string, interface{}) ot.Span      { return s }
+func (s *unsampledSpan) LogFields(...log.Field)                  {}
+func (s *unsampledSpan) LogKV(...interface{})                    {}
+func (s *unsampledSpan) LogEvent(string)                         {}
+func (s *unsampledSpan) LogEventWithPayload(string, interface{}) {}
+func (s *unsampledSpan) Log(ot.LogData)                          {}
+func (s *unsampledSpan) Tracer() ot.Tracer                       { return s.tracer }
+
+// FinishAsync returns a FinishHandle resolved with ErrSpanDropped.
+func (s *unsampledSpan) FinishAsync() FinishHandle {
+	handle := newFinishHandle()
+	handle.resolve(ErrSpanDropped)
+	return handle
+}
+
+func (s *unsampledSpan) Context() ot.SpanContext {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.context
+}
+
+func (s *unsampledSpan) SetBaggageItem(key, val string) ot.Span {
+	s.lock.Lock()
+	var event Event
+	s.context, event = s.tracer.opts.BaggageLimits.setItem(s.context, key, val)
+	s.lock.Unlock()
+	if event != nil {
+		emitEvent(event)
+	}
+	return s
+}
+
+func (s *unsampledSpan) BaggageItem(key string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.context.Baggage[key]
+}
@@ -0,0 +1,96 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var _ = Describe("unsampled spans", func() {
+	var tracer Tracer
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+		tracer = NewTracer(Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    time.Hour,
+			MinReportingPeriod: time.Hour,
+			CallerSampling: CallerSamplingOptions{
+				BaggageKey:  "caller",
+				SampleRates: map[string]float64{"load-test": 0},
+			},
+		})
+	})
+
+	AfterEach(func() {
+		closeTestTracer(tracer)
+	})
+
+	reported := func() []string {
+		tracer.Flush(context.Background())
+		var operations []string
+		for i := 0; i < fakeClient.ReportCallCount(); i++ {
+			_, req, _ := fakeClient.ReportArgsForCall(i)
+			for _, span := range req.GetSpans() {
+				operations = append(operations, span.GetOperationName())
+			}
+		}
+		return operations
+	}
+
+	loadTestContext := SpanContext{TraceID: 0x1, SpanID: 0x2, Baggage: map[string]string{"caller": "load-test"}}
+
+	It("continues the trace of an unsampled caller without reporting it", func() {
+		span := tracer.StartSpan("request", opentracing.ChildOf(loadTestContext))
+		span.SetTag("ignored", true)
+		span.LogKV("event", "ignored")
+		child := tracer.StartSpan("query", opentracing.ChildOf(span.Context()))
+		child.Finish()
+		span.Finish()
+
+		sc := child.Context().(SpanContext)
+		Expect(sc.TraceID).To(Equal(loadTestContext.TraceID))
+		Expect(sc.SpanID).ToNot(BeZero())
+		Expect(child.BaggageItem("caller")).To(Equal("load-test"))
+
+		carrier := opentracing.TextMapCarrier{}
+		Expect(tracer.Inject(sc, opentracing.TextMap, carrier)).To(Succeed())
+		Expect(carrier).To(HaveKeyWithValue("ot-baggage-caller", "load-test"))
+
+		Expect(reported()).To(BeEmpty())
+	})
+
+	It("keeps the context of finished spans", func() {
+		span := tracer.StartSpan("request", opentracing.ChildOf(loadTestContext))
+		sc := span.Context().(SpanContext)
+		span.Finish()
+		tracer.StartSpan("next", opentracing.ChildOf(loadTestContext)).Finish()
+
+		Expect(span.Context()).To(Equal(sc))
+		Expect(span.BaggageItem("caller")).To(Equal("load-test"))
+	})
+
+	It("resolves FinishAsync handles as dropped", func() {
+		span := tracer.StartSpan("request", opentracing.ChildOf(loadTestContext))
+		Expect(FinishAsync(span).Err()).To(Equal(ErrSpanDropped))
+	})
+
+	It("reports the spans of other callers and spans that must be delivered", func() {
+		tracer.StartSpan("request").Finish()
+		tracer.StartSpan("audit", opentracing.ChildOf(loadTestContext), MustDeliver{}).Finish()
+
+		Expect(reported()).To(ConsistOf("request", "audit"))
+	})
+})
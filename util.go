@@ -30,3 +30,24 @@ func genSeededGUID2() (uint64, uint64) {
 	n1, n2 := randompool.Pick().TwoInt63()
 	return uint64(n1), uint64(n2)
 }
+
+// traceSampled reports whether the trace is kept when sampling traces at
+// rate, consistently for all the spans of the trace.
+func traceSampled(traceID uint64, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	return float64(traceID&(1<<63-1)) < rate*(1<<63)
+}
+
+// sampledAt reports whether an event should be kept when sampling at rate,
+// a fraction between 0.0 and 1.0.
+func sampledAt(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return float64(genSeededGUID()) < rate*(1<<63)
+}
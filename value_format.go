@@ -0,0 +1,61 @@
+package lightstep
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxErrorChain bounds the number of wrapped errors formatErrorValue
+// follows, in case an error wraps itself.
+const maxErrorChain = 10
+
+// formatValue returns the value reported for a tag or log value when
+// Options.FormatValues is set: time.Time values in RFC 3339 format,
+// time.Duration values in milliseconds, and errors with the types of the
+// errors they wrap. It returns false for values of other types.
+func formatValue(value interface{}) (interface{}, bool) {
+	switch value := value.(type) {
+	case time.Time:
+		return value.Format(time.RFC3339Nano), true
+	case time.Duration:
+		return float64(value) / float64(time.Millisecond), true
+	case error:
+		return formatErrorValue(value), true
+	}
+	return nil, false
+}
+
+// formatErrorValue returns the message of err followed, if it wraps other
+// errors, by the types along its chain, such as
+// "open config.yaml: no such file or directory (*fs.PathError > syscall.Errno)".
+// Errors whose methods panic, such as typed nil pointers, are formatted by
+// fmt instead, which reports nil pointers as "<nil>".
+func formatErrorValue(err error) (formatted string) {
+	defer func() {
+		if recover() != nil {
+			formatted = fmt.Sprint(err)
+		}
+	}()
+	cause := unwrapError(err)
+	if cause == nil {
+		return err.Error()
+	}
+	types := []string{fmt.Sprintf("%T", err)}
+	for ; cause != nil && len(types) < maxErrorChain; cause = unwrapError(cause) {
+		types = append(types, fmt.Sprintf("%T", cause))
+	}
+	return fmt.Sprintf("%s (%s)", err.Error(), strings.Join(types, " > "))
+}
+
+// unwrapError returns the error wrapped by err, following the Unwrap method
+// of the errors package, or the Cause method of github.com/pkg/errors.
+func unwrapError(err error) error {
+	switch err := err.(type) {
+	case interface{ Unwrap() error }:
+		return err.Unwrap()
+	case interface{ Cause() error }:
+		return err.Cause()
+	}
+	return nil
+}
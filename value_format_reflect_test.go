@@ -0,0 +1,21 @@
+//go:build !lightstep_constrained
+// +build !lightstep_constrained
+
+package lightstep
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FormatValues", func() {
+	It("keeps reporting durations in nanoseconds when disabled", func() {
+		converter := newProtoConverter(Options{
+			MaxLogKeyLen:   DefaultMaxLogKeyLen,
+			MaxLogValueLen: DefaultMaxLogValueLen,
+		})
+		Expect(converter.toField("duration", 1500*time.Microsecond).GetIntValue()).To(Equal(int64(1500000)))
+	})
+})
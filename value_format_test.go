@@ -0,0 +1,74 @@
+package lightstep
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ot "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// wrappedError wraps an error as fmt.Errorf's %w verb does.
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string { return e.msg + ": " + e.err.Error() }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+var _ = Describe("FormatValues", func() {
+	var converter *protoConverter
+	finished := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+	err := &wrappedError{msg: "load config", err: errors.New("file not found")}
+
+	BeforeEach(func() {
+		converter = newProtoConverter(Options{
+			MaxLogKeyLen:   DefaultMaxLogKeyLen,
+			MaxLogValueLen: DefaultMaxLogValueLen,
+			FormatValues:   true,
+		})
+	})
+
+	It("formats times, durations and errors in tags", func() {
+		Expect(converter.toField("time", finished).GetStringValue()).To(Equal("2024-03-01T12:30:00.0000005Z"))
+		Expect(converter.toField("duration", 1500*time.Microsecond).GetDoubleValue()).To(Equal(1.5))
+		Expect(converter.toField("error", err).GetStringValue()).To(Equal(
+			"load config: file not found (*lightstep.wrappedError > *errors.errorString)"))
+		Expect(converter.toField("error", errors.New("plain")).GetStringValue()).To(Equal("plain"))
+	})
+
+	It("formats times, durations and errors in logs", func() {
+		protoLog := converter.toLog(ot.LogRecord{Fields: []log.Field{
+			log.Object("error", err),
+			log.Object("duration", 2*time.Millisecond),
+		}}, SpanContext{}, &reportBuffer{})
+
+		Expect(protoLog.Fields).To(Equal([]*cpb.KeyValue{
+			{Key: "error", Value: &cpb.KeyValue_StringValue{"load config: file not found (*lightstep.wrappedError > *errors.errorString)"}},
+			{Key: "duration", Value: &cpb.KeyValue_DoubleValue{2}},
+		}))
+	})
+
+	It("formats typed nil errors as fmt does", func() {
+		var pathErr *os.PathError
+		Expect(converter.toField("error", pathErr).GetStringValue()).To(Equal("<nil>"))
+		Expect(converter.toField("error", &wrappedError{msg: "load config"}).GetStringValue()).To(Equal(
+			fmt.Sprint(&wrappedError{msg: "load config"})))
+
+		protoLog := converter.toLog(ot.LogRecord{Fields: []log.Field{
+			log.Object("error", pathErr),
+		}}, SpanContext{}, &reportBuffer{})
+		Expect(protoLog.Fields[0].GetStringValue()).To(Equal("<nil>"))
+	})
+
+	It("keeps the default formatting when disabled", func() {
+		converter.formatValues = false
+		Expect(converter.toField("error", err).GetStringValue()).To(Equal("load config: file not found"))
+	})
+})
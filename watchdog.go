@@ -0,0 +1,250 @@
+package lightstep
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// DefaultWatchdogMaxRestartDelay is the longest wait of the default
+// WatchdogOptions.RestartBackoff.
+const DefaultWatchdogMaxRestartDelay = time.Minute
+
+// watchdogStallPeriods is how many periods of Options.MinReportingPeriod the
+// report loop may miss before the watchdog considers it stalled.
+const watchdogStallPeriods = 3
+
+// WatchdogOptions configures a watchdog over the report loop, the background
+// goroutine that reports the tracer's spans and reconnects to the collector.
+// Without the watchdog, a panic in the report loop, as in transport code,
+// crashes the process, and a report loop that blocks stops reporting without
+// notice.
+type WatchdogOptions struct {
+	// Enabled starts the watchdog. Panics in the report loop are recovered,
+	// and the loop is considered stalled when it panics or when it has not
+	// run for three periods of Options.MinReportingPeriod, plus
+	// Options.ReportTimeout to let a report in flight finish. An
+	// EventReportLoopStalled is emitted for each stall.
+	Enabled bool `yaml:"enabled"`
+
+	// Restart starts a new report loop in place of a stalled one, after the
+	// wait given by RestartBackoff. The stalled loop exits if it ever
+	// resumes. Restart implies Enabled.
+	Restart bool `yaml:"restart"`
+
+	// RestartBackoff is the wait before restarting a stalled report loop,
+	// which grows while restarted loops stall again before completing a
+	// report or running for a full stall period.
+	// Defaults to an ExponentialBackoff from Options.MinReportingPeriod to
+	// DefaultWatchdogMaxRestartDelay.
+	RestartBackoff Backoff `yaml:"-" json:"-"`
+}
+
+func (o WatchdogOptions) enabled() bool {
+	return o.Enabled || o.Restart
+}
+
+// reportLoopWatchdog tracks the liveness of the report loop. It has its own
+// lock, as a stalled report loop may hold the tracer's. A nil watchdog
+// tracks nothing, and its loop is always current.
+type reportLoopWatchdog struct {
+	lock sync.Mutex
+	// generation numbers the current report loop; loops of an earlier
+	// generation have been replaced and exit.
+	generation int
+	// started is when the current loop started, and lastTick when it last
+	// ran.
+	started    time.Time
+	lastTick   time.Time
+	panicValue interface{}
+	panicStack []byte
+	// panicked wakes the watchdog when the current loop panics.
+	panicked chan struct{}
+	// restarts counts the restarted loops that stalled again before
+	// completing a flush or running for a full stall period.
+	restarts backoffState
+	// stopped is set once the report loop exits, on Close or when the
+	// tracer is disabled, so it isn't restarted.
+	stopped bool
+	// flushing counts the report loops flushing, which hold the tracer's
+	// flushingLock. A loop stalled in a report keeps holding it, and a
+	// loop restarted meanwhile would only wait for it.
+	flushing int
+}
+
+func newReportLoopWatchdog(now time.Time) *reportLoopWatchdog {
+	return &reportLoopWatchdog{
+		started:  now,
+		lastTick: now,
+		panicked: make(chan struct{}, 1),
+	}
+}
+
+// tick records that the report loop of generation ran at now, and reports
+// whether it is still the current loop.
+func (w *reportLoopWatchdog) tick(generation int, now time.Time) bool {
+	if w == nil {
+		return true
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if generation != w.generation {
+		return false
+	}
+	w.lastTick = now
+	return true
+}
+
+// stop records that the report loop of generation exits, and reports whether
+// it was the current loop.
+func (w *reportLoopWatchdog) stop(generation int) bool {
+	if w == nil {
+		return true
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if generation != w.generation {
+		return false
+	}
+	w.stopped = true
+	return true
+}
+
+// flushStart records that a report loop starts flushing.
+func (w *reportLoopWatchdog) flushStart() {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.flushing++
+}
+
+// flushEnd records that the report loop of generation is done flushing,
+// and whether the flush completed rather than panicked. A current loop
+// that completes a flush is no longer stalling, so the backoff of its
+// restarts starts over.
+func (w *reportLoopWatchdog) flushEnd(generation int, completed bool) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.flushing--
+	if completed && generation == w.generation {
+		w.restarts.reset()
+	}
+}
+
+// recoverReportLoop recovers a panic of the report loop of generation, and
+// wakes the watchdog to report it.
+func (w *reportLoopWatchdog) recoverReportLoop(generation int) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if generation != w.generation {
+		return
+	}
+	w.panicValue = r
+	w.panicStack = debug.Stack()
+	select {
+	case w.panicked <- struct{}{}:
+	default:
+	}
+}
+
+// watchReportLoop checks the report loop every MinReportingPeriod, and as
+// soon as it panics, until the tracer is closed or disabled. It emits an
+// EventReportLoopStalled once for each stall, and restarts the loop if
+// Options.Watchdog.Restart is set.
+func (tracer *tracerImpl) watchReportLoop() {
+	w := tracer.watchdog
+	stallAfter := watchdogStallPeriods*tracer.opts.MinReportingPeriod + tracer.opts.ReportTimeout
+	ticker := time.NewTicker(tracer.opts.MinReportingPeriod)
+	defer ticker.Stop()
+
+	// reported is the last tick of the stall last reported, and restart
+	// fires when the loop replacing it is due.
+	var reported time.Time
+	var restart <-chan time.Time
+	for {
+		select {
+		case now := <-ticker.C:
+			if restart == nil && !tracer.checkReportLoop(now, stallAfter, &reported, &restart) {
+				return
+			}
+		case <-w.panicked:
+			if restart == nil && !tracer.checkReportLoop(time.Now(), 0, &reported, &restart) {
+				return
+			}
+		case now := <-restart:
+			restart = nil
+			w.lock.Lock()
+			switch {
+			case w.stopped:
+			case w.flushing > 0:
+				// The stalled loop is still in a report; check again
+				// rather than start loops that would wait for it.
+				restart = time.After(tracer.opts.MinReportingPeriod)
+			default:
+				w.started = now
+				w.lastTick = now
+				go tracer.reportLoop(w.generation)
+			}
+			w.lock.Unlock()
+		case <-tracer.closeReportLoopChannel:
+			// A loop that panicked, or awaits its restart, can't
+			// acknowledge the close itself.
+			w.lock.Lock()
+			if !w.stopped && (w.panicValue != nil || restart != nil) {
+				w.stopped = true
+				close(tracer.reportLoopClosedChannel)
+			}
+			w.lock.Unlock()
+			return
+		}
+	}
+}
+
+// checkReportLoop emits an EventReportLoopStalled if the report loop has
+// panicked or not run for stallAfter, unless that stall was reported, and
+// schedules the loop's restart. It returns false once the loop stopped.
+func (tracer *tracerImpl) checkReportLoop(now time.Time, stallAfter time.Duration, reported *time.Time, restart *<-chan time.Time) bool {
+	w := tracer.watchdog
+	w.lock.Lock()
+	if w.stopped {
+		w.lock.Unlock()
+		return false
+	}
+	stalled := now.Sub(w.lastTick)
+	if w.panicValue == nil && stalled < stallAfter {
+		// A loop that ran for a full stall period is no longer stalling.
+		if now.Sub(w.started) >= stallAfter {
+			w.restarts.reset()
+		}
+		w.lock.Unlock()
+		return true
+	}
+	if w.lastTick.Equal(*reported) {
+		w.lock.Unlock()
+		return true
+	}
+	*reported = w.lastTick
+	event := newEventReportLoopStalled(stalled, w.panicValue, w.panicStack, runtime.NumGoroutine())
+	if tracer.opts.Watchdog.Restart {
+		w.generation++
+		w.panicValue = nil
+		w.panicStack = nil
+		event.restarted = true
+		event.restartDelay = w.restarts.fail(tracer.opts.Watchdog.RestartBackoff)
+		*restart = time.After(event.restartDelay)
+	}
+	w.lock.Unlock()
+
+	emitEvent(event)
+	return true
+}
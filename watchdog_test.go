@@ -0,0 +1,193 @@
+//go:build !lightstep_nogrpc && !lightstep_constrained && !js
+// +build !lightstep_nogrpc,!lightstep_constrained,!js
+
+package lightstep_test
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	. "github.com/lightstep/lightstep-tracer-go"
+	cpb "github.com/lightstep/lightstep-tracer-go/collectorpb"
+	cpbfakes "github.com/lightstep/lightstep-tracer-go/collectorpb/collectorpbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Watchdog", func() {
+	var tracer Tracer
+	var opts Options
+	var fakeClient *cpbfakes.FakeCollectorServiceClient
+	var eventChan <-chan Event
+
+	BeforeEach(func() {
+		fakeClient = new(cpbfakes.FakeCollectorServiceClient)
+		fakeClient.ReportReturns(&cpb.ReportResponse{}, nil)
+
+		var eventHandler func(Event)
+		eventHandler, eventChan = NewEventChannel(100)
+		SetGlobalEventHandler(eventHandler)
+
+		opts = Options{
+			AccessToken:        "ACCESS_TOKEN",
+			ConnFactory:        fakeGrpcConnection(fakeClient),
+			ReportingPeriod:    10 * time.Millisecond,
+			MinReportingPeriod: 10 * time.Millisecond,
+			ReportTimeout:      10 * time.Millisecond,
+			Watchdog:           WatchdogOptions{Enabled: true},
+		}
+	})
+
+	JustBeforeEach(func() {
+		tracer = NewTracer(opts)
+		Expect(tracer).ToNot(BeNil())
+	})
+
+	AfterEach(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		tracer.Close(ctx)
+	})
+
+	// stalledEvent waits for an EventReportLoopStalled.
+	stalledEvent := func() EventReportLoopStalled {
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case event := <-eventChan:
+				if stalled, ok := event.(EventReportLoopStalled); ok {
+					return stalled
+				}
+			case <-timeout:
+				Fail("no EventReportLoopStalled")
+				return nil
+			}
+		}
+	}
+
+	It("stays quiet while the report loop runs", func() {
+		tracer.StartSpan("span").Finish()
+		Eventually(fakeClient.ReportCallCount).Should(BeNumerically(">", 0))
+		Consistently(func() bool {
+			select {
+			case event := <-eventChan:
+				_, stalled := event.(EventReportLoopStalled)
+				return stalled
+			default:
+				return false
+			}
+		}, 200*time.Millisecond).Should(BeFalse())
+	})
+
+	Context("when a report panics", func() {
+		BeforeEach(func() {
+			var panicked int32
+			fakeClient.ReportStub = func(context.Context, *cpb.ReportRequest, ...grpc.CallOption) (*cpb.ReportResponse, error) {
+				if atomic.CompareAndSwapInt32(&panicked, 0, 1) {
+					panic("collector client bug")
+				}
+				return &cpb.ReportResponse{}, nil
+			}
+		})
+
+		It("recovers the panic and reports the stalled loop", func() {
+			tracer.StartSpan("span").Finish()
+
+			stalled := stalledEvent()
+			Expect(stalled.Panic()).To(Equal("collector client bug"))
+			Expect(string(stalled.Stack())).To(ContainSubstring("reportLoop"))
+			Expect(stalled.Restarted()).To(BeFalse())
+			Expect(stalled.Goroutines()).To(BeNumerically(">", 0))
+		})
+
+		Context("with Restart", func() {
+			BeforeEach(func() {
+				opts.Watchdog = WatchdogOptions{Restart: true, RestartBackoff: ConstantBackoff(20 * time.Millisecond)}
+			})
+
+			It("resumes reporting after the restart delay", func() {
+				tracer.StartSpan("lost").Finish()
+
+				stalled := stalledEvent()
+				Expect(stalled.Panic()).To(Equal("collector client bug"))
+				Expect(stalled.Restarted()).To(BeTrue())
+				Expect(stalled.RestartDelay()).To(Equal(20 * time.Millisecond))
+
+				tracer.StartSpan("reported").Finish()
+				Eventually(func() []*cpb.Span {
+					return fakeClient.SpansByOperation("reported")
+				}).ShouldNot(BeEmpty())
+			})
+		})
+
+		Context("repeatedly, with Restart", func() {
+			BeforeEach(func() {
+				var panics int32
+				fakeClient.ReportStub = func(context.Context, *cpb.ReportRequest, ...grpc.CallOption) (*cpb.ReportResponse, error) {
+					if atomic.AddInt32(&panics, 1) <= 3 {
+						panic("collector client bug")
+					}
+					return &cpb.ReportResponse{}, nil
+				}
+				opts.Watchdog = WatchdogOptions{
+					Restart:        true,
+					RestartBackoff: ExponentialBackoff{Base: 10 * time.Millisecond, Max: time.Second},
+				}
+			})
+
+			It("backs off the restarts of loops that stall again right away", func() {
+				var delays []time.Duration
+				for i := 0; i < 3; i++ {
+					delays = append(delays, stalledEvent().RestartDelay())
+				}
+				Expect(delays).To(Equal([]time.Duration{
+					10 * time.Millisecond,
+					20 * time.Millisecond,
+					40 * time.Millisecond,
+				}))
+			})
+		})
+	})
+
+	Context("when a report blocks", func() {
+		var release chan struct{}
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			blocked := release
+			fakeClient.ReportStub = func(context.Context, *cpb.ReportRequest, ...grpc.CallOption) (*cpb.ReportResponse, error) {
+				<-blocked
+				return &cpb.ReportResponse{}, nil
+			}
+			opts.Watchdog.Restart = true
+		})
+
+		AfterEach(func() {
+			close(release)
+		})
+
+		It("restarts the report loop", func() {
+			tracer.StartSpan("span").Finish()
+
+			stalled := stalledEvent()
+			Expect(stalled.Panic()).To(BeNil())
+			Expect(stalled.Restarted()).To(BeTrue())
+			Expect(stalled.RestartDelay()).To(Equal(opts.MinReportingPeriod))
+			Expect(stalled.Stalled()).To(BeNumerically(">=", 40*time.Millisecond))
+		})
+
+		It("doesn't start more loops while the report blocks", func() {
+			tracer.StartSpan("span").Finish()
+			stalledEvent()
+
+			// Each stall period is 40ms: three reporting periods and the
+			// report timeout.
+			goroutines := runtime.NumGoroutine()
+			Consistently(runtime.NumGoroutine, 500*time.Millisecond, 20*time.Millisecond).Should(
+				BeNumerically("<=", goroutines+2))
+		})
+	})
+})